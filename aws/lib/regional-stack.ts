@@ -91,12 +91,14 @@ export class RegionalStack extends Stack {
         publicS3Bucket.grantPublicAccess();
 
         const integrationTemplateUpload = new s3deploy.BucketDeployment(this, 'UploadIntegrationTemplate', {
-            sources: [
+            // Older revisions remain available so that existing stacks can still be updated or
+            // recreated from them.
+            sources: ['integration-v3.cfn.yaml', 'integration-v4.cfn.yaml'].map((name) =>
                 s3deploy.Source.data(
-                    'integration-v3.cfn.yaml',
-                    fs.readFileSync(path.join(__dirname, '../../frontend/public/integration-v3.cfn.yaml'), 'utf8'),
+                    name,
+                    fs.readFileSync(path.join(__dirname, '../../frontend/public', name), 'utf8'),
                 ),
-            ],
+            ),
             destinationBucket: publicS3Bucket,
             contentType: 'application/yaml',
         });
@@ -196,6 +198,40 @@ export class RegionalStack extends Stack {
                     }),
                 }),
             );
+
+            // Digests are sent after the daily reports have had time to generate.
+            const dailyDigestRule = new events.Rule(this, 'DailyDigestRule', {
+                schedule: events.Schedule.cron({
+                    minute: '0',
+                    hour: '3',
+                }),
+            });
+            dailyDigestRule.addTarget(
+                new events_targets.SqsQueue(queue, {
+                    message: events.RuleTargetInput.fromObject({
+                        QueueTeamDigests: {
+                            Frequency: 'daily',
+                        },
+                    }),
+                }),
+            );
+
+            const weeklyDigestRule = new events.Rule(this, 'WeeklyDigestRule', {
+                schedule: events.Schedule.cron({
+                    minute: '0',
+                    hour: '3',
+                    weekDay: 'MON',
+                }),
+            });
+            weeklyDigestRule.addTarget(
+                new events_targets.SqsQueue(queue, {
+                    message: events.RuleTargetInput.fromObject({
+                        QueueTeamDigests: {
+                            Frequency: 'weekly',
+                        },
+                    }),
+                }),
+            );
         }
 
         const s3BucketDistDomainName = `cdn-${props.env.region}.${props.domainName}`;
@@ -206,6 +242,8 @@ export class RegionalStack extends Stack {
             APP_PASSWORDENCRYPTIONKEY: `secret:arn:aws:secretsmanager:${Aws.REGION}:${Aws.ACCOUNT_ID}:secret:${props.passwordEncryptionKeyBase64SecretName}`,
             APP_FRONTENDURL: `https://${props.domainName}`,
             APP_STORE_DYNAMODB_TABLENAME: props.dynamodbTableName,
+            // The API runs in many Lambda instances, so rate limit counters need to be shared.
+            APP_RATELIMITBACKEND: 'dynamodb',
             APP_EMAIL_SES_FROMADDRESS: `no-reply@${props.domainName}`,
             APP_CLOUDFRONTPRIVATEKEY: `secret:arn:aws:secretsmanager:${Aws.REGION}:${Aws.ACCOUNT_ID}:secret:${props.cloudfrontPrivateKeySecretName}`,
             APP_CLOUDFRONTKEYID: props.cloudfrontPublicKeyId,
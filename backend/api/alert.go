@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Unknown types are mapped to the zero value, which the app rejects as invalid.
+func AlertRuleTypeFromSpec(t apispec.AlertRuleType) model.AlertRuleType {
+	switch t {
+	case apispec.UNEXPECTEDCOUNTRY:
+		return model.AlertRuleTypeUnexpectedCountry
+	case apispec.EVENTPATTERN:
+		return model.AlertRuleTypeEventPattern
+	case apispec.ERRORRATE:
+		return model.AlertRuleTypeErrorRate
+	default:
+		return ""
+	}
+}
+
+func AlertRuleTypeFromModel(t model.AlertRuleType) apispec.AlertRuleType {
+	switch t {
+	case model.AlertRuleTypeUnexpectedCountry:
+		return apispec.UNEXPECTEDCOUNTRY
+	case model.AlertRuleTypeEventPattern:
+		return apispec.EVENTPATTERN
+	case model.AlertRuleTypeErrorRate:
+		return apispec.ERRORRATE
+	default:
+		panic(fmt.Sprintf("unexpected alert rule type: %v", string(t)))
+	}
+}
+
+func AlertRuleFromModel(rule *model.AlertRule) apispec.AlertRule {
+	ret := apispec.AlertRule{
+		Id:           rule.Id.String(),
+		TeamId:       rule.TeamId.String(),
+		CreationTime: rule.CreationTime,
+		Name:         rule.Name,
+		Type:         AlertRuleTypeFromModel(rule.Type),
+		PrincipalKey: nilIfEmpty(rule.PrincipalKey),
+		EventPattern: nilIfEmpty(rule.EventPattern),
+	}
+	if len(rule.AllowedCountryCodes) > 0 {
+		ret.AllowedCountryCodes = &rule.AllowedCountryCodes
+	}
+	if rule.ErrorRateThreshold != 0 {
+		ret.ErrorRateThreshold = pointer(float32(rule.ErrorRateThreshold))
+	}
+	return ret
+}
+
+func AlertFromModel(alert *model.Alert) apispec.Alert {
+	return apispec.Alert{
+		Id:           alert.Id.String(),
+		AlertRuleId:  alert.AlertRuleId.String(),
+		CreationTime: alert.CreationTime,
+		ReportId:     alert.ReportId.String(),
+		PrincipalKey: nilIfEmpty(alert.PrincipalKey),
+		Message:      alert.Message,
+	}
+}
+
+func (api *API) GetAlertRulesByTeamId(ctx context.Context, request apispec.GetAlertRulesByTeamIdRequestObject) (apispec.GetAlertRulesByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if rules, err := sess.GetAlertRulesByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetAlertRulesByTeamId200JSONResponse(mapSlice(rules, AlertRuleFromModel)), nil
+	}
+}
+
+func (api *API) CreateAlertRule(ctx context.Context, request apispec.CreateAlertRuleRequestObject) (apispec.CreateAlertRuleResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	input := app.CreateAlertRuleInput{
+		TeamId:              model.Id(request.TeamId),
+		Name:                request.Body.Name,
+		Type:                AlertRuleTypeFromSpec(request.Body.Type),
+		PrincipalKey:        emptyIfNil(request.Body.PrincipalKey),
+		AllowedCountryCodes: emptyIfNil(request.Body.AllowedCountryCodes),
+		EventPattern:        emptyIfNil(request.Body.EventPattern),
+	}
+	if request.Body.ErrorRateThreshold != nil {
+		input.ErrorRateThreshold = float64(*request.Body.ErrorRateThreshold)
+	}
+
+	if rule, err := sess.CreateAlertRule(ctx, input); err != nil {
+		return nil, err
+	} else {
+		return apispec.CreateAlertRule200JSONResponse(AlertRuleFromModel(rule)), nil
+	}
+}
+
+func (api *API) DeleteAlertRule(ctx context.Context, request apispec.DeleteAlertRuleRequestObject) (apispec.DeleteAlertRuleResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteAlertRuleById(ctx, model.Id(request.AlertRuleId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteAlertRule200JSONResponse{}, nil
+	}
+}
+
+func (api *API) GetAlertsByTeamId(ctx context.Context, request apispec.GetAlertsByTeamIdRequestObject) (apispec.GetAlertsByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if alerts, err := sess.GetAlertsByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetAlertsByTeamId200JSONResponse(mapSlice(alerts, AlertFromModel)), nil
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/rs/cors"
@@ -42,6 +44,11 @@ func (r *statusCodeRecorder) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Allows http.ResponseController to reach the underlying writer, e.g. to flush streamed responses.
+func (r *statusCodeRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func (api *API) httpRequestIPAddress(r *http.Request) string {
 	addr := r.RemoteAddr
 	proxyCount := api.config.ProxyCount
@@ -81,7 +88,8 @@ func New(a *app.App, config Config) *API {
 	cors := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "HEAD", "PATCH", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "Idempotency-Key", "If-None-Match", "Range"},
+		ExposedHeaders: []string{"Content-Disposition", "Content-Range", "ETag", "Idempotent-Replayed", "Retry-After"},
 		MaxAge:         10 * 60,
 	})
 
@@ -89,7 +97,12 @@ func New(a *app.App, config Config) *API {
 		// We put error sanitization both before and after auth to make sure errors always have the
 		// most detailed log fields.
 		ErrorSanitizationMiddleware,
+		// Middleware listed earlier runs later, so rate limits are checked after authentication and
+		// idempotency keys are checked after both.
+		IdempotencyMiddleware,
+		RateLimitMiddleware(ret),
 		AuthMiddleware,
+		IPAddressRateLimitMiddleware(ret),
 		ErrorSanitizationMiddleware,
 		LogOperationIdMiddleware,
 	}, apispec.StrictHTTPServerOptions{
@@ -100,6 +113,11 @@ func New(a *app.App, config Config) *API {
 		},
 		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
 			switch err := err.(type) {
+			case app.RateLimitError:
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+				WriteError(w, apispec.ErrorResponse{
+					Message: err.Error(),
+				}, http.StatusTooManyRequests)
 			case app.NotFoundError:
 				WriteError(w, apispec.ErrorResponse{
 					Message: err.Error(),
@@ -112,6 +130,15 @@ func New(a *app.App, config Config) *API {
 				WriteError(w, apispec.ErrorResponse{
 					Message: err.Error(),
 				}, http.StatusUnauthorized)
+			case app.IdempotentRequestInProgressError:
+				WriteError(w, apispec.ErrorResponse{
+					Message: err.Error(),
+				}, http.StatusConflict)
+			case app.SecondFactorRequiredError:
+				WriteError(w, apispec.ErrorResponse{
+					Message: err.Error(),
+					Code:    pointer("SECOND_FACTOR_REQUIRED"),
+				}, http.StatusBadRequest)
 			case app.InternalError:
 				WriteError(w, apispec.ErrorResponse{
 					Message: err.Error(),
@@ -130,6 +157,7 @@ func New(a *app.App, config Config) *API {
 		},
 	}), apispec.GorillaServerOptions{
 		Middlewares: []apispec.MiddlewareFunc{
+			IdempotencyRecordingMiddleware,
 			AddRequestToContextMiddleware,
 			NoCachingMiddleware,
 			LoggingMiddleware(ret),
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func AuditEventFromModel(event *model.AuditEvent) apispec.AuditEvent {
+	return apispec.AuditEvent{
+		Id:                event.Id.String(),
+		TeamId:            event.TeamId.String(),
+		Time:              event.Time,
+		ActorUserId:       nilIfEmpty(event.ActorUserId.String()),
+		ActorTeamApiKeyId: nilIfEmpty(event.ActorTeamAPIKeyId.String()),
+		IpAddress:         nilIfEmpty(event.IPAddress),
+		Action:            string(event.Action),
+		TargetId:          event.TargetId,
+		Before:            nilIfEmpty(event.Before),
+		After:             nilIfEmpty(event.After),
+	}
+}
+
+func (api *API) GetAuditEventsByTeamId(ctx context.Context, request apispec.GetAuditEventsByTeamIdRequestObject) (apispec.GetAuditEventsByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	params := request.Params
+	input := app.GetAuditEventsInput{
+		TeamId:            model.Id(request.TeamId),
+		StartTime:         emptyIfNil(params.StartTime),
+		EndTime:           emptyIfNil(params.EndTime),
+		ActorUserId:       model.Id(emptyIfNil(params.ActorUserId)),
+		ActorTeamAPIKeyId: model.Id(emptyIfNil(params.ActorTeamApiKeyId)),
+		Action:            model.AuditEventAction(emptyIfNil(params.Action)),
+		TargetId:          emptyIfNil(params.TargetId),
+		PageInput: app.PageInput{
+			Limit:  emptyIfNil(params.Limit),
+			Cursor: emptyIfNil(params.Cursor),
+		},
+	}
+
+	if page, err := sess.GetAuditEventsByTeamId(ctx, input); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetAuditEventsByTeamId200JSONResponse{
+			Items:      mapSlice(page.Items, AuditEventFromModel),
+			NextCursor: nilIfEmpty(page.NextCursor),
+		}, nil
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"context"
+	"maps"
+	"slices"
 
 	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
 	"github.com/ccbrown/cloud-snitch/backend/app"
@@ -28,6 +30,36 @@ func (api *API) GetAWSRegions(ctx context.Context, request apispec.GetAWSRegions
 	return apispec.GetAWSRegions200JSONResponse(ret), nil
 }
 
+func AWSServiceFromApp(eventSource string, service *app.AWSService) apispec.AWSService {
+	return apispec.AWSService{
+		EventSource: eventSource,
+		Namespace:   service.Namespace,
+		Name:        service.Name,
+	}
+}
+
+func (api *API) GetAWSServices(ctx context.Context, request apispec.GetAWSServicesRequestObject) (apispec.GetAWSServicesResponseObject, error) {
+	ret := make([]apispec.AWSService, 0, len(app.KnownAWSServices))
+	for _, eventSource := range slices.Sorted(maps.Keys(app.KnownAWSServices)) {
+		service := app.KnownAWSServices[eventSource]
+		ret = append(ret, AWSServiceFromApp(eventSource, &service))
+	}
+	return apispec.GetAWSServices200JSONResponse(ret), nil
+}
+
+func AWSPolicyTemplateFromModel(template model.AWSPolicyTemplate) apispec.AWSPolicyTemplate {
+	return apispec.AWSPolicyTemplate{
+		Id:          template.Id,
+		Name:        template.Name,
+		Description: template.Description,
+		Content:     template.Content,
+	}
+}
+
+func (api *API) GetAWSRCPTemplates(ctx context.Context, request apispec.GetAWSRCPTemplatesRequestObject) (apispec.GetAWSRCPTemplatesResponseObject, error) {
+	return apispec.GetAWSRCPTemplates200JSONResponse(mapSlice(app.AWSRCPTemplates, AWSPolicyTemplateFromModel)), nil
+}
+
 func (api *API) GetAWSAccountsByTeamId(ctx context.Context, request apispec.GetAWSAccountsByTeamIdRequestObject) (apispec.GetAWSAccountsByTeamIdResponseObject, error) {
 	sess := ctxSession(ctx)
 	teamId := model.Id(request.TeamId)
@@ -38,19 +70,24 @@ func (api *API) GetAWSAccountsByTeamId(ctx context.Context, request apispec.GetA
 		accounts := map[string]*apispec.AWSAccount{}
 		for _, recon := range recons {
 			for _, account := range recon.Accounts {
+				canManagePolicies := recon.CanManagePoliciesForAccount(account.Id)
 				if existing, ok := accounts[account.Id]; ok {
 					if account.Name != "" {
 						existing.Name = &account.Name
 					}
-					if recon.CanManageSCPs {
+					if recon.CanManageSCPs && canManagePolicies {
 						existing.CanManageScps = true
 					}
+					if recon.CanManageRCPs && canManagePolicies {
+						existing.CanManageRcps = true
+					}
 					existing.IntegrationIds = append(existing.IntegrationIds, recon.AWSIntegrationId.String())
 				} else {
 					accounts[account.Id] = &apispec.AWSAccount{
 						Id:             account.Id,
 						Name:           nilIfEmpty(account.Name),
-						CanManageScps:  recon.CanManageSCPs,
+						CanManageScps:  recon.CanManageSCPs && canManagePolicies,
+						CanManageRcps:  recon.CanManageRCPs && canManagePolicies,
 						IntegrationIds: []string{recon.AWSIntegrationId.String()},
 					}
 				}
@@ -63,3 +100,31 @@ func (api *API) GetAWSAccountsByTeamId(ctx context.Context, request apispec.GetA
 		return apispec.GetAWSAccountsByTeamId200JSONResponse(ret), nil
 	}
 }
+
+func GeneratedAWSIAMPolicyFromModel(policy *model.GeneratedAWSIAMPolicy) apispec.GeneratedAWSIAMPolicy {
+	return apispec.GeneratedAWSIAMPolicy{
+		PrincipalArn: policy.PrincipalARN,
+		Content:      policy.Content,
+		Actions:      policy.Actions,
+		ReportCount:  policy.ReportCount,
+		IsIncomplete: policy.IsIncomplete,
+	}
+}
+
+func (api *API) GenerateAWSIAMPolicy(ctx context.Context, request apispec.GenerateAWSIAMPolicyRequestObject) (apispec.GenerateAWSIAMPolicyResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if policy, err := sess.GenerateAWSIAMPolicy(ctx, app.GenerateAWSIAMPolicyInput{
+		TeamId:              model.Id(request.TeamId),
+		PrincipalARN:        request.Params.PrincipalArn,
+		StartTime:           request.Params.StartTime,
+		EndTime:             request.Params.EndTime,
+		UseServiceWildcards: emptyIfNil(request.Params.UseServiceWildcards),
+	}); err != nil {
+		return nil, err
+	} else if policy == nil {
+		return nil, app.NotFoundError("No activity found for the principal.")
+	} else {
+		return apispec.GenerateAWSIAMPolicy200JSONResponse(GeneratedAWSIAMPolicyFromModel(policy)), nil
+	}
+}
@@ -18,6 +18,7 @@ func AWSIntegrationFromModel(integration *model.AWSIntegration) apispec.AWSInteg
 		RoleArn:                          integration.RoleARN,
 		GetAccountNamesFromOrganizations: integration.GetAccountNamesFromOrganizations,
 		ManageScps:                       integration.ManageSCPs,
+		ManageRcps:                       integration.ManageRCPs,
 	}
 	if trail := integration.CloudTrailTrail; trail != nil {
 		ret.CloudtrailTrail = &apispec.AWSIntegrationCloudTrailTrail{
@@ -81,6 +82,9 @@ func (api *API) CreateAWSIntegration(ctx context.Context, request apispec.Create
 	if request.Body.ManageScps != nil {
 		input.ManageSCPs = *request.Body.ManageScps
 	}
+	if request.Body.ManageRcps != nil {
+		input.ManageRCPs = *request.Body.ManageRcps
+	}
 	if trail := request.Body.CloudtrailTrail; trail != nil {
 		input.CloudTrailTrail = &app.CreateAWSIntegrationCloudTrailTrailInput{
 			S3BucketName: trail.S3BucketName,
@@ -143,6 +147,38 @@ func (api *API) PutManagedAWSSCP(ctx context.Context, request apispec.PutManaged
 	}
 }
 
+func AWSRCPFromModel(rcp *model.AWSRCP) apispec.AWSRCP {
+	return apispec.AWSRCP{
+		Content: rcp.Content,
+	}
+}
+
+func (api *API) GetManagedAWSRCP(ctx context.Context, request apispec.GetManagedAWSRCPRequestObject) (apispec.GetManagedAWSRCPResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if rcp, err := sess.GetManagedAWSRCPByTeamAndAccountId(ctx, model.Id(request.TeamId), request.AccountId); err != nil {
+		return nil, err
+	} else if rcp == nil {
+		return nil, app.NotFoundError("No such RCP.")
+	} else {
+		return apispec.GetManagedAWSRCP200JSONResponse(AWSRCPFromModel(rcp)), nil
+	}
+}
+
+func (api *API) PutManagedAWSRCP(ctx context.Context, request apispec.PutManagedAWSRCPRequestObject) (apispec.PutManagedAWSRCPResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if rcp, err := sess.PutManagedAWSRCPByTeamAndAccountId(ctx, model.Id(request.TeamId), request.AccountId, app.PutManagedAWSRCPInput{
+		Content: request.Body.Content,
+	}); err != nil {
+		return nil, err
+	} else if rcp == nil {
+		return nil, app.NotFoundError("No such account.")
+	} else {
+		return apispec.PutManagedAWSRCP200JSONResponse(AWSRCPFromModel(rcp)), nil
+	}
+}
+
 func AWSAccessReportFromModel(report *model.AWSAccessReport) apispec.AWSAccessReport {
 	ret := apispec.AWSAccessReport{
 		Services: make([]apispec.AWSAccessReportService, 0, len(report.Services)),
@@ -166,8 +202,60 @@ func (api *API) GetAWSAccessReport(ctx context.Context, request apispec.GetAWSAc
 	if report, err := sess.GetAWSAccessReportByTeamAndAccountId(ctx, model.Id(request.TeamId), request.AccountId); err != nil {
 		return nil, err
 	} else if report == nil {
-		return nil, app.NotFoundError("No such account.")
+		return nil, app.NotFoundError("No access report is available.")
 	} else {
 		return apispec.GetAWSAccessReport200JSONResponse(AWSAccessReportFromModel(report)), nil
 	}
 }
+
+// Job records are persisted, so we may encounter statuses written by other versions of the app.
+// Anything unrecognized is reported as failed rather than crashing the request.
+func AWSAccessReportJobStatusFromModel(status model.AWSAccessReportJobStatus) apispec.AWSAccessReportJobStatus {
+	switch status {
+	case model.AWSAccessReportJobStatusInProgress:
+		return apispec.INPROGRESS
+	case model.AWSAccessReportJobStatusCompleted:
+		return apispec.COMPLETED
+	default:
+		return apispec.FAILED
+	}
+}
+
+func AWSAccessReportJobFromModel(job *model.AWSAccessReportJob) apispec.AWSAccessReportJob {
+	ret := apispec.AWSAccessReportJob{
+		Status:       AWSAccessReportJobStatusFromModel(job.Status),
+		CreationTime: job.CreationTime,
+		ErrorMessage: nilIfEmpty(job.ErrorMessage),
+	}
+	if !job.CompletionTime.IsZero() {
+		ret.CompletionTime = &job.CompletionTime
+	}
+	if job.Report != nil {
+		ret.Report = pointer(AWSAccessReportFromModel(job.Report))
+	}
+	return ret
+}
+
+func (api *API) QueueAWSAccessReportGeneration(ctx context.Context, request apispec.QueueAWSAccessReportGenerationRequestObject) (apispec.QueueAWSAccessReportGenerationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if job, err := sess.QueueAWSAccessReportGenerationByTeamAndAccountId(ctx, model.Id(request.TeamId), request.AccountId); err != nil {
+		return nil, err
+	} else if job == nil {
+		return nil, app.NotFoundError("No such account.")
+	} else {
+		return apispec.QueueAWSAccessReportGeneration200JSONResponse(AWSAccessReportJobFromModel(job)), nil
+	}
+}
+
+func (api *API) GetAWSAccessReportJob(ctx context.Context, request apispec.GetAWSAccessReportJobRequestObject) (apispec.GetAWSAccessReportJobResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if job, err := sess.GetAWSAccessReportJobByTeamAndAccountId(ctx, model.Id(request.TeamId), request.AccountId); err != nil {
+		return nil, err
+	} else if job == nil {
+		return nil, app.NotFoundError("No such job.")
+	} else {
+		return apispec.GetAWSAccessReportJob200JSONResponse(AWSAccessReportJobFromModel(job)), nil
+	}
+}
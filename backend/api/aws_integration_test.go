@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -207,12 +208,241 @@ func TestAPI_AWSIntegration_SCP_Management(t *testing.T) {
 	})
 
 	t.Run("AccessReport", func(t *testing.T) {
-		resp, err := api.GetAWSAccessReport(aliceCtx, apispec.GetAWSAccessReportRequestObject{
+		_, err := api.GetAWSAccessReport(aliceCtx, apispec.GetAWSAccessReportRequestObject{
 			TeamId:    team.Id.String(),
 			AccountId: "123456789012",
 		})
+		require.Error(t, err)
+
+		{
+			resp, err := api.QueueAWSAccessReportGeneration(aliceCtx, apispec.QueueAWSAccessReportGenerationRequestObject{
+				TeamId:    team.Id.String(),
+				AccountId: "123456789012",
+			})
+			require.NoError(t, err)
+			job := resp.(apispec.QueueAWSAccessReportGeneration200JSONResponse)
+			assert.Equal(t, apispec.INPROGRESS, job.Status)
+			assert.Nil(t, job.Report)
+		}
+
+		require.NoError(t, api.app.PollAWSAccessReportJob(context.Background(), app.PollAWSAccessReportJobInput{
+			TeamId:    team.Id,
+			AccountId: "123456789012",
+			IAMJobId:  "job-id",
+		}))
+
+		t.Run("Job", func(t *testing.T) {
+			resp, err := api.GetAWSAccessReportJob(aliceCtx, apispec.GetAWSAccessReportJobRequestObject{
+				TeamId:    team.Id.String(),
+				AccountId: "123456789012",
+			})
+			require.NoError(t, err)
+			job := resp.(apispec.GetAWSAccessReportJob200JSONResponse)
+			assert.Equal(t, apispec.COMPLETED, job.Status)
+			require.NotNil(t, job.Report)
+			assert.NotEmpty(t, job.Report.Services)
+		})
+
+		t.Run("Report", func(t *testing.T) {
+			resp, err := api.GetAWSAccessReport(aliceCtx, apispec.GetAWSAccessReportRequestObject{
+				TeamId:    team.Id.String(),
+				AccountId: "123456789012",
+			})
+			require.NoError(t, err)
+			report := resp.(apispec.GetAWSAccessReport200JSONResponse)
+			assert.NotEmpty(t, report.Services)
+		})
+
+		t.Run("Cached", func(t *testing.T) {
+			resp, err := api.QueueAWSAccessReportGeneration(aliceCtx, apispec.QueueAWSAccessReportGenerationRequestObject{
+				TeamId:    team.Id.String(),
+				AccountId: "123456789012",
+			})
+			require.NoError(t, err)
+			job := resp.(apispec.QueueAWSAccessReportGeneration200JSONResponse)
+			assert.Equal(t, apispec.COMPLETED, job.Status)
+		})
+	})
+}
+
+func TestAPI_AWSIntegration_RCP_Management(t *testing.T) {
+	api := NewTestAPI(t)
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := api.NewTestTeamWithSubscription(aliceCtx, app.TeamSubscriptionTierIndividual)
+
+	t.Run("CreateWithoutOrganizations", func(t *testing.T) {
+		_, err := api.CreateAWSIntegration(aliceCtx, apispec.CreateAWSIntegrationRequestObject{
+			TeamId: team.Id.String(),
+			Body: &apispec.CreateAWSIntegrationJSONRequestBody{
+				Name:       "Foo",
+				RoleArn:    "arn:aws:iam::123456789012:role/MyRole",
+				ManageRcps: pointer(true),
+			},
+		})
+		assert.Error(t, err)
+	})
+
+	{
+		resp, err := api.CreateAWSIntegration(aliceCtx, apispec.CreateAWSIntegrationRequestObject{
+			TeamId: team.Id.String(),
+			Body: &apispec.CreateAWSIntegrationJSONRequestBody{
+				Name:                             "Foo",
+				RoleArn:                          "arn:aws:iam::123456789012:role/MyRole",
+				GetAccountNamesFromOrganizations: pointer(true),
+				ManageRcps:                       pointer(true),
+			},
+		})
+		require.NoError(t, err)
+		integration := resp.(apispec.CreateAWSIntegration200JSONResponse)
+		assert.True(t, integration.ManageRcps)
+		assert.False(t, integration.ManageScps)
+	}
+
+	t.Run("AWSAccounts", func(t *testing.T) {
+		resp, err := api.GetAWSAccountsByTeamId(aliceCtx, apispec.GetAWSAccountsByTeamIdRequestObject{
+			TeamId: team.Id.String(),
+		})
 		require.NoError(t, err)
-		report := resp.(apispec.GetAWSAccessReport200JSONResponse)
-		assert.NotEmpty(t, report.Services)
+		accounts := resp.(apispec.GetAWSAccountsByTeamId200JSONResponse)
+		require.Len(t, accounts, 2)
+		for _, account := range accounts {
+			assert.True(t, account.CanManageRcps)
+			assert.False(t, account.CanManageScps)
+		}
 	})
+
+	t.Run("NoRCP", func(t *testing.T) {
+		_, err := api.GetManagedAWSRCP(aliceCtx, apispec.GetManagedAWSRCPRequestObject{
+			TeamId:    team.Id.String(),
+			AccountId: "123456789012",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("SCPManagementDisabled", func(t *testing.T) {
+		_, err := api.PutManagedAWSSCP(aliceCtx, apispec.PutManagedAWSSCPRequestObject{
+			TeamId:    team.Id.String(),
+			AccountId: "123456789012",
+			Body: &apispec.PutManagedAWSSCPJSONRequestBody{
+				Content: "foo",
+			},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("CreateRCP", func(t *testing.T) {
+		resp, err := api.PutManagedAWSRCP(aliceCtx, apispec.PutManagedAWSRCPRequestObject{
+			TeamId:    team.Id.String(),
+			AccountId: "123456789012",
+			Body: &apispec.PutManagedAWSRCPJSONRequestBody{
+				Content: "foo",
+			},
+		})
+		require.NoError(t, err)
+		rcp := resp.(apispec.PutManagedAWSRCP200JSONResponse)
+		assert.Equal(t, "foo", rcp.Content)
+
+		t.Run("UpdateRCP", func(t *testing.T) {
+			resp, err := api.PutManagedAWSRCP(aliceCtx, apispec.PutManagedAWSRCPRequestObject{
+				TeamId:    team.Id.String(),
+				AccountId: "123456789012",
+				Body: &apispec.PutManagedAWSRCPJSONRequestBody{
+					Content: "bar",
+				},
+			})
+			require.NoError(t, err)
+			rcp := resp.(apispec.PutManagedAWSRCP200JSONResponse)
+			assert.Equal(t, "bar", rcp.Content)
+
+			t.Run("GetRCP", func(t *testing.T) {
+				resp, err := api.GetManagedAWSRCP(aliceCtx, apispec.GetManagedAWSRCPRequestObject{
+					TeamId:    team.Id.String(),
+					AccountId: "123456789012",
+				})
+				require.NoError(t, err)
+				rcp := resp.(apispec.GetManagedAWSRCP200JSONResponse)
+				assert.Equal(t, "bar", rcp.Content)
+			})
+		})
+	})
+
+	t.Run("Templates", func(t *testing.T) {
+		resp, err := api.GetAWSRCPTemplates(aliceCtx, apispec.GetAWSRCPTemplatesRequestObject{})
+		require.NoError(t, err)
+		templates := resp.(apispec.GetAWSRCPTemplates200JSONResponse)
+		assert.NotEmpty(t, templates)
+	})
+}
+
+func TestAPI_AWSIntegration_SCP_Management_DelegatedAdministrator(t *testing.T) {
+	api := NewTestAPI(t)
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := api.NewTestTeamWithSubscription(aliceCtx, app.TeamSubscriptionTierIndividual)
+
+	{
+		_, err := api.CreateAWSIntegration(aliceCtx, apispec.CreateAWSIntegrationRequestObject{
+			TeamId: team.Id.String(),
+			Body: &apispec.CreateAWSIntegrationJSONRequestBody{
+				Name:                             "Foo",
+				RoleArn:                          "arn:aws:iam::210987654321:role/MyRole",
+				GetAccountNamesFromOrganizations: pointer(true),
+				ManageScps:                       pointer(true),
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("AWSAccounts", func(t *testing.T) {
+		resp, err := api.GetAWSAccountsByTeamId(aliceCtx, apispec.GetAWSAccountsByTeamIdRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+		accounts := resp.(apispec.GetAWSAccountsByTeamId200JSONResponse)
+		require.Len(t, accounts, 2)
+		for _, account := range accounts {
+			// The management account can't be managed by a delegated administrator.
+			assert.Equal(t, account.Id != "123456789012", account.CanManageScps)
+		}
+	})
+
+	t.Run("ManagementAccount", func(t *testing.T) {
+		_, err := api.PutManagedAWSSCP(aliceCtx, apispec.PutManagedAWSSCPRequestObject{
+			TeamId:    team.Id.String(),
+			AccountId: "123456789012",
+			Body: &apispec.PutManagedAWSSCPJSONRequestBody{
+				Content: "foo",
+			},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("MemberAccount", func(t *testing.T) {
+		resp, err := api.PutManagedAWSSCP(aliceCtx, apispec.PutManagedAWSSCPRequestObject{
+			TeamId:    team.Id.String(),
+			AccountId: "210987654321",
+			Body: &apispec.PutManagedAWSSCPJSONRequestBody{
+				Content: "foo",
+			},
+		})
+		require.NoError(t, err)
+		scp := resp.(apispec.PutManagedAWSSCP200JSONResponse)
+		assert.Equal(t, "foo", scp.Content)
+
+		t.Run("GetSCP", func(t *testing.T) {
+			resp, err := api.GetManagedAWSSCP(aliceCtx, apispec.GetManagedAWSSCPRequestObject{
+				TeamId:    team.Id.String(),
+				AccountId: "210987654321",
+			})
+			require.NoError(t, err)
+			scp := resp.(apispec.GetManagedAWSSCP200JSONResponse)
+			assert.Equal(t, "foo", scp.Content)
+		})
+	})
+}
+
+func TestAWSAccessReportJobStatusFromModel(t *testing.T) {
+	assert.Equal(t, apispec.COMPLETED, AWSAccessReportJobStatusFromModel(model.AWSAccessReportJobStatusCompleted))
+	assert.Equal(t, apispec.FAILED, AWSAccessReportJobStatusFromModel(model.AWSAccessReportJobStatus("bogus")))
 }
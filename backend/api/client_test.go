@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/client"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestClient(t *testing.T) {
+	api := NewTestAPI(t)
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, ctx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	sess := ctxSession(ctx)
+	team := api.NewTestTeamWithSubscription(ctx, app.TeamSubscriptionTierIndividual)
+
+	_, err := sess.CreateAWSIntegration(ctx, app.CreateAWSIntegrationInput{
+		TeamId:  team.Id,
+		Name:    "My Integration",
+		RoleARN: "arn:aws:iam::123456789012:role/MyRole",
+		CloudTrailTrail: &app.CreateAWSIntegrationCloudTrailTrailInput{
+			S3BucketName: "aws-cloudtrail-logs",
+		},
+	})
+	require.NoError(t, err)
+
+	_, secret, err := sess.CreateTeamAPIKey(ctx, app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "CI",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeManageIntegrations},
+	})
+	require.NoError(t, err)
+
+	t.Run("Authenticated", func(t *testing.T) {
+		c, err := client.New(server.URL, secret)
+		require.NoError(t, err)
+
+		resp, err := c.GetAWSIntegrationsByTeamIdWithResponse(context.Background(), team.Id.String())
+		require.NoError(t, err)
+		require.NoError(t, client.CheckResponse(resp.HTTPResponse, resp.Body))
+		require.NotNil(t, resp.JSON200)
+		require.Len(t, *resp.JSON200, 1)
+		assert.Equal(t, "My Integration", (*resp.JSON200)[0].Name)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		c, err := client.New(server.URL, "ak-bogus.bogus")
+		require.NoError(t, err)
+
+		resp, err := c.GetAWSIntegrationsByTeamIdWithResponse(context.Background(), team.Id.String())
+		require.NoError(t, err)
+
+		var responseErr *client.ResponseError
+		require.ErrorAs(t, client.CheckResponse(resp.HTTPResponse, resp.Body), &responseErr)
+		assert.Equal(t, http.StatusUnauthorized, responseErr.StatusCode)
+	})
+}
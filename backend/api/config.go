@@ -8,4 +8,7 @@ type Config struct {
 	// If set, X-Forwarded-For headers are ignored unless there is also a "Proxy-Secret" header with this
 	// value.
 	ProxySecret string
+
+	// If true, requests are not rate limited. This should only be used for testing.
+	DisableRateLimiting bool
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Records the response while passing it through to the client.
+type responseRecorder struct {
+	http.ResponseWriter
+	StatusCode int
+	Body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.StatusCode == 0 {
+		r.StatusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.StatusCode == 0 {
+		r.StatusCode = http.StatusOK
+	}
+	r.Body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+type idempotencyContextKey struct{}
+
+// The state of a request with an Idempotency-Key header, shared between the two idempotency
+// middlewares.
+type idempotentRequestState struct {
+	Key  string
+	Body []byte
+
+	// Set by IdempotencyMiddleware once the request has begun and its response should be saved.
+	Request *model.IdempotentRequest
+}
+
+// This middleware makes authenticated POST and PUT requests with an Idempotency-Key header safe to
+// retry. It buffers the request body and records the response so that IdempotencyMiddleware,
+// which runs after authentication, can save it. It must run after LoggingMiddleware, which creates
+// the session.
+func IdempotencyRecordingMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPut) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		sess := ctxSession(r.Context())
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			WriteError(w, apispec.ErrorResponse{
+				Message: "Unable to read request body.",
+			}, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		state := &idempotentRequestState{
+			Key:  key,
+			Body: body,
+		}
+		r = r.WithContext(context.WithValue(r.Context(), idempotencyContextKey{}, state))
+
+		recorder := &responseRecorder{
+			ResponseWriter: w,
+		}
+		defer func() {
+			req := state.Request
+			if req == nil {
+				return
+			}
+
+			// Make sure the outcome is saved even if the client has gone away. Otherwise retries
+			// would be rejected until the request expires.
+			ctx := context.WithoutCancel(r.Context())
+
+			statusCode := recorder.StatusCode
+			if p := recover(); p != nil {
+				sess.AbandonIdempotentRequest(ctx, req)
+				panic(p)
+			} else if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			if err := sess.CompleteIdempotentRequest(ctx, req, statusCode, w.Header().Get("Content-Type"), recorder.Body.Bytes()); err != nil {
+				sess.Logger().Warn("unable to save idempotent request: " + err.Error())
+			}
+		}()
+		h.ServeHTTP(recorder, r)
+	})
+}
+
+// This middleware begins requests recorded by IdempotencyRecordingMiddleware, replaying the saved
+// response if there is one. It must run after authentication and rate limiting so that keys are
+// scoped to the authenticated principal and replays are counted.
+func IdempotencyMiddleware(f apispec.StrictHandlerFunc, operationID string) apispec.StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, args any) (any, error) {
+		state, _ := ctx.Value(idempotencyContextKey{}).(*idempotentRequestState)
+		sess := ctxSession(ctx)
+		if state == nil || (sess.User() == nil && sess.TeamAPIKey() == nil) {
+			return f(ctx, w, r, args)
+		}
+
+		req, err := sess.BeginIdempotentRequest(ctx, app.BeginIdempotentRequestInput{
+			Key:    state.Key,
+			Method: r.Method,
+			Path:   r.URL.RequestURI(),
+			Body:   state.Body,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if req.IsComplete() {
+			if req.ResponseContentType != "" {
+				w.Header().Set("Content-Type", req.ResponseContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(req.ResponseStatusCode)
+			w.Write(sess.IdempotentRequestResponseBody(req))
+			// The response has already been written.
+			return nil, nil
+		}
+
+		state.Request = req
+		return f(ctx, w, r, args)
+	}
+}
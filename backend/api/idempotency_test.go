@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/client"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	api := NewTestAPI(t)
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, ctx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	sess := ctxSession(ctx)
+	team := api.NewTestTeamWithSubscription(ctx, app.TeamSubscriptionTierIndividual)
+
+	var err error
+
+	_, secret, err := sess.CreateTeamAPIKey(ctx, app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "CI",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeManageIntegrations},
+	})
+	require.NoError(t, err)
+
+	c, err := client.New(server.URL, secret)
+	require.NoError(t, err)
+
+	_, otherSecret, err := sess.CreateTeamAPIKey(ctx, app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "Other CI",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeManageIntegrations},
+	})
+	require.NoError(t, err)
+	otherClient, err := client.New(server.URL, otherSecret)
+	require.NoError(t, err)
+
+	withIdempotencyKey := func(key string) client.RequestEditorFn {
+		return func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Idempotency-Key", key)
+			return nil
+		}
+	}
+
+	createIntegrationWithClient := func(c *client.ClientWithResponses, name, bucket, key string) *client.CreateAWSIntegrationResponse {
+		resp, err := c.CreateAWSIntegrationWithResponse(context.Background(), team.Id.String(), client.CreateAWSIntegrationJSONRequestBody{
+			Name:    name,
+			RoleArn: "arn:aws:iam::123456789012:role/MyRole",
+			CloudtrailTrail: &client.CreateAWSIntegrationCloudTrailTrailInput{
+				S3BucketName: bucket,
+			},
+		}, withIdempotencyKey(key))
+		require.NoError(t, err)
+		return resp
+	}
+	createIntegration := func(name, bucket, key string) *client.CreateAWSIntegrationResponse {
+		return createIntegrationWithClient(c, name, bucket, key)
+	}
+
+	first := createIntegration("My Integration", "aws-cloudtrail-logs", "foo")
+	require.NoError(t, client.CheckResponse(first.HTTPResponse, first.Body))
+	assert.Empty(t, first.HTTPResponse.Header.Get("Idempotent-Replayed"))
+
+	t.Run("Replay", func(t *testing.T) {
+		resp := createIntegration("My Integration", "aws-cloudtrail-logs", "foo")
+		require.NoError(t, client.CheckResponse(resp.HTTPResponse, resp.Body))
+		assert.Equal(t, "true", resp.HTTPResponse.Header.Get("Idempotent-Replayed"))
+		assert.Equal(t, first.JSON200.Id, resp.JSON200.Id)
+
+		integrations, err := sess.GetAWSIntegrationsByTeamId(ctx, team.Id)
+		require.NoError(t, err)
+		assert.Len(t, integrations, 1)
+	})
+
+	t.Run("DifferentRequest", func(t *testing.T) {
+		resp := createIntegration("Other Integration", "other-cloudtrail-logs", "foo")
+		var responseErr *client.ResponseError
+		require.ErrorAs(t, client.CheckResponse(resp.HTTPResponse, resp.Body), &responseErr)
+		assert.Equal(t, http.StatusBadRequest, responseErr.StatusCode)
+	})
+
+	t.Run("DifferentKey", func(t *testing.T) {
+		resp := createIntegration("Other Integration", "other-cloudtrail-logs", "bar")
+		require.NoError(t, client.CheckResponse(resp.HTTPResponse, resp.Body))
+		assert.NotEqual(t, first.JSON200.Id, resp.JSON200.Id)
+	})
+
+	t.Run("DifferentPrincipal", func(t *testing.T) {
+		// Keys are scoped to the authenticated principal, so other clients can't see the response.
+		resp := createIntegrationWithClient(otherClient, "My Integration", "aws-cloudtrail-logs", "foo")
+		require.NoError(t, client.CheckResponse(resp.HTTPResponse, resp.Body))
+		assert.Empty(t, resp.HTTPResponse.Header.Get("Idempotent-Replayed"))
+		assert.NotEqual(t, first.JSON200.Id, resp.JSON200.Id)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		invalidClient, err := client.New(server.URL, "invalid")
+		require.NoError(t, err)
+		resp := createIntegrationWithClient(invalidClient, "My Integration", "aws-cloudtrail-logs", "foo")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+		assert.Empty(t, resp.HTTPResponse.Header.Get("Idempotent-Replayed"))
+	})
+}
@@ -51,7 +51,10 @@ func LoggingMiddleware(api *API) func(h http.Handler) http.Handler {
 			requestId := model.NewId("req").String()
 			sess = sess.WithLogFields(zap.String("request_id", requestId))
 			if remote := api.httpRequestIPAddress(r); remote != "" {
-				sess = sess.WithLogFields(zap.String("remote", remote))
+				sess = sess.WithLogFields(zap.String("remote", remote)).WithIPAddress(remote)
+			}
+			if userAgent := r.UserAgent(); userAgent != "" {
+				sess = sess.WithUserAgent(userAgent)
 			}
 
 			var endOfRequestLogFields []zap.Field
@@ -115,8 +118,21 @@ func AuthMiddleware(f apispec.StrictHandlerFunc, operationID string) apispec.Str
 			sess := ctxSession(ctx)
 
 			parts := strings.SplitN(auth, " ", 2)
-			if len(parts) != 2 || parts[0] != "token" {
+			if len(parts) != 2 || (parts[0] != "token" && !strings.EqualFold(parts[0], "bearer")) {
+				// The bearer scheme is accepted too since that's what SCIM clients use.
 				return nil, app.AuthenticationError{}
+			} else if app.IsTeamAPIKeySecret(parts[1]) {
+				newSess, err := sess.WithTeamAPIKey(ctx, parts[1])
+				if err != nil {
+					return nil, err
+				} else if newSess == nil {
+					return nil, app.AuthenticationError{}
+				}
+				// Make sure we log the key id in the request log too.
+				ctx = context.WithValue(ctx, endOfRequestLogFieldsContextKey, append([]zap.Field{
+					zap.String("team_api_key_id", newSess.TeamAPIKey().Id.String()),
+				}, ctx.Value(endOfRequestLogFieldsContextKey).([]zap.Field)...))
+				ctx = context.WithValue(ctx, sessionContextKey, newSess)
 			} else if token, err := base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
 				return nil, app.AuthenticationError{}
 			} else {
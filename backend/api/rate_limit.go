@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+)
+
+type rateLimitKey int
+
+const (
+	// Requests are counted per IP address.
+	rateLimitKeyIPAddress rateLimitKey = iota
+
+	// Requests are counted per user or API key, falling back to the IP address for anonymous
+	// requests.
+	rateLimitKeyPrincipal
+)
+
+type rateLimitPolicy struct {
+	Key   rateLimitKey
+	Limit app.RateLimit
+}
+
+// Operations that are particularly expensive or abusable (e.g. because they send emails) get their
+// own policies in addition to the default ones. Policies are keyed by the operation ids passed to
+// middleware by the generated handlers, which are capitalized.
+var operationRateLimitPolicies = map[string]rateLimitPolicy{
+	"Authenticate": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 30, Period: time.Hour},
+	},
+	"BeginUserRegistration": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 10, Period: time.Hour},
+	},
+	"BeginUserEmailAuthentication": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 10, Period: time.Hour},
+	},
+	"BeginUserOIDCAuthentication": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 30, Period: time.Hour},
+	},
+	"BeginUserSAMLAuthentication": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 30, Period: time.Hour},
+	},
+	"CompleteTeamSAMLAuthentication": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 30, Period: time.Hour},
+	},
+	"ContactUs": {
+		Key:   rateLimitKeyIPAddress,
+		Limit: app.RateLimit{Limit: 5, Period: time.Hour},
+	},
+	"CreateTeamInvite": {
+		Key:   rateLimitKeyPrincipal,
+		Limit: app.RateLimit{Limit: 50, Period: time.Hour},
+	},
+}
+
+var (
+	defaultAuthenticatedRateLimit = app.RateLimit{Limit: 1200, Period: time.Minute}
+	defaultAnonymousRateLimit     = app.RateLimit{Limit: 300, Period: time.Minute}
+
+	// Applied to every request before authentication, so that requests with invalid credentials
+	// are limited too. It's as high as the authenticated limit so that it doesn't get in the way
+	// of legitimate clients.
+	defaultIPAddressRateLimit = app.RateLimit{Limit: 1200, Period: time.Minute}
+)
+
+func rateLimitPrincipalKey(sess *app.Session) string {
+	if user := sess.User(); user != nil {
+		return "user:" + user.Id.String()
+	} else if key := sess.TeamAPIKey(); key != nil {
+		return "team_api_key:" + key.Id.String()
+	}
+	return ""
+}
+
+// This middleware enforces per-IP address rate limits. It must run before authentication, since
+// requests that fail authentication never reach RateLimitMiddleware.
+func IPAddressRateLimitMiddleware(api *API) apispec.StrictMiddlewareFunc {
+	return func(f apispec.StrictHandlerFunc, operationID string) apispec.StrictHandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request, args any) (any, error) {
+			if api.config.DisableRateLimiting {
+				return f(ctx, w, r, args)
+			}
+
+			sess := ctxSession(ctx)
+			if err := sess.CheckRateLimit(ctx, "unauthenticated:ip:"+sess.IPAddress(), defaultIPAddressRateLimit); err != nil {
+				return nil, err
+			}
+			return f(ctx, w, r, args)
+		}
+	}
+}
+
+// This middleware enforces rate limits. It must run after authentication so that authenticated
+// requests can be counted per principal.
+func RateLimitMiddleware(api *API) apispec.StrictMiddlewareFunc {
+	return func(f apispec.StrictHandlerFunc, operationID string) apispec.StrictHandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request, args any) (any, error) {
+			if api.config.DisableRateLimiting {
+				return f(ctx, w, r, args)
+			}
+
+			sess := ctxSession(ctx)
+			principalKey := rateLimitPrincipalKey(sess)
+			ipAddressKey := "ip:" + sess.IPAddress()
+
+			if principalKey != "" {
+				if err := sess.CheckRateLimit(ctx, principalKey, defaultAuthenticatedRateLimit); err != nil {
+					return nil, err
+				}
+			} else if err := sess.CheckRateLimit(ctx, ipAddressKey, defaultAnonymousRateLimit); err != nil {
+				return nil, err
+			}
+
+			if policy, ok := operationRateLimitPolicies[operationID]; ok {
+				key := ipAddressKey
+				if policy.Key == rateLimitKeyPrincipal && principalKey != "" {
+					key = principalKey
+				}
+				if err := sess.CheckRateLimit(ctx, operationID+":"+key, policy.Limit); err != nil {
+					return nil, err
+				}
+			}
+
+			return f(ctx, w, r, args)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+)
+
+func TestOperationRateLimitPolicies(t *testing.T) {
+	serverType := reflect.TypeFor[apispec.StrictServerInterface]()
+	for operationID := range operationRateLimitPolicies {
+		_, ok := serverType.MethodByName(operationID)
+		assert.True(t, ok, "no such operation: %v", operationID)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	api := NewTestAPI(t)
+
+	contactUs := func(remoteAddr string) *http.Response {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("POST", "/contact-us", strings.NewReader(`{"emailAddress":"alice@example.com","name":"Alice","subject":"Hi","message":"Hello!"}`))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/json")
+		r.RemoteAddr = remoteAddr
+		api.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	limit := operationRateLimitPolicies["ContactUs"].Limit.Limit
+	for i := 0; i < limit; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, contactUs("192.0.2.1:1234").StatusCode)
+	}
+
+	resp := contactUs("192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	// Other IP addresses are unaffected.
+	assert.NotEqual(t, http.StatusTooManyRequests, contactUs("192.0.2.2:1234").StatusCode)
+}
+
+func TestIPAddressRateLimitMiddleware(t *testing.T) {
+	api := NewTestAPI(t)
+
+	getUser := func() *http.Response {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/users/self", nil)
+		require.NoError(t, err)
+		r.Header.Set("Authorization", "token invalid")
+		r.RemoteAddr = "192.0.2.1:1234"
+		api.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	// Requests that fail authentication are still counted. The window may reset once along the way,
+	// so allow for up to two windows' worth of requests.
+	limited := false
+	for i := 0; i <= 2*defaultIPAddressRateLimit.Limit && !limited; i++ {
+		status := getUser().StatusCode
+		if status == http.StatusTooManyRequests {
+			limited = true
+		} else {
+			require.Equal(t, http.StatusUnauthorized, status)
+		}
+	}
+	assert.True(t, limited)
+}
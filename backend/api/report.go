@@ -1,10 +1,16 @@
 package api
 
 import (
+	"compress/gzip"
 	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
 	"github.com/ccbrown/cloud-snitch/backend/model"
 )
 
@@ -52,10 +58,24 @@ func (api *API) GetReportsByTeamId(ctx context.Context, request apispec.GetRepor
 	sess := ctxSession(ctx)
 	teamId := model.Id(request.TeamId)
 
-	if reports, err := sess.GetReportsByTeamId(ctx, teamId); err != nil {
+	params := request.Params
+	input := app.GetReportsByTeamIdInput{
+		TeamId:    teamId,
+		StartTime: emptyIfNil(params.StartTime),
+		EndTime:   emptyIfNil(params.EndTime),
+		PageInput: app.PageInput{
+			Limit:  emptyIfNil(params.Limit),
+			Cursor: emptyIfNil(params.Cursor),
+		},
+	}
+
+	if page, err := sess.GetReportsByTeamId(ctx, input); err != nil {
 		return nil, err
 	} else {
-		return apispec.GetReportsByTeamId200JSONResponse(mapSlice(reports, ReportFromModel)), nil
+		return apispec.GetReportsByTeamId200JSONResponse{
+			Items:      mapSlice(page.Items, ReportFromModel),
+			NextCursor: nilIfEmpty(page.NextCursor),
+		}, nil
 	}
 }
 
@@ -69,3 +89,102 @@ func (api *API) DeleteReportById(ctx context.Context, request apispec.DeleteRepo
 		return apispec.DeleteReportById200Response{}, nil
 	}
 }
+
+// Returns true if the Accept-Encoding header permits gzip.
+func acceptsGzip(header http.Header) bool {
+	for _, value := range header.Values("Accept-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(encoding, ";")
+			if strings.TrimSpace(name) == "gzip" {
+				return strings.ReplaceAll(params, " ", "") != "q=0"
+			}
+		}
+	}
+	return false
+}
+
+// Returns true if the If-None-Match header matches the given entity tag.
+func ifNoneMatch(header http.Header, etag string) bool {
+	for _, value := range header.Values("If-None-Match") {
+		for _, candidate := range strings.Split(value, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Streams report content directly, since the generated response types can't express compression
+// or partial content.
+type reportContentResponse struct {
+	content       *app.ReportContent
+	requestHeader http.Header
+}
+
+func (r reportContentResponse) VisitGetReportContentResponse(w http.ResponseWriter) error {
+	defer r.content.Body.Close()
+
+	gzipped := r.content.ContentRange == "" && acceptsGzip(r.requestHeader)
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if etag := r.content.ETag; etag != "" {
+		if gzipped {
+			// Different encodings of the same resource must have different entity tags.
+			etag = strings.TrimSuffix(etag, `"`) + `-gzip"`
+		}
+		w.Header().Set("ETag", etag)
+		if ifNoneMatch(r.requestHeader, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if gzipped {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+		gz := gzip.NewWriter(w)
+		if _, err := io.Copy(gz, r.content.Body); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+
+	if r.content.ContentLength > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(r.content.ContentLength, 10))
+	}
+	if r.content.ContentRange != "" {
+		w.Header().Set("Content-Range", r.content.ContentRange)
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_, err := io.Copy(w, r.content.Body)
+	return err
+}
+
+func (api *API) GetReportContent(ctx context.Context, request apispec.GetReportContentRequestObject) (apispec.GetReportContentResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	requestHeader := http.Header{}
+	if r := ctxRequest(ctx); r != nil {
+		requestHeader = r.Header
+	}
+
+	if content, err := sess.GetReportContentById(ctx, app.GetReportContentInput{
+		ReportId: model.Id(request.ReportId),
+		Range:    requestHeader.Get("Range"),
+	}); err != nil {
+		return nil, err
+	} else {
+		return reportContentResponse{
+			content:       content,
+			requestHeader: requestHeader,
+		}, nil
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+func ReportExportFormatFromSpec(format apispec.ReportExportFormat) report.ExportFormat {
+	switch format {
+	case apispec.CSV:
+		return report.ExportFormatCSV
+	case apispec.NDJSON:
+		return report.ExportFormatNDJSON
+	default:
+		panic(fmt.Sprintf("unexpected report export format: %v", string(format)))
+	}
+}
+
+// Flushes the HTTP response whenever the export writer is flushed, so that rows reach the client as
+// each report is written.
+type httpFlushingExportWriter struct {
+	report.ExportWriter
+	rc *http.ResponseController
+}
+
+func (w httpFlushingExportWriter) Flush() error {
+	if err := w.ExportWriter.Flush(); err != nil {
+		return err
+	}
+	return w.rc.Flush()
+}
+
+// Streams export rows directly, since the generated response types would require buffering the
+// entire export.
+type reportExportResponse struct {
+	ctx      context.Context
+	export   *app.ReportExport
+	format   report.ExportFormat
+	filename string
+	logger   *zap.Logger
+}
+
+func (r reportExportResponse) visit(w http.ResponseWriter) error {
+	contentType := "text/csv"
+	if r.format == report.ExportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, r.filename, r.format))
+	w.WriteHeader(http.StatusOK)
+
+	ew, err := report.NewExportWriter(w, r.format)
+	if err != nil {
+		return err
+	}
+
+	// Once the headers are written, errors can't be reported to the client. The response is just
+	// truncated.
+	if err := r.export.Write(r.ctx, httpFlushingExportWriter{
+		ExportWriter: ew,
+		rc:           http.NewResponseController(w),
+	}); err != nil {
+		r.logger.Error("report export failed", zap.Error(err))
+	}
+	return nil
+}
+
+func (r reportExportResponse) VisitExportReportResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (r reportExportResponse) VisitExportTeamReportsResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (api *API) ExportReport(ctx context.Context, request apispec.ExportReportRequestObject) (apispec.ExportReportResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if export, err := sess.ExportReportById(ctx, model.Id(request.ReportId)); err != nil {
+		return nil, err
+	} else {
+		return reportExportResponse{
+			ctx:      ctx,
+			export:   export,
+			format:   ReportExportFormatFromSpec(request.Params.Format),
+			filename: request.ReportId,
+			logger:   sess.Logger(),
+		}, nil
+	}
+}
+
+func (api *API) ExportTeamReports(ctx context.Context, request apispec.ExportTeamReportsRequestObject) (apispec.ExportTeamReportsResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	params := request.Params
+	if export, err := sess.ExportTeamReports(ctx, app.ExportTeamReportsInput{
+		TeamId:    model.Id(request.TeamId),
+		StartTime: params.StartTime,
+		EndTime:   params.EndTime,
+		AccountId: emptyIfNil(params.AccountId),
+		Region:    emptyIfNil(params.Region),
+	}); err != nil {
+		return nil, err
+	} else {
+		return reportExportResponse{
+			ctx:      ctx,
+			export:   export,
+			format:   ReportExportFormatFromSpec(params.Format),
+			filename: request.TeamId,
+			logger:   sess.Logger(),
+		}, nil
+	}
+}
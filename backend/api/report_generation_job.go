@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func ReportGenerationJobStateFromModel(state model.ReportGenerationJobState) apispec.ReportGenerationJobState {
+	switch state {
+	case model.ReportGenerationJobStateQueued:
+		return apispec.ReportGenerationJobStateQueued
+	case model.ReportGenerationJobStateScanning:
+		return apispec.ReportGenerationJobStateScanning
+	case model.ReportGenerationJobStateImporting:
+		return apispec.ReportGenerationJobStateImporting
+	case model.ReportGenerationJobStateDone:
+		return apispec.ReportGenerationJobStateDone
+	case model.ReportGenerationJobStateFailed:
+		return apispec.ReportGenerationJobStateFailed
+	default:
+		panic("unknown report generation job state")
+	}
+}
+
+func ReportGenerationJobFromModel(job *model.ReportGenerationJob) apispec.ReportGenerationJob {
+	return apispec.ReportGenerationJob{
+		Id:                  job.Id.String(),
+		CreationTime:        job.CreationTime,
+		UpdateTime:          job.UpdateTime,
+		AwsIntegrationId:    job.AWSIntegrationId.String(),
+		Scope:               ReportScopeFromModel(&job.Scope),
+		State:               ReportGenerationJobStateFromModel(job.State),
+		ImportedObjectCount: job.ImportedObjectCount,
+		TotalObjectCount:    job.TotalObjectCount,
+		ReportId:            nilIfEmpty(job.ReportId.String()),
+		FailureReason:       nilIfEmpty(job.FailureReason),
+	}
+}
+
+func (api *API) GetReportGenerationJobsByTeamId(ctx context.Context, request apispec.GetReportGenerationJobsByTeamIdRequestObject) (apispec.GetReportGenerationJobsByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if jobs, err := sess.GetReportGenerationJobsByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetReportGenerationJobsByTeamId200JSONResponse(mapSlice(jobs, ReportGenerationJobFromModel)), nil
+	}
+}
+
+const (
+	reportGenerationJobEventsPollInterval      = 2 * time.Second
+	reportGenerationJobEventsHeartbeatInterval = 15 * time.Second
+
+	// Streams are ended periodically so that clients re-authenticate and connections are spread
+	// across servers.
+	reportGenerationJobEventsMaxDuration = 5 * time.Minute
+)
+
+// Streams server-sent events by polling for job changes.
+type reportGenerationJobEventsResponse struct {
+	ctx    context.Context
+	sess   *app.Session
+	teamId model.Id
+	jobs   []*model.ReportGenerationJob
+}
+
+func (r reportGenerationJobEventsResponse) VisitStreamReportGenerationJobsByTeamIdResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	lastWriteTime := time.Now()
+	lastUpdateTimes := make(map[model.Id]time.Time)
+
+	// Once the headers are written, errors can't be reported to the client. The stream is just
+	// ended.
+	writeChanges := func(jobs []*model.ReportGenerationJob) error {
+		for _, job := range jobs {
+			if t, ok := lastUpdateTimes[job.Id]; ok && !job.UpdateTime.After(t) {
+				continue
+			}
+			lastUpdateTimes[job.Id] = job.UpdateTime
+
+			buf, err := json.Marshal(ReportGenerationJobFromModel(job))
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: job\ndata: %s\n\n", buf); err != nil {
+				return err
+			}
+			lastWriteTime = time.Now()
+		}
+		if time.Since(lastWriteTime) >= reportGenerationJobEventsHeartbeatInterval {
+			// Comments keep proxies from closing idle connections.
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+			lastWriteTime = time.Now()
+		}
+		return rc.Flush()
+	}
+
+	if err := writeChanges(r.jobs); err != nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(reportGenerationJobEventsPollInterval)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(reportGenerationJobEventsMaxDuration)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil
+		case <-deadline.C:
+			return nil
+		case <-ticker.C:
+			jobs, err := r.sess.GetReportGenerationJobsByTeamId(r.ctx, r.teamId)
+			if err != nil {
+				if r.ctx.Err() == nil {
+					r.sess.Logger().Warn("report generation job stream ended", zap.Error(err))
+				}
+				return nil
+			} else if err := writeChanges(jobs); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (api *API) StreamReportGenerationJobsByTeamId(ctx context.Context, request apispec.StreamReportGenerationJobsByTeamIdRequestObject) (apispec.StreamReportGenerationJobsByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+	teamId := model.Id(request.TeamId)
+
+	// The initial jobs are fetched here so that authorization errors can be returned normally.
+	if jobs, err := sess.GetReportGenerationJobsByTeamId(ctx, teamId); err != nil {
+		return nil, err
+	} else {
+		return reportGenerationJobEventsResponse{
+			ctx:    ctx,
+			sess:   sess,
+			teamId: teamId,
+			jobs:   jobs,
+		}, nil
+	}
+}
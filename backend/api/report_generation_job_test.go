@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestStreamReportGenerationJobsByTeamId(t *testing.T) {
+	api := NewTestAPI(t)
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, ctx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	sess := ctxSession(ctx)
+	team := api.NewTestTeamWithSubscription(ctx, app.TeamSubscriptionTierIndividual)
+
+	var err error
+
+	_, err = sess.CreateAWSIntegration(ctx, app.CreateAWSIntegrationInput{
+		TeamId:  team.Id,
+		Name:    "My Integration",
+		RoleARN: "arn:aws:iam::123456789012:role/MyRole",
+		CloudTrailTrail: &app.CreateAWSIntegrationCloudTrailTrailInput{
+			S3BucketName: "aws-cloudtrail-logs",
+		},
+		QueueReportGeneration: true,
+	})
+	require.NoError(t, err)
+
+	_, secret, err := sess.CreateTeamAPIKey(ctx, app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "CI",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeReadReports},
+	})
+	require.NoError(t, err)
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(streamCtx, "GET", server.URL+"/teams/"+team.Id.String()+"/report-generation-jobs/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token "+secret)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The stream should start with the current jobs.
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	assert.Equal(t, "event: job", scanner.Text())
+	require.True(t, scanner.Scan())
+	data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+	require.True(t, ok)
+
+	var job apispec.ReportGenerationJob
+	require.NoError(t, json.Unmarshal([]byte(data), &job))
+	assert.Equal(t, apispec.ReportGenerationJobStateQueued, job.State)
+}
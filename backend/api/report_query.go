@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+// Unknown types are mapped to the zero value, which the app rejects as invalid.
+func PrincipalTypeFromSpec(t apispec.PrincipalType) report.PrincipalType {
+	switch t {
+	case apispec.AWSASSUMEDROLE:
+		return report.PrincipalTypeAWSAssumedRole
+	case apispec.AWSROLE:
+		return report.PrincipalTypeAWSRole
+	case apispec.AWSIAMUSER:
+		return report.PrincipalTypeAWSIAMUser
+	case apispec.AWSSERVICE:
+		return report.PrincipalTypeAWSService
+	case apispec.AWSACCOUNT:
+		return report.PrincipalTypeAWSAccount
+	case apispec.WEBIDENTITYUSER:
+		return report.PrincipalTypeWebIdentityUser
+	default:
+		return report.PrincipalTypeUnknown
+	}
+}
+
+// Returns nil for unknown types.
+func PrincipalTypeFromModel(t report.PrincipalType) *apispec.PrincipalType {
+	switch t {
+	case report.PrincipalTypeAWSAssumedRole:
+		return pointer(apispec.AWSASSUMEDROLE)
+	case report.PrincipalTypeAWSRole:
+		return pointer(apispec.AWSROLE)
+	case report.PrincipalTypeAWSIAMUser:
+		return pointer(apispec.AWSIAMUSER)
+	case report.PrincipalTypeAWSService:
+		return pointer(apispec.AWSSERVICE)
+	case report.PrincipalTypeAWSAccount:
+		return pointer(apispec.AWSACCOUNT)
+	case report.PrincipalTypeWebIdentityUser:
+		return pointer(apispec.WEBIDENTITYUSER)
+	default:
+		return nil
+	}
+}
+
+func ReportQuerySortFromSpec(sort apispec.ReportQuerySort) model.ReportQuerySort {
+	switch sort {
+	case apispec.COUNT:
+		return model.ReportQuerySortCount
+	case apispec.NAME:
+		return model.ReportQuerySortName
+	default:
+		panic(fmt.Sprintf("unexpected report query sort: %v", string(sort)))
+	}
+}
+
+// The query operations all share the same parameters, so their parameter types are converted to
+// QueryReportPrincipalsParams by the handlers.
+func ReportQueryInputFromSpec(teamId string, params apispec.QueryReportPrincipalsParams) app.ReportQueryInput {
+	ret := app.ReportQueryInput{
+		TeamId:      model.Id(teamId),
+		StartTime:   params.StartTime,
+		EndTime:     params.EndTime,
+		AccountId:   emptyIfNil(params.AccountId),
+		Region:      emptyIfNil(params.Region),
+		EventSource: emptyIfNil(params.EventSource),
+		HasErrors:   emptyIfNil(params.HasErrors),
+		Offset:      emptyIfNil(params.Offset),
+		Limit:       emptyIfNil(params.Limit),
+	}
+	if params.PrincipalType != nil {
+		ret.PrincipalType = pointer(PrincipalTypeFromSpec(*params.PrincipalType))
+	}
+	if params.Sort != nil {
+		ret.Sort = ReportQuerySortFromSpec(*params.Sort)
+	}
+	return ret
+}
+
+func ReportQueryPrincipalFromModel(principal *model.ReportQueryPrincipal) apispec.ReportQueryPrincipal {
+	return apispec.ReportQueryPrincipal{
+		Key:            principal.Key,
+		Name:           principal.Name,
+		Type:           PrincipalTypeFromModel(principal.Type),
+		Arn:            nilIfEmpty(principal.ARN),
+		EventCount:     principal.EventCount,
+		ErrorCount:     principal.ErrorCount,
+		IpAddressCount: principal.IPAddressCount,
+	}
+}
+
+func ReportQueryIPAddressFromModel(ip *model.ReportQueryIPAddress) apispec.ReportQueryIPAddress {
+	return apispec.ReportQueryIPAddress{
+		IpAddress:      ip.IPAddress,
+		Network:        nilIfEmpty(ip.Network),
+		EventCount:     ip.EventCount,
+		PrincipalCount: ip.PrincipalCount,
+	}
+}
+
+func ReportQueryNetworkFromModel(network *model.ReportQueryNetwork) apispec.ReportQueryNetwork {
+	return apispec.ReportQueryNetwork{
+		Network:        network.Network,
+		CountryCode:    nilIfEmpty(network.CountryCode),
+		CountryName:    nilIfEmpty(network.CountryName),
+		CityName:       nilIfEmpty(network.CityName),
+		EventCount:     network.EventCount,
+		IpAddressCount: network.IPAddressCount,
+		PrincipalCount: network.PrincipalCount,
+	}
+}
+
+func ReportQueryEventFromModel(event *model.ReportQueryEvent) apispec.ReportQueryEvent {
+	errorCodes := event.ErrorCodes
+	if errorCodes == nil {
+		errorCodes = map[string]int{}
+	}
+	return apispec.ReportQueryEvent{
+		Source:         event.Source,
+		Name:           event.Name,
+		EventCount:     event.EventCount,
+		ErrorCount:     event.ErrorCount,
+		ErrorCodes:     errorCodes,
+		PrincipalCount: event.PrincipalCount,
+	}
+}
+
+func (api *API) QueryReportPrincipals(ctx context.Context, request apispec.QueryReportPrincipalsRequestObject) (apispec.QueryReportPrincipalsResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if page, err := sess.QueryReportPrincipals(ctx, ReportQueryInputFromSpec(request.TeamId, request.Params)); err != nil {
+		return nil, err
+	} else {
+		return apispec.QueryReportPrincipals200JSONResponse{
+			Items:        mapSlice(page.Items, ReportQueryPrincipalFromModel),
+			TotalCount:   page.TotalCount,
+			ReportCount:  page.ReportCount,
+			IsIncomplete: page.IsIncomplete,
+		}, nil
+	}
+}
+
+func (api *API) QueryReportIPAddresses(ctx context.Context, request apispec.QueryReportIPAddressesRequestObject) (apispec.QueryReportIPAddressesResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if page, err := sess.QueryReportIPAddresses(ctx, ReportQueryInputFromSpec(request.TeamId, apispec.QueryReportPrincipalsParams(request.Params))); err != nil {
+		return nil, err
+	} else {
+		return apispec.QueryReportIPAddresses200JSONResponse{
+			Items:        mapSlice(page.Items, ReportQueryIPAddressFromModel),
+			TotalCount:   page.TotalCount,
+			ReportCount:  page.ReportCount,
+			IsIncomplete: page.IsIncomplete,
+		}, nil
+	}
+}
+
+func (api *API) QueryReportNetworks(ctx context.Context, request apispec.QueryReportNetworksRequestObject) (apispec.QueryReportNetworksResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if page, err := sess.QueryReportNetworks(ctx, ReportQueryInputFromSpec(request.TeamId, apispec.QueryReportPrincipalsParams(request.Params))); err != nil {
+		return nil, err
+	} else {
+		return apispec.QueryReportNetworks200JSONResponse{
+			Items:        mapSlice(page.Items, ReportQueryNetworkFromModel),
+			TotalCount:   page.TotalCount,
+			ReportCount:  page.ReportCount,
+			IsIncomplete: page.IsIncomplete,
+		}, nil
+	}
+}
+
+func (api *API) QueryReportEvents(ctx context.Context, request apispec.QueryReportEventsRequestObject) (apispec.QueryReportEventsResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if page, err := sess.QueryReportEvents(ctx, ReportQueryInputFromSpec(request.TeamId, apispec.QueryReportPrincipalsParams(request.Params))); err != nil {
+		return nil, err
+	} else {
+		return apispec.QueryReportEvents200JSONResponse{
+			Items:        mapSlice(page.Items, ReportQueryEventFromModel),
+			TotalCount:   page.TotalCount,
+			ReportCount:  page.ReportCount,
+			IsIncomplete: page.IsIncomplete,
+		}, nil
+	}
+}
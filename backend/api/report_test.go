@@ -0,0 +1,96 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+func TestAPI_GetReportContent(t *testing.T) {
+	api := NewTestAPI(t)
+
+	_, ctx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	sess := ctxSession(ctx)
+
+	var err error
+
+	team := api.NewTestTeamWithSubscription(ctx, app.TeamSubscriptionTierIndividual)
+
+	reportModel := api.app.NewTestAWSCloudTrailReport(sess, team.Id)
+
+	_, secret, err := sess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "Downloads",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeReadReports},
+	})
+	require.NoError(t, err)
+
+	get := func(header http.Header) *http.Response {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/reports/"+reportModel.Id.String()+"/content", nil)
+		require.NoError(t, err)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		api.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	resp := get(http.Header{
+		"Authorization":   {"token " + secret},
+		"Accept-Encoding": {"gzip, deflate"},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	var contents report.Report
+	require.NoError(t, jsoniter.NewDecoder(gz).Decode(&contents))
+	resp.Body.Close()
+	assert.NotEmpty(t, contents.Principals)
+
+	t.Run("NotModified", func(t *testing.T) {
+		resp := get(http.Header{
+			"Authorization":   {"token " + secret},
+			"Accept-Encoding": {"gzip"},
+			"If-None-Match":   {etag},
+		})
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp := get(http.Header{
+			"Authorization":   {"token " + secret},
+			"Accept-Encoding": {"gzip"},
+			"Range":           {"bytes=0-9"},
+		})
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assert.Len(t, body, 10)
+		assert.Equal(t, `{"startTim`, string(body))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		resp := get(http.Header{})
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Unknown types are mapped to the zero value, which the app rejects as invalid.
+func SIEMSinkTypeFromSpec(t apispec.SIEMSinkType) model.SIEMSinkType {
+	switch t {
+	case apispec.SYSLOG:
+		return model.SIEMSinkTypeSyslog
+	case apispec.HEC:
+		return model.SIEMSinkTypeHEC
+	default:
+		return ""
+	}
+}
+
+func SIEMSinkTypeFromModel(t model.SIEMSinkType) apispec.SIEMSinkType {
+	switch t {
+	case model.SIEMSinkTypeSyslog:
+		return apispec.SYSLOG
+	case model.SIEMSinkTypeHEC:
+		return apispec.HEC
+	default:
+		panic(fmt.Sprintf("unexpected siem sink type: %v", string(t)))
+	}
+}
+
+func SIEMSinkFromModel(sink *model.SIEMSink) apispec.SIEMSink {
+	ret := apispec.SIEMSink{
+		Id:           sink.Id.String(),
+		TeamId:       sink.TeamId.String(),
+		CreationTime: sink.CreationTime,
+		Name:         sink.Name,
+		Type:         SIEMSinkTypeFromModel(sink.Type),
+		Address:      sink.Address,
+	}
+	if sink.Type == model.SIEMSinkTypeSyslog {
+		ret.UseTls = &sink.UseTLS
+	}
+	return ret
+}
+
+func (api *API) GetSIEMSinksByTeamId(ctx context.Context, request apispec.GetSIEMSinksByTeamIdRequestObject) (apispec.GetSIEMSinksByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if sinks, err := sess.GetSIEMSinksByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetSIEMSinksByTeamId200JSONResponse(mapSlice(sinks, SIEMSinkFromModel)), nil
+	}
+}
+
+func (api *API) CreateSIEMSink(ctx context.Context, request apispec.CreateSIEMSinkRequestObject) (apispec.CreateSIEMSinkResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if sink, err := sess.CreateSIEMSink(ctx, app.CreateSIEMSinkInput{
+		TeamId:  model.Id(request.TeamId),
+		Name:    request.Body.Name,
+		Type:    SIEMSinkTypeFromSpec(request.Body.Type),
+		Address: request.Body.Address,
+		UseTLS:  emptyIfNil(request.Body.UseTls),
+		Token:   emptyIfNil(request.Body.Token),
+	}); err != nil {
+		return nil, err
+	} else {
+		return apispec.CreateSIEMSink200JSONResponse(SIEMSinkFromModel(sink)), nil
+	}
+}
+
+func (api *API) DeleteSIEMSink(ctx context.Context, request apispec.DeleteSIEMSinkRequestObject) (apispec.DeleteSIEMSinkResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteSIEMSinkById(ctx, model.Id(request.SiemSinkId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteSIEMSink200JSONResponse{}, nil
+	}
+}
@@ -18,6 +18,7 @@ func TeamFromModel(team *model.Team) apispec.Team {
 			IndividualFeatures: team.Entitlements.IndividualFeatures,
 			TeamFeatures:       team.Entitlements.TeamFeatures,
 		},
+		RequireSecondFactor: team.RequireSecondFactor,
 	}
 }
 
@@ -47,29 +48,86 @@ func TeamMembershipRoleFromSpec(role apispec.TeamMembershipRole) model.TeamMembe
 	switch role {
 	case apispec.TeamMembershipRoleADMINISTRATOR:
 		return model.TeamMembershipRoleAdministrator
-	case apispec.TeamMembershipRoleMEMBER:
-		return model.TeamMembershipRoleMember
+	case apispec.TeamMembershipRoleSCPMANAGER:
+		return model.TeamMembershipRoleSCPManager
+	case apispec.TeamMembershipRoleANALYST, apispec.TeamMembershipRoleMEMBER:
+		return model.TeamMembershipRoleAnalyst
+	case apispec.TeamMembershipRoleBILLING:
+		return model.TeamMembershipRoleBilling
+	case apispec.TeamMembershipRoleVIEWER:
+		return model.TeamMembershipRoleViewer
 	default:
 		panic(fmt.Sprintf("unknown team membership role: %v", role))
 	}
 }
 
+// Legacy roles are reported as the roles they'll be migrated to.
 func TeamMembershipRoleFromModel(role model.TeamMembershipRole) apispec.TeamMembershipRole {
-	switch role {
+	switch role.Migrated() {
 	case model.TeamMembershipRoleAdministrator:
 		return apispec.TeamMembershipRoleADMINISTRATOR
-	case model.TeamMembershipRoleMember:
-		return apispec.TeamMembershipRoleMEMBER
+	case model.TeamMembershipRoleSCPManager:
+		return apispec.TeamMembershipRoleSCPMANAGER
+	case model.TeamMembershipRoleAnalyst:
+		return apispec.TeamMembershipRoleANALYST
+	case model.TeamMembershipRoleBilling:
+		return apispec.TeamMembershipRoleBILLING
+	case model.TeamMembershipRoleViewer:
+		return apispec.TeamMembershipRoleVIEWER
 	default:
 		panic(fmt.Sprintf("unexpected team membership role: %v", string(role)))
 	}
 }
 
+func TeamPermissionFromModel(permission model.TeamPermission) apispec.TeamPermission {
+	switch permission {
+	case model.TeamPermissionReadReports:
+		return apispec.TeamPermissionREADREPORTS
+	case model.TeamPermissionAnalyzeReports:
+		return apispec.TeamPermissionANALYZEREPORTS
+	case model.TeamPermissionManageSCPs:
+		return apispec.TeamPermissionMANAGESCPS
+	case model.TeamPermissionManageBilling:
+		return apispec.TeamPermissionMANAGEBILLING
+	case model.TeamPermissionManageTeam:
+		return apispec.TeamPermissionMANAGETEAM
+	default:
+		panic(fmt.Sprintf("unexpected team permission: %v", string(permission)))
+	}
+}
+
+// Unknown frequencies are mapped to an invalid value, which the app rejects.
+func DigestFrequencyFromSpec(f apispec.DigestFrequency) model.DigestFrequency {
+	switch f {
+	case apispec.NONE:
+		return model.DigestFrequencyNone
+	case apispec.DAILY:
+		return model.DigestFrequencyDaily
+	case apispec.WEEKLY:
+		return model.DigestFrequencyWeekly
+	default:
+		return model.DigestFrequency(f)
+	}
+}
+
+func DigestFrequencyFromModel(f model.DigestFrequency) apispec.DigestFrequency {
+	switch f {
+	case model.DigestFrequencyDaily:
+		return apispec.DAILY
+	case model.DigestFrequencyWeekly:
+		return apispec.WEEKLY
+	default:
+		return apispec.NONE
+	}
+}
+
 func TeamMembershipFromModel(membership *model.TeamMembership) apispec.TeamMembership {
 	return apispec.TeamMembership{
-		TeamId: membership.TeamId.String(),
-		UserId: membership.UserId.String(),
-		Role:   TeamMembershipRoleFromModel(membership.Role),
+		TeamId:          membership.TeamId.String(),
+		UserId:          membership.UserId.String(),
+		Role:            TeamMembershipRoleFromModel(membership.Role),
+		Permissions:     mapSlice(membership.Role.Permissions(), TeamPermissionFromModel),
+		DigestFrequency: DigestFrequencyFromModel(membership.DigestFrequency),
 	}
 }
 
@@ -85,10 +143,18 @@ func TeamInviteFromModel(invite *model.TeamInvite) apispec.TeamInvite {
 func (api *API) GetTeams(ctx context.Context, request apispec.GetTeamsRequestObject) (apispec.GetTeamsResponseObject, error) {
 	sess := ctxSession(ctx)
 
-	if teams, err := sess.GetTeams(ctx); err != nil {
+	input := app.PageInput{
+		Limit:  emptyIfNil(request.Params.Limit),
+		Cursor: emptyIfNil(request.Params.Cursor),
+	}
+
+	if page, err := sess.GetTeams(ctx, input); err != nil {
 		return nil, err
 	} else {
-		return apispec.GetTeams200JSONResponse(mapSlice(teams, TeamFromModel)), nil
+		return apispec.GetTeams200JSONResponse{
+			Items:      mapSlice(page.Items, TeamFromModel),
+			NextCursor: nilIfEmpty(page.NextCursor),
+		}, nil
 	}
 }
 
@@ -123,7 +189,8 @@ func (api *API) UpdateTeam(ctx context.Context, request apispec.UpdateTeamReques
 	sess := ctxSession(ctx)
 
 	patch := app.TeamPatch{
-		Name: request.Body.Name,
+		Name:                request.Body.Name,
+		RequireSecondFactor: request.Body.RequireSecondFactor,
 	}
 
 	if team, err := sess.PatchTeamById(ctx, model.Id(request.TeamId), patch); err != nil {
@@ -189,10 +256,18 @@ func (api *API) GetTeamMembershipsByTeamId(ctx context.Context, request apispec.
 	sess := ctxSession(ctx)
 	teamId := model.Id(request.TeamId)
 
-	if memberships, err := sess.GetTeamMembershipsByTeamId(ctx, teamId); err != nil {
+	input := app.PageInput{
+		Limit:  emptyIfNil(request.Params.Limit),
+		Cursor: emptyIfNil(request.Params.Cursor),
+	}
+
+	if page, err := sess.GetTeamMembershipsByTeamId(ctx, teamId, input); err != nil {
 		return nil, err
 	} else {
-		return apispec.GetTeamMembershipsByTeamId200JSONResponse(mapSlice(memberships, TeamTeamMembershipFromApp)), nil
+		return apispec.GetTeamMembershipsByTeamId200JSONResponse{
+			Items:      mapSlice(page.Items, TeamTeamMembershipFromApp),
+			NextCursor: nilIfEmpty(page.NextCursor),
+		}, nil
 	}
 }
 
@@ -217,6 +292,9 @@ func (api *API) UpdateTeamMembership(ctx context.Context, request apispec.Update
 	if request.Body.Role != nil {
 		patch.Role = pointer(TeamMembershipRoleFromSpec(*request.Body.Role))
 	}
+	if request.Body.DigestFrequency != nil {
+		patch.DigestFrequency = pointer(DigestFrequencyFromSpec(*request.Body.DigestFrequency))
+	}
 
 	if membership, err := sess.PatchTeamMembershipByTeamAndUserId(ctx, teamId, userId, patch); err != nil {
 		return nil, err
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Unknown scopes are mapped to the zero value, which the app rejects as invalid.
+func TeamAPIKeyScopeFromSpec(scope apispec.TeamAPIKeyScope) model.TeamAPIKeyScope {
+	switch scope {
+	case apispec.TeamAPIKeyScopeREADREPORTS:
+		return model.TeamAPIKeyScopeReadReports
+	case apispec.TeamAPIKeyScopeMANAGEINTEGRATIONS:
+		return model.TeamAPIKeyScopeManageIntegrations
+	case apispec.TeamAPIKeyScopeMANAGESCPS:
+		return model.TeamAPIKeyScopeManageSCPs
+	case apispec.TeamAPIKeyScopeMANAGESCIM:
+		return model.TeamAPIKeyScopeManageSCIM
+	default:
+		return ""
+	}
+}
+
+func TeamAPIKeyScopeFromModel(scope model.TeamAPIKeyScope) apispec.TeamAPIKeyScope {
+	switch scope {
+	case model.TeamAPIKeyScopeReadReports:
+		return apispec.TeamAPIKeyScopeREADREPORTS
+	case model.TeamAPIKeyScopeManageIntegrations:
+		return apispec.TeamAPIKeyScopeMANAGEINTEGRATIONS
+	case model.TeamAPIKeyScopeManageSCPs:
+		return apispec.TeamAPIKeyScopeMANAGESCPS
+	case model.TeamAPIKeyScopeManageSCIM:
+		return apispec.TeamAPIKeyScopeMANAGESCIM
+	default:
+		panic(fmt.Sprintf("unexpected team api key scope: %v", string(scope)))
+	}
+}
+
+func TeamAPIKeyFromModel(key *model.TeamAPIKey) apispec.TeamAPIKey {
+	ret := apispec.TeamAPIKey{
+		Id:             key.Id.String(),
+		TeamId:         key.TeamId.String(),
+		CreatorId:      key.CreatorId.String(),
+		Name:           key.Name,
+		Scopes:         mapSlice(key.Scopes, TeamAPIKeyScopeFromModel),
+		CreationTime:   key.CreationTime,
+		ExpirationTime: key.ExpirationTime,
+	}
+	if !key.LastUseTime.IsZero() {
+		ret.LastUseTime = pointer(key.LastUseTime)
+	}
+	return ret
+}
+
+func (api *API) GetTeamAPIKeysByTeamId(ctx context.Context, request apispec.GetTeamAPIKeysByTeamIdRequestObject) (apispec.GetTeamAPIKeysByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if keys, err := sess.GetTeamAPIKeysByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetTeamAPIKeysByTeamId200JSONResponse(mapSlice(keys, TeamAPIKeyFromModel)), nil
+	}
+}
+
+func (api *API) CreateTeamAPIKey(ctx context.Context, request apispec.CreateTeamAPIKeyRequestObject) (apispec.CreateTeamAPIKeyResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	input := app.CreateTeamAPIKeyInput{
+		TeamId: model.Id(request.TeamId),
+		Name:   request.Body.Name,
+		Scopes: mapSlice(request.Body.Scopes, TeamAPIKeyScopeFromSpec),
+	}
+	if request.Body.ExpirationTime != nil {
+		input.ExpirationTime = *request.Body.ExpirationTime
+	}
+
+	if key, secret, err := sess.CreateTeamAPIKey(ctx, input); err != nil {
+		return nil, err
+	} else {
+		ret := TeamAPIKeyFromModel(key)
+		ret.Secret = &secret
+		return apispec.CreateTeamAPIKey200JSONResponse(ret), nil
+	}
+}
+
+func (api *API) DeleteTeamAPIKey(ctx context.Context, request apispec.DeleteTeamAPIKeyRequestObject) (apispec.DeleteTeamAPIKeyResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteTeamAPIKeyById(ctx, model.Id(request.ApiKeyId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteTeamAPIKey200JSONResponse{}, nil
+	}
+}
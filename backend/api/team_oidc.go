@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TeamOIDCConfigurationFromModel(config *model.TeamOIDCConfiguration) apispec.TeamOIDCConfiguration {
+	ret := apispec.TeamOIDCConfiguration{
+		TeamId:              config.TeamId.String(),
+		CreationTime:        config.CreationTime,
+		UpdateTime:          config.UpdateTime,
+		Issuer:              config.Issuer,
+		ClientId:            config.ClientId,
+		AllowedEmailDomains: config.AllowedEmailDomains,
+	}
+	if config.AutoJoinRole != model.TeamMembershipRoleNone {
+		ret.AutoJoinRole = pointer(TeamMembershipRoleFromModel(config.AutoJoinRole))
+	}
+	return ret
+}
+
+func (api *API) GetTeamOIDCConfiguration(ctx context.Context, request apispec.GetTeamOIDCConfigurationRequestObject) (apispec.GetTeamOIDCConfigurationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if config, err := sess.GetTeamOIDCConfigurationByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetTeamOIDCConfiguration200JSONResponse(TeamOIDCConfigurationFromModel(config)), nil
+	}
+}
+
+func (api *API) PutTeamOIDCConfiguration(ctx context.Context, request apispec.PutTeamOIDCConfigurationRequestObject) (apispec.PutTeamOIDCConfigurationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	input := app.PutTeamOIDCConfigurationInput{
+		TeamId:              model.Id(request.TeamId),
+		Issuer:              request.Body.Issuer,
+		ClientId:            request.Body.ClientId,
+		ClientSecret:        emptyIfNil(request.Body.ClientSecret),
+		AllowedEmailDomains: request.Body.AllowedEmailDomains,
+	}
+	if request.Body.AutoJoinRole != nil {
+		input.AutoJoinRole = TeamMembershipRoleFromSpec(*request.Body.AutoJoinRole)
+	}
+
+	if config, err := sess.PutTeamOIDCConfiguration(ctx, input); err != nil {
+		return nil, err
+	} else {
+		return apispec.PutTeamOIDCConfiguration200JSONResponse(TeamOIDCConfigurationFromModel(config)), nil
+	}
+}
+
+func (api *API) DeleteTeamOIDCConfiguration(ctx context.Context, request apispec.DeleteTeamOIDCConfigurationRequestObject) (apispec.DeleteTeamOIDCConfigurationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteTeamOIDCConfigurationByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteTeamOIDCConfiguration200JSONResponse{}, nil
+	}
+}
+
+func (api *API) BeginUserOIDCAuthentication(ctx context.Context, request apispec.BeginUserOIDCAuthenticationRequestObject) (apispec.BeginUserOIDCAuthenticationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if output, err := sess.BeginTeamOIDCAuthentication(ctx, model.Id(request.Body.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.BeginUserOIDCAuthentication200JSONResponse{
+			AuthorizationUrl: output.AuthorizationURL,
+		}, nil
+	}
+}
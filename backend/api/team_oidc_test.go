@@ -0,0 +1,90 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestAPI_TeamOIDC(t *testing.T) {
+	api := NewTestAPI(t)
+	provider := apptest.NewFakeOIDCProvider(t)
+
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := api.NewTestTeamWithSubscription(aliceCtx, app.TeamSubscriptionTierTeam)
+
+	role := apispec.TeamMembershipRoleANALYST
+	putResp, err := api.PutTeamOIDCConfiguration(aliceCtx, apispec.PutTeamOIDCConfigurationRequestObject{
+		TeamId: team.Id.String(),
+		Body: &apispec.PutTeamOIDCConfigurationJSONRequestBody{
+			Issuer:              provider.URL,
+			ClientId:            provider.ClientId,
+			ClientSecret:        &provider.ClientSecret,
+			AllowedEmailDomains: []string{"example.com"},
+			AutoJoinRole:        &role,
+		},
+	})
+	require.NoError(t, err)
+	config := putResp.(apispec.PutTeamOIDCConfiguration200JSONResponse)
+	assert.Equal(t, provider.URL, config.Issuer)
+	assert.Equal(t, &role, config.AutoJoinRole)
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := api.GetTeamOIDCConfiguration(aliceCtx, apispec.GetTeamOIDCConfigurationRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+		got := resp.(apispec.GetTeamOIDCConfiguration200JSONResponse)
+		assert.Equal(t, config.Issuer, got.Issuer)
+		assert.Equal(t, config.ClientId, got.ClientId)
+		assert.Equal(t, []string{"example.com"}, got.AllowedEmailDomains)
+		assert.Equal(t, &role, got.AutoJoinRole)
+	})
+
+	t.Run("SignIn", func(t *testing.T) {
+		beginResp, err := api.BeginUserOIDCAuthentication(api.AnonymousContext, apispec.BeginUserOIDCAuthenticationRequestObject{
+			Body: &apispec.BeginUserOIDCAuthenticationJSONRequestBody{
+				TeamId: team.Id.String(),
+			},
+		})
+		require.NoError(t, err)
+
+		state, code := provider.Authorize(beginResp.(apispec.BeginUserOIDCAuthentication200JSONResponse).AuthorizationUrl, apptest.FakeOIDCUser{
+			Subject:       "bob",
+			EmailAddress:  "bob@example.com",
+			EmailVerified: true,
+		})
+
+		var creds apispec.UserCredentials
+		require.NoError(t, creds.FromUserOIDCCredentials(apispec.UserOIDCCredentials{
+			State: state,
+			Code:  code,
+		}))
+
+		authResp, err := api.Authenticate(api.AnonymousContext, apispec.AuthenticateRequestObject{
+			Body: &creds,
+		})
+		require.NoError(t, err)
+		output := authResp.(apispec.Authenticate200JSONResponse)
+		assert.Equal(t, "bob@example.com", output.User.EmailAddress)
+		assert.NotEmpty(t, output.Token)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := api.DeleteTeamOIDCConfiguration(aliceCtx, apispec.DeleteTeamOIDCConfigurationRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+
+		_, err = api.GetTeamOIDCConfiguration(aliceCtx, apispec.GetTeamOIDCConfigurationRequestObject{
+			TeamId: team.Id.String(),
+		})
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TeamSAMLConfigurationFromApp(config *app.TeamSAMLConfiguration) apispec.TeamSAMLConfiguration {
+	ret := apispec.TeamSAMLConfiguration{
+		TeamId:                      config.TeamId.String(),
+		CreationTime:                config.CreationTime,
+		UpdateTime:                  config.UpdateTime,
+		IdpMetadataXml:              config.IdPMetadataXML,
+		IdpEntityId:                 config.IdPEntityId,
+		RoleMappings:                []apispec.TeamSAMLRoleMapping{},
+		AllowedEmailDomains:         config.AllowedEmailDomains,
+		AllowIdpInitiated:           config.AllowIdPInitiated,
+		ServiceProviderEntityId:     config.ServiceProviderEntityId,
+		AssertionConsumerServiceUrl: config.AssertionConsumerServiceURL,
+	}
+	if config.EmailAttribute != "" {
+		ret.EmailAttribute = pointer(config.EmailAttribute)
+	}
+	if config.RoleAttribute != "" {
+		ret.RoleAttribute = pointer(config.RoleAttribute)
+	}
+	for _, mapping := range config.RoleMappings {
+		ret.RoleMappings = append(ret.RoleMappings, apispec.TeamSAMLRoleMapping{
+			Value: mapping.Value,
+			Role:  TeamMembershipRoleFromModel(mapping.Role),
+		})
+	}
+	if config.DefaultRole != model.TeamMembershipRoleNone {
+		ret.DefaultRole = pointer(TeamMembershipRoleFromModel(config.DefaultRole))
+	}
+	return ret
+}
+
+func (api *API) GetTeamSAMLConfiguration(ctx context.Context, request apispec.GetTeamSAMLConfigurationRequestObject) (apispec.GetTeamSAMLConfigurationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if config, err := sess.GetTeamSAMLConfigurationByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetTeamSAMLConfiguration200JSONResponse(TeamSAMLConfigurationFromApp(config)), nil
+	}
+}
+
+func (api *API) PutTeamSAMLConfiguration(ctx context.Context, request apispec.PutTeamSAMLConfigurationRequestObject) (apispec.PutTeamSAMLConfigurationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	input := app.PutTeamSAMLConfigurationInput{
+		TeamId:              model.Id(request.TeamId),
+		IdPMetadataXML:      request.Body.IdpMetadataXml,
+		EmailAttribute:      emptyIfNil(request.Body.EmailAttribute),
+		RoleAttribute:       emptyIfNil(request.Body.RoleAttribute),
+		AllowedEmailDomains: request.Body.AllowedEmailDomains,
+		AllowIdPInitiated:   emptyIfNil(request.Body.AllowIdpInitiated),
+	}
+	for _, mapping := range emptyIfNil(request.Body.RoleMappings) {
+		input.RoleMappings = append(input.RoleMappings, model.TeamSAMLRoleMapping{
+			Value: mapping.Value,
+			Role:  TeamMembershipRoleFromSpec(mapping.Role),
+		})
+	}
+	if request.Body.DefaultRole != nil {
+		input.DefaultRole = TeamMembershipRoleFromSpec(*request.Body.DefaultRole)
+	}
+
+	if config, err := sess.PutTeamSAMLConfiguration(ctx, input); err != nil {
+		return nil, err
+	} else {
+		return apispec.PutTeamSAMLConfiguration200JSONResponse(TeamSAMLConfigurationFromApp(config)), nil
+	}
+}
+
+func (api *API) DeleteTeamSAMLConfiguration(ctx context.Context, request apispec.DeleteTeamSAMLConfigurationRequestObject) (apispec.DeleteTeamSAMLConfigurationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteTeamSAMLConfigurationByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteTeamSAMLConfiguration200JSONResponse{}, nil
+	}
+}
+
+func (api *API) GetTeamSAMLServiceProviderMetadata(ctx context.Context, request apispec.GetTeamSAMLServiceProviderMetadataRequestObject) (apispec.GetTeamSAMLServiceProviderMetadataResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if metadata, err := sess.GetTeamSAMLServiceProviderMetadata(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetTeamSAMLServiceProviderMetadata200ApplicationsamlmetadataXmlResponse{
+			Body:          bytes.NewReader(metadata),
+			ContentLength: int64(len(metadata)),
+		}, nil
+	}
+}
+
+func (api *API) CompleteTeamSAMLAuthentication(ctx context.Context, request apispec.CompleteTeamSAMLAuthenticationRequestObject) (apispec.CompleteTeamSAMLAuthenticationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	output := sess.CompleteTeamSAMLAuthentication(ctx, app.CompleteTeamSAMLAuthenticationInput{
+		TeamId:       model.Id(request.TeamId),
+		SAMLResponse: request.Body.SAMLResponse,
+		RelayState:   emptyIfNil(request.Body.RelayState),
+	})
+	return apispec.CompleteTeamSAMLAuthentication303Response{
+		Headers: apispec.CompleteTeamSAMLAuthentication303ResponseHeaders{
+			Location: output.RedirectURL,
+		},
+	}, nil
+}
+
+func (api *API) BeginUserSAMLAuthentication(ctx context.Context, request apispec.BeginUserSAMLAuthenticationRequestObject) (apispec.BeginUserSAMLAuthenticationResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if output, err := sess.BeginTeamSAMLAuthentication(ctx, model.Id(request.Body.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.BeginUserSAMLAuthentication200JSONResponse{
+			AuthenticationUrl: output.AuthenticationURL,
+		}, nil
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/base64"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestAPI_TeamSAML(t *testing.T) {
+	api := NewTestAPI(t)
+	idp := apptest.NewFakeSAMLIdentityProvider(t)
+
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := api.NewTestTeamWithSubscription(aliceCtx, app.TeamSubscriptionTierTeam)
+
+	role := apispec.TeamMembershipRoleANALYST
+	emailAttribute := "email"
+	putResp, err := api.PutTeamSAMLConfiguration(aliceCtx, apispec.PutTeamSAMLConfigurationRequestObject{
+		TeamId: team.Id.String(),
+		Body: &apispec.PutTeamSAMLConfigurationJSONRequestBody{
+			IdpMetadataXml:      idp.MetadataXML(),
+			EmailAttribute:      &emailAttribute,
+			AllowedEmailDomains: []string{"example.com"},
+			DefaultRole:         &role,
+		},
+	})
+	require.NoError(t, err)
+	config := putResp.(apispec.PutTeamSAMLConfiguration200JSONResponse)
+	assert.Equal(t, apptest.FakeSAMLIdentityProviderEntityId, config.IdpEntityId)
+	assert.Equal(t, &role, config.DefaultRole)
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := api.GetTeamSAMLConfiguration(aliceCtx, apispec.GetTeamSAMLConfigurationRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+		got := resp.(apispec.GetTeamSAMLConfiguration200JSONResponse)
+		assert.Equal(t, config.IdpEntityId, got.IdpEntityId)
+		assert.Equal(t, config.AssertionConsumerServiceUrl, got.AssertionConsumerServiceUrl)
+		assert.Equal(t, []string{"example.com"}, got.AllowedEmailDomains)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		resp, err := api.GetTeamSAMLServiceProviderMetadata(api.AnonymousContext, apispec.GetTeamSAMLServiceProviderMetadataRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.(apispec.GetTeamSAMLServiceProviderMetadata200ApplicationsamlmetadataXmlResponse).Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), config.ServiceProviderEntityId)
+	})
+
+	t.Run("SignIn", func(t *testing.T) {
+		beginResp, err := api.BeginUserSAMLAuthentication(api.AnonymousContext, apispec.BeginUserSAMLAuthenticationRequestObject{
+			Body: &apispec.BeginUserSAMLAuthenticationJSONRequestBody{
+				TeamId: team.Id.String(),
+			},
+		})
+		require.NoError(t, err)
+
+		samlResponse, relayState := idp.Authenticate(beginResp.(apispec.BeginUserSAMLAuthentication200JSONResponse).AuthenticationUrl, apptest.FakeSAMLUser{
+			NameId:       "bob",
+			EmailAddress: "bob@example.com",
+		})
+
+		completeResp, err := api.CompleteTeamSAMLAuthentication(api.AnonymousContext, apispec.CompleteTeamSAMLAuthenticationRequestObject{
+			TeamId: team.Id.String(),
+			Body: &apispec.CompleteTeamSAMLAuthenticationFormdataRequestBody{
+				SAMLResponse: samlResponse,
+				RelayState:   &relayState,
+			},
+		})
+		require.NoError(t, err)
+		location, err := url.Parse(completeResp.(apispec.CompleteTeamSAMLAuthentication303Response).Headers.Location)
+		require.NoError(t, err)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+		require.Empty(t, fragment.Get("error"))
+
+		var creds apispec.UserCredentials
+		require.NoError(t, creds.FromUserEmailCredentials(apispec.UserEmailCredentials{
+			Token: fragment.Get("token"),
+		}))
+
+		authResp, err := api.Authenticate(api.AnonymousContext, apispec.AuthenticateRequestObject{
+			Body: &creds,
+		})
+		require.NoError(t, err)
+		output := authResp.(apispec.Authenticate200JSONResponse)
+		assert.Equal(t, "bob@example.com", output.User.EmailAddress)
+		assert.NotEmpty(t, output.Token)
+	})
+
+	t.Run("InvalidResponse", func(t *testing.T) {
+		resp, err := api.CompleteTeamSAMLAuthentication(api.AnonymousContext, apispec.CompleteTeamSAMLAuthenticationRequestObject{
+			TeamId: team.Id.String(),
+			Body: &apispec.CompleteTeamSAMLAuthenticationFormdataRequestBody{
+				SAMLResponse: base64.StdEncoding.EncodeToString([]byte("<Response/>")),
+			},
+		})
+		require.NoError(t, err)
+		location, err := url.Parse(resp.(apispec.CompleteTeamSAMLAuthentication303Response).Headers.Location)
+		require.NoError(t, err)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+		assert.NotEmpty(t, fragment.Get("error"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := api.DeleteTeamSAMLConfiguration(aliceCtx, apispec.DeleteTeamSAMLConfigurationRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+
+		_, err = api.GetTeamSAMLConfiguration(aliceCtx, apispec.GetTeamSAMLConfigurationRequestObject{
+			TeamId: team.Id.String(),
+		})
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+}
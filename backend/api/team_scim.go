@@ -0,0 +1,527 @@
+package api
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+
+	maxSCIMPageSize = 100
+)
+
+func TeamSCIMGroupFromModel(group *model.TeamSCIMGroup) apispec.TeamSCIMGroup {
+	ret := apispec.TeamSCIMGroup{
+		Id:           group.Id.String(),
+		TeamId:       group.TeamId.String(),
+		DisplayName:  group.DisplayName,
+		MemberCount:  len(group.MemberIds),
+		CreationTime: group.CreationTime,
+		UpdateTime:   group.UpdateTime,
+	}
+	if group.Role != model.TeamMembershipRoleNone {
+		ret.Role = pointer(TeamMembershipRoleFromModel(group.Role))
+	}
+	return ret
+}
+
+func SCIMUserFromModel(user *model.TeamSCIMUser) apispec.SCIMUser {
+	return apispec.SCIMUser{
+		Schemas:    []string{scimUserSchema},
+		Id:         user.UserId.String(),
+		ExternalId: nilIfEmpty(user.ExternalId),
+		UserName:   user.UserName,
+		Active:     user.Active,
+		Emails: []apispec.SCIMEmail{
+			{
+				Value:   user.UserName,
+				Type:    pointer("work"),
+				Primary: pointer(true),
+			},
+		},
+		Meta: apispec.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreationTime,
+			LastModified: user.UpdateTime,
+		},
+	}
+}
+
+func SCIMGroupFromModel(group *model.TeamSCIMGroup) apispec.SCIMGroup {
+	return apispec.SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		Id:          group.Id.String(),
+		ExternalId:  nilIfEmpty(group.ExternalId),
+		DisplayName: group.DisplayName,
+		Members: mapSlice(group.MemberIds, func(id model.Id) apispec.SCIMGroupMember {
+			return apispec.SCIMGroupMember{
+				Value: id.String(),
+			}
+		}),
+		Meta: apispec.SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreationTime,
+			LastModified: group.UpdateTime,
+		},
+	}
+}
+
+var scimFilterRegexp = regexp.MustCompile(`(?i)^\s*([a-z]+)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// Parses filters of the form `attribute eq "value"`, which is all that identity providers use in
+// practice. The attribute name is lowercased since SCIM attribute names are case-insensitive.
+func parseSCIMFilter(filter string) (attribute, value string, err app.UserFacingError) {
+	match := scimFilterRegexp.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", app.NewUserError("Unsupported filter.")
+	}
+	value, unquoteErr := strconv.Unquote(match[2])
+	if unquoteErr != nil {
+		return "", "", app.NewUserError("Invalid filter.")
+	}
+	return strings.ToLower(match[1]), value, nil
+}
+
+// Applies SCIM's 1-based pagination to the given items.
+func scimPage[T any](items []T, startIndex, count *int) ([]T, int) {
+	start := max(emptyIfNil(startIndex), 1)
+	limit := maxSCIMPageSize
+	if count != nil {
+		limit = min(max(*count, 0), maxSCIMPageSize)
+	}
+	if start > len(items) {
+		return []T{}, start
+	}
+	items = items[start-1:]
+	return items[:min(limit, len(items))], start
+}
+
+func scimBool(v any) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		// Some identity providers send booleans as strings such as "False".
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+// Converts SCIM patch operations to the equivalent patch. Operations on unsupported attributes
+// such as names are ignored, since identity providers commonly send them.
+func teamSCIMUserPatchFromSpec(input *apispec.SCIMPatchInput) (app.TeamSCIMUserPatch, app.UserFacingError) {
+	var patch app.TeamSCIMUserPatch
+
+	apply := func(op, attribute string, value any) app.UserFacingError {
+		remove := op == "remove"
+		switch strings.ToLower(attribute) {
+		case "active":
+			if remove {
+				return app.NewUserError("The active attribute can't be removed.")
+			} else if active, ok := scimBool(value); !ok {
+				return app.NewUserError("The active attribute must be a boolean.")
+			} else {
+				patch.Active = &active
+			}
+		case "externalid":
+			if remove {
+				patch.ExternalId = pointer("")
+			} else if externalId, ok := value.(string); !ok {
+				return app.NewUserError("The externalId attribute must be a string.")
+			} else {
+				patch.ExternalId = &externalId
+			}
+		case "username":
+			if remove {
+				return app.NewUserError("The userName attribute can't be removed.")
+			} else if userName, ok := value.(string); !ok {
+				return app.NewUserError("The userName attribute must be a string.")
+			} else {
+				patch.UserName = &userName
+			}
+		}
+		return nil
+	}
+
+	for _, operation := range input.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return patch, app.NewUserError("Unsupported patch operation.")
+		}
+		value := emptyIfNil(operation.Value)
+		if path := emptyIfNil(operation.Path); path != "" {
+			if err := apply(op, path, value); err != nil {
+				return patch, err
+			}
+		} else if attributes, ok := value.(map[string]any); ok && op != "remove" {
+			for attribute, value := range attributes {
+				if err := apply(op, attribute, value); err != nil {
+					return patch, err
+				}
+			}
+		} else {
+			return patch, app.NewUserError("Patch operations without a path must have an object value.")
+		}
+	}
+
+	return patch, nil
+}
+
+var scimMemberFilterPathRegexp = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+
+func scimGroupMemberIds(value any) ([]model.Id, app.UserFacingError) {
+	members, ok := value.([]any)
+	if !ok {
+		return nil, app.NewUserError("The members attribute must be an array.")
+	}
+	ret := make([]model.Id, 0, len(members))
+	for _, member := range members {
+		if member, ok := member.(map[string]any); !ok {
+			return nil, app.NewUserError("Members must be objects.")
+		} else if id, ok := member["value"].(string); !ok {
+			return nil, app.NewUserError("Members must have a value.")
+		} else {
+			ret = append(ret, model.Id(id))
+		}
+	}
+	return ret, nil
+}
+
+// Converts SCIM patch operations to the equivalent patch. Operations on unsupported attributes are
+// ignored.
+func teamSCIMGroupPatchFromSpec(input *apispec.SCIMPatchInput) (app.TeamSCIMGroupPatch, app.UserFacingError) {
+	var patch app.TeamSCIMGroupPatch
+
+	addMembers := func(ids []model.Id) {
+		patch.AddMemberIds = append(patch.AddMemberIds, ids...)
+		patch.RemoveMemberIds = slices.DeleteFunc(patch.RemoveMemberIds, func(id model.Id) bool {
+			return slices.Contains(ids, id)
+		})
+	}
+
+	removeMembers := func(ids []model.Id) {
+		patch.RemoveMemberIds = append(patch.RemoveMemberIds, ids...)
+		patch.AddMemberIds = slices.DeleteFunc(patch.AddMemberIds, func(id model.Id) bool {
+			return slices.Contains(ids, id)
+		})
+	}
+
+	apply := func(op, attribute string, value any) app.UserFacingError {
+		if match := scimMemberFilterPathRegexp.FindStringSubmatch(attribute); match != nil && op == "remove" {
+			id, err := strconv.Unquote(match[1])
+			if err != nil {
+				return app.NewUserError("Invalid path.")
+			}
+			removeMembers([]model.Id{model.Id(id)})
+			return nil
+		}
+
+		switch strings.ToLower(attribute) {
+		case "displayname":
+			if op == "remove" {
+				return app.NewUserError("The displayName attribute can't be removed.")
+			} else if displayName, ok := value.(string); !ok {
+				return app.NewUserError("The displayName attribute must be a string.")
+			} else {
+				patch.DisplayName = &displayName
+			}
+		case "externalid":
+			if op == "remove" {
+				patch.ExternalId = pointer("")
+			} else if externalId, ok := value.(string); !ok {
+				return app.NewUserError("The externalId attribute must be a string.")
+			} else {
+				patch.ExternalId = &externalId
+			}
+		case "members":
+			if op == "remove" && value == nil {
+				patch.MemberIds = &[]model.Id{}
+				patch.AddMemberIds = nil
+				patch.RemoveMemberIds = nil
+				return nil
+			}
+			ids, err := scimGroupMemberIds(value)
+			if err != nil {
+				return err
+			}
+			switch op {
+			case "add":
+				addMembers(ids)
+			case "remove":
+				removeMembers(ids)
+			case "replace":
+				patch.MemberIds = &ids
+				patch.AddMemberIds = nil
+				patch.RemoveMemberIds = nil
+			}
+		}
+		return nil
+	}
+
+	for _, operation := range input.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return patch, app.NewUserError("Unsupported patch operation.")
+		}
+		value := emptyIfNil(operation.Value)
+		if path := emptyIfNil(operation.Path); path != "" {
+			if err := apply(op, path, value); err != nil {
+				return patch, err
+			}
+		} else if attributes, ok := value.(map[string]any); ok && op != "remove" {
+			for attribute, value := range attributes {
+				if err := apply(op, attribute, value); err != nil {
+					return patch, err
+				}
+			}
+		} else {
+			return patch, app.NewUserError("Patch operations without a path must have an object value.")
+		}
+	}
+
+	return patch, nil
+}
+
+func (api *API) GetTeamSCIMGroupsByTeamId(ctx context.Context, request apispec.GetTeamSCIMGroupsByTeamIdRequestObject) (apispec.GetTeamSCIMGroupsByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if groups, err := sess.GetTeamSCIMGroupsByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetTeamSCIMGroupsByTeamId200JSONResponse(mapSlice(groups, TeamSCIMGroupFromModel)), nil
+	}
+}
+
+func (api *API) PutTeamSCIMGroupRole(ctx context.Context, request apispec.PutTeamSCIMGroupRoleRequestObject) (apispec.PutTeamSCIMGroupRoleResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	role := model.TeamMembershipRoleNone
+	if request.Body.Role != nil {
+		role = TeamMembershipRoleFromSpec(*request.Body.Role)
+	}
+
+	if group, err := sess.PutTeamSCIMGroupRole(ctx, model.Id(request.TeamId), model.Id(request.GroupId), role); err != nil {
+		return nil, err
+	} else {
+		return apispec.PutTeamSCIMGroupRole200JSONResponse(TeamSCIMGroupFromModel(group)), nil
+	}
+}
+
+func (api *API) GetSCIMUsers(ctx context.Context, request apispec.GetSCIMUsersRequestObject) (apispec.GetSCIMUsersResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	users, err := sess.GetTeamSCIMUsersByTeamId(ctx, model.Id(request.TeamId))
+	if err != nil {
+		return nil, err
+	}
+
+	if filter := emptyIfNil(request.Params.Filter); filter != "" {
+		attribute, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		var match func(*model.TeamSCIMUser) bool
+		switch attribute {
+		case "id":
+			match = func(user *model.TeamSCIMUser) bool { return user.UserId.String() == value }
+		case "username":
+			match = func(user *model.TeamSCIMUser) bool { return strings.EqualFold(user.UserName, value) }
+		case "externalid":
+			match = func(user *model.TeamSCIMUser) bool { return user.ExternalId == value }
+		default:
+			return nil, app.NewUserError("Unsupported filter.")
+		}
+		users = slices.DeleteFunc(users, func(user *model.TeamSCIMUser) bool { return !match(user) })
+	}
+
+	page, startIndex := scimPage(users, request.Params.StartIndex, request.Params.Count)
+	return apispec.GetSCIMUsers200ApplicationScimPlusJSONResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(users),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    mapSlice(page, SCIMUserFromModel),
+	}, nil
+}
+
+func (api *API) CreateSCIMUser(ctx context.Context, request apispec.CreateSCIMUserRequestObject) (apispec.CreateSCIMUserResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if user, err := sess.CreateTeamSCIMUser(ctx, app.CreateTeamSCIMUserInput{
+		TeamId:     model.Id(request.TeamId),
+		ExternalId: emptyIfNil(request.Body.ExternalId),
+		UserName:   request.Body.UserName,
+		Active:     request.Body.Active == nil || *request.Body.Active,
+	}); err != nil {
+		return nil, err
+	} else {
+		return apispec.CreateSCIMUser201ApplicationScimPlusJSONResponse(SCIMUserFromModel(user)), nil
+	}
+}
+
+func (api *API) GetSCIMUser(ctx context.Context, request apispec.GetSCIMUserRequestObject) (apispec.GetSCIMUserResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if user, err := sess.GetTeamSCIMUserByTeamAndUserId(ctx, model.Id(request.TeamId), model.Id(request.UserId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetSCIMUser200ApplicationScimPlusJSONResponse(SCIMUserFromModel(user)), nil
+	}
+}
+
+func (api *API) ReplaceSCIMUser(ctx context.Context, request apispec.ReplaceSCIMUserRequestObject) (apispec.ReplaceSCIMUserResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if user, err := sess.PatchTeamSCIMUserByTeamAndUserId(ctx, model.Id(request.TeamId), model.Id(request.UserId), app.TeamSCIMUserPatch{
+		ExternalId: pointer(emptyIfNil(request.Body.ExternalId)),
+		UserName:   &request.Body.UserName,
+		Active:     pointer(request.Body.Active == nil || *request.Body.Active),
+	}); err != nil {
+		return nil, err
+	} else {
+		return apispec.ReplaceSCIMUser200ApplicationScimPlusJSONResponse(SCIMUserFromModel(user)), nil
+	}
+}
+
+func (api *API) PatchSCIMUser(ctx context.Context, request apispec.PatchSCIMUserRequestObject) (apispec.PatchSCIMUserResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	patch, err := teamSCIMUserPatchFromSpec(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if user, err := sess.PatchTeamSCIMUserByTeamAndUserId(ctx, model.Id(request.TeamId), model.Id(request.UserId), patch); err != nil {
+		return nil, err
+	} else {
+		return apispec.PatchSCIMUser200ApplicationScimPlusJSONResponse(SCIMUserFromModel(user)), nil
+	}
+}
+
+func (api *API) DeleteSCIMUser(ctx context.Context, request apispec.DeleteSCIMUserRequestObject) (apispec.DeleteSCIMUserResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteTeamSCIMUserByTeamAndUserId(ctx, model.Id(request.TeamId), model.Id(request.UserId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteSCIMUser204Response{}, nil
+	}
+}
+
+func (api *API) GetSCIMGroups(ctx context.Context, request apispec.GetSCIMGroupsRequestObject) (apispec.GetSCIMGroupsResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	groups, err := sess.GetTeamSCIMGroupsByTeamId(ctx, model.Id(request.TeamId))
+	if err != nil {
+		return nil, err
+	}
+
+	if filter := emptyIfNil(request.Params.Filter); filter != "" {
+		attribute, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		var match func(*model.TeamSCIMGroup) bool
+		switch attribute {
+		case "id":
+			match = func(group *model.TeamSCIMGroup) bool { return group.Id.String() == value }
+		case "displayname":
+			match = func(group *model.TeamSCIMGroup) bool { return strings.EqualFold(group.DisplayName, value) }
+		case "externalid":
+			match = func(group *model.TeamSCIMGroup) bool { return group.ExternalId == value }
+		default:
+			return nil, app.NewUserError("Unsupported filter.")
+		}
+		groups = slices.DeleteFunc(groups, func(group *model.TeamSCIMGroup) bool { return !match(group) })
+	}
+
+	page, startIndex := scimPage(groups, request.Params.StartIndex, request.Params.Count)
+	return apispec.GetSCIMGroups200ApplicationScimPlusJSONResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(groups),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    mapSlice(page, SCIMGroupFromModel),
+	}, nil
+}
+
+func scimGroupMemberIdsFromSpec(members *[]apispec.SCIMGroupMember) []model.Id {
+	return mapSlice(emptyIfNil(members), func(member apispec.SCIMGroupMember) model.Id {
+		return model.Id(member.Value)
+	})
+}
+
+func (api *API) CreateSCIMGroup(ctx context.Context, request apispec.CreateSCIMGroupRequestObject) (apispec.CreateSCIMGroupResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if group, err := sess.CreateTeamSCIMGroup(ctx, app.CreateTeamSCIMGroupInput{
+		TeamId:      model.Id(request.TeamId),
+		ExternalId:  emptyIfNil(request.Body.ExternalId),
+		DisplayName: request.Body.DisplayName,
+		MemberIds:   scimGroupMemberIdsFromSpec(request.Body.Members),
+	}); err != nil {
+		return nil, err
+	} else {
+		return apispec.CreateSCIMGroup201ApplicationScimPlusJSONResponse(SCIMGroupFromModel(group)), nil
+	}
+}
+
+func (api *API) GetSCIMGroup(ctx context.Context, request apispec.GetSCIMGroupRequestObject) (apispec.GetSCIMGroupResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if group, err := sess.GetTeamSCIMGroupByTeamIdAndId(ctx, model.Id(request.TeamId), model.Id(request.GroupId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetSCIMGroup200ApplicationScimPlusJSONResponse(SCIMGroupFromModel(group)), nil
+	}
+}
+
+func (api *API) ReplaceSCIMGroup(ctx context.Context, request apispec.ReplaceSCIMGroupRequestObject) (apispec.ReplaceSCIMGroupResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if group, err := sess.PatchTeamSCIMGroupByTeamIdAndId(ctx, model.Id(request.TeamId), model.Id(request.GroupId), app.TeamSCIMGroupPatch{
+		ExternalId:  pointer(emptyIfNil(request.Body.ExternalId)),
+		DisplayName: &request.Body.DisplayName,
+		MemberIds:   pointer(scimGroupMemberIdsFromSpec(request.Body.Members)),
+	}); err != nil {
+		return nil, err
+	} else {
+		return apispec.ReplaceSCIMGroup200ApplicationScimPlusJSONResponse(SCIMGroupFromModel(group)), nil
+	}
+}
+
+func (api *API) PatchSCIMGroup(ctx context.Context, request apispec.PatchSCIMGroupRequestObject) (apispec.PatchSCIMGroupResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	patch, err := teamSCIMGroupPatchFromSpec(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if group, err := sess.PatchTeamSCIMGroupByTeamIdAndId(ctx, model.Id(request.TeamId), model.Id(request.GroupId), patch); err != nil {
+		return nil, err
+	} else {
+		return apispec.PatchSCIMGroup200ApplicationScimPlusJSONResponse(SCIMGroupFromModel(group)), nil
+	}
+}
+
+func (api *API) DeleteSCIMGroup(ctx context.Context, request apispec.DeleteSCIMGroupRequestObject) (apispec.DeleteSCIMGroupResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteTeamSCIMGroupByTeamIdAndId(ctx, model.Id(request.TeamId), model.Id(request.GroupId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteSCIMGroup204Response{}, nil
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	attribute, value, err := parseSCIMFilter(`userName eq "alice@example.com"`)
+	require.NoError(t, err)
+	assert.Equal(t, "username", attribute)
+	assert.Equal(t, "alice@example.com", value)
+
+	attribute, value, err = parseSCIMFilter(`displayName EQ "The \"Admins\""`)
+	require.NoError(t, err)
+	assert.Equal(t, "displayname", attribute)
+	assert.Equal(t, `The "Admins"`, value)
+
+	_, _, err = parseSCIMFilter(`userName sw "alice"`)
+	assert.Error(t, err)
+
+	_, _, err = parseSCIMFilter(`userName eq "alice" or userName eq "bob"`)
+	assert.Error(t, err)
+}
+
+func TestTeamSCIMGroupPatchFromSpec(t *testing.T) {
+	var input apispec.SCIMPatchInput
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Add", "path": "members", "value": [{"value": "u-1"}, {"value": "u-2"}]},
+			{"op": "Remove", "path": "members[value eq \"u-1\"]"},
+			{"op": "Replace", "value": {"displayName": "Admins"}}
+		]
+	}`), &input))
+
+	patch, err := teamSCIMGroupPatchFromSpec(&input)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Id{"u-2"}, patch.AddMemberIds)
+	assert.Equal(t, []model.Id{"u-1"}, patch.RemoveMemberIds)
+	assert.Equal(t, "Admins", *patch.DisplayName)
+	assert.Nil(t, patch.MemberIds)
+}
+
+func TestAPI_TeamSCIM(t *testing.T) {
+	api := NewTestAPI(t)
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := api.NewTestTeamWithSubscription(aliceCtx, app.TeamSubscriptionTierTeam)
+
+	// New accounts are limited to the team's single sign-on domains.
+	provider := apptest.NewFakeOIDCProvider(t)
+	_, err := ctxSession(aliceCtx).PutTeamOIDCConfiguration(aliceCtx, app.PutTeamOIDCConfigurationInput{
+		TeamId:              team.Id,
+		Issuer:              provider.URL,
+		ClientId:            provider.ClientId,
+		ClientSecret:        provider.ClientSecret,
+		AllowedEmailDomains: []string{"example.com"},
+		AutoJoinRole:        model.TeamMembershipRoleAnalyst,
+	})
+	require.NoError(t, err)
+
+	_, secret, err := ctxSession(aliceCtx).CreateTeamAPIKey(aliceCtx, app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "IdP",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeManageSCIM},
+	})
+	require.NoError(t, err)
+
+	baseURL := server.URL + "/teams/" + team.Id.String() + "/scim/v2"
+
+	// Makes requests the way identity providers do, including their content type and
+	// authorization scheme.
+	do := func(method, path string, body any, result any) int {
+		var reader *bytes.Reader
+		if body != nil {
+			buf, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(buf)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req, err := http.NewRequest(method, baseURL+path, reader)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		req.Header.Set("Content-Type", "application/scim+json")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if result != nil && resp.StatusCode < 300 {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+		return resp.StatusCode
+	}
+
+	var user apispec.SCIMUser
+	require.Equal(t, http.StatusCreated, do("POST", "/Users", map[string]any{
+		"schemas":    []string{scimUserSchema},
+		"userName":   "bob@example.com",
+		"externalId": "00u1",
+		"name": map[string]any{
+			"givenName":  "Bob",
+			"familyName": "Smith",
+		},
+		"active": true,
+	}, &user))
+	assert.Equal(t, "bob@example.com", user.UserName)
+	assert.True(t, user.Active)
+
+	t.Run("Filter", func(t *testing.T) {
+		var list apispec.SCIMUserListResponse
+		require.Equal(t, http.StatusOK, do("GET", "/Users?filter="+url.QueryEscape(`userName eq "BOB@example.com"`), nil, &list))
+		assert.Equal(t, 1, list.TotalResults)
+		require.Len(t, list.Resources, 1)
+		assert.Equal(t, user.Id, list.Resources[0].Id)
+
+		require.Equal(t, http.StatusOK, do("GET", "/Users?filter="+url.QueryEscape(`userName eq "carol@example.com"`), nil, &list))
+		assert.Equal(t, 0, list.TotalResults)
+		assert.Empty(t, list.Resources)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		var group apispec.SCIMGroup
+		require.Equal(t, http.StatusCreated, do("POST", "/Groups", map[string]any{
+			"schemas":     []string{scimGroupSchema},
+			"displayName": "Admins",
+		}, &group))
+
+		require.Equal(t, http.StatusOK, do("PATCH", "/Groups/"+group.Id, map[string]any{
+			"Operations": []map[string]any{
+				{"op": "add", "path": "members", "value": []map[string]any{{"value": user.Id}}},
+			},
+		}, &group))
+		require.Len(t, group.Members, 1)
+		assert.Equal(t, user.Id, group.Members[0].Value)
+
+		role := apispec.TeamMembershipRoleADMINISTRATOR
+		resp, err := api.PutTeamSCIMGroupRole(aliceCtx, apispec.PutTeamSCIMGroupRoleRequestObject{
+			TeamId:  team.Id.String(),
+			GroupId: group.Id,
+			Body: &apispec.PutTeamSCIMGroupRoleJSONRequestBody{
+				Role: &role,
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.(apispec.PutTeamSCIMGroupRole200JSONResponse).MemberCount)
+
+		membershipsResp, err := api.GetTeamMembershipsByTeamId(aliceCtx, apispec.GetTeamMembershipsByTeamIdRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+		for _, membership := range membershipsResp.(apispec.GetTeamMembershipsByTeamId200JSONResponse).Items {
+			if membership.User.Id == user.Id {
+				assert.Equal(t, apispec.TeamMembershipRoleADMINISTRATOR, membership.Membership.Role)
+			}
+		}
+	})
+
+	t.Run("Deactivate", func(t *testing.T) {
+		// Some identity providers send booleans as strings.
+		require.Equal(t, http.StatusOK, do("PATCH", "/Users/"+user.Id, map[string]any{
+			"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{
+				{"op": "Replace", "path": "active", "value": "False"},
+			},
+		}, &user))
+		assert.False(t, user.Active)
+
+		membershipsResp, err := api.GetTeamMembershipsByTeamId(aliceCtx, apispec.GetTeamMembershipsByTeamIdRequestObject{
+			TeamId: team.Id.String(),
+		})
+		require.NoError(t, err)
+		for _, membership := range membershipsResp.(apispec.GetTeamMembershipsByTeamId200JSONResponse).Items {
+			assert.NotEqual(t, user.Id, membership.User.Id)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do("DELETE", "/Users/"+user.Id, nil, nil))
+		assert.Equal(t, http.StatusNotFound, do("GET", "/Users/"+user.Id, nil, nil))
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := api.GetSCIMUsers(aliceCtx, apispec.GetSCIMUsersRequestObject{
+			TeamId: team.Id.String(),
+		})
+		assert.Error(t, err)
+	})
+}
@@ -12,6 +12,15 @@ import (
 	"github.com/ccbrown/cloud-snitch/backend/model"
 )
 
+func TestTeamMembershipRoleFromSpec(t *testing.T) {
+	assert.Equal(t, model.TeamMembershipRoleAnalyst, TeamMembershipRoleFromSpec(apispec.TeamMembershipRoleMEMBER))
+	assert.Equal(t, apispec.TeamMembershipRoleANALYST, TeamMembershipRoleFromModel(model.TeamMembershipRoleLegacyMember))
+
+	for _, role := range model.TeamMembershipRoles {
+		assert.Equal(t, role, TeamMembershipRoleFromSpec(TeamMembershipRoleFromModel(role)))
+	}
+}
+
 func TestAPI_Team(t *testing.T) {
 	api := NewTestAPI(t)
 	_, adminCtx := api.NewTestUser("admin@example.com", model.UserRoleAdministrator)
@@ -76,7 +85,8 @@ func TestAPI_Team(t *testing.T) {
 		resp, err := api.GetTeams(adminCtx, apispec.GetTeamsRequestObject{})
 		require.NoError(t, err)
 		teams := resp.(apispec.GetTeams200JSONResponse)
-		assert.Len(t, teams, 1)
+		assert.Len(t, teams.Items, 1)
+		assert.Nil(t, teams.NextCursor)
 	})
 
 	t.Run("JoinWithoutInvite", func(t *testing.T) {
@@ -94,7 +104,7 @@ func TestAPI_Team(t *testing.T) {
 				TeamId: team.Id.String(),
 				Body: &apispec.CreateTeamInviteJSONRequestBody{
 					EmailAddress: "bob@example.com",
-					Role:         apispec.TeamMembershipRoleMEMBER,
+					Role:         apispec.TeamMembershipRoleANALYST,
 				},
 			})
 			require.Error(t, err)
@@ -106,7 +116,7 @@ func TestAPI_Team(t *testing.T) {
 			TeamId: team.Id.String(),
 			Body: &apispec.CreateTeamInviteJSONRequestBody{
 				EmailAddress: "bob@example.com",
-				Role:         apispec.TeamMembershipRoleMEMBER,
+				Role:         apispec.TeamMembershipRoleANALYST,
 			},
 		})
 		require.NoError(t, err)
@@ -119,7 +129,7 @@ func TestAPI_Team(t *testing.T) {
 				TeamId: team.Id.String(),
 				Body: &apispec.CreateTeamInviteJSONRequestBody{
 					EmailAddress: "foo@example.com",
-					Role:         apispec.TeamMembershipRoleMEMBER,
+					Role:         apispec.TeamMembershipRoleANALYST,
 				},
 			})
 			require.Error(t, err)
@@ -171,7 +181,7 @@ func TestAPI_Team(t *testing.T) {
 				TeamId: team.Id.String(),
 				Body: &apispec.CreateTeamInviteJSONRequestBody{
 					EmailAddress: "bob@example.com",
-					Role:         apispec.TeamMembershipRoleMEMBER,
+					Role:         apispec.TeamMembershipRoleANALYST,
 				},
 			})
 			require.NoError(t, err)
@@ -199,7 +209,7 @@ func TestAPI_Team(t *testing.T) {
 				})
 				require.NoError(t, err)
 				memberships := resp.(apispec.GetTeamMembershipsByTeamId200JSONResponse)
-				assert.Len(t, memberships, 2)
+				assert.Len(t, memberships.Items, 2)
 			})
 		})
 
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -33,6 +34,9 @@ func UserFromModel(user *model.User) apispec.User {
 	if user.HasPassword() {
 		ret.HasPassword = pointer(true)
 	}
+	if user.HasTOTP() {
+		ret.HasTotp = pointer(true)
+	}
 	if user.Role != model.UserRoleNone {
 		ret.Role = pointer(UserRoleFromModel(user.Role))
 	}
@@ -73,7 +77,11 @@ func UserRoleFromModel(role model.UserRole) apispec.UserRole {
 func sessionWithCredentials(ctx context.Context, userCredentials *apispec.UserCredentials) (*app.Session, error) {
 	sess := ctxSession(ctx)
 	if creds, _ := userCredentials.AsUserEmailAddressAndPasswordCredentials(); creds.EmailAddress != "" && creds.Password != "" {
-		return sess.WithUserCredentials(ctx, creds.EmailAddress, creds.Password)
+		var totpCode string
+		if creds.TotpCode != nil {
+			totpCode = *creds.TotpCode
+		}
+		return sess.WithUserCredentials(ctx, creds.EmailAddress, creds.Password, totpCode)
 	} else if creds, _ := userCredentials.AsUserPasskeyCredentials(); creds.SessionId != "" {
 		if response, err := reshape[protocol.CredentialAssertionResponse](creds.CredentialAssertionResponse); err != nil {
 			return nil, err
@@ -82,7 +90,13 @@ func sessionWithCredentials(ctx context.Context, userCredentials *apispec.UserCr
 		}
 	} else if creds, _ := userCredentials.AsUserEmailCredentials(); creds.Token != "" {
 		token, _ := base64.RawURLEncoding.DecodeString(creds.Token)
-		return sess.WithUserEmailAuthentication(ctx, token)
+		var totpCode string
+		if creds.TotpCode != nil {
+			totpCode = *creds.TotpCode
+		}
+		return sess.WithUserEmailAuthentication(ctx, token, totpCode)
+	} else if creds, _ := userCredentials.AsUserOIDCCredentials(); creds.State != "" && creds.Code != "" {
+		return sess.WithUserOIDCAuthentication(ctx, creds.State, creds.Code)
 	} else {
 		return nil, nil
 	}
@@ -118,13 +132,73 @@ func (*API) SignOut(ctx context.Context, request apispec.SignOutRequestObject) (
 	}
 }
 
+func UserSessionFromModel(token *model.UserAccessToken, current bool) apispec.UserSession {
+	ret := apispec.UserSession{
+		Id:             token.Id().String(),
+		CreationTime:   token.CreationTime,
+		ExpirationTime: token.ExpirationTime,
+		Current:        current,
+	}
+	if !token.LastUseTime.IsZero() {
+		ret.LastUseTime = pointer(token.LastUseTime)
+	}
+	if token.CreationIPAddress != "" {
+		ret.IpAddress = pointer(token.CreationIPAddress)
+	}
+	if token.UserAgent != "" {
+		ret.UserAgent = pointer(token.UserAgent)
+	}
+	return ret
+}
+
+func (api *API) GetUserSessions(ctx context.Context, request apispec.GetUserSessionsRequestObject) (apispec.GetUserSessionsResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if tokens, err := sess.GetUserAccessTokensByUserId(ctx, UserIdFromRequest(sess, request.UserId)); err != nil {
+		return nil, err
+	} else {
+		current := sess.UserAccessToken()
+		return apispec.GetUserSessions200JSONResponse(mapSlice(tokens, func(token *model.UserAccessToken) apispec.UserSession {
+			return UserSessionFromModel(token, current != nil && bytes.Equal(current.Hash, token.Hash))
+		})), nil
+	}
+}
+
+func (api *API) DeleteUserSession(ctx context.Context, request apispec.DeleteUserSessionRequestObject) (apispec.DeleteUserSessionResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteUserAccessTokenByUserIdAndId(ctx, UserIdFromRequest(sess, request.UserId), model.Id(request.SessionId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteUserSession200Response{}, nil
+	}
+}
+
+func (api *API) SignOutEverywhere(ctx context.Context, request apispec.SignOutEverywhereRequestObject) (apispec.SignOutEverywhereResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.SignOutEverywhere(ctx, UserIdFromRequest(sess, request.UserId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.SignOutEverywhere200Response{}, nil
+	}
+}
+
 func (api *API) GetUsers(ctx context.Context, request apispec.GetUsersRequestObject) (apispec.GetUsersResponseObject, error) {
 	sess := ctxSession(ctx)
 
-	if users, err := sess.GetUsers(ctx); err != nil {
+	input := app.PageInput{
+		Limit:  emptyIfNil(request.Params.Limit),
+		Cursor: emptyIfNil(request.Params.Cursor),
+	}
+
+	if page, err := sess.GetUsers(ctx, input); err != nil {
 		return nil, err
 	} else {
-		return apispec.GetUsers200JSONResponse(mapSlice(users, UserFromModel)), nil
+		return apispec.GetUsers200JSONResponse{
+			Items:      mapSlice(page.Items, UserFromModel),
+			NextCursor: nilIfEmpty(page.NextCursor),
+		}, nil
 	}
 }
 
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -38,7 +39,37 @@ func TestAPI_GetUser(t *testing.T) {
 		resp, err := api.GetUsers(adminCtx, apispec.GetUsersRequestObject{})
 		require.NoError(t, err)
 		users := resp.(apispec.GetUsers200JSONResponse)
-		assert.Len(t, users, 2)
+		assert.Len(t, users.Items, 2)
+
+		t.Run("Pages", func(t *testing.T) {
+			resp, err := api.GetUsers(adminCtx, apispec.GetUsersRequestObject{
+				Params: apispec.GetUsersParams{
+					Limit: pointer(1),
+				},
+			})
+			require.NoError(t, err)
+			first := resp.(apispec.GetUsers200JSONResponse)
+			require.Len(t, first.Items, 1)
+			require.NotNil(t, first.NextCursor)
+
+			resp, err = api.GetUsers(adminCtx, apispec.GetUsersRequestObject{
+				Params: apispec.GetUsersParams{
+					Limit:  pointer(1),
+					Cursor: first.NextCursor,
+				},
+			})
+			require.NoError(t, err)
+			second := resp.(apispec.GetUsers200JSONResponse)
+			require.Len(t, second.Items, 1)
+			assert.NotEqual(t, first.Items[0].Id, second.Items[0].Id)
+
+			_, err = api.GetUsers(adminCtx, apispec.GetUsersRequestObject{
+				Params: apispec.GetUsersParams{
+					Cursor: pointer("bogus"),
+				},
+			})
+			require.Error(t, err)
+		})
 	})
 
 	t.Run("BadAuth", func(t *testing.T) {
@@ -246,3 +277,56 @@ func TestAPI_UserEmailAuthentication(t *testing.T) {
 
 	assert.Equal(t, "alice@example.com", user.EmailAddress)
 }
+
+func TestAPI_UserSessions(t *testing.T) {
+	api := NewTestAPI(t)
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	var err error
+
+	token, err := ctxSession(aliceCtx).CreateUserAccessToken(aliceCtx)
+	require.NoError(t, err)
+	encodedToken := base64.RawURLEncoding.EncodeToString(token)
+
+	getSelf := func() int {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/users/self", nil)
+		require.NoError(t, err)
+		r.Header.Set("Authorization", "token "+encodedToken)
+		r.Header.Set("User-Agent", "test-agent")
+		api.ServeHTTP(w, r)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, getSelf())
+
+	resp, err := api.GetUserSessions(aliceCtx, apispec.GetUserSessionsRequestObject{
+		UserId: "self",
+	})
+	require.NoError(t, err)
+	sessions := resp.(apispec.GetUserSessions200JSONResponse)
+	require.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := api.DeleteUserSession(aliceCtx, apispec.DeleteUserSessionRequestObject{
+			UserId:    "self",
+			SessionId: sessions[0].Id,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, getSelf())
+	})
+
+	t.Run("SignOutEverywhere", func(t *testing.T) {
+		_, err := api.SignOutEverywhere(aliceCtx, apispec.SignOutEverywhereRequestObject{
+			UserId: "self",
+		})
+		require.NoError(t, err)
+
+		resp, err := api.GetUserSessions(aliceCtx, apispec.GetUserSessionsRequestObject{
+			UserId: "self",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.(apispec.GetUserSessions200JSONResponse))
+	})
+}
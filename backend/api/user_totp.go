@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+)
+
+func (api *API) BeginUserTOTPEnrollment(ctx context.Context, request apispec.BeginUserTOTPEnrollmentRequestObject) (apispec.BeginUserTOTPEnrollmentResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if !sess.HasUserId(UserIdFromRequest(sess, request.UserId)) {
+		return nil, app.AuthorizationError{}
+	}
+
+	if output, err := sess.BeginUserTOTPEnrollment(ctx); err != nil {
+		return nil, err
+	} else {
+		return apispec.BeginUserTOTPEnrollment200JSONResponse{
+			Secret:          output.Secret,
+			ProvisioningUri: output.ProvisioningURI,
+			RecoveryCodes:   output.RecoveryCodes,
+		}, nil
+	}
+}
+
+func (api *API) CompleteUserTOTPEnrollment(ctx context.Context, request apispec.CompleteUserTOTPEnrollmentRequestObject) (apispec.CompleteUserTOTPEnrollmentResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if !sess.HasUserId(UserIdFromRequest(sess, request.UserId)) {
+		return nil, app.AuthorizationError{}
+	}
+
+	if user, err := sess.CompleteUserTOTPEnrollment(ctx, request.Body.Code); err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, app.NotFoundError("No such user.")
+	} else {
+		return apispec.CompleteUserTOTPEnrollment200JSONResponse(UserFromModel(user)), nil
+	}
+}
+
+func (api *API) DeleteUserTOTP(ctx context.Context, request apispec.DeleteUserTOTPRequestObject) (apispec.DeleteUserTOTPResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	code := ""
+	if request.Body != nil && request.Body.Code != nil {
+		code = *request.Body.Code
+	}
+
+	if user, err := sess.DeleteUserTOTPByUserId(ctx, UserIdFromRequest(sess, request.UserId), code); err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, app.NotFoundError("No such user.")
+	} else {
+		return apispec.DeleteUserTOTP200JSONResponse(UserFromModel(user)), nil
+	}
+}
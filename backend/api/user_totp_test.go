@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestAPI_UserTOTP(t *testing.T) {
+	api := NewTestAPI(t)
+	_, aliceCtx := api.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	password := "correct horse battery staple"
+	_, err := api.UpdateUser(aliceCtx, apispec.UpdateUserRequestObject{
+		UserId: "self",
+		Body: &apispec.UpdateUserJSONRequestBody{
+			Password: &password,
+		},
+	})
+	require.NoError(t, err)
+
+	beginResp, err := api.BeginUserTOTPEnrollment(aliceCtx, apispec.BeginUserTOTPEnrollmentRequestObject{
+		UserId: "self",
+	})
+	require.NoError(t, err)
+	enrollment := beginResp.(apispec.BeginUserTOTPEnrollment200JSONResponse)
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+
+	completeResp, err := api.CompleteUserTOTPEnrollment(aliceCtx, apispec.CompleteUserTOTPEnrollmentRequestObject{
+		UserId: "self",
+		Body: &apispec.CompleteUserTOTPEnrollmentJSONRequestBody{
+			Code: model.TOTPCode(secret, model.TOTPTimeStep(time.Now())),
+		},
+	})
+	require.NoError(t, err)
+	user := completeResp.(apispec.CompleteUserTOTPEnrollment200JSONResponse)
+	require.NotNil(t, user.HasTotp)
+	assert.True(t, *user.HasTotp)
+
+	authenticate := func(body map[string]any) (int, []byte) {
+		buf, err := json.Marshal(body)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("POST", "/authenticate", bytes.NewReader(buf))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/json")
+		api.ServeHTTP(w, r)
+		return w.Code, w.Body.Bytes()
+	}
+
+	t.Run("CodeRequired", func(t *testing.T) {
+		status, body := authenticate(map[string]any{
+			"emailAddress": "alice@example.com",
+			"password":     password,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		var apiErr apispec.Error
+		require.NoError(t, json.Unmarshal(body, &apiErr))
+		require.NotNil(t, apiErr.Code)
+		assert.Equal(t, "SECOND_FACTOR_REQUIRED", *apiErr.Code)
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		status, body := authenticate(map[string]any{
+			"emailAddress": "alice@example.com",
+			"password":     password,
+			"totpCode":     enrollment.RecoveryCodes[0],
+		})
+		require.Equal(t, http.StatusOK, status)
+
+		var output apispec.AuthenticateOutput
+		require.NoError(t, json.Unmarshal(body, &output))
+		assert.NotEmpty(t, output.Token)
+	})
+
+	t.Run("EmailAuthentication", func(t *testing.T) {
+		_, err := api.BeginUserEmailAuthentication(api.AnonymousContext, apispec.BeginUserEmailAuthenticationRequestObject{
+			Body: &apispec.BeginUserEmailAuthenticationJSONRequestBody{
+				EmailAddress: "alice@example.com",
+			},
+		})
+		require.NoError(t, err)
+		email := <-api.app.Emails()
+		token := regexp.MustCompile(`token=([a-zA-Z0-9_\-]+)`).FindStringSubmatch(email.HTML)[1]
+
+		status, body := authenticate(map[string]any{
+			"token": token,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		var apiErr apispec.Error
+		require.NoError(t, json.Unmarshal(body, &apiErr))
+		require.NotNil(t, apiErr.Code)
+		assert.Equal(t, "SECOND_FACTOR_REQUIRED", *apiErr.Code)
+
+		// The token can be used again once the code is given.
+		status, body = authenticate(map[string]any{
+			"token":    token,
+			"totpCode": enrollment.RecoveryCodes[1],
+		})
+		require.Equal(t, http.StatusOK, status)
+
+		var output apispec.AuthenticateOutput
+		require.NoError(t, json.Unmarshal(body, &output))
+		assert.NotEmpty(t, output.Token)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := api.DeleteUserTOTP(aliceCtx, apispec.DeleteUserTOTPRequestObject{
+			UserId: "self",
+			Body:   &apispec.DeleteUserTOTPJSONRequestBody{},
+		})
+		assert.Error(t, err)
+
+		resp, err := api.DeleteUserTOTP(aliceCtx, apispec.DeleteUserTOTPRequestObject{
+			UserId: "self",
+			Body: &apispec.DeleteUserTOTPJSONRequestBody{
+				Code: &enrollment.RecoveryCodes[2],
+			},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, resp.(apispec.DeleteUserTOTP200JSONResponse).HasTotp)
+	})
+}
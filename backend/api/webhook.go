@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccbrown/cloud-snitch/backend/api/apispec"
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Unknown types are mapped to the zero value, which the app rejects as invalid.
+func WebhookEventTypeFromSpec(t apispec.WebhookEventType) model.WebhookEventType {
+	switch t {
+	case apispec.REPORTGENERATED:
+		return model.WebhookEventTypeReportGenerated
+	case apispec.INTEGRATIONFAILURE:
+		return model.WebhookEventTypeIntegrationFailure
+	case apispec.SCPCHANGED:
+		return model.WebhookEventTypeSCPChanged
+	case apispec.ALERTTRIGGERED:
+		return model.WebhookEventTypeAlertTriggered
+	default:
+		return ""
+	}
+}
+
+func WebhookEventTypeFromModel(t model.WebhookEventType) apispec.WebhookEventType {
+	switch t {
+	case model.WebhookEventTypeReportGenerated:
+		return apispec.REPORTGENERATED
+	case model.WebhookEventTypeIntegrationFailure:
+		return apispec.INTEGRATIONFAILURE
+	case model.WebhookEventTypeSCPChanged:
+		return apispec.SCPCHANGED
+	case model.WebhookEventTypeAlertTriggered:
+		return apispec.ALERTTRIGGERED
+	default:
+		panic(fmt.Sprintf("unexpected webhook event type: %v", string(t)))
+	}
+}
+
+func WebhookFromModel(webhook *model.Webhook) apispec.Webhook {
+	return apispec.Webhook{
+		Id:           webhook.Id.String(),
+		TeamId:       webhook.TeamId.String(),
+		CreationTime: webhook.CreationTime,
+		Url:          webhook.URL,
+		EventTypes:   mapSlice(webhook.EventTypes, WebhookEventTypeFromModel),
+	}
+}
+
+func WebhookDeliveryFromModel(delivery *model.WebhookDelivery) apispec.WebhookDelivery {
+	ret := apispec.WebhookDelivery{
+		Id:                   delivery.Id.String(),
+		CreationTime:         delivery.CreationTime,
+		EventId:              delivery.EventId.String(),
+		EventType:            WebhookEventTypeFromModel(delivery.EventType),
+		Attempt:              delivery.Attempt,
+		ErrorMessage:         nilIfEmpty(delivery.ErrorMessage),
+		DurationMilliseconds: int(delivery.Duration.Milliseconds()),
+	}
+	if delivery.StatusCode != 0 {
+		ret.StatusCode = pointer(delivery.StatusCode)
+	}
+	return ret
+}
+
+func (api *API) GetWebhooksByTeamId(ctx context.Context, request apispec.GetWebhooksByTeamIdRequestObject) (apispec.GetWebhooksByTeamIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if webhooks, err := sess.GetWebhooksByTeamId(ctx, model.Id(request.TeamId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetWebhooksByTeamId200JSONResponse(mapSlice(webhooks, WebhookFromModel)), nil
+	}
+}
+
+func (api *API) CreateWebhook(ctx context.Context, request apispec.CreateWebhookRequestObject) (apispec.CreateWebhookResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if webhook, secret, err := sess.CreateWebhook(ctx, app.CreateWebhookInput{
+		TeamId:     model.Id(request.TeamId),
+		URL:        request.Body.Url,
+		EventTypes: mapSlice(request.Body.EventTypes, WebhookEventTypeFromSpec),
+		Secret:     emptyIfNil(request.Body.Secret),
+	}); err != nil {
+		return nil, err
+	} else {
+		ret := WebhookFromModel(webhook)
+		ret.Secret = &secret
+		return apispec.CreateWebhook200JSONResponse(ret), nil
+	}
+}
+
+func (api *API) DeleteWebhook(ctx context.Context, request apispec.DeleteWebhookRequestObject) (apispec.DeleteWebhookResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if err := sess.DeleteWebhookById(ctx, model.Id(request.WebhookId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.DeleteWebhook200JSONResponse{}, nil
+	}
+}
+
+func (api *API) GetWebhookDeliveriesByWebhookId(ctx context.Context, request apispec.GetWebhookDeliveriesByWebhookIdRequestObject) (apispec.GetWebhookDeliveriesByWebhookIdResponseObject, error) {
+	sess := ctxSession(ctx)
+
+	if deliveries, err := sess.GetWebhookDeliveriesByWebhookId(ctx, model.Id(request.WebhookId)); err != nil {
+		return nil, err
+	} else {
+		return apispec.GetWebhookDeliveriesByWebhookId200JSONResponse(mapSlice(deliveries, WebhookDeliveryFromModel)), nil
+	}
+}
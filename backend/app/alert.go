@@ -0,0 +1,325 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+// Alerts with the same fingerprint are only created once within this window.
+const alertDeduplicationDuration = 24 * time.Hour
+
+// How long alerts are kept after they're created.
+const alertRetentionDuration = 90 * 24 * time.Hour
+
+// Principals with fewer events than this never trigger error rate rules. Otherwise a single failed
+// call would be a 100% error rate.
+const minErrorRateAlertEventCount = 10
+
+type CreateAlertRuleInput struct {
+	TeamId              model.Id
+	Name                string
+	Type                model.AlertRuleType
+	PrincipalKey        string
+	AllowedCountryCodes []string
+	EventPattern        string
+	ErrorRateThreshold  float64
+}
+
+var countryCodeRegexp = regexp.MustCompile(`^[A-Z]{2}$`)
+
+func (s *Session) CreateAlertRule(ctx context.Context, input CreateAlertRuleInput) (*model.AlertRule, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, err
+	}
+
+	if input.Name == "" {
+		return nil, NewUserError("A name is required.")
+	} else if len(input.Name) > 100 {
+		return nil, NewUserError("Names are limited to 100 characters.")
+	}
+
+	if input.PrincipalKey != "" {
+		if err := ValidatePrincipalId(input.PrincipalKey); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &model.AlertRule{
+		Id:           model.NewAlertRuleId(),
+		TeamId:       input.TeamId,
+		CreationTime: time.Now(),
+		Name:         input.Name,
+		Type:         input.Type,
+		PrincipalKey: input.PrincipalKey,
+	}
+
+	switch input.Type {
+	case model.AlertRuleTypeUnexpectedCountry:
+		if len(input.AllowedCountryCodes) == 0 {
+			return nil, NewUserError("At least one allowed country is required.")
+		}
+		for _, code := range input.AllowedCountryCodes {
+			if !countryCodeRegexp.MatchString(code) {
+				return nil, NewUserError("Country codes must be two uppercase letters.")
+			}
+		}
+		rule.AllowedCountryCodes = input.AllowedCountryCodes
+	case model.AlertRuleTypeEventPattern:
+		if input.EventPattern == "" {
+			return nil, NewUserError("An event pattern is required.")
+		} else if len(input.EventPattern) > 256 {
+			return nil, NewUserError("Event patterns are limited to 256 characters.")
+		}
+		rule.EventPattern = input.EventPattern
+	case model.AlertRuleTypeErrorRate:
+		if input.ErrorRateThreshold <= 0 || input.ErrorRateThreshold > 100 {
+			return nil, NewUserError("The error rate threshold must be a percentage greater than 0.")
+		}
+		rule.ErrorRateThreshold = input.ErrorRateThreshold
+	default:
+		return nil, NewUserError("Invalid alert rule type.")
+	}
+
+	if err := s.app.store.PutAlertRule(ctx, rule); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, rule.TeamId, model.AuditEventActionAlertRuleCreate, rule.Id.String(), nil, rule)
+	return rule, nil
+}
+
+func (s *Session) GetAlertRulesByTeamId(ctx context.Context, teamId model.Id) ([]*model.AlertRule, UserFacingError) {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionReadReports); err != nil {
+		return nil, err
+	}
+	rules, err := s.app.store.GetAlertRulesByTeamId(ctx, teamId)
+	return rules, s.SanitizedError(err)
+}
+
+func (s *Session) DeleteAlertRuleById(ctx context.Context, id model.Id) UserFacingError {
+	rule, err := s.app.store.GetAlertRuleById(ctx, id)
+	if err != nil || rule == nil {
+		return s.SanitizedError(err)
+	} else if err := s.RequireTeamAdministrator(ctx, rule.TeamId); err != nil {
+		return err
+	} else if err := s.app.store.DeleteAlertRuleById(ctx, id); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, rule.TeamId, model.AuditEventActionAlertRuleDelete, rule.Id.String(), rule, nil)
+	return nil
+}
+
+// Gets the team's unexpired alerts, most recent first.
+func (s *Session) GetAlertsByTeamId(ctx context.Context, teamId model.Id) ([]*model.Alert, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, teamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	}
+	alerts, err := s.app.store.GetAlertsByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	now := time.Now()
+	ret := make([]*model.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.ExpirationTime.After(now) {
+			ret = append(ret, alert)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].CreationTime.After(ret[j].CreationTime)
+	})
+	return ret, nil
+}
+
+type alertFinding struct {
+	Fingerprint  string
+	PrincipalKey string
+	Message      string
+}
+
+func principalDisplayName(key string, principal *report.Principal) string {
+	if principal.Name != "" {
+		return principal.Name
+	}
+	return key
+}
+
+// Converts a pattern such as "iam:Create*" into a case-insensitive regular expression.
+func eventPatternRegexp(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+}
+
+func evaluateAlertRule(rule *model.AlertRule, r *report.Report) []alertFinding {
+	var ret []alertFinding
+
+	var eventPattern *regexp.Regexp
+	if rule.Type == model.AlertRuleTypeEventPattern {
+		eventPattern = eventPatternRegexp(rule.EventPattern)
+	}
+
+	for key, principal := range r.Principals {
+		if rule.PrincipalKey != "" && rule.PrincipalKey != key && rule.PrincipalKey != principal.ARN {
+			continue
+		}
+		name := principalDisplayName(key, principal)
+
+		switch rule.Type {
+		case model.AlertRuleTypeUnexpectedCountry:
+			ipsByCountry := map[string][]string{}
+			countryNames := map[string]string{}
+			for ip := range principal.IPAddresses {
+				network := r.IPAddressNetworks[ip]
+				if network == nil {
+					continue
+				}
+				location := r.NetworkLocations[*network]
+				if location == nil || location.CountryCode == "" || slices.Contains(rule.AllowedCountryCodes, location.CountryCode) {
+					continue
+				}
+				ipsByCountry[location.CountryCode] = append(ipsByCountry[location.CountryCode], ip)
+				countryNames[location.CountryCode] = location.CountryName
+			}
+			for code, ips := range ipsByCountry {
+				sort.Strings(ips)
+				ret = append(ret, alertFinding{
+					Fingerprint:  key + ":" + code,
+					PrincipalKey: key,
+					Message:      fmt.Sprintf("%v made calls from %v (%v).", name, countryNames[code], strings.Join(ips, ", ")),
+				})
+			}
+		case model.AlertRuleTypeEventPattern:
+			for _, event := range principal.Events {
+				action := AWSServiceForEventSource(event.Source).Namespace + ":" + event.Name
+				if !eventPattern.MatchString(action) {
+					continue
+				}
+				ret = append(ret, alertFinding{
+					Fingerprint:  key + ":" + action,
+					PrincipalKey: key,
+					Message:      fmt.Sprintf("%v called %v %v time(s).", name, action, event.Count),
+				})
+			}
+		case model.AlertRuleTypeErrorRate:
+			total, errors := 0, 0
+			for _, event := range principal.Events {
+				total += event.Count
+				for _, count := range event.ErrorCodes {
+					errors += count
+				}
+			}
+			if total < minErrorRateAlertEventCount {
+				continue
+			}
+			if rate := 100 * float64(errors) / float64(total); rate > rule.ErrorRateThreshold {
+				ret = append(ret, alertFinding{
+					Fingerprint:  key,
+					PrincipalKey: key,
+					Message:      fmt.Sprintf("%.1f%% of %v's %v calls resulted in errors.", rate, name, total),
+				})
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Fingerprint < ret[j].Fingerprint
+	})
+	return ret
+}
+
+// Evaluates the team's alert rules against a newly generated report. New alerts are persisted and
+// emailed to the team's members. Alerts that duplicate recent ones are ignored.
+func (a *App) evaluateAlertRules(ctx context.Context, reportModel *model.Report, r *report.Report) ([]*model.Alert, error) {
+	rules, err := a.store.GetAlertRulesByTeamId(ctx, reportModel.TeamId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules: %w", err)
+	} else if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var alerts []*model.Alert
+	var emailAlerts []map[string]any
+
+	for _, rule := range rules {
+		for _, finding := range evaluateAlertRule(rule, r) {
+			now := time.Now()
+			alert := &model.Alert{
+				Id:             model.NewAlertId(),
+				TeamId:         rule.TeamId,
+				AlertRuleId:    rule.Id,
+				CreationTime:   now,
+				ExpirationTime: now.Add(alertRetentionDuration),
+				Fingerprint:    finding.Fingerprint,
+				ReportId:       reportModel.Id,
+				PrincipalKey:   finding.PrincipalKey,
+				Message:        finding.Message,
+			}
+			if created, err := a.store.CreateAlert(ctx, alert, now.Add(alertDeduplicationDuration)); err != nil {
+				return nil, fmt.Errorf("failed to create alert: %w", err)
+			} else if !created {
+				continue
+			}
+			alerts = append(alerts, alert)
+			if err := a.emitWebhookEvent(ctx, alert.TeamId, model.WebhookEventTypeAlertTriggered, map[string]any{
+				"alertId":      alert.Id,
+				"alertRuleId":  rule.Id,
+				"ruleName":     rule.Name,
+				"reportId":     alert.ReportId,
+				"principalKey": alert.PrincipalKey,
+				"message":      alert.Message,
+			}); err != nil {
+				// The alert was already created, so don't report this as a failure.
+				zap.L().Error("failed to emit alert triggered webhook event", zap.String("alert_id", alert.Id.String()), zap.Error(err))
+			}
+			emailAlerts = append(emailAlerts, map[string]any{
+				"RuleName": rule.Name,
+				"Message":  alert.Message,
+			})
+		}
+	}
+
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	// The alerts were already created, so delivery failures are logged rather than returned.
+	if err := a.emailAlerts(ctx, reportModel.TeamId, emailAlerts); err != nil {
+		zap.L().Error("failed to email alerts", zap.String("team_id", reportModel.TeamId.String()), zap.Error(err))
+	}
+
+	return alerts, nil
+}
+
+func (a *App) emailAlerts(ctx context.Context, teamId model.Id, emailAlerts []map[string]any) error {
+	team, err := a.store.GetTeamById(ctx, teamId, store.ConsistencyEventual)
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	} else if team == nil {
+		return nil
+	}
+
+	users, err := a.getTeamMemberUsers(ctx, team.Id, model.TeamPermissionReadReports)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := a.Email(ctx, user.EmailAddress, "Alert: "+team.Name, "alert_email.html.tmpl", map[string]any{
+			"TeamId":   team.Id,
+			"TeamName": team.Name,
+			"Alerts":   emailAlerts,
+		}); err != nil {
+			return fmt.Errorf("failed to send alert email: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestAlertRules(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	integration, err := sess.CreateAWSIntegration(context.Background(), app.CreateAWSIntegrationInput{
+		TeamId:  team.Id,
+		Name:    "My Integration",
+		RoleARN: "arn:aws:iam::123456789012:role/MyRole",
+		CloudTrailTrail: &app.CreateAWSIntegrationCloudTrailTrailInput{
+			S3BucketName: "aws-cloudtrail-logs",
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := sess.CreateAlertRule(context.Background(), app.CreateAlertRuleInput{
+			TeamId: team.Id,
+			Name:   "Bad Countries",
+			Type:   model.AlertRuleTypeUnexpectedCountry,
+		})
+		assert.Error(t, err)
+
+		_, err = sess.CreateAlertRule(context.Background(), app.CreateAlertRuleInput{
+			TeamId:             team.Id,
+			Name:               "Bad Error Rate",
+			Type:               model.AlertRuleTypeErrorRate,
+			ErrorRateThreshold: 101,
+		})
+		assert.Error(t, err)
+	})
+
+	countryRule, err := sess.CreateAlertRule(context.Background(), app.CreateAlertRuleInput{
+		TeamId:              team.Id,
+		Name:                "Outside the US",
+		Type:                model.AlertRuleTypeUnexpectedCountry,
+		AllowedCountryCodes: []string{"US"},
+	})
+	require.NoError(t, err)
+
+	_, err = sess.CreateAlertRule(context.Background(), app.CreateAlertRuleInput{
+		TeamId:       team.Id,
+		Name:         "SSM Updates",
+		Type:         model.AlertRuleTypeEventPattern,
+		PrincipalKey: "arn:aws:iam::222222222222:role/ecs-cluster-instance",
+		EventPattern: "ssm:update*",
+	})
+	require.NoError(t, err)
+
+	rules, err := sess.GetAlertRulesByTeamId(context.Background(), team.Id)
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	generateReport := func() *model.Report {
+		report, err := a.GenerateAWSCloudTrailReport(context.Background(), app.GenerateAWSCloudTrailReportInput{
+			FutureReportId:    model.NewReportId(),
+			AWSIntegrationId:  integration.Id,
+			StartTime:         time.Date(2025, 3, 6, 2, 25, 0, 0, time.UTC),
+			Duration:          60 * time.Minute,
+			AccountsKeyPrefix: "AWSLogs/o-1234abcde/",
+			AccountId:         "222222222222",
+			Region:            "us-east-1",
+			BucketRegion:      "us-east-1",
+			Retention:         model.ReportRetentionOneWeek,
+		})
+		require.NoError(t, err)
+		return report
+	}
+
+	report := generateReport()
+
+	alerts, err := sess.GetAlertsByTeamId(context.Background(), team.Id)
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	messages := []string{alerts[0].Message, alerts[1].Message}
+	assert.Contains(t, messages, "arn:aws:iam::222222222222:user/chris made calls from China (123.12.3.4).")
+	assert.Contains(t, messages, "arn:aws:iam::222222222222:role/ecs-cluster-instance called ssm:UpdateInstanceInformation 1 time(s).")
+	for _, alert := range alerts {
+		assert.Equal(t, report.Id, alert.ReportId)
+	}
+
+	for {
+		email := <-a.Emails()
+		if email.Subject == "Alert: Test Team" {
+			assert.Equal(t, "alice@example.com", email.To)
+			assert.Contains(t, email.HTML, "made calls from China")
+			break
+		}
+	}
+
+	t.Run("Deduplication", func(t *testing.T) {
+		generateReport()
+
+		alerts, err := sess.GetAlertsByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		assert.Len(t, alerts, 2)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, sess.DeleteAlertRuleById(context.Background(), countryRule.Id))
+
+		rules, err := sess.GetAlertRulesByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		assert.Len(t, rules, 1)
+	})
+
+	t.Run("NonMember", func(t *testing.T) {
+		_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+
+		_, err := otherSess.GetAlertRulesByTeamId(context.Background(), team.Id)
+		assert.Error(t, err)
+
+		_, err = otherSess.GetAlertsByTeamId(context.Background(), team.Id)
+		assert.Error(t, err)
+	})
+}
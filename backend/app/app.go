@@ -8,9 +8,11 @@ import (
 	"encoding/pem"
 	"fmt"
 	"html/template"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -61,6 +63,11 @@ type App struct {
 	iamFactory           AWSIAMAPIFactory
 	urlSigner            *sign.URLSigner
 	stripe               *client.API
+	httpClient           *http.Client
+	webhookHTTPClient    *http.Client
+	oidcHTTPClient       *http.Client
+	siemHTTPClient       *http.Client
+	rateLimiter          RateLimiter
 }
 
 func New(cfg Config) (*App, error) {
@@ -80,6 +87,11 @@ func New(cfg Config) (*App, error) {
 		return nil, fmt.Errorf("unable to initialize store: %w", err)
 	}
 
+	rateLimiter, err := NewRateLimiter(cfg.RateLimitBackend, store)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize rate limiter: %w", err)
+	}
+
 	emailer, err := NewEmailer(cfg.Email)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize emailer: %w", err)
@@ -168,6 +180,42 @@ func New(cfg Config) (*App, error) {
 		urlSigner = sign.NewURLSigner(cfg.CloudFrontKeyId, key.(crypto.Signer))
 	}
 
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+
+	webhookHTTPClient := cfg.HTTPClient
+	if webhookHTTPClient == nil {
+		if cfg.AllowInsecureWebhookURLs {
+			webhookHTTPClient = httpClient
+		} else {
+			webhookHTTPClient = newPublicHTTPClient(10 * time.Second)
+		}
+	}
+
+	// Identity provider endpoints come from team administrators and from the providers' discovery
+	// documents, so they get the same protection as webhooks.
+	oidcHTTPClient := cfg.HTTPClient
+	if oidcHTTPClient == nil {
+		if cfg.AllowInsecureOIDCIssuers {
+			oidcHTTPClient = httpClient
+		} else {
+			oidcHTTPClient = newPublicHTTPClient(10 * time.Second)
+		}
+	}
+
+	siemHTTPClient := cfg.HTTPClient
+	if siemHTTPClient == nil {
+		if cfg.AllowInsecureSIEMSinks {
+			siemHTTPClient = httpClient
+		} else {
+			siemHTTPClient = newPublicHTTPClient(10 * time.Second)
+		}
+	}
+
 	awsRegion := os.Getenv("AWS_REGION")
 	if awsRegion == "" {
 		awsRegion = "us-east-1"
@@ -187,6 +235,11 @@ func New(cfg Config) (*App, error) {
 		s3Factory:            s3Factory,
 		urlSigner:            urlSigner,
 		stripe:               stripeClient,
+		httpClient:           httpClient,
+		webhookHTTPClient:    webhookHTTPClient,
+		oidcHTTPClient:       oidcHTTPClient,
+		siemHTTPClient:       siemHTTPClient,
+		rateLimiter:          rateLimiter,
 	}, nil
 }
 
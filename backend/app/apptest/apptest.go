@@ -6,9 +6,11 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ccbrown/cloud-snitch/backend/app"
@@ -75,6 +77,10 @@ rSWtxxB19xvfLgkTclF4LJanKhKR+G6JuMRdtBHAoB/iQ6Thguuqy9X9QZYhZfyc
 		S3CDNURL:     testFrontendURL,
 		S3BucketName: "MyTestBucket",
 		SQSQueueName: "MyTestQueue",
+
+		AllowInsecureWebhookURLs: true,
+		AllowInsecureSIEMSinks:   true,
+		AllowInsecureOIDCIssuers: true,
 	}
 	a, err := app.New(cfg)
 	require.NoError(t, err)
@@ -161,3 +167,48 @@ func (a *TestApp) NewTestTeamWithSubscription(sess *app.Session, tier app.TeamSu
 func (a *TestApp) SQSRequests(region string) []*sqs.SendMessageBatchInput {
 	return a.sqsFactory.Requests(region)
 }
+
+// Decodes the messages sent to the given region's queue, skipping the first ignoreSQSRequests
+// requests.
+func (a *TestApp) SQSMessages(region string, ignoreSQSRequests int) []app.QueueMessage {
+	var ret []app.QueueMessage
+	for _, request := range a.SQSRequests(region)[ignoreSQSRequests:] {
+		for _, entry := range request.Entries {
+			var message app.QueueMessage
+			require.NoError(a.T, jsoniter.UnmarshalFromString(*entry.MessageBody, &message))
+			ret = append(ret, message)
+		}
+	}
+	return ret
+}
+
+// Creates an integration for the team and uses it to generate a report from the test CloudTrail
+// logs. The report covers an hour of activity in account 222222222222's us-east-1 region.
+func (a *TestApp) NewTestAWSCloudTrailReport(sess *app.Session, teamId model.Id) *model.Report {
+	var err error
+
+	integration, err := sess.CreateAWSIntegration(context.Background(), app.CreateAWSIntegrationInput{
+		TeamId:  teamId,
+		Name:    "My Integration",
+		RoleARN: "arn:aws:iam::123456789012:role/MyRole",
+		CloudTrailTrail: &app.CreateAWSIntegrationCloudTrailTrailInput{
+			S3BucketName: "aws-cloudtrail-logs",
+		},
+	})
+	require.NoError(a.T, err)
+
+	report, err := a.GenerateAWSCloudTrailReport(context.Background(), app.GenerateAWSCloudTrailReportInput{
+		FutureReportId:    model.NewReportId(),
+		AWSIntegrationId:  integration.Id,
+		StartTime:         time.Date(2025, 3, 6, 2, 25, 0, 0, time.UTC),
+		Duration:          60 * time.Minute,
+		AccountsKeyPrefix: "AWSLogs/o-1234abcde/",
+		AccountId:         "222222222222",
+		Region:            "us-east-1",
+		BucketRegion:      "us-east-1",
+		Retention:         model.ReportRetentionOneWeek,
+	})
+	require.NoError(a.T, err)
+
+	return report
+}
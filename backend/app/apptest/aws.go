@@ -3,6 +3,8 @@ package apptest
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -45,7 +47,10 @@ var bucketPaths = map[string]string{
 	"aws-cloudtrail-logs": "report/testdata/aws-cloudtrail-logs",
 }
 
-type TestAmazonS3API struct{}
+type TestAmazonS3API struct {
+	m       sync.Mutex
+	objects map[string][]byte
+}
 
 func (api *TestAmazonS3API) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
 	return &s3.HeadObjectOutput{}, nil
@@ -65,10 +70,45 @@ func (api *TestAmazonS3API) GetObject(ctx context.Context, params *s3.GetObjectI
 		}, nil
 	}
 
+	api.m.Lock()
+	defer api.m.Unlock()
+	if buf, ok := api.objects[*params.Bucket+"/"+*params.Key]; ok {
+		hash := md5.Sum(buf)
+		ret := &s3.GetObjectOutput{
+			ETag: aws.String(`"` + hex.EncodeToString(hash[:]) + `"`),
+		}
+		if params.Range != nil {
+			// Only the "bytes=start-end" and "bytes=start-" forms are supported here.
+			var start, end int
+			if n, _ := fmt.Sscanf(*params.Range, "bytes=%d-%d", &start, &end); n == 0 {
+				return nil, fmt.Errorf("unsupported range: %v", *params.Range)
+			} else if n == 1 || end >= len(buf) {
+				end = len(buf) - 1
+			}
+			ret.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, len(buf)))
+			buf = buf[start : end+1]
+		}
+		ret.Body = io.NopCloser(bytes.NewReader(buf))
+		ret.ContentLength = aws.Int64(int64(len(buf)))
+		return ret, nil
+	}
+
 	return &s3.GetObjectOutput{}, nil
 }
 
 func (api *TestAmazonS3API) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	buf, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	api.m.Lock()
+	defer api.m.Unlock()
+	if api.objects == nil {
+		api.objects = make(map[string][]byte)
+	}
+	api.objects[*params.Bucket+"/"+*params.Key] = buf
+
 	return &s3.PutObjectOutput{}, nil
 }
 
@@ -238,10 +278,37 @@ func (api *TestAWSOrganizationsAPI) ListAccounts(ctx context.Context, params *or
 				Name:   aws.String("Test Account"),
 				Status: organizationstypes.AccountStatusActive,
 			},
+			{
+				Id:     aws.String("210987654321"),
+				Name:   aws.String("Test Delegated Administrator Account"),
+				Status: organizationstypes.AccountStatusActive,
+			},
 		},
 	}, nil
 }
 
+func (api *TestAWSOrganizationsAPI) DescribeOrganization(ctx context.Context, params *organizations.DescribeOrganizationInput, optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error) {
+	return &organizations.DescribeOrganizationOutput{
+		Organization: &organizationstypes.Organization{
+			Id:              aws.String("o-1234"),
+			MasterAccountId: aws.String("123456789012"),
+		},
+	}, nil
+}
+
+func (api *TestAWSOrganizationsAPI) ListPolicies(ctx context.Context, params *organizations.ListPoliciesInput, optFns ...func(*organizations.Options)) (*organizations.ListPoliciesOutput, error) {
+	api.m.Lock()
+	defer api.m.Unlock()
+
+	ret := &organizations.ListPoliciesOutput{}
+	for _, policy := range api.policiesById {
+		if policy.PolicySummary.Type == params.Filter {
+			ret.Policies = append(ret.Policies, *policy.PolicySummary)
+		}
+	}
+	return ret, nil
+}
+
 func (api *TestAWSOrganizationsAPI) ListParents(ctx context.Context, params *organizations.ListParentsInput, optFns ...func(*organizations.Options)) (*organizations.ListParentsOutput, error) {
 	return &organizations.ListParentsOutput{
 		Parents: []organizationstypes.Parent{
@@ -259,7 +326,9 @@ func (api *TestAWSOrganizationsAPI) ListPoliciesForTarget(ctx context.Context, p
 
 	ret := &organizations.ListPoliciesForTargetOutput{}
 	for _, id := range api.attachedPolicyIds[*params.TargetId] {
-		ret.Policies = append(ret.Policies, *api.policiesById[id].PolicySummary)
+		if summary := api.policiesById[id].PolicySummary; summary.Type == params.Filter {
+			ret.Policies = append(ret.Policies, *summary)
+		}
 	}
 	return ret, nil
 }
@@ -301,6 +370,7 @@ func (api *TestAWSOrganizationsAPI) CreatePolicy(ctx context.Context, params *or
 		PolicySummary: &organizationstypes.PolicySummary{
 			Id:   aws.String(model.NewId("p").String()),
 			Name: params.Name,
+			Type: params.Type,
 		},
 	}
 	api.policiesById[*policy.PolicySummary.Id] = policy
@@ -336,6 +406,10 @@ func (api *TestAWSOrganizationsAPI) ListRoots(ctx context.Context, params *organ
 						Type:   organizationstypes.PolicyTypeServiceControlPolicy,
 						Status: organizationstypes.PolicyTypeStatusEnabled,
 					},
+					{
+						Type:   organizationstypes.PolicyTypeResourceControlPolicy,
+						Status: organizationstypes.PolicyTypeStatusEnabled,
+					},
 				},
 			},
 		},
@@ -0,0 +1,167 @@
+package apptest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+type FakeOIDCUser struct {
+	Subject       string
+	EmailAddress  string
+	EmailVerified bool
+}
+
+type fakeOIDCAuthorization struct {
+	User          FakeOIDCUser
+	RedirectURI   string
+	Nonce         string
+	CodeChallenge string
+}
+
+// A minimal OpenID Connect provider that supports the authorization code flow with PKCE.
+type FakeOIDCProvider struct {
+	*httptest.Server
+
+	ClientId     string
+	ClientSecret string
+
+	t              *testing.T
+	key            *rsa.PrivateKey
+	mutex          sync.Mutex
+	authorizations map[string]fakeOIDCAuthorization
+}
+
+const fakeOIDCKeyId = "test-key"
+
+func NewFakeOIDCProvider(t *testing.T) *FakeOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &FakeOIDCProvider{
+		ClientId:       "test-client",
+		ClientSecret:   "test-secret",
+		t:              t,
+		key:            key,
+		authorizations: make(map[string]fakeOIDCAuthorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		p.writeJSON(w, map[string]any{
+			"issuer":                           p.URL,
+			"authorization_endpoint":           p.URL + "/authorize",
+			"token_endpoint":                   p.URL + "/token",
+			"jwks_uri":                         p.URL + "/jwks",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.writeJSON(w, map[string]any{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": fakeOIDCKeyId,
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	mux.HandleFunc("POST /token", p.handleToken)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func (p *FakeOIDCProvider) writeJSON(w http.ResponseWriter, v any) {
+	buf, err := jsoniter.Marshal(v)
+	require.NoError(p.t, err)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf)
+}
+
+// Simulates the user signing in at the provider after being sent to the given authorization URL.
+// Returns the state and code that the provider would redirect the user back with.
+func (p *FakeOIDCProvider) Authorize(authorizationURL string, user FakeOIDCUser) (state, code string) {
+	u, err := url.Parse(authorizationURL)
+	require.NoError(p.t, err)
+	require.Equal(p.t, p.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+
+	query := u.Query()
+	require.Equal(p.t, "code", query.Get("response_type"))
+	require.Equal(p.t, p.ClientId, query.Get("client_id"))
+	require.Equal(p.t, "S256", query.Get("code_challenge_method"))
+	require.NotEmpty(p.t, query.Get("code_challenge"))
+	require.NotEmpty(p.t, query.Get("state"))
+
+	code = rand.Text()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.authorizations[code] = fakeOIDCAuthorization{
+		User:          user,
+		RedirectURI:   query.Get("redirect_uri"),
+		Nonce:         query.Get("nonce"),
+		CodeChallenge: query.Get("code_challenge"),
+	}
+	return query.Get("state"), code
+}
+
+func (p *FakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok || clientId != p.ClientId || clientSecret != p.ClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	code := r.PostFormValue("code")
+	p.mutex.Lock()
+	authorization, ok := p.authorizations[code]
+	delete(p.authorizations, code)
+	p.mutex.Unlock()
+
+	codeChallenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok ||
+		r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != authorization.RedirectURI ||
+		base64.RawURLEncoding.EncodeToString(codeChallenge[:]) != authorization.CodeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		p.writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.URL,
+		"aud":            p.ClientId,
+		"sub":            authorization.User.Subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          authorization.Nonce,
+		"email":          authorization.User.EmailAddress,
+		"email_verified": authorization.User.EmailVerified,
+	})
+	token.Header["kid"] = fakeOIDCKeyId
+	idToken, err := token.SignedString(p.key)
+	require.NoError(p.t, err)
+
+	p.writeJSON(w, map[string]any{
+		"access_token": "unused",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
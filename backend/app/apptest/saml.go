@@ -0,0 +1,146 @@
+package apptest
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/require"
+)
+
+type FakeSAMLUser struct {
+	NameId       string
+	EmailAddress string
+	Roles        []string
+}
+
+// A minimal SAML identity provider that signs responses for the HTTP-Redirect and HTTP-POST
+// bindings. Users are released with "email" and "role" attributes.
+type FakeSAMLIdentityProvider struct {
+	t   *testing.T
+	idp *saml.IdentityProvider
+}
+
+const (
+	FakeSAMLIdentityProviderEntityId = "https://idp.example.com/metadata"
+	fakeSAMLIdentityProviderSSOURL   = "https://idp.example.com/sso"
+)
+
+// Creates a new identity provider with its own signing key. All fake identity providers have the
+// same entity id.
+func NewFakeSAMLIdentityProvider(t *testing.T) *FakeSAMLIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	metadataURL, _ := url.Parse(FakeSAMLIdentityProviderEntityId)
+	ssoURL, _ := url.Parse(fakeSAMLIdentityProviderSSOURL)
+	return &FakeSAMLIdentityProvider{
+		t: t,
+		idp: &saml.IdentityProvider{
+			Key:         key,
+			Certificate: cert,
+			MetadataURL: *metadataURL,
+			SSOURL:      *ssoURL,
+		},
+	}
+}
+
+func (p *FakeSAMLIdentityProvider) MetadataXML() string {
+	buf, err := xml.Marshal(p.idp.Metadata())
+	require.NoError(p.t, err)
+	return string(buf)
+}
+
+// Simulates the user signing in at the identity provider after being sent to the given
+// authentication URL. Returns the form values that the identity provider would post to the
+// assertion consumer service.
+func (p *FakeSAMLIdentityProvider) Authenticate(authenticationURL string, user FakeSAMLUser) (samlResponse, relayState string) {
+	u, err := url.Parse(authenticationURL)
+	require.NoError(p.t, err)
+	require.Equal(p.t, fakeSAMLIdentityProviderSSOURL, u.Scheme+"://"+u.Host+u.Path)
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.NoError(p.t, err)
+	buf, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(p.t, err)
+	var request saml.AuthnRequest
+	require.NoError(p.t, xml.Unmarshal(buf, &request))
+	require.NotNil(p.t, request.Issuer)
+
+	return p.respond(request, request.Issuer.Value, request.AssertionConsumerServiceURL, user), u.Query().Get("RelayState")
+}
+
+// Simulates the user starting sign-in at the identity provider. Returns the SAML response that the
+// identity provider would post to the assertion consumer service.
+func (p *FakeSAMLIdentityProvider) Initiate(serviceProviderEntityId, assertionConsumerServiceURL string, user FakeSAMLUser) string {
+	return p.respond(saml.AuthnRequest{}, serviceProviderEntityId, assertionConsumerServiceURL, user)
+}
+
+func (p *FakeSAMLIdentityProvider) respond(request saml.AuthnRequest, serviceProviderEntityId, assertionConsumerServiceURL string, user FakeSAMLUser) string {
+	req := &saml.IdpAuthnRequest{
+		IDP:         p.idp,
+		HTTPRequest: httptest.NewRequest("GET", fakeSAMLIdentityProviderSSOURL, nil),
+		Request:     request,
+		ServiceProviderMetadata: &saml.EntityDescriptor{
+			EntityID: serviceProviderEntityId,
+		},
+		SPSSODescriptor: &saml.SPSSODescriptor{},
+		ACSEndpoint: &saml.IndexedEndpoint{
+			Binding:  saml.HTTPPostBinding,
+			Location: assertionConsumerServiceURL,
+		},
+		Now: saml.TimeNow(),
+	}
+
+	attributes := []saml.Attribute{
+		{
+			Name:   "email",
+			Values: []saml.AttributeValue{{Type: "xs:string", Value: user.EmailAddress}},
+		},
+	}
+	if len(user.Roles) > 0 {
+		attribute := saml.Attribute{Name: "role"}
+		for _, role := range user.Roles {
+			attribute.Values = append(attribute.Values, saml.AttributeValue{Type: "xs:string", Value: role})
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	now := time.Now()
+	require.NoError(p.t, saml.DefaultAssertionMaker{}.MakeAssertion(req, &saml.Session{
+		ID:               rand.Text(),
+		CreateTime:       now,
+		ExpireTime:       now.Add(time.Hour),
+		NameID:           user.NameId,
+		NameIDFormat:     string(saml.PersistentNameIDFormat),
+		CustomAttributes: attributes,
+	}))
+
+	form, err := req.PostBinding()
+	require.NoError(p.t, err)
+	return form.SAMLResponse
+}
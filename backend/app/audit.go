@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const auditEventRetention = 365 * 24 * time.Hour
+
+// If no start time is given, audit events from this far back are returned.
+const defaultAuditEventWindow = 30 * 24 * time.Hour
+
+// Marshals a value for an audit event's Before or After field. Nil values are represented by empty
+// strings.
+func auditEventValue(v any) string {
+	if v == nil {
+		return ""
+	} else if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return ""
+	}
+	buf, err := jsoniter.Marshal(v)
+	if err != nil {
+		zap.L().Error("unable to marshal audit event value", zap.Error(err))
+		return ""
+	}
+	return string(buf)
+}
+
+// Records an audit event for a change made by the session. Values containing secrets must be
+// sanitized by the caller. Failures are logged rather than returned since the change has already
+// been made by the time this is invoked.
+func (s *Session) recordAuditEvent(ctx context.Context, teamId model.Id, action model.AuditEventAction, targetId string, before, after any) {
+	now := time.Now()
+	event := &model.AuditEvent{
+		Id:             model.NewAuditEventId(),
+		TeamId:         teamId,
+		Time:           now,
+		ExpirationTime: now.Add(auditEventRetention),
+		IPAddress:      s.ipAddress,
+		Action:         action,
+		TargetId:       targetId,
+		Before:         auditEventValue(before),
+		After:          auditEventValue(after),
+	}
+	if s.user != nil {
+		event.ActorUserId = s.user.Id
+	} else if s.teamAPIKey != nil {
+		event.ActorTeamAPIKeyId = s.teamAPIKey.Id
+	}
+	if err := s.app.store.CreateAuditEvent(ctx, event); err != nil {
+		s.Logger().Error("unable to record audit event",
+			zap.Error(err),
+			zap.String("team_id", teamId.String()),
+			zap.String("action", string(action)),
+		)
+	}
+}
+
+type GetAuditEventsInput struct {
+	TeamId model.Id
+
+	// Defaults to defaultAuditEventWindow before the end time.
+	StartTime time.Time
+
+	// Defaults to the current time.
+	EndTime time.Time
+
+	// Optional filters.
+	ActorUserId       model.Id
+	ActorTeamAPIKeyId model.Id
+	Action            model.AuditEventAction
+	TargetId          string
+
+	// Filters are applied after each page is read, so pages may contain fewer items than the limit
+	// even when more are available.
+	PageInput
+}
+
+// Gets a page of the team's audit events, most recent first. Only team administrators can view
+// audit events.
+func (s *Session) GetAuditEventsByTeamId(ctx context.Context, input GetAuditEventsInput) (*Page[*model.AuditEvent], UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, err
+	}
+
+	if input.EndTime.IsZero() {
+		input.EndTime = time.Now()
+	}
+	if input.StartTime.IsZero() {
+		input.StartTime = input.EndTime.Add(-defaultAuditEventWindow)
+	}
+	if !input.EndTime.After(input.StartTime) {
+		return nil, NewUserError("The end time must be after the start time.")
+	}
+
+	pageInput, err := input.storePageInput()
+	if err != nil {
+		return nil, err
+	}
+
+	page, storeErr := s.app.store.GetAuditEventPageByTeamId(ctx, input.TeamId, store.GetAuditEventPageByTeamIdInput{
+		PageInput: pageInput,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+	})
+	if storeErr != nil {
+		return nil, s.sanitizedPageError(storeErr)
+	}
+
+	events := slices.DeleteFunc(page.Items, func(event *model.AuditEvent) bool {
+		return (input.ActorUserId != "" && event.ActorUserId != input.ActorUserId) ||
+			(input.ActorTeamAPIKeyId != "" && event.ActorTeamAPIKeyId != input.ActorTeamAPIKeyId) ||
+			(input.Action != "" && event.Action != input.Action) ||
+			(input.TargetId != "" && event.TargetId != input.TargetId)
+	})
+	return &Page[*model.AuditEvent]{
+		Items:      events,
+		NextCursor: page.Cursor,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestAuditEvents(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	user, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+
+	for _, name := range []string{"First", "Second", "Third"} {
+		_, err := sess.PatchTeamById(context.Background(), team.Id, app.TeamPatch{
+			Name: &name,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		page, err := sess.GetAuditEventsByTeamId(context.Background(), app.GetAuditEventsInput{
+			TeamId: team.Id,
+			Action: model.AuditEventActionTeamUpdate,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 3)
+
+		// The most recent event should come first.
+		event := page.Items[0]
+		assert.Equal(t, user.Id, event.ActorUserId)
+		assert.Equal(t, team.Id.String(), event.TargetId)
+		assert.Contains(t, event.Before, `"Second"`)
+		assert.Contains(t, event.After, `"Third"`)
+
+		page, err = sess.GetAuditEventsByTeamId(context.Background(), app.GetAuditEventsInput{
+			TeamId: team.Id,
+			Action: model.AuditEventActionTeamCreate,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Empty(t, page.Items[0].Before)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		var events []*model.AuditEvent
+		input := app.GetAuditEventsInput{
+			TeamId: team.Id,
+			Action: model.AuditEventActionTeamUpdate,
+			PageInput: app.PageInput{
+				Limit: 1,
+			},
+		}
+		for {
+			page, err := sess.GetAuditEventsByTeamId(context.Background(), input)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Items), 1)
+			events = append(events, page.Items...)
+			if page.NextCursor == "" {
+				break
+			}
+			input.Cursor = page.NextCursor
+		}
+		require.Len(t, events, 3)
+		assert.Contains(t, events[1].After, `"Second"`)
+
+		input.Cursor = "bogus"
+		_, err := sess.GetAuditEventsByTeamId(context.Background(), input)
+		assert.Error(t, err)
+	})
+
+	t.Run("Secrets", func(t *testing.T) {
+		_, secret, err := sess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+			TeamId: team.Id,
+			Name:   "CI",
+			Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeReadReports},
+		})
+		require.NoError(t, err)
+
+		page, err := sess.GetAuditEventsByTeamId(context.Background(), app.GetAuditEventsInput{
+			TeamId: team.Id,
+			Action: model.AuditEventActionTeamAPIKeyCreate,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Contains(t, page.Items[0].After, `"CI"`)
+		assert.NotContains(t, page.Items[0].After, "Hash")
+		assert.NotContains(t, page.Items[0].After, secret)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := otherSess.GetAuditEventsByTeamId(context.Background(), app.GetAuditEventsInput{
+			TeamId: team.Id,
+		})
+		assert.Error(t, err)
+	})
+}
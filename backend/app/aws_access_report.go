@@ -0,0 +1,278 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	organizationstypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+// How long completed access reports are cached for if not configured.
+const DefaultAWSAccessReportCacheDuration = 24 * time.Hour
+
+// How long we wait between checks on an in-progress access report job.
+const awsAccessReportJobPollInterval = 5 * time.Second
+
+// If an access report job hasn't completed within this time, we give up on it.
+const awsAccessReportJobTimeout = time.Hour
+
+func (a *App) awsAccessReportCacheDuration() time.Duration {
+	if a.config.AWSAccessReportCacheDuration > 0 {
+		return a.config.AWSAccessReportCacheDuration
+	}
+	return DefaultAWSAccessReportCacheDuration
+}
+
+// Gets the organizations entity path for the given account, e.g. "o-1234/r-1234/123456789012".
+func awsOrganizationsEntityPath(ctx context.Context, orgsClient AWSOrganizationsAPI, accountId string) (string, error) {
+	// It sure takes a lot of effort to get the entity path... Is there an easier way to do this?
+	entityPathComponents := []string{accountId}
+	for {
+		output, err := orgsClient.ListParents(ctx, &organizations.ListParentsInput{
+			ChildId: aws.String(entityPathComponents[0]),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list parents: %w", err)
+		}
+		if len(output.Parents) == 0 {
+			return "", fmt.Errorf("failed to find organization root")
+		}
+		p := output.Parents[0]
+		entityPathComponents = append([]string{*p.Id}, entityPathComponents...)
+		if p.Type == organizationstypes.ParentTypeRoot {
+			break
+		}
+	}
+
+	output, err := orgsClient.ListRoots(ctx, &organizations.ListRootsInput{})
+	if err != nil || len(output.Roots) == 0 {
+		return "", fmt.Errorf("failed to list roots: %w", err)
+	}
+	rootArnParts := strings.Split(*output.Roots[0].Arn, "/")
+	organizationId := rootArnParts[1]
+	entityPathComponents = append([]string{organizationId}, entityPathComponents...)
+
+	return strings.Join(entityPathComponents, "/"), nil
+}
+
+// Starts generating an access report for the given account. If a report is already being generated
+// or a completed report is cached, the existing job is returned instead. If the team has no
+// integration capable of generating the report, nil is returned.
+func (s *Session) QueueAWSAccessReportGenerationByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSAccessReportJob, UserFacingError) {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionAnalyzeReports); err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.app.store.GetAWSAccessReportJobByTeamAndAccountId(ctx, teamId, accountId, store.ConsistencyStrongInRegion); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if existing != nil && existing.Status != model.AWSAccessReportJobStatusFailed && time.Now().Before(existing.ExpirationTime) {
+		return existing, nil
+	}
+
+	integration, err := s.app.awsSCPManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId)
+	if err != nil || integration == nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	creds, err := s.app.assumeAWSIntegrationRole(ctx, integration)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("failed to assume role: %w", err))
+	}
+
+	orgsClient, err := s.app.organizationsFactory.NewFromSTSCredentials(ctx, creds)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("failed to create organizations client: %w", err))
+	}
+
+	entityPath, err := awsOrganizationsEntityPath(ctx, orgsClient, accountId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	iamClient, err := s.app.iamFactory.NewFromSTSCredentials(ctx, creds)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("failed to create iam client: %w", err))
+	}
+
+	output, err := iamClient.GenerateOrganizationsAccessReport(ctx, &iam.GenerateOrganizationsAccessReportInput{
+		EntityPath: aws.String(entityPath),
+	})
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("failed to generate access report: %w", err))
+	}
+
+	now := time.Now()
+	job := &model.AWSAccessReportJob{
+		TeamId:           teamId,
+		AccountId:        accountId,
+		AWSIntegrationId: integration.Id,
+		IAMJobId:         *output.JobId,
+		CreationTime:     now,
+		ExpirationTime:   now.Add(awsAccessReportJobTimeout),
+		Status:           model.AWSAccessReportJobStatusInProgress,
+	}
+	if err := s.app.store.PutAWSAccessReportJob(ctx, job); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	if err := s.app.queueAWSAccessReportJobPoll(ctx, job); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	return job, nil
+}
+
+func (a *App) queueAWSAccessReportJobPoll(ctx context.Context, job *model.AWSAccessReportJob) error {
+	if err := a.QueueMessages(ctx, map[string][]OutgoingQueueMessage{
+		a.awsRegion: {
+			{
+				Delay: awsAccessReportJobPollInterval,
+				Message: QueueMessage{
+					PollAWSAccessReportJob: &PollAWSAccessReportJobInput{
+						TeamId:    job.TeamId,
+						AccountId: job.AccountId,
+						IAMJobId:  job.IAMJobId,
+					},
+				},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to queue access report job poll: %w", err)
+	}
+	return nil
+}
+
+// Gets the status of the most recent access report job for the given account.
+func (s *Session) GetAWSAccessReportJobByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSAccessReportJob, UserFacingError) {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionReadReports); err != nil {
+		return nil, err
+	}
+	job, err := s.app.store.GetAWSAccessReportJobByTeamAndAccountId(ctx, teamId, accountId, store.ConsistencyEventual)
+	if err != nil || job == nil || job.ExpirationTime.Before(time.Now()) {
+		return nil, s.SanitizedError(err)
+	}
+	return job, nil
+}
+
+// Gets the cached access report for the given account. If no completed report is available, nil is
+// returned.
+func (s *Session) GetAWSAccessReportByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSAccessReport, UserFacingError) {
+	job, err := s.GetAWSAccessReportJobByTeamAndAccountId(ctx, teamId, accountId)
+	if err != nil || job == nil || job.Status != model.AWSAccessReportJobStatusCompleted {
+		return nil, err
+	}
+	return job.Report, nil
+}
+
+type PollAWSAccessReportJobInput struct {
+	TeamId    model.Id
+	AccountId string
+	IAMJobId  string
+}
+
+// Checks on an in-progress access report job. If the job is still in progress, another poll is
+// queued. Otherwise the results are persisted.
+func (a *App) PollAWSAccessReportJob(ctx context.Context, input PollAWSAccessReportJobInput) error {
+	job, err := a.store.GetAWSAccessReportJobByTeamAndAccountId(ctx, input.TeamId, input.AccountId, store.ConsistencyStrongInRegion)
+	if err != nil {
+		return fmt.Errorf("failed to get access report job: %w", err)
+	} else if job == nil || job.IAMJobId != input.IAMJobId || job.Status != model.AWSAccessReportJobStatusInProgress {
+		// The job has been superseded or is already done.
+		return nil
+	}
+
+	report, status, errorMessage, err := a.getAWSOrganizationsAccessReport(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch status {
+	case iamtypes.JobStatusTypeInProgress:
+		if now.Before(job.ExpirationTime) {
+			return a.queueAWSAccessReportJobPoll(ctx, job)
+		}
+		job.Status = model.AWSAccessReportJobStatusFailed
+		job.ErrorMessage = "The access report took too long to generate."
+	case iamtypes.JobStatusTypeCompleted:
+		job.Status = model.AWSAccessReportJobStatusCompleted
+		job.Report = report
+	default:
+		job.Status = model.AWSAccessReportJobStatusFailed
+		job.ErrorMessage = errorMessage
+	}
+	job.CompletionTime = now
+	job.ExpirationTime = now.Add(a.awsAccessReportCacheDuration())
+
+	if err := a.store.PutAWSAccessReportJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to put access report job: %w", err)
+	}
+	return nil
+}
+
+// Fetches the results of the job from IAM. The report is only returned if the job is completed.
+func (a *App) getAWSOrganizationsAccessReport(ctx context.Context, job *model.AWSAccessReportJob) (*model.AWSAccessReport, iamtypes.JobStatusType, string, error) {
+	integration, err := a.store.GetAWSIntegrationById(ctx, job.AWSIntegrationId)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get aws integration: %w", err)
+	} else if integration == nil {
+		return nil, iamtypes.JobStatusTypeFailed, "The integration used to generate the report no longer exists.", nil
+	}
+
+	creds, err := a.assumeAWSIntegrationRole(ctx, integration)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to assume role: %w", err)
+	}
+
+	iamClient, err := a.iamFactory.NewFromSTSCredentials(ctx, creds)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to create iam client: %w", err)
+	}
+
+	var ret model.AWSAccessReport
+	var marker *string
+
+	for {
+		output, err := iamClient.GetOrganizationsAccessReport(ctx, &iam.GetOrganizationsAccessReportInput{
+			JobId:  aws.String(job.IAMJobId),
+			Marker: marker,
+		})
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to get access report: %w", err)
+		}
+		if output.JobStatus != iamtypes.JobStatusTypeCompleted {
+			var message string
+			if output.ErrorDetails != nil && output.ErrorDetails.Message != nil {
+				message = *output.ErrorDetails.Message
+			}
+			return nil, output.JobStatus, message, nil
+		}
+
+		for _, detail := range output.AccessDetails {
+			service := model.AWSAccessReportService{
+				Name:      *detail.ServiceName,
+				Namespace: *detail.ServiceNamespace,
+			}
+			if detail.LastAuthenticatedTime != nil {
+				service.LastAuthenticationTime = *detail.LastAuthenticatedTime
+			}
+			ret.Services = append(ret.Services, service)
+		}
+
+		if !output.IsTruncated {
+			break
+		}
+		marker = output.Marker
+	}
+
+	return &ret, iamtypes.JobStatusTypeCompleted, "", nil
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+// The longest window that can be used to generate a policy.
+const maxGeneratedAWSIAMPolicyWindow = 90 * 24 * time.Hour
+
+// Some events don't have a corresponding action with the same name.
+var awsIAMActionExceptions = map[string]string{
+	"lambda:Invoke":                      "lambda:InvokeFunction",
+	"s3:ListBuckets":                     "s3:ListAllMyBuckets",
+	"s3:HeadBucket":                      "s3:ListBucket",
+	"s3:ListObjects":                     "s3:ListBucket",
+	"s3:ListObjectsV2":                   "s3:ListBucket",
+	"s3:ListObjectVersions":              "s3:ListBucketVersions",
+	"s3:HeadObject":                      "s3:GetObject",
+	"s3:CreateMultipartUpload":           "s3:PutObject",
+	"s3:UploadPart":                      "s3:PutObject",
+	"s3:UploadPartCopy":                  "s3:PutObject",
+	"s3:CompleteMultipartUpload":         "s3:PutObject",
+	"s3:CopyObject":                      "s3:PutObject",
+	"s3:DeleteObjects":                   "s3:DeleteObject",
+	"s3:ListParts":                       "s3:ListMultipartUploadParts",
+	"s3:ListMultipartUploads":            "s3:ListBucketMultipartUploads",
+	"s3:GetBucketEncryption":             "s3:GetEncryptionConfiguration",
+	"s3:PutBucketEncryption":             "s3:PutEncryptionConfiguration",
+	"s3:GetBucketLifecycleConfiguration": "s3:GetLifecycleConfiguration",
+	"s3:PutBucketLifecycleConfiguration": "s3:PutLifecycleConfiguration",
+}
+
+// Events that don't require any permissions and so are never included in policies.
+var awsIAMUnauthorizedEvents = map[string]struct{}{
+	"sts:GetCallerIdentity": {},
+}
+
+// Namespaces whose events don't correspond to IAM actions.
+var awsIAMUnauthorizedNamespaces = map[string]struct{}{
+	"signin": {},
+}
+
+// Some services include API versions in their event names, e.g. "Invoke20150331" or
+// "GetDistribution2020_05_31".
+var awsEventNameVersionSuffixes = map[string]*regexp.Regexp{
+	"lambda":     regexp.MustCompile(`\d{8}(v\d+)?$`),
+	"cloudfront": regexp.MustCompile(`\d{4}_\d{2}_\d{2}$`),
+}
+
+// Gets the IAM action required to perform the given event, e.g. "s3:GetObject". If the event
+// doesn't require any permissions, an empty string is returned.
+func awsIAMActionForEvent(source, name string) string {
+	namespace := AWSServiceForEventSource(source).Namespace
+	if _, ok := awsIAMUnauthorizedNamespaces[namespace]; ok {
+		return ""
+	}
+	if re, ok := awsEventNameVersionSuffixes[namespace]; ok {
+		name = re.ReplaceAllString(name, "")
+	}
+	action := namespace + ":" + name
+	if _, ok := awsIAMUnauthorizedEvents[action]; ok {
+		return ""
+	}
+	if exception, ok := awsIAMActionExceptions[action]; ok {
+		return exception
+	}
+	return action
+}
+
+// Returns true if the error code indicates that the principal wasn't allowed to perform the action.
+func isAWSAccessDeniedErrorCode(code string) bool {
+	return strings.Contains(code, "AccessDenied") || strings.Contains(code, "UnauthorizedOperation")
+}
+
+// Gets the sorted set of actions used by the principal. Events that were always denied are omitted
+// since the principal evidently doesn't need them.
+func awsIAMActionsForPrincipal(principal *report.Principal, useServiceWildcards bool) []string {
+	actions := map[string]struct{}{}
+	for _, event := range principal.Events {
+		denied := 0
+		for code, count := range event.ErrorCodes {
+			if isAWSAccessDeniedErrorCode(code) {
+				denied += count
+			}
+		}
+		if denied >= event.Count {
+			continue
+		}
+		action := awsIAMActionForEvent(event.Source, event.Name)
+		if action == "" {
+			continue
+		}
+		if useServiceWildcards {
+			action = strings.SplitN(action, ":", 2)[0] + ":*"
+		}
+		actions[action] = struct{}{}
+	}
+	ret := make([]string, 0, len(actions))
+	for action := range actions {
+		ret = append(ret, action)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+type awsIAMPolicyDocument struct {
+	Version   string                  `json:"Version"`
+	Statement []awsIAMPolicyStatement `json:"Statement"`
+}
+
+type awsIAMPolicyStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+type GenerateAWSIAMPolicyInput struct {
+	TeamId       model.Id
+	PrincipalARN string
+	StartTime    time.Time
+	EndTime      time.Time
+
+	// If true, the policy will grant access to entire services rather than individual actions.
+	UseServiceWildcards bool
+}
+
+// Generates a least-privilege IAM policy for a principal based on the activity observed in the
+// team's reports. If no activity is found for the principal, nil is returned.
+func (s *Session) GenerateAWSIAMPolicy(ctx context.Context, input GenerateAWSIAMPolicyInput) (*model.GeneratedAWSIAMPolicy, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, input.TeamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	}
+
+	if input.PrincipalARN == "" {
+		return nil, NewUserError("A principal ARN is required.")
+	} else if !input.EndTime.After(input.StartTime) {
+		return nil, NewUserError("The end time must be after the start time.")
+	} else if input.EndTime.Sub(input.StartTime) > maxGeneratedAWSIAMPolicyWindow {
+		return nil, NewUserError("The time range must not exceed 90 days.")
+	}
+
+	merged, reportCount, err := s.app.loadMergedTeamReport(ctx, loadMergedTeamReportInput{
+		TeamId:    input.TeamId,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+	})
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	var principal *report.Principal
+	for key, p := range merged.Principals {
+		if p.ARN == input.PrincipalARN || key == input.PrincipalARN {
+			principal = p
+			break
+		}
+	}
+	if principal == nil {
+		return nil, nil
+	}
+
+	actions := awsIAMActionsForPrincipal(principal, input.UseServiceWildcards)
+	if len(actions) == 0 {
+		return nil, NewUserError("The principal didn't perform any actions that require permissions.")
+	}
+
+	content, err := json.MarshalIndent(awsIAMPolicyDocument{
+		Version: "2012-10-17",
+		Statement: []awsIAMPolicyStatement{
+			{
+				Effect:   "Allow",
+				Action:   actions,
+				Resource: "*",
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("failed to marshal policy: %w", err))
+	}
+
+	return &model.GeneratedAWSIAMPolicy{
+		PrincipalARN: input.PrincipalARN,
+		Content:      string(content),
+		Actions:      actions,
+		ReportCount:  reportCount,
+		IsIncomplete: merged.IsIncomplete,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestGenerateAWSIAMPolicy(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	a.NewTestAWSCloudTrailReport(sess, team.Id)
+
+	input := app.GenerateAWSIAMPolicyInput{
+		TeamId:       team.Id,
+		PrincipalARN: "arn:aws:iam::222222222222:role/ecs-cluster-instance",
+		StartTime:    time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC),
+		EndTime:      time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Actions", func(t *testing.T) {
+		policy, err := sess.GenerateAWSIAMPolicy(context.Background(), input)
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, []string{"ssm:UpdateInstanceInformation"}, policy.Actions)
+		assert.Equal(t, 1, policy.ReportCount)
+		assert.JSONEq(t, `{
+			"Version": "2012-10-17",
+			"Statement": [
+				{
+					"Effect": "Allow",
+					"Action": ["ssm:UpdateInstanceInformation"],
+					"Resource": "*"
+				}
+			]
+		}`, policy.Content)
+	})
+
+	t.Run("ServiceWildcards", func(t *testing.T) {
+		input := input
+		input.UseServiceWildcards = true
+		policy, err := sess.GenerateAWSIAMPolicy(context.Background(), input)
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, []string{"ssm:*"}, policy.Actions)
+	})
+
+	t.Run("OutsideWindow", func(t *testing.T) {
+		input := input
+		input.StartTime = time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+		input.EndTime = time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+		policy, err := sess.GenerateAWSIAMPolicy(context.Background(), input)
+		require.NoError(t, err)
+		assert.Nil(t, policy)
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		input := input
+		input.EndTime = input.StartTime
+		_, err := sess.GenerateAWSIAMPolicy(context.Background(), input)
+		assert.Error(t, err)
+	})
+
+	t.Run("NonMember", func(t *testing.T) {
+		_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+		_, err := otherSess.GenerateAWSIAMPolicy(context.Background(), input)
+		assert.Error(t, err)
+	})
+}
@@ -8,14 +8,13 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/iam"
-	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	organizationstypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
 	"github.com/aws/smithy-go"
+	"go.uber.org/zap"
 
 	"github.com/ccbrown/cloud-snitch/backend/model"
 	"github.com/ccbrown/cloud-snitch/backend/store"
@@ -27,6 +26,7 @@ type CreateAWSIntegrationInput struct {
 	RoleARN                          string
 	GetAccountNamesFromOrganizations bool
 	ManageSCPs                       bool
+	ManageRCPs                       bool
 	CloudTrailTrail                  *CreateAWSIntegrationCloudTrailTrailInput
 	QueueReportGeneration            bool
 }
@@ -39,6 +39,8 @@ type CreateAWSIntegrationCloudTrailTrailInput struct {
 func (s *Session) ValidateAWSIntegration(ctx context.Context, input CreateAWSIntegrationInput) UserFacingError {
 	if input.ManageSCPs && !input.GetAccountNamesFromOrganizations {
 		return NewUserError("To manage SCPs you must also allow the integration to get account info from AWS Organizations.")
+	} else if input.ManageRCPs && !input.GetAccountNamesFromOrganizations {
+		return NewUserError("To manage RCPs you must also allow the integration to get account info from AWS Organizations.")
 	}
 
 	// First, make sure we *can't* assume the role without the external id.
@@ -72,21 +74,69 @@ func (s *Session) ValidateAWSIntegration(ctx context.Context, input CreateAWSInt
 			return NewUserError("Unable to get account info. Please make sure the role has permission to perform the organizations:ListAccounts action.")
 		}
 
-		if input.ManageSCPs {
+		if input.ManageSCPs || input.ManageRCPs {
+			var policyTypes []organizationstypes.PolicyType
+			if input.ManageSCPs {
+				policyTypes = append(policyTypes, organizationstypes.PolicyTypeServiceControlPolicy)
+			}
+			if input.ManageRCPs {
+				policyTypes = append(policyTypes, organizationstypes.PolicyTypeResourceControlPolicy)
+			}
+
+			// organizations:DescribeOrganization
+			access, err := describeAWSOrganizationsAccess(ctx, orgsClient, input.RoleARN)
+			if err != nil {
+				return NewUserError("Unable to describe the organization. Please make sure the role has permission to perform the organizations:DescribeOrganization action.")
+			}
+
+			// Delegated administrators can't manage policies for the management account, so make sure
+			// we check permissions against an account that they can manage.
+			targetId := accounts.Accounts[0].Id
+			if access.IsDelegatedAdministrator {
+				targetId = nil
+				for _, account := range accounts.Accounts {
+					if account.Id != nil && *account.Id != access.ManagementAccountId {
+						targetId = account.Id
+						break
+					}
+				}
+				if targetId == nil {
+					return NewUserError("The role belongs to a delegated administrator account, but there are no other accounts in the organization that it can manage policies for.")
+				}
+			}
+
 			// organizations:ListPoliciesForTarget
-			{
+			for _, policyType := range policyTypes {
 				if _, err := orgsClient.ListPoliciesForTarget(ctx, &organizations.ListPoliciesForTargetInput{
-					Filter:   organizationstypes.PolicyTypeServiceControlPolicy,
-					TargetId: accounts.Accounts[0].Id,
+					Filter:   policyType,
+					TargetId: targetId,
 				}); err != nil {
+					if access.IsDelegatedAdministrator {
+						return NewUserError("Unable to get policies. Please make sure the role has permission to perform the organizations:ListPoliciesForTarget action and that the account's delegation policy allows it.")
+					}
 					return NewUserError("Unable to get policies. Please make sure the role has permission to perform the organizations:ListPoliciesForTarget action.")
 				}
 			}
 
+			// organizations:ListPolicies
+			//
+			// This is only used to recover from failed attachments, so it's optional in the
+			// management account. Delegated administrators need it though, since their delegation
+			// policy is what most commonly causes those failures.
+			if access.IsDelegatedAdministrator {
+				for _, policyType := range policyTypes {
+					if _, err := orgsClient.ListPolicies(ctx, &organizations.ListPoliciesInput{
+						Filter: policyType,
+					}); err != nil {
+						return NewUserError("Unable to list policies. Please make sure the role has permission to perform the organizations:ListPolicies action and that the account's delegation policy allows it.")
+					}
+				}
+			}
+
 			// organizations:ListParents
 			{
 				if _, err := orgsClient.ListParents(ctx, &organizations.ListParentsInput{
-					ChildId: accounts.Accounts[0].Id,
+					ChildId: targetId,
 				}); err != nil {
 					return NewUserError("Unable to get account parents. Please make sure the role has permission to perform the organizations:ListParents action.")
 				}
@@ -98,15 +148,17 @@ func (s *Session) ValidateAWSIntegration(ctx context.Context, input CreateAWSInt
 				if err != nil || len(output.Roots) == 0 {
 					return NewUserError("Unable to get organization roots. Please make sure the role has permission to perform the organizations:ListRoots action.")
 				}
-				var hasSCPsEnabled bool
-				for _, t := range output.Roots[0].PolicyTypes {
-					if t.Status == organizationstypes.PolicyTypeStatusEnabled && t.Type == organizationstypes.PolicyTypeServiceControlPolicy {
-						hasSCPsEnabled = true
-						break
+				for _, policyType := range policyTypes {
+					var isEnabled bool
+					for _, t := range output.Roots[0].PolicyTypes {
+						if t.Status == organizationstypes.PolicyTypeStatusEnabled && t.Type == policyType {
+							isEnabled = true
+							break
+						}
+					}
+					if !isEnabled {
+						return NewUserError(fmt.Sprintf("%vs are not enabled for the organization. Please enable them and try again.", awsPolicyTypeAbbreviation(policyType)))
 					}
-				}
-				if !hasSCPsEnabled {
-					return NewUserError("SCPs are not enabled for the organization. Please enable them and try again.")
 				}
 			}
 		}
@@ -155,7 +207,7 @@ func (s *Session) ValidateAWSIntegration(ctx context.Context, input CreateAWSInt
 }
 
 func (s *Session) CreateAWSIntegration(ctx context.Context, input CreateAWSIntegrationInput) (*model.AWSIntegration, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+	if err := s.RequireTeamAdministratorOrAPIKeyScope(ctx, input.TeamId, model.TeamAPIKeyScopeManageIntegrations); err != nil {
 		return nil, err
 	} else if err := ValidateName(input.Name); err != nil {
 		return nil, err
@@ -195,6 +247,7 @@ func (s *Session) CreateAWSIntegration(ctx context.Context, input CreateAWSInteg
 		RoleARN:                          input.RoleARN,
 		GetAccountNamesFromOrganizations: input.GetAccountNamesFromOrganizations,
 		ManageSCPs:                       input.ManageSCPs,
+		ManageRCPs:                       input.ManageRCPs,
 	}
 	if trail := input.CloudTrailTrail; trail != nil {
 		integration.CloudTrailTrail = &model.AWSIntegrationCloudTrailTrail{
@@ -207,6 +260,8 @@ func (s *Session) CreateAWSIntegration(ctx context.Context, input CreateAWSInteg
 		return nil, s.SanitizedError(err)
 	}
 
+	s.recordAuditEvent(ctx, integration.TeamId, model.AuditEventActionAWSIntegrationCreate, integration.Id.String(), nil, integration)
+
 	{
 		today := time.Now().Truncate(24 * time.Hour)
 		for i := 0; i < 7; i++ {
@@ -226,7 +281,7 @@ func (s *Session) CreateAWSIntegration(ctx context.Context, input CreateAWSInteg
 }
 
 func (s *Session) GetAWSIntegrationsByTeamId(ctx context.Context, teamId model.Id) ([]*model.AWSIntegration, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequireTeamAdministratorOrAPIKeyScope(ctx, teamId, model.TeamAPIKeyScopeManageIntegrations); err != nil {
 		return nil, err
 	}
 	integrations, err := s.app.store.GetAWSIntegrationsByTeamId(ctx, teamId)
@@ -238,11 +293,13 @@ type AWSIntegrationPatch struct {
 }
 
 func (s *Session) PatchAWSIntegrationById(ctx context.Context, id model.Id, patch AWSIntegrationPatch) (*model.AWSIntegration, UserFacingError) {
-	if err := s.RequireUser(); err != nil {
-		return nil, err
-	} else if integration, err := s.app.store.GetAWSIntegrationById(ctx, id); integration == nil || err != nil {
+	if s.user == nil && s.teamAPIKey == nil {
+		return nil, AuthorizationError{}
+	}
+	before, err := s.app.store.GetAWSIntegrationById(ctx, id)
+	if before == nil || err != nil {
 		return nil, s.SanitizedError(err)
-	} else if err := s.RequireTeamAdministrator(ctx, integration.TeamId); err != nil {
+	} else if err := s.RequireTeamAdministratorOrAPIKeyScope(ctx, before.TeamId, model.TeamAPIKeyScopeManageIntegrations); err != nil {
 		return nil, err
 	}
 
@@ -254,18 +311,26 @@ func (s *Session) PatchAWSIntegrationById(ctx context.Context, id model.Id, patc
 			return nil, err
 		}
 	}
-	team, err := s.app.store.PatchAWSIntegrationById(ctx, id, storePatch)
-	return team, s.SanitizedError(err)
+	integration, err := s.app.store.PatchAWSIntegrationById(ctx, id, storePatch)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	if integration != nil {
+		s.recordAuditEvent(ctx, integration.TeamId, model.AuditEventActionAWSIntegrationUpdate, id.String(), before, integration)
+	}
+
+	return integration, nil
 }
 
 func (s *Session) DeleteAWSIntegrationById(ctx context.Context, id model.Id, deleteAssociatedData bool) UserFacingError {
-	if err := s.RequireUser(); err != nil {
-		return err
+	if s.user == nil && s.teamAPIKey == nil {
+		return AuthorizationError{}
 	}
 	integration, err := s.app.store.GetAWSIntegrationById(ctx, id)
 	if integration == nil || err != nil {
 		return s.SanitizedError(err)
-	} else if err := s.RequireTeamAdministrator(ctx, integration.TeamId); err != nil {
+	} else if err := s.RequireTeamAdministratorOrAPIKeyScope(ctx, integration.TeamId, model.TeamAPIKeyScopeManageIntegrations); err != nil {
 		return err
 	}
 
@@ -289,7 +354,13 @@ func (s *Session) DeleteAWSIntegrationById(ctx context.Context, id model.Id, del
 		}
 	}
 
-	return s.SanitizedError(s.app.store.DeleteAWSIntegrationById(ctx, id))
+	if err := s.app.store.DeleteAWSIntegrationById(ctx, id); err != nil {
+		return s.SanitizedError(err)
+	}
+
+	s.recordAuditEvent(ctx, integration.TeamId, model.AuditEventActionAWSIntegrationDelete, id.String(), integration, nil)
+
+	return nil
 }
 
 func BestAvailableAWSRegion(region string, available []string) string {
@@ -321,6 +392,10 @@ type PutAWSIntegrationReconInput struct {
 	Time             time.Time
 	Accounts         []PutAWSIntegrationReconAccountInput
 	CanManageSCPs    bool
+	CanManageRCPs    bool
+
+	ManagementAccountId      string
+	IsDelegatedAdministrator bool
 }
 
 type PutAWSIntegrationReconAccountInput struct {
@@ -330,12 +405,15 @@ type PutAWSIntegrationReconAccountInput struct {
 
 func (a *App) PutAWSIntegrationRecon(ctx context.Context, input PutAWSIntegrationReconInput) error {
 	recon := &model.AWSIntegrationRecon{
-		AWSIntegrationId: input.AWSIntegrationId,
-		TeamId:           input.TeamId,
-		Time:             input.Time,
-		ExpirationTime:   input.Time.Add(3 * 24 * time.Hour),
-		CanManageSCPs:    input.CanManageSCPs,
-		Accounts:         make([]model.AWSIntegrationAccountRecon, len(input.Accounts)),
+		AWSIntegrationId:         input.AWSIntegrationId,
+		TeamId:                   input.TeamId,
+		Time:                     input.Time,
+		ExpirationTime:           input.Time.Add(3 * 24 * time.Hour),
+		CanManageSCPs:            input.CanManageSCPs,
+		CanManageRCPs:            input.CanManageRCPs,
+		ManagementAccountId:      input.ManagementAccountId,
+		IsDelegatedAdministrator: input.IsDelegatedAdministrator,
+		Accounts:                 make([]model.AWSIntegrationAccountRecon, len(input.Accounts)),
 	}
 	for i, account := range input.Accounts {
 		recon.Accounts[i] = model.AWSIntegrationAccountRecon{
@@ -352,7 +430,7 @@ func (a *App) PutAWSIntegrationRecon(ctx context.Context, input PutAWSIntegratio
 }
 
 func (s *Session) GetAWSIntegrationReconsByTeamId(ctx context.Context, teamId model.Id) ([]*model.AWSIntegrationRecon, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, teamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeManageIntegrations); err != nil {
 		return nil, err
 	}
 	ret, err := s.app.store.GetAWSIntegrationReconsByTeamId(ctx, teamId)
@@ -373,42 +451,121 @@ func (a *App) assumeAWSIntegrationRole(ctx context.Context, integration *model.A
 
 // Finds an integration for the given team which is capable of managing SCPs for the given account.
 func (a *App) awsSCPManagementIntegrationByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSIntegration, error) {
+	return a.awsPolicyManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId, organizationstypes.PolicyTypeServiceControlPolicy)
+}
+
+// Finds an integration for the given team which is capable of managing policies of the given type
+// for the given account.
+func (a *App) awsPolicyManagementIntegrationByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string, policyType organizationstypes.PolicyType) (*model.AWSIntegration, error) {
 	recons, err := a.store.GetAWSIntegrationReconsByTeamId(ctx, teamId)
 	if err != nil {
 		return nil, err
 	}
+	// Integrations in the management account are preferred, but we'll fall back to a delegated
+	// administrator if that's all we have.
+	var delegatedAdministratorIntegration *model.AWSIntegration
 	for _, recon := range recons {
+		if !recon.CanManagePoliciesForAccount(accountId) {
+			continue
+		}
 		for _, account := range recon.Accounts {
 			if account.Id == accountId {
 				if integration, err := a.store.GetAWSIntegrationById(ctx, recon.AWSIntegrationId); err != nil {
 					return nil, err
-				} else if integration != nil && integration.ManageSCPs {
-					return integration, nil
+				} else if integration != nil && awsIntegrationCanManagePolicyType(integration, policyType) {
+					if !recon.IsDelegatedAdministrator {
+						return integration, nil
+					} else if delegatedAdministratorIntegration == nil {
+						delegatedAdministratorIntegration = integration
+					}
 				}
 			}
 		}
 	}
-	return nil, nil
+	return delegatedAdministratorIntegration, nil
+}
+
+// Gets the account id from an ARN such as "arn:aws:iam::123456789012:role/MyRole".
+func awsAccountIdFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 5 {
+		return ""
+	}
+	return parts[4]
+}
+
+type awsOrganizationsAccess struct {
+	ManagementAccountId      string
+	IsDelegatedAdministrator bool
+}
+
+// Determines whether the given role is in the organization's management account or in a delegated
+// administrator account.
+func describeAWSOrganizationsAccess(ctx context.Context, orgsClient AWSOrganizationsAPI, roleARN string) (*awsOrganizationsAccess, error) {
+	output, err := orgsClient.DescribeOrganization(ctx, &organizations.DescribeOrganizationInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe organization: %w", err)
+	} else if output.Organization == nil || output.Organization.MasterAccountId == nil {
+		return nil, fmt.Errorf("organization has no management account")
+	}
+	managementAccountId := *output.Organization.MasterAccountId
+	return &awsOrganizationsAccess{
+		ManagementAccountId:      managementAccountId,
+		IsDelegatedAdministrator: awsAccountIdFromARN(roleARN) != managementAccountId,
+	}, nil
+}
+
+func awsIntegrationCanManagePolicyType(integration *model.AWSIntegration, policyType organizationstypes.PolicyType) bool {
+	switch policyType {
+	case organizationstypes.PolicyTypeServiceControlPolicy:
+		return integration.ManageSCPs
+	case organizationstypes.PolicyTypeResourceControlPolicy:
+		return integration.ManageRCPs
+	default:
+		return false
+	}
+}
+
+func awsPolicyTypeAbbreviation(policyType organizationstypes.PolicyType) string {
+	switch policyType {
+	case organizationstypes.PolicyTypeServiceControlPolicy:
+		return "SCP"
+	case organizationstypes.PolicyTypeResourceControlPolicy:
+		return "RCP"
+	default:
+		return string(policyType)
+	}
 }
 
 const ManagedAWSSCPNamePrefix = "CloudSnitchManagedSCP-"
 
+const ManagedAWSRCPNamePrefix = "CloudSnitchManagedRCP-"
+
 const CloudSnitchManagedResourceTag = "CloudSnitchManaged"
 
-func (a *App) findManagedAWSSCP(ctx context.Context, orgsClient AWSOrganizationsAPI, accountId string) (*organizationstypes.PolicySummary, error) {
+func managedAWSPolicyName(policyType organizationstypes.PolicyType, accountId string) string {
+	if policyType == organizationstypes.PolicyTypeResourceControlPolicy {
+		return ManagedAWSRCPNamePrefix + accountId
+	}
+	return ManagedAWSSCPNamePrefix + accountId
+}
+
+func (a *App) findManagedAWSPolicy(ctx context.Context, orgsClient AWSOrganizationsAPI, policyType organizationstypes.PolicyType, accountId string) (*organizationstypes.PolicySummary, error) {
+	name := managedAWSPolicyName(policyType, accountId)
+
 	var nextToken *string
 	for {
 		output, err := orgsClient.ListPoliciesForTarget(ctx, &organizations.ListPoliciesForTargetInput{
-			Filter:    organizationstypes.PolicyTypeServiceControlPolicy,
+			Filter:    policyType,
 			TargetId:  aws.String(accountId),
 			NextToken: nextToken,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list account scps: %w", err)
+			return nil, fmt.Errorf("failed to list account %vs: %w", strings.ToLower(awsPolicyTypeAbbreviation(policyType)), err)
 		}
 
 		for _, policySummary := range output.Policies {
-			if policySummary.Name == nil || *policySummary.Name != ManagedAWSSCPNamePrefix+accountId {
+			if policySummary.Name == nil || *policySummary.Name != name {
 				continue
 			}
 			return &policySummary, nil
@@ -421,12 +578,48 @@ func (a *App) findManagedAWSSCP(ctx context.Context, orgsClient AWSOrganizations
 	}
 }
 
-func (s *Session) GetManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSSCP, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
+// Finds a managed policy that exists but isn't attached to the account. This can happen if a
+// previous attachment failed, which is more likely for delegated administrators whose delegation
+// policy permits creating policies but not attaching them.
+//
+// Integrations created with templates older than V4 aren't permitted to list policies. This isn't
+// essential to updating the policy, so failures are logged and nil is returned.
+func (s *Session) findDetachedManagedAWSPolicy(ctx context.Context, orgsClient AWSOrganizationsAPI, policyType organizationstypes.PolicyType, accountId string) *organizationstypes.PolicySummary {
+	name := managedAWSPolicyName(policyType, accountId)
+
+	var nextToken *string
+	for {
+		output, err := orgsClient.ListPolicies(ctx, &organizations.ListPoliciesInput{
+			Filter:    policyType,
+			NextToken: nextToken,
+		})
+		if err != nil {
+			s.Logger().Warn("failed to list organization policies", zap.String("policy_type", string(policyType)), zap.Error(err))
+			return nil
+		}
+
+		for _, policySummary := range output.Policies {
+			if policySummary.Name == nil || *policySummary.Name != name {
+				continue
+			}
+			return &policySummary
+		}
+
+		if output.NextToken == nil {
+			return nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+// Gets the content of the managed policy of the given type for the given account. If the team has
+// no integration capable of managing the policy or the policy doesn't exist, nil is returned.
+func (s *Session) getManagedAWSPolicyContent(ctx context.Context, teamId model.Id, accountId string, policyType organizationstypes.PolicyType) (*string, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, teamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeManageSCPs); err != nil {
 		return nil, err
 	}
 
-	integration, err := s.app.awsSCPManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId)
+	integration, err := s.app.awsPolicyManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId, policyType)
 	if err != nil || integration == nil {
 		return nil, s.SanitizedError(err)
 	}
@@ -441,7 +634,7 @@ func (s *Session) GetManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId
 		return nil, s.SanitizedError(fmt.Errorf("failed to create organizations client: %w", err))
 	}
 
-	policySummary, err := s.app.findManagedAWSSCP(ctx, orgsClient, accountId)
+	policySummary, err := s.app.findManagedAWSPolicy(ctx, orgsClient, policyType, accountId)
 	if err != nil || policySummary == nil {
 		return nil, s.SanitizedError(err)
 	}
@@ -451,64 +644,76 @@ func (s *Session) GetManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId
 	}); err != nil {
 		return nil, s.SanitizedError(fmt.Errorf("failed to describe policy: %w", err))
 	} else if policy.Policy != nil && policy.Policy.Content != nil {
-		return &model.AWSSCP{
-			Content: *policy.Policy.Content,
-		}, nil
+		return policy.Policy.Content, nil
 	} else {
 		return nil, nil
 	}
 }
 
-type PutManagedAWSSCPInput struct {
-	Content string
-}
-
-func (s *Session) PutManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string, input PutManagedAWSSCPInput) (*model.AWSSCP, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
-		return nil, err
+// Creates or updates the managed policy of the given type for the given account. If the team has no
+// integration capable of managing the policy, false is returned.
+func (s *Session) putManagedAWSPolicyContent(ctx context.Context, teamId model.Id, accountId string, policyType organizationstypes.PolicyType, content string) (bool, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, teamId, model.TeamPermissionManageSCPs, model.TeamAPIKeyScopeManageSCPs); err != nil {
+		return false, err
 	}
 
-	integration, err := s.app.awsSCPManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId)
+	integration, err := s.app.awsPolicyManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId, policyType)
 	if err != nil || integration == nil {
-		return nil, s.SanitizedError(err)
+		return false, s.SanitizedError(err)
 	}
 
 	creds, err := s.app.assumeAWSIntegrationRole(ctx, integration)
 	if err != nil {
-		return nil, s.SanitizedError(fmt.Errorf("failed to assume role: %w", err))
+		return false, s.SanitizedError(fmt.Errorf("failed to assume role: %w", err))
 	}
 
 	orgsClient, err := s.app.organizationsFactory.NewFromSTSCredentials(ctx, creds)
 	if err != nil {
-		return nil, s.SanitizedError(fmt.Errorf("failed to create organizations client: %w", err))
-	}
-
-	ret := &model.AWSSCP{
-		Content: input.Content,
+		return false, s.SanitizedError(fmt.Errorf("failed to create organizations client: %w", err))
 	}
 
-	policySummary, err := s.app.findManagedAWSSCP(ctx, orgsClient, accountId)
+	policySummary, err := s.app.findManagedAWSPolicy(ctx, orgsClient, policyType, accountId)
 	if err != nil {
-		return nil, s.SanitizedError(err)
+		return false, s.SanitizedError(err)
 	}
 
+	var previousContent *string
+
 	if policySummary != nil {
 		// Existing policy found, just update it.
 
+		previousContent = s.describeManagedAWSPolicyContent(ctx, orgsClient, policySummary.Id)
+
 		if _, err := orgsClient.UpdatePolicy(ctx, &organizations.UpdatePolicyInput{
 			PolicyId: policySummary.Id,
-			Content:  aws.String(input.Content),
+			Content:  aws.String(content),
 		}); err != nil {
-			return nil, s.SanitizedError(fmt.Errorf("failed to update policy: %w", err))
+			return false, s.SanitizedError(fmt.Errorf("failed to update policy: %w", err))
+		}
+	} else if policySummary := s.findDetachedManagedAWSPolicy(ctx, orgsClient, policyType, accountId); policySummary != nil {
+		// Existing policy found, but it's not attached. Update it and attach it.
+
+		if _, err := orgsClient.UpdatePolicy(ctx, &organizations.UpdatePolicyInput{
+			PolicyId: policySummary.Id,
+			Content:  aws.String(content),
+		}); err != nil {
+			return false, s.SanitizedError(fmt.Errorf("failed to update policy: %w", err))
+		}
+
+		if _, err := orgsClient.AttachPolicy(ctx, &organizations.AttachPolicyInput{
+			PolicyId: policySummary.Id,
+			TargetId: aws.String(accountId),
+		}); err != nil {
+			return false, s.SanitizedError(fmt.Errorf("failed to attach policy: %w", err))
 		}
 	} else {
 		// Create a new policy and attach it.
 
 		policy, err := orgsClient.CreatePolicy(ctx, &organizations.CreatePolicyInput{
-			Name:        aws.String(ManagedAWSSCPNamePrefix + accountId),
+			Name:        aws.String(managedAWSPolicyName(policyType, accountId)),
 			Description: aws.String("Managed by CloudSnitch (" + s.app.config.FrontendURL + "). Do not modify directly."),
-			Type:        organizationstypes.PolicyTypeServiceControlPolicy,
-			Content:     &input.Content,
+			Type:        policyType,
+			Content:     &content,
 			Tags: []organizationstypes.Tag{
 				{
 					Key:   aws.String(CloudSnitchManagedResourceTag),
@@ -517,125 +722,100 @@ func (s *Session) PutManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId
 			},
 		})
 		if err != nil {
-			return nil, s.SanitizedError(fmt.Errorf("failed to create policy: %w", err))
+			return false, s.SanitizedError(fmt.Errorf("failed to create policy: %w", err))
 		}
 
 		if _, err := orgsClient.AttachPolicy(ctx, &organizations.AttachPolicyInput{
 			PolicyId: policy.Policy.PolicySummary.Id,
 			TargetId: aws.String(accountId),
 		}); err != nil {
-			return nil, s.SanitizedError(fmt.Errorf("failed to attach policy: %w", err))
+			return false, s.SanitizedError(fmt.Errorf("failed to attach policy: %w", err))
 		}
 	}
 
-	return ret, nil
-}
-
-func (s *Session) GetAWSAccessReportByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSAccessReport, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
-		return nil, err
+	action := model.AuditEventActionAWSSCPUpdate
+	if policyType == organizationstypes.PolicyTypeResourceControlPolicy {
+		action = model.AuditEventActionAWSRCPUpdate
 	}
-
-	integration, err := s.app.awsSCPManagementIntegrationByTeamAndAccountId(ctx, teamId, accountId)
-	if err != nil || integration == nil {
-		return nil, s.SanitizedError(err)
+	var before any
+	if previousContent != nil {
+		before = map[string]string{"Content": *previousContent}
 	}
+	s.recordAuditEvent(ctx, teamId, action, accountId, before, map[string]string{"Content": content})
 
-	creds, err := s.app.assumeAWSIntegrationRole(ctx, integration)
-	if err != nil {
-		return nil, s.SanitizedError(fmt.Errorf("failed to assume role: %w", err))
-	}
+	return true, nil
+}
 
-	orgsClient, err := s.app.organizationsFactory.NewFromSTSCredentials(ctx, creds)
+// Gets the content of an existing policy so that changes to it can be audited. This isn't essential
+// to updating the policy, so failures are logged and nil is returned.
+func (s *Session) describeManagedAWSPolicyContent(ctx context.Context, orgsClient AWSOrganizationsAPI, policyId *string) *string {
+	policy, err := orgsClient.DescribePolicy(ctx, &organizations.DescribePolicyInput{
+		PolicyId: policyId,
+	})
 	if err != nil {
-		return nil, s.SanitizedError(fmt.Errorf("failed to create organizations client: %w", err))
+		s.Logger().Warn("failed to describe policy", zap.Error(err))
+		return nil
+	} else if policy.Policy == nil {
+		return nil
 	}
+	return policy.Policy.Content
+}
 
-	var entityPath string
-
-	// It sure takes a lot of effort to get the entity path... Is there an easier way to do this?
-	{
-		entityPathComponents := []string{accountId}
-		for {
-			output, err := orgsClient.ListParents(ctx, &organizations.ListParentsInput{
-				ChildId: aws.String(entityPathComponents[0]),
-			})
-			if err != nil {
-				return nil, s.SanitizedError(fmt.Errorf("failed to list parents: %w", err))
-			}
-			if len(output.Parents) == 0 {
-				return nil, s.SanitizedError(fmt.Errorf("failed to find organization root"))
-			}
-			p := output.Parents[0]
-			entityPathComponents = append([]string{*p.Id}, entityPathComponents...)
-			if p.Type == organizationstypes.ParentTypeRoot {
-				break
-			}
-		}
+func (s *Session) GetManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSSCP, UserFacingError) {
+	if content, err := s.getManagedAWSPolicyContent(ctx, teamId, accountId, organizationstypes.PolicyTypeServiceControlPolicy); err != nil || content == nil {
+		return nil, err
+	} else {
+		return &model.AWSSCP{
+			Content: *content,
+		}, nil
+	}
+}
 
-		output, err := orgsClient.ListRoots(ctx, &organizations.ListRootsInput{})
-		if err != nil || len(output.Roots) == 0 {
-			return nil, s.SanitizedError(fmt.Errorf("failed to list roots: %w", err))
-		}
-		rootArnParts := strings.Split(*output.Roots[0].Arn, "/")
-		organizationId := rootArnParts[1]
-		entityPathComponents = append([]string{organizationId}, entityPathComponents...)
+type PutManagedAWSSCPInput struct {
+	Content string
+}
 
-		entityPath = strings.Join(entityPathComponents, "/")
+func (s *Session) PutManagedAWSSCPByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string, input PutManagedAWSSCPInput) (*model.AWSSCP, UserFacingError) {
+	if ok, err := s.putManagedAWSPolicyContent(ctx, teamId, accountId, organizationstypes.PolicyTypeServiceControlPolicy, input.Content); err != nil || !ok {
+		return nil, err
 	}
-
-	iamClient, err := s.app.iamFactory.NewFromSTSCredentials(ctx, creds)
-	if err != nil {
-		return nil, s.SanitizedError(fmt.Errorf("failed to create organizations client: %w", err))
+	data := map[string]any{
+		"accountId": accountId,
+		"content":   input.Content,
 	}
-
-	output, err := iamClient.GenerateOrganizationsAccessReport(ctx, &iam.GenerateOrganizationsAccessReportInput{
-		EntityPath: aws.String(entityPath),
-	})
-	if err != nil {
-		return nil, s.SanitizedError(fmt.Errorf("failed to generate access report: %w", err))
+	if s.user != nil {
+		data["userId"] = s.user.Id
+	} else if s.teamAPIKey != nil {
+		data["teamApiKeyId"] = s.teamAPIKey.Id
 	}
+	if err := s.app.emitWebhookEvent(ctx, teamId, model.WebhookEventTypeSCPChanged, data); err != nil {
+		// The SCP was already changed, so don't report this as a failure.
+		s.Logger().Error("failed to emit scp changed webhook event", zap.Error(err))
+	}
+	return &model.AWSSCP{
+		Content: input.Content,
+	}, nil
+}
 
-	var ret model.AWSAccessReport
-
-	jobId := *output.JobId
-	var marker *string
-
-	for {
-		output, err := iamClient.GetOrganizationsAccessReport(ctx, &iam.GetOrganizationsAccessReportInput{
-			JobId:  aws.String(jobId),
-			Marker: marker,
-		})
-		if err != nil {
-			return nil, s.SanitizedError(fmt.Errorf("failed to get access report: %w", err))
-		}
-		if output.JobStatus == iamtypes.JobStatusTypeInProgress {
-			time.Sleep(time.Second)
-			continue
-		} else if output.JobStatus != iamtypes.JobStatusTypeCompleted {
-			var message string
-			if output.ErrorDetails != nil && output.ErrorDetails.Message != nil {
-				message = *output.ErrorDetails.Message
-			}
-			return nil, s.SanitizedError(fmt.Errorf("unexpected job status for access report: %s, error message: %s", string(output.JobStatus), message))
-		}
+func (s *Session) GetManagedAWSRCPByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string) (*model.AWSRCP, UserFacingError) {
+	if content, err := s.getManagedAWSPolicyContent(ctx, teamId, accountId, organizationstypes.PolicyTypeResourceControlPolicy); err != nil || content == nil {
+		return nil, err
+	} else {
+		return &model.AWSRCP{
+			Content: *content,
+		}, nil
+	}
+}
 
-		for _, detail := range output.AccessDetails {
-			service := model.AWSAccessReportService{
-				Name:      *detail.ServiceName,
-				Namespace: *detail.ServiceNamespace,
-			}
-			if detail.LastAuthenticatedTime != nil {
-				service.LastAuthenticationTime = *detail.LastAuthenticatedTime
-			}
-			ret.Services = append(ret.Services, service)
-		}
+type PutManagedAWSRCPInput struct {
+	Content string
+}
 
-		if !output.IsTruncated {
-			break
-		}
-		marker = output.Marker
+func (s *Session) PutManagedAWSRCPByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string, input PutManagedAWSRCPInput) (*model.AWSRCP, UserFacingError) {
+	if ok, err := s.putManagedAWSPolicyContent(ctx, teamId, accountId, organizationstypes.PolicyTypeResourceControlPolicy, input.Content); err != nil || !ok {
+		return nil, err
 	}
-
-	return &ret, nil
+	return &model.AWSRCP{
+		Content: input.Content,
+	}, nil
 }
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Templates for common data perimeter rules that can be used as a starting point for managed RCPs.
+// Occurrences of "<ORGANIZATION_ID>" must be replaced with the organization's id before use.
+//
+// These are based on the examples at https://github.com/aws-samples/data-perimeter-policy-examples.
+var AWSRCPTemplates = []model.AWSPolicyTemplate{
+	{
+		Id:          "enforce-identity-perimeter",
+		Name:        "Enforce Identity Perimeter",
+		Description: "Prevents principals outside of your organization from accessing your resources, unless they are AWS services acting on your behalf.",
+		Content: `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "EnforceOrgIdentities",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": [
+        "s3:*",
+        "sqs:*",
+        "kms:*",
+        "secretsmanager:*",
+        "sts:AssumeRole",
+        "sts:DecodeAuthorizationMessage",
+        "sts:GetAccessKeyInfo",
+        "sts:GetFederationToken",
+        "sts:GetServiceBearerToken",
+        "sts:GetSessionToken",
+        "sts:SetContext"
+      ],
+      "Resource": "*",
+      "Condition": {
+        "StringNotEqualsIfExists": {
+          "aws:PrincipalOrgID": "<ORGANIZATION_ID>"
+        },
+        "BoolIfExists": {
+          "aws:PrincipalIsAWSService": "false"
+        }
+      }
+    }
+  ]
+}`,
+	},
+	{
+		Id:          "enforce-confused-deputy-protection",
+		Name:        "Enforce Confused Deputy Protection",
+		Description: "Prevents AWS services from accessing your resources on behalf of accounts outside of your organization.",
+		Content: `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "EnforceConfusedDeputyProtection",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": [
+        "s3:*",
+        "sqs:*",
+        "kms:*",
+        "secretsmanager:*",
+        "sts:*"
+      ],
+      "Resource": "*",
+      "Condition": {
+        "StringNotEqualsIfExists": {
+          "aws:SourceOrgID": "<ORGANIZATION_ID>"
+        },
+        "Null": {
+          "aws:SourceAccount": "false"
+        },
+        "Bool": {
+          "aws:PrincipalIsAWSService": "true"
+        }
+      }
+    }
+  ]
+}`,
+	},
+	{
+		Id:          "enforce-secure-transport",
+		Name:        "Enforce Secure Transport",
+		Description: "Denies any request to your resources that is not made over HTTPS.",
+		Content: `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "EnforceSecureTransport",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": [
+        "s3:*",
+        "sqs:*",
+        "kms:*",
+        "secretsmanager:*",
+        "sts:*"
+      ],
+      "Resource": "*",
+      "Condition": {
+        "BoolIfExists": {
+          "aws:SecureTransport": "false"
+        }
+      }
+    }
+  ]
+}`,
+	},
+	{
+		Id:          "enforce-s3-tls-version",
+		Name:        "Enforce Minimum S3 TLS Version",
+		Description: "Denies requests to your S3 buckets made using TLS versions older than 1.2.",
+		Content: `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "EnforceS3TLSVersion",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": "s3:*",
+      "Resource": "*",
+      "Condition": {
+        "NumericLessThan": {
+          "s3:TlsVersion": "1.2"
+        }
+      }
+    }
+  ]
+}`,
+	},
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+type AWSService struct {
+	// The service's IAM namespace, i.e. the prefix used for its actions in policies.
+	Namespace string
+	Name      string
+}
+
+// Metadata on known AWS services, keyed by CloudTrail event source. It should be expected that this
+// is not exhaustive as AWS regularly adds new services.
+var KnownAWSServices = map[string]AWSService{
+	// XXX: DO NOT EDIT MANUALLY. Data is generated by ../scripts/gather_aws_services.py.
+	"access-analyzer.amazonaws.com":         {"access-analyzer", "Access Analyzer"},
+	"account.amazonaws.com":                 {"account", "AWS Account"},
+	"acm-pca.amazonaws.com":                 {"acm-pca", "AWS Certificate Manager Private Certificate Authority"},
+	"acm.amazonaws.com":                     {"acm", "AWS Certificate Manager"},
+	"airflow.amazonaws.com":                 {"airflow", "AmazonMWAA"},
+	"amplify.amazonaws.com":                 {"amplify", "AWS Amplify"},
+	"api.ecr.amazonaws.com":                 {"ecr", "Amazon EC2 Container Registry"},
+	"apigateway.amazonaws.com":              {"apigateway", "Amazon API Gateway"},
+	"appconfig.amazonaws.com":               {"appconfig", "Amazon AppConfig"},
+	"application-autoscaling.amazonaws.com": {"application-autoscaling", "Application Auto Scaling"},
+	"apprunner.amazonaws.com":               {"apprunner", "AWS App Runner"},
+	"appstream.amazonaws.com":               {"appstream", "Amazon AppStream"},
+	"appsync.amazonaws.com":                 {"appsync", "AWS AppSync"},
+	"athena.amazonaws.com":                  {"athena", "Amazon Athena"},
+	"autoscaling.amazonaws.com":             {"autoscaling", "Auto Scaling"},
+	"backup.amazonaws.com":                  {"backup", "AWS Backup"},
+	"batch.amazonaws.com":                   {"batch", "AWS Batch"},
+	"bedrock.amazonaws.com":                 {"bedrock", "Amazon Bedrock"},
+	"billingconsole.amazonaws.com":          {"aws-portal", "AWS Billing Console"},
+	"budgets.amazonaws.com":                 {"budgets", "AWS Budgets"},
+	"ce.amazonaws.com":                      {"ce", "AWS Cost Explorer Service"},
+	"chatbot.amazonaws.com":                 {"chatbot", "AWS Chatbot"},
+	"cloud9.amazonaws.com":                  {"cloud9", "AWS Cloud9"},
+	"cloudcontrolapi.amazonaws.com":         {"cloudformation", "AWS Cloud Control API"},
+	"cloudformation.amazonaws.com":          {"cloudformation", "AWS CloudFormation"},
+	"cloudfront.amazonaws.com":              {"cloudfront", "Amazon CloudFront"},
+	"cloudhsm.amazonaws.com":                {"cloudhsm", "AWS CloudHSM V2"},
+	"cloudshell.amazonaws.com":              {"cloudshell", "AWS CloudShell"},
+	"cloudtrail.amazonaws.com":              {"cloudtrail", "AWS CloudTrail"},
+	"codeartifact.amazonaws.com":            {"codeartifact", "CodeArtifact"},
+	"codebuild.amazonaws.com":               {"codebuild", "AWS CodeBuild"},
+	"codecommit.amazonaws.com":              {"codecommit", "AWS CodeCommit"},
+	"codedeploy.amazonaws.com":              {"codedeploy", "AWS CodeDeploy"},
+	"codepipeline.amazonaws.com":            {"codepipeline", "AWS CodePipeline"},
+	"codestar-connections.amazonaws.com":    {"codestar-connections", "AWS CodeStar connections"},
+	"cognito-identity.amazonaws.com":        {"cognito-identity", "Amazon Cognito Identity"},
+	"cognito-idp.amazonaws.com":             {"cognito-idp", "Amazon Cognito Identity Provider"},
+	"comprehend.amazonaws.com":              {"comprehend", "Amazon Comprehend"},
+	"compute-optimizer.amazonaws.com":       {"compute-optimizer", "AWS Compute Optimizer"},
+	"config.amazonaws.com":                  {"config", "AWS Config"},
+	"connect.amazonaws.com":                 {"connect", "Amazon Connect Service"},
+	"controltower.amazonaws.com":            {"controltower", "AWS Control Tower"},
+	"cost-optimization-hub.amazonaws.com":   {"cost-optimization-hub", "Cost Optimization Hub"},
+	"databrew.amazonaws.com":                {"databrew", "AWS Glue DataBrew"},
+	"datasync.amazonaws.com":                {"datasync", "AWS DataSync"},
+	"dax.amazonaws.com":                     {"dax", "Amazon DynamoDB Accelerator (DAX)"},
+	"detective.amazonaws.com":               {"detective", "Amazon Detective"},
+	"devicefarm.amazonaws.com":              {"devicefarm", "AWS Device Farm"},
+	"directconnect.amazonaws.com":           {"directconnect", "AWS Direct Connect"},
+	"dms.amazonaws.com":                     {"dms", "AWS Database Migration Service"},
+	"ds.amazonaws.com":                      {"ds", "AWS Directory Service"},
+	"dynamodb.amazonaws.com":                {"dynamodb", "Amazon DynamoDB"},
+	"ebs.amazonaws.com":                     {"ebs", "Amazon Elastic Block Store"},
+	"ec2-instance-connect.amazonaws.com":    {"ec2-instance-connect", "AWS EC2 Instance Connect"},
+	"ec2.amazonaws.com":                     {"ec2", "Amazon Elastic Compute Cloud"},
+	"ecr-public.amazonaws.com":              {"ecr-public", "Amazon Elastic Container Registry Public"},
+	"ecr.amazonaws.com":                     {"ecr", "Amazon EC2 Container Registry"},
+	"ecs.amazonaws.com":                     {"ecs", "Amazon EC2 Container Service"},
+	"eks.amazonaws.com":                     {"eks", "Amazon Elastic Kubernetes Service"},
+	"elasticache.amazonaws.com":             {"elasticache", "Amazon ElastiCache"},
+	"elasticbeanstalk.amazonaws.com":        {"elasticbeanstalk", "AWS Elastic Beanstalk"},
+	"elasticfilesystem.amazonaws.com":       {"elasticfilesystem", "Amazon Elastic File System"},
+	"elasticloadbalancing.amazonaws.com":    {"elasticloadbalancing", "Elastic Load Balancing"},
+	"elasticmapreduce.amazonaws.com":        {"elasticmapreduce", "Amazon EMR"},
+	"email.amazonaws.com":                   {"ses", "Amazon Simple Email Service"},
+	"es.amazonaws.com":                      {"es", "Amazon OpenSearch Service"},
+	"events.amazonaws.com":                  {"events", "Amazon EventBridge"},
+	"firehose.amazonaws.com":                {"firehose", "Amazon Kinesis Firehose"},
+	"fms.amazonaws.com":                     {"fms", "Firewall Management Service"},
+	"freetier.amazonaws.com":                {"freetier", "AWS Free Tier"},
+	"fsx.amazonaws.com":                     {"fsx", "Amazon FSx"},
+	"glacier.amazonaws.com":                 {"glacier", "Amazon Glacier"},
+	"globalaccelerator.amazonaws.com":       {"globalaccelerator", "AWS Global Accelerator"},
+	"glue.amazonaws.com":                    {"glue", "AWS Glue"},
+	"guardduty.amazonaws.com":               {"guardduty", "Amazon GuardDuty"},
+	"health.amazonaws.com":                  {"health", "AWS Health APIs and Notifications"},
+	"iam.amazonaws.com":                     {"iam", "AWS Identity and Access Management"},
+	"identitystore.amazonaws.com":           {"identitystore", "AWS SSO Identity Store"},
+	"imagebuilder.amazonaws.com":            {"imagebuilder", "EC2 Image Builder"},
+	"inspector2.amazonaws.com":              {"inspector2", "Inspector2"},
+	"iot-data.amazonaws.com":                {"iot", "AWS IoT Data Plane"},
+	"iot.amazonaws.com":                     {"iot", "AWS IoT"},
+	"iotdata.amazonaws.com":                 {"iot", "AWS IoT Data Plane"},
+	"kafka.amazonaws.com":                   {"kafka", "Managed Streaming for Kafka"},
+	"kinesis.amazonaws.com":                 {"kinesis", "Amazon Kinesis"},
+	"kinesisanalytics.amazonaws.com":        {"kinesisanalytics", "Amazon Kinesis Analytics"},
+	"kms.amazonaws.com":                     {"kms", "AWS Key Management Service"},
+	"lakeformation.amazonaws.com":           {"lakeformation", "AWS Lake Formation"},
+	"lambda.amazonaws.com":                  {"lambda", "AWS Lambda"},
+	"lex.amazonaws.com":                     {"lex", "Amazon Lex Model Building V2"},
+	"license-manager.amazonaws.com":         {"license-manager", "AWS License Manager"},
+	"lightsail.amazonaws.com":               {"lightsail", "Amazon Lightsail"},
+	"logs.amazonaws.com":                    {"logs", "Amazon CloudWatch Logs"},
+	"macie2.amazonaws.com":                  {"macie2", "Amazon Macie 2"},
+	"mediaconvert.amazonaws.com":            {"mediaconvert", "AWS Elemental MediaConvert"},
+	"memorydb.amazonaws.com":                {"memorydb", "Amazon MemoryDB"},
+	"metering-marketplace.amazonaws.com":    {"aws-marketplace", "AWSMarketplace Metering"},
+	"models.lex.amazonaws.com":              {"lex", "Amazon Lex Model Building Service"},
+	"monitoring.amazonaws.com":              {"cloudwatch", "Amazon CloudWatch"},
+	"mq.amazonaws.com":                      {"mq", "AmazonMQ"},
+	"network-firewall.amazonaws.com":        {"network-firewall", "AWS Network Firewall"},
+	"networkmanager.amazonaws.com":          {"networkmanager", "AWS Network Manager"},
+	"notifications.amazonaws.com":           {"notifications", "AWS User Notifications"},
+	"organizations.amazonaws.com":           {"organizations", "AWS Organizations"},
+	"pipes.amazonaws.com":                   {"pipes", "Amazon EventBridge Pipes"},
+	"pricing.amazonaws.com":                 {"pricing", "AWS Price List Service"},
+	"quicksight.amazonaws.com":              {"quicksight", "Amazon QuickSight"},
+	"ram.amazonaws.com":                     {"ram", "AWS Resource Access Manager"},
+	"rds-data.amazonaws.com":                {"rds-data", "AWS RDS DataService"},
+	"rds.amazonaws.com":                     {"rds", "Amazon Relational Database Service"},
+	"redshift-data.amazonaws.com":           {"redshift-data", "Redshift Data API Service"},
+	"redshift.amazonaws.com":                {"redshift", "Amazon Redshift"},
+	"rekognition.amazonaws.com":             {"rekognition", "Amazon Rekognition"},
+	"resource-explorer-2.amazonaws.com":     {"resource-explorer-2", "AWS Resource Explorer"},
+	"resource-groups.amazonaws.com":         {"resource-groups", "AWS Resource Groups"},
+	"route53.amazonaws.com":                 {"route53", "Amazon Route 53"},
+	"route53domains.amazonaws.com":          {"route53domains", "Amazon Route 53 Domains"},
+	"route53resolver.amazonaws.com":         {"route53resolver", "Amazon Route 53 Resolver"},
+	"runtime.lex.amazonaws.com":             {"lex", "Amazon Lex Runtime Service"},
+	"s3-outposts.amazonaws.com":             {"s3-outposts", "Amazon S3 on Outposts"},
+	"s3.amazonaws.com":                      {"s3", "Amazon Simple Storage Service"},
+	"sagemaker.amazonaws.com":               {"sagemaker", "Amazon SageMaker Service"},
+	"scheduler.amazonaws.com":               {"scheduler", "Amazon EventBridge Scheduler"},
+	"schemas.amazonaws.com":                 {"schemas", "Schemas"},
+	"secretsmanager.amazonaws.com":          {"secretsmanager", "AWS Secrets Manager"},
+	"securityhub.amazonaws.com":             {"securityhub", "AWS SecurityHub"},
+	"servicecatalog.amazonaws.com":          {"servicecatalog", "AWS Service Catalog"},
+	"servicediscovery.amazonaws.com":        {"servicediscovery", "AWS Cloud Map"},
+	"servicequotas.amazonaws.com":           {"servicequotas", "Service Quotas"},
+	"ses.amazonaws.com":                     {"ses", "Amazon Simple Email Service"},
+	"shield.amazonaws.com":                  {"shield", "AWS Shield"},
+	"signin.amazonaws.com":                  {"signin", "AWS Sign-In"},
+	"sns.amazonaws.com":                     {"sns", "Amazon Simple Notification Service"},
+	"sqs.amazonaws.com":                     {"sqs", "Amazon Simple Queue Service"},
+	"ssm-incidents.amazonaws.com":           {"ssm-incidents", "AWS Systems Manager Incident Manager"},
+	"ssm.amazonaws.com":                     {"ssm", "Amazon Simple Systems Manager (SSM)"},
+	"ssmmessages.amazonaws.com":             {"ssmmessages", "Amazon Session Manager Message Gateway Service"},
+	"sso-directory.amazonaws.com":           {"sso-directory", "AWS IAM Identity Center Directory"},
+	"sso.amazonaws.com":                     {"sso", "AWS IAM Identity Center"},
+	"states.amazonaws.com":                  {"states", "AWS Step Functions"},
+	"storagegateway.amazonaws.com":          {"storagegateway", "AWS Storage Gateway"},
+	"sts.amazonaws.com":                     {"sts", "AWS Security Token Service"},
+	"support.amazonaws.com":                 {"support", "AWS Support"},
+	"synthetics.amazonaws.com":              {"synthetics", "Synthetics"},
+	"tagging.amazonaws.com":                 {"tag", "AWS Resource Groups Tagging API"},
+	"textract.amazonaws.com":                {"textract", "Amazon Textract"},
+	"transcribe.amazonaws.com":              {"transcribe", "Amazon Transcribe Service"},
+	"transfer.amazonaws.com":                {"transfer", "AWS Transfer Family"},
+	"translate.amazonaws.com":               {"translate", "Amazon Translate"},
+	"trustedadvisor.amazonaws.com":          {"trustedadvisor", "AWS Trusted Advisor"},
+	"waf-regional.amazonaws.com":            {"waf-regional", "AWS WAF Regional"},
+	"waf.amazonaws.com":                     {"waf", "AWS WAF"},
+	"wafv2.amazonaws.com":                   {"wafv2", "AWS WAFV2"},
+	"workspaces.amazonaws.com":              {"workspaces", "Amazon WorkSpaces"},
+	"xray.amazonaws.com":                    {"xray", "AWS X-Ray"},
+}
+
+// Gets the service for a CloudTrail event source. If the event source isn't known, the namespace is
+// guessed from the event source and the event source is used as the name.
+func AWSServiceForEventSource(source string) AWSService {
+	if service, ok := KnownAWSServices[source]; ok {
+		return service
+	}
+	return AWSService{
+		Namespace: strings.TrimSuffix(source, ".amazonaws.com"),
+		Name:      source,
+	}
+}
+
+// Fills in the service of each of the report's event summaries so that consumers of the report
+// don't need their own copy of the catalogue.
+func annotateReportAWSServices(r *report.Report) {
+	for _, principal := range r.Principals {
+		for _, event := range principal.Events {
+			service := AWSServiceForEventSource(event.Source)
+			event.ServiceNamespace = service.Namespace
+			event.ServiceName = service.Name
+		}
+	}
+}
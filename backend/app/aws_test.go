@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -61,3 +62,39 @@ func TestClosestAvailableAWSRegion(t *testing.T) {
 		})
 	}
 }
+
+func TestAWSRCPTemplates(t *testing.T) {
+	ids := map[string]bool{}
+	for _, template := range AWSRCPTemplates {
+		assert.False(t, ids[template.Id], "duplicate template id %v", template.Id)
+		ids[template.Id] = true
+		assert.True(t, json.Valid([]byte(template.Content)), "invalid json in template %v", template.Id)
+	}
+}
+
+func TestAWSIAMActionForEvent(t *testing.T) {
+	for _, tc := range []struct {
+		Source   string
+		Name     string
+		Expected string
+	}{
+		{"ssm.amazonaws.com", "UpdateInstanceInformation", "ssm:UpdateInstanceInformation"},
+		{"monitoring.amazonaws.com", "PutMetricData", "cloudwatch:PutMetricData"},
+		{"s3.amazonaws.com", "ListObjectsV2", "s3:ListBucket"},
+		{"s3.amazonaws.com", "HeadObject", "s3:GetObject"},
+		{"lambda.amazonaws.com", "Invoke20150331", "lambda:InvokeFunction"},
+		{"lambda.amazonaws.com", "GetFunction20150331v2", "lambda:GetFunction"},
+		{"cloudfront.amazonaws.com", "GetDistribution2020_05_31", "cloudfront:GetDistribution"},
+		{"sts.amazonaws.com", "GetCallerIdentity", ""},
+		{"signin.amazonaws.com", "ConsoleLogin", ""},
+	} {
+		assert.Equal(t, tc.Expected, awsIAMActionForEvent(tc.Source, tc.Name), tc.Source+":"+tc.Name)
+	}
+}
+
+func TestAWSServiceForEventSource(t *testing.T) {
+	assert.Equal(t, AWSService{"cloudwatch", "Amazon CloudWatch"}, AWSServiceForEventSource("monitoring.amazonaws.com"))
+	assert.Equal(t, "lex", AWSServiceForEventSource("runtime.lex.amazonaws.com").Namespace)
+	assert.Equal(t, "iot", AWSServiceForEventSource("iot-data.amazonaws.com").Namespace)
+	assert.Equal(t, AWSService{"foo", "foo.amazonaws.com"}, AWSServiceForEventSource("foo.amazonaws.com"))
+}
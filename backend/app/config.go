@@ -1,6 +1,9 @@
 package app
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/stripe/stripe-go/v81"
 
 	"github.com/ccbrown/cloud-snitch/backend/store"
@@ -23,6 +26,25 @@ type Config struct {
 	StripeSecretKey           string
 	Pricing                   PricingConfig
 
+	// How long completed AWS access reports are cached for. Defaults to
+	// DefaultAWSAccessReportCacheDuration.
+	AWSAccessReportCacheDuration time.Duration
+
+	// Where rate limit counters are kept. Defaults to RateLimitBackendMemory.
+	RateLimitBackend RateLimitBackend
+
+	// If true, webhooks may use plain HTTP URLs and non-public addresses. This should only be used
+	// for testing.
+	AllowInsecureWebhookURLs bool
+
+	// If true, SIEM sinks may use plain HTTP URLs and non-public addresses. This should only be
+	// used for testing.
+	AllowInsecureSIEMSinks bool
+
+	// If true, OpenID Connect issuers may use plain HTTP URLs and non-public addresses. This should
+	// only be used for testing.
+	AllowInsecureOIDCIssuers bool
+
 	// These can be overridden with mock implementations for testing.
 	STS                  AWSSTSAPI
 	SQSFactory           AmazonSQSAPIFactory
@@ -31,6 +53,7 @@ type Config struct {
 	OrganizationsFactory AWSOrganizationsAPIFactory
 	IAMFactory           AWSIAMAPIFactory
 	StripeAPIBackend     stripe.Backend
+	HTTPClient           *http.Client
 }
 
 type PricingConfig struct {
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+// Digests compare the latest period against this much preceding activity to determine what's new.
+const digestBaselineDuration = 7 * 24 * time.Hour
+
+// Each section of a digest is truncated to this many items.
+const maxDigestSectionItems = 10
+
+type QueueTeamDigestsInput struct {
+	Frequency model.DigestFrequency
+
+	// The end of the period covered by the digests. If not given, the time the message was sent is
+	// used, truncated to the start of the day.
+	EndTime time.Time
+}
+
+// Queues digest generation for every team. Teams without any members subscribed to the given
+// frequency are skipped when the digest is generated.
+func (a *App) QueueTeamDigests(ctx context.Context, input QueueTeamDigestsInput) error {
+	if input.Frequency.Duration() == 0 {
+		return fmt.Errorf("invalid digest frequency: %q", input.Frequency)
+	}
+
+	teams, err := a.store.GetTeams(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get teams: %w", err)
+	}
+
+	var msgs []OutgoingQueueMessage
+	for _, team := range teams {
+		msgs = append(msgs, OutgoingQueueMessage{
+			Delay: time.Duration(rand.Intn(int(MaxQueueDelay/time.Second))) * time.Second,
+			Message: QueueMessage{
+				SendTeamDigest: &SendTeamDigestInput{
+					TeamId:    team.Id,
+					Frequency: input.Frequency,
+					EndTime:   input.EndTime,
+				},
+			},
+		})
+	}
+
+	if err := a.QueueMessages(ctx, map[string][]OutgoingQueueMessage{
+		a.awsRegion: msgs,
+	}); err != nil {
+		return fmt.Errorf("unable to queue team digests: %w", err)
+	}
+
+	return nil
+}
+
+type digestPrincipalActivity struct {
+	Name  string
+	Count int
+}
+
+type digestError struct {
+	Action    string
+	ErrorCode string
+	Count     int
+}
+
+type teamDigest struct {
+	// If false, there wasn't any earlier activity to compare against, so NewPrincipals and
+	// NewCountries are omitted.
+	HasBaseline bool
+
+	EventCount    int
+	NewPrincipals []string
+	NewCountries  []string
+	TopErrors     []digestError
+	RootActivity  []digestPrincipalActivity
+	ConsoleLogins []digestPrincipalActivity
+}
+
+func (d *teamDigest) IsEmpty() bool {
+	return d.EventCount == 0
+}
+
+func reportCountryNames(r *report.Report) map[string]string {
+	ret := map[string]string{}
+	for _, location := range r.NetworkLocations {
+		if location.CountryCode != "" {
+			ret[location.CountryCode] = location.CountryName
+		}
+	}
+	return ret
+}
+
+func isRootPrincipal(principal *report.Principal) bool {
+	return principal.Type == report.PrincipalTypeAWSAccount || strings.HasSuffix(principal.ARN, ":root")
+}
+
+func sortDigestPrincipalActivity(activity []digestPrincipalActivity) []digestPrincipalActivity {
+	sort.Slice(activity, func(i, j int) bool {
+		if activity[i].Count != activity[j].Count {
+			return activity[i].Count > activity[j].Count
+		}
+		return activity[i].Name < activity[j].Name
+	})
+	return activity[:min(len(activity), maxDigestSectionItems)]
+}
+
+// Summarizes the activity in the current report. If a baseline is given, principals and countries
+// that don't appear in it are called out as new.
+func newTeamDigest(current, baseline *report.Report) *teamDigest {
+	ret := &teamDigest{
+		HasBaseline: baseline != nil,
+	}
+
+	errorCounts := map[digestError]int{}
+
+	for key, principal := range current.Principals {
+		name := principalDisplayName(key, principal)
+
+		if baseline != nil {
+			if _, ok := baseline.Principals[key]; !ok {
+				ret.NewPrincipals = append(ret.NewPrincipals, name)
+			}
+		}
+
+		total, consoleLogins := 0, 0
+		for _, event := range principal.Events {
+			total += event.Count
+			action := AWSServiceForEventSource(event.Source).Namespace + ":" + event.Name
+			if event.Source == "signin.amazonaws.com" && event.Name == "ConsoleLogin" {
+				consoleLogins += event.Count
+			}
+			for code, count := range event.ErrorCodes {
+				errorCounts[digestError{Action: action, ErrorCode: code}] += count
+			}
+		}
+		ret.EventCount += total
+
+		if isRootPrincipal(principal) && total > 0 {
+			ret.RootActivity = append(ret.RootActivity, digestPrincipalActivity{Name: name, Count: total})
+		}
+		if consoleLogins > 0 {
+			ret.ConsoleLogins = append(ret.ConsoleLogins, digestPrincipalActivity{Name: name, Count: consoleLogins})
+		}
+	}
+
+	if baseline != nil {
+		baselineCountries := reportCountryNames(baseline)
+		for code, name := range reportCountryNames(current) {
+			if _, ok := baselineCountries[code]; !ok {
+				ret.NewCountries = append(ret.NewCountries, name)
+			}
+		}
+	}
+
+	for e, count := range errorCounts {
+		e.Count = count
+		ret.TopErrors = append(ret.TopErrors, e)
+	}
+	sort.Slice(ret.TopErrors, func(i, j int) bool {
+		if ret.TopErrors[i].Count != ret.TopErrors[j].Count {
+			return ret.TopErrors[i].Count > ret.TopErrors[j].Count
+		}
+		return ret.TopErrors[i].Action+ret.TopErrors[i].ErrorCode < ret.TopErrors[j].Action+ret.TopErrors[j].ErrorCode
+	})
+	ret.TopErrors = ret.TopErrors[:min(len(ret.TopErrors), maxDigestSectionItems)]
+
+	sort.Strings(ret.NewPrincipals)
+	ret.NewPrincipals = ret.NewPrincipals[:min(len(ret.NewPrincipals), maxDigestSectionItems)]
+	sort.Strings(ret.NewCountries)
+	ret.RootActivity = sortDigestPrincipalActivity(ret.RootActivity)
+	ret.ConsoleLogins = sortDigestPrincipalActivity(ret.ConsoleLogins)
+
+	return ret
+}
+
+type SendTeamDigestInput struct {
+	TeamId    model.Id
+	Frequency model.DigestFrequency
+	EndTime   time.Time
+}
+
+// Emails a digest of the team's recent activity to each member subscribed to the given frequency.
+// Members whose roles no longer allow them to read reports are skipped.
+func (a *App) SendTeamDigest(ctx context.Context, input SendTeamDigestInput) error {
+	duration := input.Frequency.Duration()
+	if duration == 0 {
+		return fmt.Errorf("invalid digest frequency: %q", input.Frequency)
+	}
+
+	memberships, err := a.store.GetTeamMembershipsByTeamId(ctx, input.TeamId)
+	if err != nil {
+		return fmt.Errorf("failed to get team memberships: %w", err)
+	}
+	var userIds []model.Id
+	for _, membership := range memberships {
+		if membership.DigestFrequency == input.Frequency && membership.Role.HasPermission(model.TeamPermissionReadReports) {
+			userIds = append(userIds, membership.UserId)
+		}
+	}
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	team, err := a.store.GetTeamById(ctx, input.TeamId, store.ConsistencyEventual)
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	} else if team == nil {
+		return nil
+	}
+
+	startTime := input.EndTime.Add(-duration)
+	current, count, err := a.loadMergedTeamReport(ctx, loadMergedTeamReportInput{
+		TeamId:    input.TeamId,
+		StartTime: startTime,
+		EndTime:   input.EndTime,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load current reports: %w", err)
+	} else if count == 0 {
+		return nil
+	}
+
+	baseline, count, err := a.loadMergedTeamReport(ctx, loadMergedTeamReportInput{
+		TeamId:    input.TeamId,
+		StartTime: startTime.Add(-digestBaselineDuration),
+		EndTime:   startTime,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load baseline reports: %w", err)
+	} else if count == 0 {
+		baseline = nil
+	}
+
+	digest := newTeamDigest(current, baseline)
+	if digest.IsEmpty() {
+		return nil
+	}
+
+	users, err := a.store.GetUsersByIds(ctx, userIds...)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	period := "Daily"
+	if input.Frequency == model.DigestFrequencyWeekly {
+		period = "Weekly"
+	}
+
+	for _, user := range users {
+		if err := a.Email(ctx, user.EmailAddress, period+" Digest: "+team.Name, "digest_email.html.tmpl", map[string]any{
+			"TeamId":    team.Id,
+			"TeamName":  team.Name,
+			"Period":    strings.ToLower(period),
+			"StartTime": startTime.UTC().Format(time.RFC1123),
+			"EndTime":   input.EndTime.UTC().Format(time.RFC1123),
+			"Digest":    digest,
+		}); err != nil {
+			return fmt.Errorf("failed to send digest email: %w", err)
+		}
+	}
+
+	return nil
+}
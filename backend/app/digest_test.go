@@ -0,0 +1,90 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestSendTeamDigest(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	alice, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierTeam)
+
+	var err error
+
+	a.NewTestAWSCloudTrailReport(sess, team.Id)
+
+	daily := model.DigestFrequencyDaily
+
+	t.Run("InvalidFrequency", func(t *testing.T) {
+		frequency := model.DigestFrequency("hourly")
+		_, err := sess.PatchTeamMembershipByTeamAndUserId(context.Background(), team.Id, alice.Id, app.TeamMembershipPatch{
+			DigestFrequency: &frequency,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+		_, err := otherSess.PatchTeamMembershipByTeamAndUserId(context.Background(), team.Id, alice.Id, app.TeamMembershipPatch{
+			DigestFrequency: &daily,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("NoReportAccess", func(t *testing.T) {
+		carol, carolSess := a.NewTestUser("carol@example.com", model.UserRoleCustomer)
+		require.NoError(t, sess.InviteToTeam(context.Background(), app.InviteToTeamInput{
+			TeamId:       team.Id,
+			EmailAddress: carol.EmailAddress,
+			Role:         model.TeamMembershipRoleBilling,
+		}))
+		_, err := carolSess.JoinTeam(context.Background(), team.Id)
+		require.NoError(t, err)
+
+		_, err = carolSess.PatchTeamMembershipByTeamAndUserId(context.Background(), team.Id, carol.Id, app.TeamMembershipPatch{
+			DigestFrequency: &daily,
+		})
+		assert.Error(t, err)
+	})
+
+	membership, err := sess.PatchTeamMembershipByTeamAndUserId(context.Background(), team.Id, alice.Id, app.TeamMembershipPatch{
+		DigestFrequency: &daily,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.DigestFrequencyDaily, membership.DigestFrequency)
+
+	// Nobody is subscribed to weekly digests, so this shouldn't send anything.
+	require.NoError(t, a.SendTeamDigest(context.Background(), app.SendTeamDigestInput{
+		TeamId:    team.Id,
+		Frequency: model.DigestFrequencyWeekly,
+		EndTime:   time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC),
+	}))
+
+	require.NoError(t, a.SendTeamDigest(context.Background(), app.SendTeamDigestInput{
+		TeamId:    team.Id,
+		Frequency: model.DigestFrequencyDaily,
+		EndTime:   time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC),
+	}))
+
+	for {
+		email := <-a.Emails()
+		if email.Subject == "Daily Digest: Test Team" {
+			assert.Equal(t, "alice@example.com", email.To)
+			assert.Contains(t, email.HTML, "Top Errors")
+			// There's no earlier activity, so nothing can be called out as new.
+			assert.NotContains(t, email.HTML, "New Principals")
+			break
+		}
+	}
+}
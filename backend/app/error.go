@@ -68,6 +68,18 @@ func (e AuthenticationError) Error() string {
 	return "Bad authorization."
 }
 
+// Returned when the credentials are valid, but the user has a TOTP authenticator and no code was
+// provided. Clients should prompt for a code and try again.
+type SecondFactorRequiredError struct{}
+
+func (e SecondFactorRequiredError) UserFacingError() string {
+	return e.Error()
+}
+
+func (e SecondFactorRequiredError) Error() string {
+	return "Please enter the code from your authenticator app."
+}
+
 type NotFoundError string
 
 func (e NotFoundError) UserFacingError() string {
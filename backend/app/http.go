@@ -0,0 +1,51 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// The carrier-grade NAT range isn't covered by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Returns true if the address is reachable on the public internet. Requests to user-provided URLs
+// are made from within our network, so they must not be allowed to target loopback, link-local
+// (including the instance metadata service at 169.254.169.254), or private addresses.
+func isPublicIPAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// Creates a dialer which refuses to connect to non-public addresses. The check is done after DNS
+// resolution, so hostnames can't be used to get around it.
+func newPublicDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("failed to parse address: %w", err)
+			} else if !isPublicIPAddress(addrPort.Addr()) {
+				return fmt.Errorf("refusing to connect to non-public address %v", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+}
+
+// Creates an HTTP client which refuses to connect to non-public addresses.
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := newPublicDialer(timeout)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would make the connection on our behalf, bypassing the check.
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
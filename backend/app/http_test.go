@@ -0,0 +1,52 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIPAddress(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"8.8.8.8":                true,
+		"2606:4700::1111":        true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"169.254.169.254":        false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"fd00::1":                false,
+		"fe80::1":                false,
+		"::ffff:169.254.169.254": false,
+	} {
+		assert.Equal(t, expected, isPublicIPAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestNewPublicHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := newPublicHTTPClient(time.Second).Get(server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "non-public address")
+}
+
+func TestNewPublicDialer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	_, err = newPublicDialer(time.Second).Dial("tcp", listener.Addr().String())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "non-public address")
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// How long responses to idempotent requests are kept for replay.
+const IdempotentRequestRetention = 24 * time.Hour
+
+const maxIdempotencyKeyLength = 255
+
+// Responses larger than this aren't kept, since they may not fit in the store.
+const maxIdempotentResponseBodySize = 256 * 1024
+
+type IdempotentRequestInProgressError struct{}
+
+func (e IdempotentRequestInProgressError) UserFacingError() string {
+	return e.Error()
+}
+
+func (e IdempotentRequestInProgressError) Error() string {
+	return "A request with this idempotency key is already in progress."
+}
+
+type BeginIdempotentRequestInput struct {
+	// The client-provided idempotency key.
+	Key string
+
+	Method string
+	Path   string
+	Body   []byte
+}
+
+func hashWithLengthPrefixes(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, part := range parts {
+		n := len(part)
+		h.Write([]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// Keys are scoped to the authenticated user or API key so that clients can't observe each other's
+// responses.
+func (s *Session) idempotencyPrincipal() string {
+	if s.user != nil {
+		return "user:" + s.user.Id.String()
+	} else if s.teamAPIKey != nil {
+		return "team_api_key:" + s.teamAPIKey.Id.String()
+	}
+	return ""
+}
+
+// Begins a request with an idempotency key. If the key hasn't been used before, a new request is
+// returned and should be completed or abandoned once the response is known. If the key has already
+// been used for an identical completed request, the completed request is returned so that its
+// response can be replayed.
+func (s *Session) BeginIdempotentRequest(ctx context.Context, input BeginIdempotentRequestInput) (*model.IdempotentRequest, UserFacingError) {
+	principal := s.idempotencyPrincipal()
+	if principal == "" {
+		return nil, AuthenticationError{}
+	} else if input.Key == "" || len(input.Key) > maxIdempotencyKeyLength {
+		return nil, NewUserError("Idempotency keys must be between 1 and 255 characters.")
+	}
+
+	now := time.Now()
+	r := &model.IdempotentRequest{
+		KeyHash:        hashWithLengthPrefixes([]byte(principal), []byte(input.Key)),
+		Fingerprint:    hashWithLengthPrefixes([]byte(input.Method), []byte(input.Path), input.Body),
+		CreationTime:   now,
+		ExpirationTime: now.Add(IdempotentRequestRetention),
+	}
+
+	if created, err := s.app.store.CreateIdempotentRequest(ctx, r); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if created {
+		return r, nil
+	}
+
+	existing, err := s.app.store.GetIdempotentRequestByKeyHash(ctx, r.KeyHash)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if existing == nil {
+		// The request was abandoned between our create and get.
+		return nil, IdempotentRequestInProgressError{}
+	} else if !bytes.Equal(existing.Fingerprint, r.Fingerprint) {
+		return nil, NewUserError("This idempotency key has already been used for a different request.")
+	} else if !existing.IsComplete() {
+		return nil, IdempotentRequestInProgressError{}
+	}
+	return existing, nil
+}
+
+// Saves the response to a request returned by BeginIdempotentRequest. Only successful responses
+// are saved. Otherwise the request is abandoned so that the client can retry it.
+func (s *Session) CompleteIdempotentRequest(ctx context.Context, r *model.IdempotentRequest, statusCode int, contentType string, body []byte) UserFacingError {
+	if statusCode >= 400 || len(body) > maxIdempotentResponseBodySize {
+		return s.AbandonIdempotentRequest(ctx, r)
+	}
+
+	r.ResponseStatusCode = statusCode
+	r.ResponseContentType = contentType
+	r.EncryptedResponseBody = model.EncryptSecret(body, s.app.config.PasswordEncryptionKey)
+	return s.SanitizedError(s.app.store.PutIdempotentRequest(ctx, r))
+}
+
+// Returns the saved response body of a completed request.
+func (s *Session) IdempotentRequestResponseBody(r *model.IdempotentRequest) []byte {
+	return model.DecryptSecret(r.EncryptedResponseBody, s.app.config.PasswordEncryptionKey)
+}
+
+// Deletes a request returned by BeginIdempotentRequest so that its key can be used again.
+func (s *Session) AbandonIdempotentRequest(ctx context.Context, r *model.IdempotentRequest) UserFacingError {
+	return s.SanitizedError(s.app.store.DeleteIdempotentRequestByKeyHash(ctx, r.KeyHash))
+}
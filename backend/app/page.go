@@ -0,0 +1,47 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+type PageInput struct {
+	// Defaults to defaultPageLimit.
+	Limit int
+
+	// A cursor returned with a previous page. If empty, the first page is returned.
+	Cursor string
+}
+
+func (input PageInput) storePageInput() (store.PageInput, UserFacingError) {
+	if input.Limit < 0 || input.Limit > maxPageLimit {
+		return store.PageInput{}, NewUserError("The limit must be between 1 and 1000.")
+	} else if input.Limit == 0 {
+		input.Limit = defaultPageLimit
+	}
+	return store.PageInput{
+		Limit:  input.Limit,
+		Cursor: input.Cursor,
+	}, nil
+}
+
+type Page[T any] struct {
+	Items []T
+
+	// Can be used to get the next page. Empty if there are no more items.
+	NextCursor string
+}
+
+// Like SanitizedError, but reports invalid cursors to the user.
+func (s *Session) sanitizedPageError(err error) UserFacingError {
+	if errors.Is(err, store.ErrInvalidCursor) {
+		return NewUserError("Invalid cursor.")
+	}
+	return s.SanitizedError(err)
+}
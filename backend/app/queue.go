@@ -21,6 +21,11 @@ type QueueMessage struct {
 	UpdateTeamStripeSubscription       *UpdateTeamStripeSubscriptionInput `json:",omitempty"`
 	QueueTeamEntitlementRefreshes      *struct{}                          `json:",omitempty"`
 	RefreshTeamEntitlements            *RefreshTeamEntitlementsInput      `json:",omitempty"`
+	PollAWSAccessReportJob             *PollAWSAccessReportJobInput       `json:",omitempty"`
+	DeliverWebhook                     *DeliverWebhookInput               `json:",omitempty"`
+	QueueTeamDigests                   *QueueTeamDigestsInput             `json:",omitempty"`
+	SendTeamDigest                     *SendTeamDigestInput               `json:",omitempty"`
+	ForwardToSIEMSink                  *ForwardToSIEMSinkInput            `json:",omitempty"`
 }
 
 type OutgoingQueueMessage struct {
@@ -72,6 +77,36 @@ func (a *App) HandleQueueMessage(ctx context.Context, message QueueMessage, attr
 			return fmt.Errorf("failed to refresh team entitlements: %w", err)
 		}
 	}
+	if message.PollAWSAccessReportJob != nil {
+		if err := a.PollAWSAccessReportJob(ctx, *message.PollAWSAccessReportJob); err != nil {
+			return fmt.Errorf("failed to poll aws access report job: %w", err)
+		}
+	}
+	if message.DeliverWebhook != nil {
+		if err := a.DeliverWebhook(ctx, *message.DeliverWebhook); err != nil {
+			return fmt.Errorf("failed to deliver webhook: %w", err)
+		}
+	}
+	if message.QueueTeamDigests != nil {
+		input := *message.QueueTeamDigests
+		if input.EndTime.IsZero() {
+			// Align to daily report boundaries so that reports don't straddle the digest periods.
+			input.EndTime = attrs.SendTime.Truncate(24 * time.Hour)
+		}
+		if err := a.QueueTeamDigests(ctx, input); err != nil {
+			return fmt.Errorf("failed to queue team digests: %w", err)
+		}
+	}
+	if message.SendTeamDigest != nil {
+		if err := a.SendTeamDigest(ctx, *message.SendTeamDigest); err != nil {
+			return fmt.Errorf("failed to send team digest: %w", err)
+		}
+	}
+	if message.ForwardToSIEMSink != nil {
+		if err := a.ForwardToSIEMSink(ctx, *message.ForwardToSIEMSink); err != nil {
+			return fmt.Errorf("failed to forward to siem sink: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+type RateLimitBackend string
+
+const (
+	// Counters are kept in memory, so limits are enforced per process. This is the default.
+	RateLimitBackendMemory RateLimitBackend = "memory"
+
+	// Counters are kept in DynamoDB, so limits are shared by all processes.
+	RateLimitBackendDynamoDB RateLimitBackend = "dynamodb"
+)
+
+type RateLimit struct {
+	// The maximum number of requests permitted per period.
+	Limit  int
+	Period time.Duration
+}
+
+type RateLimitError struct {
+	// How long until the limit resets.
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) UserFacingError() string {
+	return e.Error()
+}
+
+func (e RateLimitError) Error() string {
+	return "Too many requests. Please try again later."
+}
+
+type RateLimiter interface {
+	// Atomically increments the counter with the given key and returns its new value. Counters
+	// start at zero and may be discarded after the given expiration time.
+	Increment(ctx context.Context, key string, expirationTime time.Time) (int, error)
+}
+
+func NewRateLimiter(backend RateLimitBackend, s *store.Store) (RateLimiter, error) {
+	switch backend {
+	case "", RateLimitBackendMemory:
+		return NewMemoryRateLimiter(), nil
+	case RateLimitBackendDynamoDB:
+		return &StoreRateLimiter{
+			store: s,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend: %s", backend)
+	}
+}
+
+type memoryRateLimitCounter struct {
+	count          int
+	expirationTime time.Time
+}
+
+type MemoryRateLimiter struct {
+	mutex         sync.Mutex
+	counters      map[string]*memoryRateLimitCounter
+	lastPruneTime time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		counters: make(map[string]*memoryRateLimitCounter),
+	}
+}
+
+func (l *MemoryRateLimiter) Increment(ctx context.Context, key string, expirationTime time.Time) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+
+	// Expired counters are discarded periodically to keep memory bounded.
+	if now.Sub(l.lastPruneTime) > time.Minute {
+		for k, counter := range l.counters {
+			if counter.expirationTime.Before(now) {
+				delete(l.counters, k)
+			}
+		}
+		l.lastPruneTime = now
+	}
+
+	counter, ok := l.counters[key]
+	if !ok || counter.expirationTime.Before(now) {
+		counter = &memoryRateLimitCounter{
+			expirationTime: expirationTime,
+		}
+		l.counters[key] = counter
+	}
+	counter.count++
+	return counter.count, nil
+}
+
+type StoreRateLimiter struct {
+	store *store.Store
+}
+
+func (l *StoreRateLimiter) Increment(ctx context.Context, key string, expirationTime time.Time) (int, error) {
+	return l.store.IncrementRateLimitCounter(ctx, key, expirationTime)
+}
+
+// Counts a request against the given limit using fixed windows. If the limit has been exceeded, a
+// RateLimitError is returned.
+func (s *Session) CheckRateLimit(ctx context.Context, key string, limit RateLimit) UserFacingError {
+	now := time.Now()
+	windowStart := now.Truncate(limit.Period)
+	windowEnd := windowStart.Add(limit.Period)
+
+	count, err := s.app.rateLimiter.Increment(ctx, key+":"+strconv.FormatInt(windowStart.Unix(), 10), windowEnd)
+	if err != nil {
+		// Rate limiting is best effort. We don't want a problem with the backend to take down the
+		// whole API.
+		s.logger.Error("unable to check rate limit: " + err.Error())
+		return nil
+	} else if count > limit.Limit {
+		return RateLimitError{
+			RetryAfter: windowEnd.Sub(now),
+		}
+	}
+	return nil
+}
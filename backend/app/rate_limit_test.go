@@ -0,0 +1,55 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+)
+
+func TestCheckRateLimit(t *testing.T) {
+	a := apptest.NewTestApp(t)
+	sess := a.NewAnonymousSession()
+
+	limit := app.RateLimit{
+		Limit:  3,
+		Period: time.Hour,
+	}
+
+	for i := 0; i < limit.Limit; i++ {
+		require.NoError(t, sess.CheckRateLimit(context.Background(), "foo", limit))
+	}
+
+	err := sess.CheckRateLimit(context.Background(), "foo", limit)
+	var rateLimitErr app.RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.True(t, rateLimitErr.RetryAfter > 0 && rateLimitErr.RetryAfter <= time.Hour)
+
+	// Other keys are counted separately.
+	assert.NoError(t, sess.CheckRateLimit(context.Background(), "bar", limit))
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	l := app.NewMemoryRateLimiter()
+
+	count, err := l.Increment(context.Background(), "foo", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = l.Increment(context.Background(), "foo", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Expired counters start over.
+	count, err = l.Increment(context.Background(), "bar", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = l.Increment(context.Background(), "bar", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,19 +14,61 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
 
 	"github.com/ccbrown/cloud-snitch/backend/model"
 	"github.com/ccbrown/cloud-snitch/backend/report"
+	"github.com/ccbrown/cloud-snitch/backend/store"
 )
 
-func (s *Session) GetReportsByTeamId(ctx context.Context, teamId model.Id) ([]*model.Report, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
+type GetReportsByTeamIdInput struct {
+	TeamId model.Id
+
+	// If given, only reports that start within this time range are returned.
+	StartTime time.Time
+	EndTime   time.Time
+
+	PageInput
+}
+
+// Gets a page of the team's reports, most recent first.
+func (s *Session) GetReportsByTeamId(ctx context.Context, input GetReportsByTeamIdInput) (*Page[*model.Report], UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, input.TeamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	} else if !input.StartTime.IsZero() && !input.EndTime.IsZero() && !input.EndTime.After(input.StartTime) {
+		return nil, NewUserError("The end time must be after the start time.")
+	}
+
+	pageInput, err := input.storePageInput()
+	if err != nil {
 		return nil, err
 	}
-	reports, err := s.app.store.GetReportsByTeamId(ctx, teamId)
-	return reports, s.SanitizedError(err)
+
+	// Later pages can't contain reports that the first page's reindexing missed.
+	if pageInput.Cursor == "" {
+		if _, err := s.app.reindexLegacyTeamReports(ctx, input.TeamId); err != nil {
+			return nil, s.SanitizedError(err)
+		}
+	}
+
+	page, storeErr := s.app.store.GetReportPageByTeamId(ctx, input.TeamId, store.GetReportPageByTeamIdInput{
+		PageInput:    pageInput,
+		MinStartTime: input.StartTime,
+		MaxStartTime: input.EndTime,
+	})
+	if storeErr != nil {
+		return nil, s.sanitizedPageError(storeErr)
+	}
+	return &Page[*model.Report]{
+		Items:      page.Items,
+		NextCursor: page.Cursor,
+	}, nil
 }
 
+// The longest time range a single report can cover. This bounds how far back we need to look for
+// reports that overlap a given time range.
+const maxReportDuration = 24 * time.Hour
+
 type QueueReportGenerationInput struct {
 	StartTime time.Time
 	Duration  time.Duration
@@ -70,6 +115,8 @@ type QueueTeamReportGenerationInput struct {
 func (s *Session) QueueTeamReportGeneration(ctx context.Context, input QueueTeamReportGenerationInput) UserFacingError {
 	if !s.HasUserRole(model.UserRoleAdministrator) {
 		return AuthorizationError{}
+	} else if input.Duration <= 0 || input.Duration > maxReportDuration {
+		return NewUserError("The duration must be positive and at most 24 hours.")
 	}
 	return s.SanitizedError(s.app.QueueTeamReportGeneration(ctx, input))
 }
@@ -104,6 +151,8 @@ type QueueAWSIntegrationReportGenerationInput struct {
 func (s *Session) QueueAWSIntegrationReportGeneration(ctx context.Context, input QueueAWSIntegrationReportGenerationInput) UserFacingError {
 	if !s.HasUserRole(model.UserRoleAdministrator) {
 		return AuthorizationError{}
+	} else if input.Duration <= 0 || input.Duration > maxReportDuration {
+		return NewUserError("The duration must be positive and at most 24 hours.")
 	}
 	integration, err := s.app.store.GetAWSIntegrationById(ctx, input.IntegrationId)
 	if err != nil {
@@ -135,10 +184,12 @@ type doReconAndQueueAWSIntegrationReportGenerationInput struct {
 func (a *App) doReconAndQueueAWSIntegrationReportGeneration(ctx context.Context, input doReconAndQueueAWSIntegrationReportGenerationInput) error {
 	creds, err := a.assumeAWSIntegrationRole(ctx, input.Integration)
 	if err != nil {
+		a.emitAWSIntegrationFailureWebhookEvent(ctx, input.Integration, "Unable to assume the integration's role.")
 		return fmt.Errorf("failed to assume role: %w", err)
 	}
 
 	accountRecon := map[string]PutAWSIntegrationReconAccountInput{}
+	var organizationsAccess *awsOrganizationsAccess
 
 	if input.Integration.GetAccountNamesFromOrganizations {
 		orgsClient, err := a.organizationsFactory.NewFromSTSCredentials(ctx, creds)
@@ -146,6 +197,17 @@ func (a *App) doReconAndQueueAWSIntegrationReportGeneration(ctx context.Context,
 			return fmt.Errorf("failed to create organizations client: %w", err)
 		}
 
+		if input.Integration.ManageSCPs || input.Integration.ManageRCPs {
+			// Older integrations may not have permission to describe the organization. In that case
+			// we assume the role is in the management account, which was the only supported
+			// configuration at the time.
+			if access, err := describeAWSOrganizationsAccess(ctx, orgsClient, input.Integration.RoleARN); err != nil {
+				zap.L().Warn("failed to describe aws organizations access", zap.String("integration_id", input.Integration.Id.String()), zap.Error(err))
+			} else {
+				organizationsAccess = access
+			}
+		}
+
 		var nextToken *string
 		for {
 			output, err := orgsClient.ListAccounts(ctx, &organizations.ListAccountsInput{
@@ -218,6 +280,16 @@ func (a *App) doReconAndQueueAWSIntegrationReportGeneration(ctx context.Context,
 		}
 
 		if !input.ReconOnly {
+			var jobs []*model.ReportGenerationJob
+			for _, messages := range messagesByQueueRegion {
+				for _, message := range messages {
+					jobs = append(jobs, newQueuedReportGenerationJob(input.Integration.TeamId, message.Message.GenerateAWSCloudTrailReport))
+				}
+			}
+			if err := a.store.PutReportGenerationJobs(ctx, jobs...); err != nil {
+				return fmt.Errorf("failed to put report generation jobs: %w", err)
+			}
+
 			if err := a.QueueMessages(ctx, messagesByQueueRegion); err != nil {
 				return fmt.Errorf("failed to queue messages: %w", err)
 			}
@@ -228,13 +300,19 @@ func (a *App) doReconAndQueueAWSIntegrationReportGeneration(ctx context.Context,
 	for _, accountRecon := range accountRecon {
 		accountRecons = append(accountRecons, accountRecon)
 	}
-	if err := a.PutAWSIntegrationRecon(ctx, PutAWSIntegrationReconInput{
+	reconInput := PutAWSIntegrationReconInput{
 		AWSIntegrationId: input.Integration.Id,
 		TeamId:           input.Integration.TeamId,
 		Time:             time.Now(),
 		Accounts:         accountRecons,
 		CanManageSCPs:    input.Integration.ManageSCPs,
-	}); err != nil {
+		CanManageRCPs:    input.Integration.ManageRCPs,
+	}
+	if organizationsAccess != nil {
+		reconInput.ManagementAccountId = organizationsAccess.ManagementAccountId
+		reconInput.IsDelegatedAdministrator = organizationsAccess.IsDelegatedAdministrator
+	}
+	if err := a.PutAWSIntegrationRecon(ctx, reconInput); err != nil {
 		return fmt.Errorf("failed to put aws integration recon: %w", err)
 	}
 
@@ -256,28 +334,69 @@ type GenerateAWSCloudTrailReportInput struct {
 	MaxSourceBytes    int64
 }
 
-// Synchronously generates and persists a report.
+// Synchronously generates and persists a report, keeping the report's generation job up to date.
 func (a *App) GenerateAWSCloudTrailReport(ctx context.Context, input GenerateAWSCloudTrailReportInput) (*model.Report, error) {
-	startTime := time.Now()
-
 	integration, err := a.store.GetAWSIntegrationById(ctx, input.AWSIntegrationId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get aws integration: %w", err)
 	}
 
+	job, err := a.store.GetReportGenerationJobById(ctx, input.FutureReportId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report generation job: %w", err)
+	} else if job == nil {
+		// Jobs are normally created when generation is queued, but they're just informational so
+		// we'll happily create them here if needed.
+		job = newQueuedReportGenerationJob(integration.TeamId, &input)
+	}
+
+	job.State = model.ReportGenerationJobStateScanning
+	job.ImportedObjectCount = 0
+	job.TotalObjectCount = 0
+	job.FailureReason = ""
+	if err := a.updateReportGenerationJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update report generation job: %w", err)
+	}
+
+	ret, failureReason, err := a.generateAWSCloudTrailReport(ctx, integration, job, input)
+	if err != nil {
+		job.State = model.ReportGenerationJobStateFailed
+		job.FailureReason = failureReason
+		if job.FailureReason == "" {
+			job.FailureReason = "An internal error occurred."
+		}
+	} else {
+		job.State = model.ReportGenerationJobStateDone
+		if ret != nil {
+			job.ReportId = ret.Id
+		}
+	}
+	if err := a.updateReportGenerationJob(ctx, job); err != nil {
+		zap.L().Error("failed to update report generation job", zap.String("job_id", job.Id.String()), zap.Error(err))
+	}
+
+	return ret, err
+}
+
+// Does the work for GenerateAWSCloudTrailReport. If an error is returned, a user-facing failure
+// reason may also be returned.
+func (a *App) generateAWSCloudTrailReport(ctx context.Context, integration *model.AWSIntegration, job *model.ReportGenerationJob, input GenerateAWSCloudTrailReportInput) (*model.Report, string, error) {
+	startTime := time.Now()
+
 	output, err := a.sts.AssumeRole(ctx, &sts.AssumeRoleInput{
 		RoleArn:         &integration.RoleARN,
 		RoleSessionName: aws.String("cloud_snitch"),
 		ExternalId:      aws.String(integration.TeamId.String()),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to assume role: %w", err)
+		a.emitAWSIntegrationFailureWebhookEvent(ctx, integration, "Unable to assume the integration's role.")
+		return nil, "Unable to assume the integration's role.", fmt.Errorf("failed to assume role: %w", err)
 	}
 	creds := output.Credentials
 
 	s3Client, err := a.s3Factory.NewFromSTSCredentials(ctx, creds, input.BucketRegion)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+		return nil, "", fmt.Errorf("failed to create s3 client: %w", err)
 	}
 
 	r := &report.Report{
@@ -292,17 +411,32 @@ func (a *App) GenerateAWSCloudTrailReport(ctx context.Context, input GenerateAWS
 		AccountId:      input.AccountId,
 		Region:         input.Region,
 		MaxSourceBytes: input.MaxSourceBytes,
+		Progress: func(imported, total int) {
+			if imported > 0 && imported < total && time.Since(job.UpdateTime) < reportGenerationJobProgressInterval {
+				return
+			}
+			job.State = model.ReportGenerationJobStateImporting
+			job.ImportedObjectCount = imported
+			job.TotalObjectCount = total
+			if err := a.updateReportGenerationJob(ctx, job); err != nil {
+				// Progress is just informational, so this isn't worth failing over.
+				zap.L().Warn("failed to update report generation job", zap.String("job_id", job.Id.String()), zap.Error(err))
+			}
+		},
 	}); err != nil {
-		return nil, fmt.Errorf("failed to import aws cloudtrail logs: %w", err)
+		a.emitAWSIntegrationFailureWebhookEvent(ctx, integration, "Unable to import CloudTrail logs.")
+		return nil, "Unable to import CloudTrail logs.", fmt.Errorf("failed to import aws cloudtrail logs: %w", err)
 	}
 
 	if r.IsEmpty() {
-		return nil, nil
+		return nil, "", nil
 	}
 
+	annotateReportAWSServices(r)
+
 	buf, err := jsoniter.Marshal(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal report: %w", err)
+		return nil, "", fmt.Errorf("failed to marshal report: %w", err)
 	}
 
 	key := "reports/" + input.FutureReportId.String() + ".json"
@@ -313,7 +447,7 @@ func (a *App) GenerateAWSCloudTrailReport(ctx context.Context, input GenerateAWS
 		Body:    bytes.NewReader(buf),
 		Tagging: aws.String("team_id=" + integration.TeamId.String() + "&retention=" + string(input.Retention)),
 	}); err != nil {
-		return nil, fmt.Errorf("failed to put report in s3: %w", err)
+		return nil, "", fmt.Errorf("failed to put report in s3: %w", err)
 	}
 
 	expirationTime := input.StartTime.Add(input.Duration + input.Retention.Duration())
@@ -323,7 +457,7 @@ func (a *App) GenerateAWSCloudTrailReport(ctx context.Context, input GenerateAWS
 		url := a.config.S3CDNURL + "/" + key
 		downloadURL, err = a.urlSigner.Sign(url, expirationTime.Add(5*time.Minute))
 		if err != nil {
-			return nil, fmt.Errorf("failed to sign url: %w", err)
+			return nil, "", fmt.Errorf("failed to sign url: %w", err)
 		}
 	}
 
@@ -354,7 +488,7 @@ func (a *App) GenerateAWSCloudTrailReport(ctx context.Context, input GenerateAWS
 	}
 
 	if err := a.store.PutReport(ctx, ret); err != nil {
-		return nil, fmt.Errorf("failed to put report in store: %w", err)
+		return nil, "", fmt.Errorf("failed to put report in store: %w", err)
 	}
 
 	if err := a.store.PutTeamBillableAccount(ctx, &model.TeamBillableAccount{
@@ -362,12 +496,232 @@ func (a *App) GenerateAWSCloudTrailReport(ctx context.Context, input GenerateAWS
 		TeamId:         integration.TeamId,
 		ExpirationTime: time.Now().Add(72 * time.Hour),
 	}); err != nil {
-		return nil, fmt.Errorf("failed to put team billable account: %w", err)
+		return nil, "", fmt.Errorf("failed to put team billable account: %w", err)
+	}
+
+	// The report was already stored, so alerting failures are logged rather than failing the job,
+	// which would cause it to be retried and the report to be generated again.
+	alerts, err := a.evaluateAlertRules(ctx, ret, r)
+	if err != nil {
+		zap.L().Error("failed to evaluate alert rules", zap.String("report_id", ret.Id.String()), zap.Error(err))
+	}
+
+	if err := a.queueSIEMForwarding(ctx, ret, r, alerts); err != nil {
+		// The report was already stored, so don't report this as a failure.
+		zap.L().Error("failed to queue siem forwarding", zap.String("report_id", ret.Id.String()), zap.Error(err))
+	}
+
+	if err := a.emitWebhookEvent(ctx, ret.TeamId, model.WebhookEventTypeReportGenerated, map[string]any{
+		"reportId":         ret.Id,
+		"awsIntegrationId": ret.AWSIntegrationId,
+		"accountId":        ret.Scope.AWS.AccountId,
+		"region":           ret.Scope.AWS.Region,
+		"startTime":        ret.Scope.StartTime.UTC(),
+		"durationSeconds":  int(ret.Scope.Duration.Seconds()),
+		"isIncomplete":     ret.IsIncomplete,
+	}); err != nil {
+		// The report was already stored, so don't report this as a failure.
+		zap.L().Error("failed to emit report generated webhook event", zap.String("report_id", ret.Id.String()), zap.Error(err))
+	}
+
+	return ret, "", nil
+}
+
+// Fetches and decodes the contents of a report from S3.
+func (a *App) loadReport(ctx context.Context, r *model.Report) (*report.Report, error) {
+	output, err := a.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &r.Location.S3Bucket,
+		Key:    &r.Location.Key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report from s3: %w", err)
+	} else if output.Body == nil {
+		return nil, fmt.Errorf("report not found in s3")
+	}
+	defer output.Body.Close()
+
+	var ret report.Report
+	if err := jsoniter.NewDecoder(output.Body).Decode(&ret); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	return &ret, nil
+}
+
+var singleByteRangeRegexp = regexp.MustCompile(`^bytes=(\d*)-(\d*)$`)
+
+type GetReportContentInput struct {
+	ReportId model.Id
+
+	// An HTTP Range header value. Only single byte ranges such as "bytes=0-1023" are supported.
+	// Anything else is ignored and the entire report is returned.
+	Range string
+}
+
+type ReportContent struct {
+	Body io.ReadCloser
+
+	// The length of the returned content, which may be less than the report's size if a range was
+	// requested. Zero if unknown.
+	ContentLength int64
+
+	// If only part of the report is returned, this is set to an HTTP Content-Range header value.
+	ContentRange string
+
+	// The quoted entity tag of the report, if known.
+	ETag string
+}
+
+// Fetches the raw JSON contents of a report. This allows reports to be downloaded when no CDN is
+// configured to serve them via DownloadURL. The caller must close the returned body.
+func (s *Session) GetReportContentById(ctx context.Context, input GetReportContentInput) (*ReportContent, UserFacingError) {
+	r, err := s.app.store.GetReportById(ctx, input.ReportId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if r == nil || !r.ExpirationTime.After(time.Now()) {
+		return nil, NotFoundError("Report not found.")
+	} else if err := s.RequirePermissionOrAPIKeyScope(ctx, r.TeamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	}
+
+	getObjectInput := &s3.GetObjectInput{
+		Bucket: &r.Location.S3Bucket,
+		Key:    &r.Location.Key,
+	}
+	if m := singleByteRangeRegexp.FindStringSubmatch(input.Range); m != nil && (m[1] != "" || m[2] != "") {
+		if m[1] != "" {
+			if start, err := strconv.ParseInt(m[1], 10, 64); err != nil || start >= int64(r.Size) {
+				return nil, NewUserError("The requested range is not satisfiable.")
+			}
+		}
+		getObjectInput.Range = &input.Range
+	}
+
+	output, err := s.app.s3.GetObject(ctx, getObjectInput)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("failed to get report from s3: %w", err))
+	} else if output.Body == nil {
+		return nil, NotFoundError("Report not found.")
+	}
+
+	return &ReportContent{
+		Body:          output.Body,
+		ContentLength: aws.ToInt64(output.ContentLength),
+		ContentRange:  aws.ToString(output.ContentRange),
+		ETag:          aws.ToString(output.ETag),
+	}, nil
+}
+
+// The maximum number of reports that will be merged into a single report. This bounds the amount of
+// work done for a single request.
+const maxMergedReports = 1000
+
+type loadMergedTeamReportInput struct {
+	TeamId    model.Id
+	StartTime time.Time
+	EndTime   time.Time
+
+	// If given, only reports for this account and region are merged.
+	AccountId string
+	Region    string
+}
+
+// Reports written by older versions are indexed by id rather than start time, so they can't be found
+// by time range. They're reindexed as they're encountered, and returned so that callers can include
+// them until the index catches up.
+func (a *App) reindexLegacyTeamReports(ctx context.Context, teamId model.Id) ([]*model.Report, error) {
+	reports, err := a.store.GetLegacyIndexedReportsByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legacy reports: %w", err)
+	}
+	for _, r := range reports {
+		if _, err := a.store.ReindexReport(ctx, r); err != nil {
+			return nil, fmt.Errorf("failed to reindex report %v: %w", r.Id, err)
+		}
+	}
+	return reports, nil
+}
+
+// Gets the team's reports that overlap the input's time range and match its filters.
+func (a *App) getTeamReportsInRange(ctx context.Context, input loadMergedTeamReportInput) ([]*model.Report, error) {
+	matches := func(r *model.Report) bool {
+		if !r.Scope.StartTime.Before(input.EndTime) || !r.Scope.StartTime.Add(r.Scope.Duration).After(input.StartTime) {
+			return false
+		} else if input.AccountId != "" && r.Scope.AWS.AccountId != input.AccountId {
+			return false
+		} else if input.Region != "" && r.Scope.AWS.Region != input.Region {
+			return false
+		}
+		return true
+	}
+
+	legacy, err := a.reindexLegacyTeamReports(ctx, input.TeamId)
+	if err != nil {
+		return nil, err
 	}
 
+	pageInput := store.GetReportPageByTeamIdInput{
+		PageInput: store.PageInput{
+			Limit: maxPageLimit,
+		},
+		// Reports that start any earlier can't overlap the range.
+		MinStartTime: input.StartTime.Add(-maxReportDuration),
+		MaxStartTime: input.EndTime,
+	}
+
+	var ret []*model.Report
+	seen := map[model.Id]struct{}{}
+	for {
+		page, err := a.store.GetReportPageByTeamId(ctx, input.TeamId, pageInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reports: %w", err)
+		}
+		for _, r := range page.Items {
+			if matches(r) {
+				ret = append(ret, r)
+				seen[r.Id] = struct{}{}
+			}
+		}
+		if page.Cursor == "" {
+			break
+		}
+		pageInput.Cursor = page.Cursor
+	}
+
+	for _, r := range legacy {
+		if _, ok := seen[r.Id]; !ok && matches(r) {
+			ret = append(ret, r)
+		}
+	}
 	return ret, nil
 }
 
+// Loads all of the team's reports that overlap the given time range and merges them into a single
+// report. The number of reports merged is returned alongside the merged report.
+func (a *App) loadMergedTeamReport(ctx context.Context, input loadMergedTeamReportInput) (*report.Report, int, error) {
+	reports, err := a.getTeamReportsInRange(ctx, input)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ret := &report.Report{}
+	count := 0
+
+	for _, r := range reports {
+		if count >= maxMergedReports {
+			ret.IsIncomplete = true
+			break
+		}
+		contents, err := a.loadReport(ctx, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		ret.Merge(contents)
+		count++
+	}
+
+	return ret, count, nil
+}
+
 func (s *Session) DeleteReportById(ctx context.Context, id model.Id) UserFacingError {
 	report, err := s.app.store.GetReportById(ctx, id)
 	if err != nil || report == nil {
@@ -378,6 +732,33 @@ func (s *Session) DeleteReportById(ctx context.Context, id model.Id) UserFacingE
 	if err := s.app.store.DeleteReportById(ctx, id); err != nil {
 		return s.SanitizedError(err)
 	}
+	s.recordAuditEvent(ctx, report.TeamId, model.AuditEventActionReportDelete, report.Id.String(), report, nil)
 	// TODO: should we also delete from S3?
 	return nil
 }
+
+// Reindexes all reports so that they can be found by time range. Reports written by older versions
+// are also reindexed as they're read, so this is only needed to finish the job up front. It's safe
+// to run at any time and more than once. Returns the number of reports updated.
+func (a *App) MigrateReportKeys(ctx context.Context) (int, error) {
+	teams, err := a.store.GetTeams(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	total := 0
+	for _, team := range teams {
+		reports, err := a.store.GetReportsByTeamId(ctx, team.Id)
+		if err != nil {
+			return total, fmt.Errorf("failed to get reports for team %v: %w", team.Id, err)
+		}
+		for _, r := range reports {
+			if ok, err := a.store.ReindexReport(ctx, r); err != nil {
+				return total, fmt.Errorf("failed to reindex report %v: %w", r.Id, err)
+			} else if ok {
+				total++
+			}
+		}
+	}
+	return total, nil
+}
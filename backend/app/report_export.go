@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+type ExportTeamReportsInput struct {
+	TeamId    model.Id
+	StartTime time.Time
+	EndTime   time.Time
+
+	// If given, only reports for this account and region are exported.
+	AccountId string
+	Region    string
+}
+
+// An export of one or more reports. Reports aren't loaded until the export is written, so that
+// large exports can be streamed without holding every report in memory.
+type ReportExport struct {
+	app     *App
+	reports []*model.Report
+}
+
+func (a *App) newReportExport(reports []*model.Report) *ReportExport {
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].Scope.StartTime.Equal(reports[j].Scope.StartTime) {
+			return reports[i].Scope.StartTime.Before(reports[j].Scope.StartTime)
+		}
+		return reports[i].Id < reports[j].Id
+	})
+	return &ReportExport{
+		app:     a,
+		reports: reports,
+	}
+}
+
+func (e *ReportExport) ReportCount() int {
+	return len(e.reports)
+}
+
+// Loads the reports one at a time and writes their rows, oldest report first. The writer is flushed
+// after each report.
+func (e *ReportExport) Write(ctx context.Context, w report.ExportWriter) error {
+	for _, r := range e.reports {
+		contents, err := e.app.loadReport(ctx, r)
+		if err != nil {
+			return err
+		}
+		if err := contents.ExportRows(report.ExportSource{
+			ReportId:  r.Id.String(),
+			AccountId: r.Scope.AWS.AccountId,
+			Region:    r.Scope.AWS.Region,
+		}, w.WriteRow); err != nil {
+			return err
+		} else if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Exports the team's reports without any authorization or limits. This is intended for
+// administrative tooling.
+func (a *App) ExportTeamReports(ctx context.Context, input ExportTeamReportsInput) (*ReportExport, error) {
+	reports, err := a.getTeamReportsInRange(ctx, loadMergedTeamReportInput{
+		TeamId:    input.TeamId,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		AccountId: input.AccountId,
+		Region:    input.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.newReportExport(reports), nil
+}
+
+// Exports the team's reports that overlap the given time range.
+func (s *Session) ExportTeamReports(ctx context.Context, input ExportTeamReportsInput) (*ReportExport, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, input.TeamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	}
+
+	if !input.EndTime.After(input.StartTime) {
+		return nil, NewUserError("The end time must be after the start time.")
+	} else if input.EndTime.Sub(input.StartTime) > maxReportQueryWindow {
+		return nil, NewUserError("The time range must not exceed 31 days.")
+	}
+
+	export, err := s.app.ExportTeamReports(ctx, input)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if len(export.reports) > maxMergedReports {
+		return nil, NewUserError("Too many reports match. Please narrow the time range.")
+	}
+	return export, nil
+}
+
+func (s *Session) ExportReportById(ctx context.Context, id model.Id) (*ReportExport, UserFacingError) {
+	r, err := s.app.store.GetReportById(ctx, id)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if r == nil || !r.ExpirationTime.After(time.Now()) {
+		return nil, NotFoundError("Report not found.")
+	} else if err := s.RequirePermissionOrAPIKeyScope(ctx, r.TeamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	}
+	return s.app.newReportExport([]*model.Report{r}), nil
+}
@@ -0,0 +1,75 @@
+package app_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+func TestExportTeamReports(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	var err error
+
+	generated := a.NewTestAWSCloudTrailReport(sess, team.Id)
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := sess.ExportTeamReports(context.Background(), app.ExportTeamReportsInput{
+			TeamId:    team.Id,
+			StartTime: time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC),
+		})
+		assert.Error(t, err)
+	})
+
+	export, err := sess.ExportTeamReports(context.Background(), app.ExportTeamReportsInput{
+		TeamId:    team.Id,
+		StartTime: time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, export.ReportCount())
+
+	var buf bytes.Buffer
+	w, err := report.NewExportWriter(&buf, report.ExportFormatNDJSON)
+	require.NoError(t, err)
+	require.NoError(t, export.Write(context.Background(), w))
+
+	eventCount := 0
+	var chrisIPAddressRow *report.ExportRow
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var row report.ExportRow
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		assert.Equal(t, generated.Id.String(), row.ReportId)
+		assert.Equal(t, "222222222222", row.AccountId)
+		switch row.Type {
+		case report.ExportRowTypeEvent:
+			eventCount += row.Count
+		case report.ExportRowTypeIPAddress:
+			if row.PrincipalARN == "arn:aws:iam::222222222222:user/chris" {
+				chrisIPAddressRow = &row
+			}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, 20, eventCount)
+	require.NotNil(t, chrisIPAddressRow)
+	assert.Equal(t, "123.12.3.4", chrisIPAddressRow.IPAddress)
+	assert.Equal(t, "CN", chrisIPAddressRow.CountryCode)
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// How long jobs are kept after their last update.
+const reportGenerationJobRetention = 24 * time.Hour
+
+// Import progress is persisted at most this often to limit writes.
+const reportGenerationJobProgressInterval = 2 * time.Second
+
+func newQueuedReportGenerationJob(teamId model.Id, input *GenerateAWSCloudTrailReportInput) *model.ReportGenerationJob {
+	now := time.Now()
+	return &model.ReportGenerationJob{
+		Id:               input.FutureReportId,
+		CreationTime:     now,
+		UpdateTime:       now,
+		ExpirationTime:   now.Add(reportGenerationJobRetention),
+		TeamId:           teamId,
+		AWSIntegrationId: input.AWSIntegrationId,
+		Scope: model.ReportScope{
+			StartTime: input.StartTime,
+			Duration:  input.Duration,
+			AWS: model.ReportScopeAWS{
+				AccountId: input.AccountId,
+				Region:    input.Region,
+			},
+		},
+		State: model.ReportGenerationJobStateQueued,
+	}
+}
+
+func (a *App) updateReportGenerationJob(ctx context.Context, job *model.ReportGenerationJob) error {
+	job.UpdateTime = time.Now()
+	job.ExpirationTime = job.UpdateTime.Add(reportGenerationJobRetention)
+	return a.store.PutReportGenerationJob(ctx, job)
+}
+
+// Gets the team's recent report generation jobs, ordered by report start time.
+func (s *Session) GetReportGenerationJobsByTeamId(ctx context.Context, teamId model.Id) ([]*model.ReportGenerationJob, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, teamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.app.store.GetReportGenerationJobsByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	now := time.Now()
+	jobs = slices.DeleteFunc(jobs, func(job *model.ReportGenerationJob) bool {
+		return !job.ExpirationTime.After(now)
+	})
+	slices.SortFunc(jobs, func(a, b *model.ReportGenerationJob) int {
+		if c := a.Scope.StartTime.Compare(b.Scope.StartTime); c != 0 {
+			return c
+		}
+		return a.CreationTime.Compare(b.CreationTime)
+	})
+	return jobs, nil
+}
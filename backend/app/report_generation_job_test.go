@@ -0,0 +1,44 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestReportGenerationJobs(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+
+	integration, err := sess.CreateAWSIntegration(context.Background(), app.CreateAWSIntegrationInput{
+		TeamId:  team.Id,
+		Name:    "My Integration",
+		RoleARN: "arn:aws:iam::123456789012:role/MyRole",
+		CloudTrailTrail: &app.CreateAWSIntegrationCloudTrailTrailInput{
+			S3BucketName: "aws-cloudtrail-logs",
+		},
+		QueueReportGeneration: true,
+	})
+	require.NoError(t, err)
+
+	jobs, err := sess.GetReportGenerationJobsByTeamId(context.Background(), team.Id)
+	require.NoError(t, err)
+	require.NotEmpty(t, jobs)
+	for _, job := range jobs {
+		assert.Equal(t, integration.Id, job.AWSIntegrationId)
+		assert.Equal(t, model.ReportGenerationJobStateQueued, job.State)
+	}
+
+	_, err = otherSess.GetReportGenerationJobsByTeamId(context.Background(), team.Id)
+	assert.Error(t, err)
+}
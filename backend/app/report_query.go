@@ -0,0 +1,316 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+// The longest time range that can be queried at once.
+const maxReportQueryWindow = 31 * 24 * time.Hour
+
+const (
+	defaultReportQueryLimit = 100
+	maxReportQueryLimit     = 1000
+)
+
+var reportQueryPrincipalTypes = []report.PrincipalType{
+	report.PrincipalTypeAWSAssumedRole,
+	report.PrincipalTypeAWSRole,
+	report.PrincipalTypeAWSIAMUser,
+	report.PrincipalTypeAWSService,
+	report.PrincipalTypeAWSAccount,
+	report.PrincipalTypeWebIdentityUser,
+}
+
+type ReportQueryInput struct {
+	TeamId    model.Id
+	StartTime time.Time
+	EndTime   time.Time
+
+	// If given, only reports for this account and region are included.
+	AccountId string
+	Region    string
+
+	// If given, only principals of this type are included.
+	PrincipalType *report.PrincipalType
+
+	// If given, only events from this source (e.g. "s3.amazonaws.com") are included, and principals
+	// without any such events are excluded.
+	EventSource string
+
+	// If true, only events that resulted in errors are included, and principals without any such
+	// events are excluded.
+	HasErrors bool
+
+	// Defaults to ReportQuerySortCount.
+	Sort model.ReportQuerySort
+
+	Offset int
+
+	// Defaults to defaultReportQueryLimit.
+	Limit int
+}
+
+// Validates the input, then loads the matching reports and applies the principal and event filters.
+// The returned report's principals are copies, so their events can be filtered freely.
+func (s *Session) loadReportQueryReport(ctx context.Context, input *ReportQueryInput) (*report.Report, int, UserFacingError) {
+	if err := s.RequirePermissionOrAPIKeyScope(ctx, input.TeamId, model.TeamPermissionReadReports, model.TeamAPIKeyScopeReadReports); err != nil {
+		return nil, 0, err
+	}
+
+	if !input.EndTime.After(input.StartTime) {
+		return nil, 0, NewUserError("The end time must be after the start time.")
+	} else if input.EndTime.Sub(input.StartTime) > maxReportQueryWindow {
+		return nil, 0, NewUserError("The time range must not exceed 31 days.")
+	} else if input.PrincipalType != nil && !slices.Contains(reportQueryPrincipalTypes, *input.PrincipalType) {
+		return nil, 0, NewUserError("Invalid principal type.")
+	} else if input.Offset < 0 {
+		return nil, 0, NewUserError("The offset must not be negative.")
+	} else if input.Limit < 0 || input.Limit > maxReportQueryLimit {
+		return nil, 0, NewUserError("The limit must be between 1 and 1000.")
+	}
+
+	switch input.Sort {
+	case "":
+		input.Sort = model.ReportQuerySortCount
+	case model.ReportQuerySortCount, model.ReportQuerySortName:
+	default:
+		return nil, 0, NewUserError("Invalid sort.")
+	}
+	if input.Limit == 0 {
+		input.Limit = defaultReportQueryLimit
+	}
+
+	merged, reportCount, err := s.app.loadMergedTeamReport(ctx, loadMergedTeamReportInput{
+		TeamId:    input.TeamId,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		AccountId: input.AccountId,
+		Region:    input.Region,
+	})
+	if err != nil {
+		return nil, 0, s.SanitizedError(err)
+	}
+
+	principals := make(map[string]*report.Principal, len(merged.Principals))
+	for key, principal := range merged.Principals {
+		if input.PrincipalType != nil && principal.Type != *input.PrincipalType {
+			continue
+		}
+
+		filtered := *principal
+		if input.EventSource != "" || input.HasErrors {
+			filtered.Events = make(map[string]*report.EventSummary)
+			for eventKey, event := range principal.Events {
+				if input.EventSource != "" && event.Source != input.EventSource {
+					continue
+				} else if input.HasErrors && len(event.ErrorCodes) == 0 {
+					continue
+				}
+				filtered.Events[eventKey] = event
+			}
+			if len(filtered.Events) == 0 {
+				continue
+			}
+		}
+		principals[key] = &filtered
+	}
+	merged.Principals = principals
+
+	return merged, reportCount, nil
+}
+
+func eventSummaryErrorCount(event *report.EventSummary) int {
+	count := 0
+	for _, n := range event.ErrorCodes {
+		count += n
+	}
+	return count
+}
+
+// Sorts the items by count (descending) or name (ascending), then applies the offset and limit.
+func paginateReportQueryItems[T any](input ReportQueryInput, items []T, count func(T) int, name func(T) string) []T {
+	sort.Slice(items, func(i, j int) bool {
+		if input.Sort == model.ReportQuerySortCount {
+			if a, b := count(items[i]), count(items[j]); a != b {
+				return a > b
+			}
+		}
+		return name(items[i]) < name(items[j])
+	})
+	start := min(input.Offset, len(items))
+	end := min(start+input.Limit, len(items))
+	return items[start:end]
+}
+
+func newReportQueryPage[T any](r *report.Report, reportCount int, input ReportQueryInput, items []T, count func(T) int, name func(T) string) *model.ReportQueryPage[T] {
+	return &model.ReportQueryPage[T]{
+		TotalCount:   len(items),
+		Items:        paginateReportQueryItems(input, items, count, name),
+		ReportCount:  reportCount,
+		IsIncomplete: r.IsIncomplete,
+	}
+}
+
+// Gets the principals that were active within the query's time range.
+func (s *Session) QueryReportPrincipals(ctx context.Context, input ReportQueryInput) (*model.ReportQueryPage[*model.ReportQueryPrincipal], UserFacingError) {
+	r, reportCount, err := s.loadReportQueryReport(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.ReportQueryPrincipal, 0, len(r.Principals))
+	for key, principal := range r.Principals {
+		item := &model.ReportQueryPrincipal{
+			Key:            key,
+			Name:           principalDisplayName(key, principal),
+			Type:           principal.Type,
+			ARN:            principal.ARN,
+			IPAddressCount: len(principal.IPAddresses),
+		}
+		for _, event := range principal.Events {
+			item.EventCount += event.Count
+			item.ErrorCount += eventSummaryErrorCount(event)
+		}
+		items = append(items, item)
+	}
+
+	return newReportQueryPage(r, reportCount, input, items,
+		func(p *model.ReportQueryPrincipal) int { return p.EventCount },
+		func(p *model.ReportQueryPrincipal) string { return p.Name },
+	), nil
+}
+
+// Gets the IP addresses used by the matching principals. Event counts are attributed to addresses
+// per principal, so they aren't narrowed by the event filters.
+func (s *Session) QueryReportIPAddresses(ctx context.Context, input ReportQueryInput) (*model.ReportQueryPage[*model.ReportQueryIPAddress], UserFacingError) {
+	r, reportCount, err := s.loadReportQueryReport(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := map[string]*model.ReportQueryIPAddress{}
+	for _, principal := range r.Principals {
+		for ip, count := range principal.IPAddresses {
+			item, ok := byAddress[ip]
+			if !ok {
+				item = &model.ReportQueryIPAddress{
+					IPAddress: ip,
+				}
+				if network := r.IPAddressNetworks[ip]; network != nil {
+					item.Network = *network
+				}
+				byAddress[ip] = item
+			}
+			item.EventCount += count
+			item.PrincipalCount++
+		}
+	}
+
+	items := make([]*model.ReportQueryIPAddress, 0, len(byAddress))
+	for _, item := range byAddress {
+		items = append(items, item)
+	}
+
+	return newReportQueryPage(r, reportCount, input, items,
+		func(ip *model.ReportQueryIPAddress) int { return ip.EventCount },
+		func(ip *model.ReportQueryIPAddress) string { return ip.IPAddress },
+	), nil
+}
+
+// Gets the networks used by the matching principals. Addresses with unknown networks are omitted.
+func (s *Session) QueryReportNetworks(ctx context.Context, input ReportQueryInput) (*model.ReportQueryPage[*model.ReportQueryNetwork], UserFacingError) {
+	r, reportCount, err := s.loadReportQueryReport(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	byNetwork := map[string]*model.ReportQueryNetwork{}
+	ipAddresses := map[string]map[string]struct{}{}
+	for _, principal := range r.Principals {
+		principalNetworks := map[string]struct{}{}
+		for ip, count := range principal.IPAddresses {
+			network := r.IPAddressNetworks[ip]
+			if network == nil {
+				continue
+			}
+			item, ok := byNetwork[*network]
+			if !ok {
+				item = &model.ReportQueryNetwork{
+					Network: *network,
+				}
+				if location := r.NetworkLocations[*network]; location != nil {
+					item.CountryCode = location.CountryCode
+					item.CountryName = location.CountryName
+					item.CityName = location.CityName
+				}
+				byNetwork[*network] = item
+				ipAddresses[*network] = map[string]struct{}{}
+			}
+			item.EventCount += count
+			ipAddresses[*network][ip] = struct{}{}
+			principalNetworks[*network] = struct{}{}
+		}
+		for network := range principalNetworks {
+			byNetwork[network].PrincipalCount++
+		}
+	}
+
+	items := make([]*model.ReportQueryNetwork, 0, len(byNetwork))
+	for network, item := range byNetwork {
+		item.IPAddressCount = len(ipAddresses[network])
+		items = append(items, item)
+	}
+
+	return newReportQueryPage(r, reportCount, input, items,
+		func(n *model.ReportQueryNetwork) int { return n.EventCount },
+		func(n *model.ReportQueryNetwork) string { return n.Network },
+	), nil
+}
+
+// Gets the events performed by the matching principals, aggregated by source and name.
+func (s *Session) QueryReportEvents(ctx context.Context, input ReportQueryInput) (*model.ReportQueryPage[*model.ReportQueryEvent], UserFacingError) {
+	r, reportCount, err := s.loadReportQueryReport(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*model.ReportQueryEvent{}
+	for _, principal := range r.Principals {
+		for _, event := range principal.Events {
+			key := event.Source + ":" + event.Name
+			item, ok := byKey[key]
+			if !ok {
+				item = &model.ReportQueryEvent{
+					Source: event.Source,
+					Name:   event.Name,
+				}
+				byKey[key] = item
+			}
+			item.EventCount += event.Count
+			item.PrincipalCount++
+			for code, count := range event.ErrorCodes {
+				if item.ErrorCodes == nil {
+					item.ErrorCodes = map[string]int{}
+				}
+				item.ErrorCodes[code] += count
+				item.ErrorCount += count
+			}
+		}
+	}
+
+	items := make([]*model.ReportQueryEvent, 0, len(byKey))
+	for _, item := range byKey {
+		items = append(items, item)
+	}
+
+	return newReportQueryPage(r, reportCount, input, items,
+		func(e *model.ReportQueryEvent) int { return e.EventCount },
+		func(e *model.ReportQueryEvent) string { return e.Source + ":" + e.Name },
+	), nil
+}
@@ -0,0 +1,135 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+func TestReportQueries(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	a.NewTestAWSCloudTrailReport(sess, team.Id)
+
+	input := app.ReportQueryInput{
+		TeamId:    team.Id,
+		StartTime: time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Principals", func(t *testing.T) {
+		page, err := sess.QueryReportPrincipals(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, page.ReportCount)
+		require.NotEmpty(t, page.Items)
+		assert.Len(t, page.Items, page.TotalCount)
+		for i := 1; i < len(page.Items); i++ {
+			assert.GreaterOrEqual(t, page.Items[i-1].EventCount, page.Items[i].EventCount)
+		}
+
+		iamUser := report.PrincipalTypeAWSIAMUser
+		input := input
+		input.PrincipalType = &iamUser
+		page, err = sess.QueryReportPrincipals(context.Background(), input)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, "arn:aws:iam::222222222222:user/chris", page.Items[0].ARN)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		input := input
+		input.Sort = model.ReportQuerySortName
+		all, err := sess.QueryReportPrincipals(context.Background(), input)
+		require.NoError(t, err)
+		require.Greater(t, all.TotalCount, 2)
+
+		input.Offset = 1
+		input.Limit = 2
+		page, err := sess.QueryReportPrincipals(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, all.TotalCount, page.TotalCount)
+		assert.Equal(t, all.Items[1:3], page.Items)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		input := input
+		input.EventSource = "kms.amazonaws.com"
+		events, err := sess.QueryReportEvents(context.Background(), input)
+		require.NoError(t, err)
+		require.Len(t, events.Items, 1)
+		assert.Equal(t, "Decrypt", events.Items[0].Name)
+
+		input = app.ReportQueryInput{
+			TeamId:    team.Id,
+			StartTime: input.StartTime,
+			EndTime:   input.EndTime,
+			HasErrors: true,
+		}
+		principals, err := sess.QueryReportPrincipals(context.Background(), input)
+		require.NoError(t, err)
+		assert.Empty(t, principals.Items)
+
+		input.HasErrors = false
+		input.AccountId = "333333333333"
+		principals, err = sess.QueryReportPrincipals(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, 0, principals.ReportCount)
+	})
+
+	t.Run("IPAddresses", func(t *testing.T) {
+		page, err := sess.QueryReportIPAddresses(context.Background(), input)
+		require.NoError(t, err)
+		var found bool
+		for _, ip := range page.Items {
+			if ip.IPAddress == "123.12.3.4" {
+				found = true
+				assert.Equal(t, 1, ip.PrincipalCount)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Networks", func(t *testing.T) {
+		page, err := sess.QueryReportNetworks(context.Background(), input)
+		require.NoError(t, err)
+		var countryCodes []string
+		for _, network := range page.Items {
+			countryCodes = append(countryCodes, network.CountryCode)
+		}
+		assert.Contains(t, countryCodes, "CN")
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		input := input
+		input.EndTime = input.StartTime.Add(60 * 24 * time.Hour)
+		_, err := sess.QueryReportPrincipals(context.Background(), input)
+		assert.Error(t, err)
+
+		input = app.ReportQueryInput{
+			TeamId:    team.Id,
+			StartTime: input.StartTime,
+			EndTime:   input.StartTime.Add(time.Hour),
+			Limit:     5000,
+		}
+		_, err = sess.QueryReportPrincipals(context.Background(), input)
+		assert.Error(t, err)
+	})
+
+	t.Run("NonMember", func(t *testing.T) {
+		_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+		_, err := otherSess.QueryReportPrincipals(context.Background(), input)
+		assert.Error(t, err)
+	})
+}
@@ -54,8 +54,35 @@ func TestGenerateAWSCloudTrailReport(t *testing.T) {
 		},
 	}, report.Scope)
 
-	reports, err := sess.GetReportsByTeamId(context.Background(), team.Id)
+	jobs, err := sess.GetReportGenerationJobsByTeamId(context.Background(), team.Id)
 	require.NoError(t, err)
-	require.Len(t, reports, 1)
-	assert.Equal(t, report.Id, reports[0].Id)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, report.Id, jobs[0].Id)
+	assert.Equal(t, model.ReportGenerationJobStateDone, jobs[0].State)
+	assert.Equal(t, report.Id, jobs[0].ReportId)
+	assert.NotZero(t, jobs[0].TotalObjectCount)
+	assert.Equal(t, jobs[0].TotalObjectCount, jobs[0].ImportedObjectCount)
+
+	page, err := sess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{
+		TeamId: team.Id,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, report.Id, page.Items[0].Id)
+	assert.Empty(t, page.NextCursor)
+
+	page, err = sess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{
+		TeamId:    team.Id,
+		StartTime: report.Scope.StartTime.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	_, err = sess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{
+		TeamId: team.Id,
+		PageInput: app.PageInput{
+			Cursor: "bogus",
+		},
+	})
+	assert.Error(t, err)
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -15,8 +16,64 @@ import (
 type Session struct {
 	user            *model.User
 	userAccessToken *model.UserAccessToken
-	app             *App
-	logger          *zap.Logger
+
+	// How the session's user authenticated. Teams that require a second factor check this.
+	authentication model.UserAuthentication
+
+	// Sessions authenticated with an API key have no user. They can only access the key's team,
+	// and only via operations permitted by the key's scopes.
+	teamAPIKey *model.TeamAPIKey
+
+	// The IP address the session's requests originate from, if known. This is recorded in audit
+	// events.
+	ipAddress string
+
+	// The user agent of the session's client, if known. This is recorded when access tokens are
+	// created.
+	userAgent string
+
+	// Teams loaded for authorization checks. Sessions are request-scoped, so this lets a request
+	// make any number of checks against a team while only loading it once.
+	teamCache *sessionTeamCache
+
+	app    *App
+	logger *zap.Logger
+}
+
+type sessionTeamCache struct {
+	mutex sync.Mutex
+	teams map[model.Id]*model.Team
+}
+
+// Gets a team for authorization checks, loading it at most once per session. Callers that return
+// the team to the user should load it from the store instead so that it's up to date.
+func (s *Session) getTeamForAuthorization(ctx context.Context, teamId model.Id) (*model.Team, error) {
+	if s.teamCache == nil {
+		return s.app.store.GetTeamById(ctx, teamId, store.ConsistencyEventual)
+	}
+
+	s.teamCache.mutex.Lock()
+	defer s.teamCache.mutex.Unlock()
+
+	if team, ok := s.teamCache.teams[teamId]; ok {
+		return team, nil
+	}
+	team, err := s.app.store.GetTeamById(ctx, teamId, store.ConsistencyEventual)
+	if err != nil {
+		return nil, err
+	}
+	s.teamCache.teams[teamId] = team
+	return team, nil
+}
+
+// Replaces the session's cached copy of the team after it's been modified.
+func (s *Session) updateTeamForAuthorization(team *model.Team) {
+	if s.teamCache == nil {
+		return
+	}
+	s.teamCache.mutex.Lock()
+	defer s.teamCache.mutex.Unlock()
+	s.teamCache.teams[team.Id] = team
 }
 
 func (s *Session) RequireUser() UserFacingError {
@@ -26,44 +83,88 @@ func (s *Session) RequireUser() UserFacingError {
 	return AuthorizationError{}
 }
 
-func (s *Session) RequireTeamMember(ctx context.Context, teamId model.Id) UserFacingError {
+// Returns the session user's role in the team. Global administrators are treated as team
+// administrators, even if they aren't members.
+func (s *Session) requireTeamMembershipRole(ctx context.Context, teamId model.Id) (model.TeamMembershipRole, UserFacingError) {
 	if err := s.RequireUser(); err != nil {
-		return err
+		return model.TeamMembershipRoleNone, err
 	} else if s.user.Role == model.UserRoleAdministrator {
 		// Just make sure the team exists.
-		if team, err := s.app.store.GetTeamById(ctx, teamId, store.ConsistencyEventual); err != nil {
-			return s.SanitizedError(err)
+		if team, err := s.getTeamForAuthorization(ctx, teamId); err != nil {
+			return model.TeamMembershipRoleNone, s.SanitizedError(err)
 		} else if team == nil {
-			return AuthorizationError{}
+			return model.TeamMembershipRoleNone, AuthorizationError{}
 		}
-		return nil
+		return model.TeamMembershipRoleAdministrator, nil
 	} else if membership, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, s.user.Id); err != nil {
-		return s.SanitizedError(err)
+		return model.TeamMembershipRoleNone, s.SanitizedError(err)
 	} else if membership == nil {
+		return model.TeamMembershipRoleNone, AuthorizationError{}
+	} else if err := s.requireTeamSecondFactorPolicy(ctx, teamId); err != nil {
+		return model.TeamMembershipRoleNone, err
+	} else {
+		return membership.Role, nil
+	}
+}
+
+// Requires the session's user to be a member of the team, regardless of their role.
+func (s *Session) RequireTeamMember(ctx context.Context, teamId model.Id) UserFacingError {
+	_, err := s.requireTeamMembershipRole(ctx, teamId)
+	return err
+}
+
+// Requires the session's user to have a role in the team that grants the given permission.
+func (s *Session) RequirePermission(ctx context.Context, teamId model.Id, permission model.TeamPermission) UserFacingError {
+	if role, err := s.requireTeamMembershipRole(ctx, teamId); err != nil {
+		return err
+	} else if !role.HasPermission(permission) {
 		return AuthorizationError{}
 	}
 	return nil
 }
 
 func (s *Session) RequireTeamAdministrator(ctx context.Context, teamId model.Id) UserFacingError {
-	if err := s.RequireUser(); err != nil {
-		return err
-	} else if s.user.Role == model.UserRoleAdministrator {
-		// Just make sure the team exists.
-		if team, err := s.app.store.GetTeamById(ctx, teamId, store.ConsistencyEventual); err != nil {
-			return s.SanitizedError(err)
-		} else if team == nil {
-			return AuthorizationError{}
-		}
+	return s.RequirePermission(ctx, teamId, model.TeamPermissionManageTeam)
+}
+
+// Members of teams that require a second factor can only access the team once they've added one
+// and signed in with it, or signed in with the team's identity provider.
+func (s *Session) requireTeamSecondFactorPolicy(ctx context.Context, teamId model.Id) UserFacingError {
+	if team, err := s.getTeamForAuthorization(ctx, teamId); err != nil {
+		return s.SanitizedError(err)
+	} else if team == nil || !team.RequireSecondFactor || s.authentication.SecondFactor || s.authentication.SSOTeamId == teamId {
 		return nil
-	} else if membership, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, s.user.Id); err != nil {
+	} else if ok, err := s.app.userHasSecondFactor(ctx, s.user); err != nil {
 		return s.SanitizedError(err)
-	} else if membership == nil || membership.Role != model.TeamMembershipRoleAdministrator {
+	} else if !ok {
+		return NewUserError("This team requires two-factor authentication. Please add an authenticator app or passkey to your account to continue.")
+	}
+	return NewUserError("This team requires two-factor authentication. Please sign in again with your authenticator app or passkey to continue.")
+}
+
+func (s *Session) requireTeamAPIKeyScope(teamId model.Id, scope model.TeamAPIKeyScope) UserFacingError {
+	if s.teamAPIKey == nil || s.teamAPIKey.TeamId != teamId || !s.teamAPIKey.HasScope(scope) {
 		return AuthorizationError{}
 	}
 	return nil
 }
 
+// Like RequirePermission, but also permits the team's API keys with the given scope.
+func (s *Session) RequirePermissionOrAPIKeyScope(ctx context.Context, teamId model.Id, permission model.TeamPermission, scope model.TeamAPIKeyScope) UserFacingError {
+	if s.teamAPIKey != nil {
+		return s.requireTeamAPIKeyScope(teamId, scope)
+	}
+	return s.RequirePermission(ctx, teamId, permission)
+}
+
+// Like RequireTeamAdministrator, but also permits the team's API keys with the given scope.
+func (s *Session) RequireTeamAdministratorOrAPIKeyScope(ctx context.Context, teamId model.Id, scope model.TeamAPIKeyScope) UserFacingError {
+	if s.teamAPIKey != nil {
+		return s.requireTeamAPIKeyScope(teamId, scope)
+	}
+	return s.RequireTeamAdministrator(ctx, teamId)
+}
+
 func (s *Session) HasUserRole(role model.UserRole) bool {
 	return s.user != nil && s.user.Role == role
 }
@@ -74,6 +175,9 @@ func (s *Session) HasUserId(userId model.Id) bool {
 
 func (a *App) NewAnonymousSession() *Session {
 	return &Session{
+		teamCache: &sessionTeamCache{
+			teams: map[model.Id]*model.Team{},
+		},
 		app:    a,
 		logger: zap.L(),
 	}
@@ -87,22 +191,54 @@ func (sess *Session) User() *model.User {
 	return sess.user
 }
 
+func (sess *Session) TeamAPIKey() *model.TeamAPIKey {
+	return sess.teamAPIKey
+}
+
 func (sess Session) WithLogFields(fields ...zap.Field) *Session {
 	sess.logger = sess.logger.With(fields...)
 	return &sess
 }
 
+func (sess Session) WithIPAddress(ipAddress string) *Session {
+	sess.ipAddress = ipAddress
+	return &sess
+}
+
+func (sess *Session) IPAddress() string {
+	return sess.ipAddress
+}
+
+func (sess Session) WithUserAgent(userAgent string) *Session {
+	sess.userAgent = userAgent
+	return &sess
+}
+
+// Returns the access token used to authenticate the session, if any.
+func (sess *Session) UserAccessToken() *model.UserAccessToken {
+	return sess.userAccessToken
+}
+
 // NewUserSession returns a context with an associated user, if the email and password are valid.
+// If the user has a TOTP authenticator, totpCode must be a code from it or a recovery code.
 // Otherwise, it returns nil.
-func (sess Session) WithUserCredentials(ctx context.Context, email, password string) (*Session, UserFacingError) {
+func (sess Session) WithUserCredentials(ctx context.Context, email, password, totpCode string) (*Session, UserFacingError) {
 	if users, err := sess.app.store.GetUsersByEmailAddress(ctx, email); len(users) == 0 || err != nil {
 		return nil, sess.SanitizedError(err)
 	} else if len(users) != 1 {
 		return nil, sess.SanitizedError(fmt.Errorf("expected 1 user, got %d for email: %s", len(users), email))
 	} else if !users[0].VerifyPassword(password, sess.app.config.PasswordEncryptionKey) {
 		return nil, nil
+	} else if !users[0].HasTOTP() {
+		sess.user = users[0]
+		return &sess, nil
+	} else if totpCode == "" {
+		return nil, SecondFactorRequiredError{}
+	} else if ok, err := sess.verifyUserTOTP(ctx, users[0].Id, totpCode); !ok || err != nil {
+		return nil, err
 	} else {
 		sess.user = users[0]
+		sess.authentication.SecondFactor = true
 		return &sess, nil
 	}
 }
@@ -111,13 +247,14 @@ func (sess Session) WithUserCredentials(ctx context.Context, email, password str
 // Otherwise, it returns nil.
 func (sess Session) WithUserAccessToken(ctx context.Context, token []byte) (*Session, UserFacingError) {
 	hash := model.TokenHash(token)
-	if accessToken, err := sess.app.store.GetUserAccessTokenByHash(ctx, hash); accessToken == nil || accessToken.ExpirationTime.Before(time.Now()) || err != nil {
+	if accessToken, err := sess.app.store.GetUserAccessTokenByHash(ctx, hash); accessToken == nil || isUserAccessTokenExpired(accessToken) || err != nil {
 		return nil, sess.SanitizedError(err)
 	} else if user, err := sess.app.store.GetUserById(ctx, accessToken.UserId, store.ConsistencyEventual); user == nil || err != nil {
 		return nil, sess.SanitizedError(err)
 	} else {
 		sess.user = user
-		sess.userAccessToken = accessToken
+		sess.userAccessToken = sess.touchUserAccessToken(ctx, accessToken)
+		sess.authentication = accessToken.Authentication
 		return &sess, nil
 	}
 }
@@ -132,22 +269,60 @@ func (sess Session) WithUserPasskey(ctx context.Context, sessionId model.Id, ass
 		return nil, sess.SanitizedError(err)
 	} else {
 		sess.user = user
+		sess.authentication.SecondFactor = true
 		return &sess, nil
 	}
 }
 
-// NewUserSession returns a context with an associated user, if passkey authentication can be
-// completed using the given data. Otherwise, it returns nil.
-func (sess Session) WithUserEmailAuthentication(ctx context.Context, token []byte) (*Session, UserFacingError) {
+// NewUserSession returns a context with an associated user, if the email authentication token is
+// valid. If the user has a TOTP authenticator, totpCode must be a code from it or a recovery code.
+// Otherwise, it returns nil.
+func (sess Session) WithUserEmailAuthentication(ctx context.Context, token []byte, totpCode string) (*Session, UserFacingError) {
 	hash := model.TokenHash(token)
-	if accessToken, err := sess.app.store.GetUserEmailAuthenticationTokenByHash(ctx, hash); accessToken == nil || accessToken.ExpirationTime.Before(time.Now()) || err != nil {
+	accessToken, err := sess.app.store.GetUserEmailAuthenticationTokenByHash(ctx, hash)
+	if accessToken == nil || accessToken.ExpirationTime.Before(time.Now()) || err != nil {
 		return nil, sess.SanitizedError(err)
-	} else if user, err := sess.app.store.GetUserById(ctx, accessToken.UserId, store.ConsistencyEventual); user == nil || err != nil {
+	}
+
+	user, err := sess.app.store.GetUserById(ctx, accessToken.UserId, store.ConsistencyEventual)
+	if user == nil || err != nil {
 		return nil, sess.SanitizedError(err)
-	} else if err := sess.app.store.DeleteUserEmailAuthenticationTokenByHash(ctx, hash); err != nil {
+	}
+
+	// The token isn't consumed until the second factor is verified, so the same link can be used
+	// again along with a code. Tokens for sign-in with a team's identity provider don't need one
+	// since the identity provider is responsible for it.
+	if accessToken.SSOTeamId != "" {
+		sess.authentication.SSOTeamId = accessToken.SSOTeamId
+	} else if user.HasTOTP() {
+		if totpCode == "" {
+			return nil, SecondFactorRequiredError{}
+		} else if ok, err := sess.verifyUserTOTP(ctx, user.Id, totpCode); !ok || err != nil {
+			return nil, err
+		}
+		sess.authentication.SecondFactor = true
+	}
+
+	if err := sess.app.store.DeleteUserEmailAuthenticationTokenByHash(ctx, hash); err != nil {
 		return nil, sess.SanitizedError(err)
+	}
+	sess.user = user
+	return &sess, nil
+}
+
+// NewUserSession returns a context with an associated user, if sign-in with a team's identity
+// provider can be completed using the given data. Otherwise, it returns nil. The identity provider
+// is responsible for any second factor, so the user's authenticator app isn't required. Teams that
+// require a second factor accept sign-in with their own identity provider, but not others'.
+func (sess Session) WithUserOIDCAuthentication(ctx context.Context, state, code string) (*Session, UserFacingError) {
+	if output, err := sess.CompleteTeamOIDCAuthentication(ctx, CompleteTeamOIDCAuthenticationInput{
+		State: state,
+		Code:  code,
+	}); output == nil {
+		return nil, err
 	} else {
-		sess.user = user
+		sess.user = output.User
+		sess.authentication.SSOTeamId = output.TeamId
 		return &sess, nil
 	}
 }
@@ -0,0 +1,408 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+const (
+	maxSIEMSinksPerTeam = 5
+
+	// Events are sent to sinks in batches of up to this many.
+	maxSIEMEventBatchSize = 100
+
+	// Forwarding is retried with increasing delays until this many attempts have been made.
+	maxSIEMForwardingAttempts = 5
+
+	siemDialTimeout = 10 * time.Second
+)
+
+type CreateSIEMSinkInput struct {
+	TeamId  model.Id
+	Name    string
+	Type    model.SIEMSinkType
+	Address string
+	UseTLS  bool
+	Token   string
+}
+
+// Omits the sink's token from audit events.
+func siemSinkAuditValue(sink *model.SIEMSink) map[string]any {
+	return map[string]any{
+		"Name":    sink.Name,
+		"Type":    sink.Type,
+		"Address": sink.Address,
+		"UseTLS":  sink.UseTLS,
+	}
+}
+
+func (s *Session) CreateSIEMSink(ctx context.Context, input CreateSIEMSinkInput) (*model.SIEMSink, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateName(input.Name); err != nil {
+		return nil, err
+	}
+
+	sink := &model.SIEMSink{
+		Id:           model.NewSIEMSinkId(),
+		TeamId:       input.TeamId,
+		CreationTime: time.Now(),
+		Name:         input.Name,
+		Type:         input.Type,
+		Address:      input.Address,
+	}
+
+	switch input.Type {
+	case model.SIEMSinkTypeSyslog:
+		host, port, err := net.SplitHostPort(input.Address)
+		if err != nil || host == "" || port == "" {
+			return nil, NewUserError("Syslog addresses must be in the form \"host:port\".")
+		} else if err := s.app.validateSIEMSinkHost(host); err != nil {
+			return nil, err
+		}
+		sink.UseTLS = input.UseTLS
+	case model.SIEMSinkTypeHEC:
+		u, err := url.Parse(input.Address)
+		if err != nil || u.Host == "" {
+			return nil, NewUserError("Invalid URL.")
+		} else if u.Scheme != "https" && (u.Scheme != "http" || !s.app.config.AllowInsecureSIEMSinks) {
+			return nil, NewUserError("HTTP event collector URLs must use HTTPS.")
+		} else if err := s.app.validateSIEMSinkHost(u.Hostname()); err != nil {
+			return nil, err
+		}
+		if input.Token == "" {
+			return nil, NewUserError("A token is required.")
+		} else if len(input.Token) > 200 {
+			return nil, NewUserError("Tokens are limited to 200 characters.")
+		}
+		sink.EncryptedToken = model.EncryptSecret([]byte(input.Token), s.app.config.PasswordEncryptionKey)
+	default:
+		return nil, NewUserError("Invalid sink type.")
+	}
+
+	existing, err := s.app.store.GetSIEMSinksByTeamId(ctx, input.TeamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if len(existing) >= maxSIEMSinksPerTeam {
+		return nil, NewUserError(fmt.Sprintf("Teams are limited to %d SIEM sinks.", maxSIEMSinksPerTeam))
+	}
+
+	if err := s.app.store.PutSIEMSink(ctx, sink); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, sink.TeamId, model.AuditEventActionSIEMSinkCreate, sink.Id.String(), nil, siemSinkAuditValue(sink))
+	return sink, nil
+}
+
+// Hostnames are checked when they're resolved at delivery time, but we can reject obviously bad
+// addresses up front.
+func (a *App) validateSIEMSinkHost(host string) UserFacingError {
+	if ip, err := netip.ParseAddr(host); err == nil && !isPublicIPAddress(ip) && !a.config.AllowInsecureSIEMSinks {
+		return NewUserError("SIEM sinks must use public addresses.")
+	}
+	return nil
+}
+
+func (s *Session) GetSIEMSinksByTeamId(ctx context.Context, teamId model.Id) ([]*model.SIEMSink, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return nil, err
+	}
+	sinks, err := s.app.store.GetSIEMSinksByTeamId(ctx, teamId)
+	return sinks, s.SanitizedError(err)
+}
+
+func (s *Session) DeleteSIEMSinkById(ctx context.Context, id model.Id) UserFacingError {
+	sink, err := s.app.store.GetSIEMSinkById(ctx, id)
+	if err != nil || sink == nil {
+		return s.SanitizedError(err)
+	} else if err := s.RequireTeamAdministrator(ctx, sink.TeamId); err != nil {
+		return err
+	} else if err := s.app.store.DeleteSIEMSinkById(ctx, id); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, sink.TeamId, model.AuditEventActionSIEMSinkDelete, sink.Id.String(), siemSinkAuditValue(sink), nil)
+	return nil
+}
+
+type SIEMEventType string
+
+const (
+	SIEMEventTypeReportSummary SIEMEventType = "report_summary"
+	SIEMEventTypeFinding       SIEMEventType = "finding"
+)
+
+type SIEMEvent struct {
+	Type   SIEMEventType  `json:"type"`
+	Time   time.Time      `json:"time"`
+	TeamId model.Id       `json:"teamId"`
+	Data   map[string]any `json:"data"`
+}
+
+// Builds the events describing a newly generated report: a summary of the report followed by one
+// finding for each alert it triggered.
+func siemEventsForReport(reportModel *model.Report, r *report.Report, alerts []*model.Alert) []SIEMEvent {
+	eventCount, errorCount := 0, 0
+	for _, principal := range r.Principals {
+		for _, event := range principal.Events {
+			eventCount += event.Count
+			for _, count := range event.ErrorCodes {
+				errorCount += count
+			}
+		}
+	}
+
+	var countryCodes []string
+	for code := range reportCountryNames(r) {
+		countryCodes = append(countryCodes, code)
+	}
+	slices.Sort(countryCodes)
+
+	now := time.Now().UTC()
+	ret := []SIEMEvent{
+		{
+			Type:   SIEMEventTypeReportSummary,
+			Time:   now,
+			TeamId: reportModel.TeamId,
+			Data: map[string]any{
+				"reportId":         reportModel.Id,
+				"awsIntegrationId": reportModel.AWSIntegrationId,
+				"accountId":        reportModel.Scope.AWS.AccountId,
+				"region":           reportModel.Scope.AWS.Region,
+				"startTime":        reportModel.Scope.StartTime.UTC(),
+				"durationSeconds":  int(reportModel.Scope.Duration.Seconds()),
+				"isIncomplete":     reportModel.IsIncomplete,
+				"principalCount":   len(r.Principals),
+				"eventCount":       eventCount,
+				"errorCount":       errorCount,
+				"countryCodes":     countryCodes,
+			},
+		},
+	}
+
+	for _, alert := range alerts {
+		ret = append(ret, SIEMEvent{
+			Type:   SIEMEventTypeFinding,
+			Time:   now,
+			TeamId: reportModel.TeamId,
+			Data: map[string]any{
+				"reportId":     reportModel.Id,
+				"accountId":    reportModel.Scope.AWS.AccountId,
+				"region":       reportModel.Scope.AWS.Region,
+				"alertId":      alert.Id,
+				"alertRuleId":  alert.AlertRuleId,
+				"principalKey": alert.PrincipalKey,
+				"message":      alert.Message,
+			},
+		})
+	}
+
+	return ret
+}
+
+// Queues forwarding of a newly generated report's summary and findings to each of the team's SIEM
+// sinks.
+func (a *App) queueSIEMForwarding(ctx context.Context, reportModel *model.Report, r *report.Report, alerts []*model.Alert) error {
+	sinks, err := a.store.GetSIEMSinksByTeamId(ctx, reportModel.TeamId)
+	if err != nil {
+		return fmt.Errorf("failed to get siem sinks: %w", err)
+	} else if len(sinks) == 0 {
+		return nil
+	}
+
+	events := siemEventsForReport(reportModel, r, alerts)
+
+	var msgs []OutgoingQueueMessage
+	for _, sink := range sinks {
+		for batchStart := 0; batchStart < len(events); batchStart += maxSIEMEventBatchSize {
+			msgs = append(msgs, OutgoingQueueMessage{
+				Message: QueueMessage{
+					ForwardToSIEMSink: &ForwardToSIEMSinkInput{
+						SIEMSinkId: sink.Id,
+						Events:     events[batchStart:min(batchStart+maxSIEMEventBatchSize, len(events))],
+						Attempt:    1,
+					},
+				},
+			})
+		}
+	}
+
+	if err := a.QueueMessages(ctx, map[string][]OutgoingQueueMessage{
+		a.awsRegion: msgs,
+	}); err != nil {
+		return fmt.Errorf("failed to queue siem forwarding: %w", err)
+	}
+	return nil
+}
+
+// Syslog severities as defined by RFC 5424.
+const (
+	syslogSeverityWarning       = 4
+	syslogSeverityInformational = 6
+)
+
+// The "log audit" facility.
+const syslogFacility = 13
+
+// Formats an event as an RFC 5424 message with RFC 6587 octet-counting framing.
+func formatSyslogMessage(event SIEMEvent) ([]byte, error) {
+	severity := syslogSeverityInformational
+	if event.Type == SIEMEventTypeFinding {
+		severity = syslogSeverityWarning
+	}
+	body, err := jsoniter.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("<%d>1 %s cloud-snitch cloud-snitch - %s - %s", syslogFacility*8+severity, event.Time.UTC().Format(time.RFC3339Nano), event.Type, body)
+	return []byte(fmt.Sprintf("%d %s", len(msg), msg)), nil
+}
+
+func (a *App) sendSyslogEvents(ctx context.Context, sink *model.SIEMSink, events []SIEMEvent) error {
+	var buf bytes.Buffer
+	for _, event := range events {
+		msg, err := formatSyslogMessage(event)
+		if err != nil {
+			return fmt.Errorf("failed to format syslog message: %w", err)
+		}
+		buf.Write(msg)
+	}
+
+	dialer := newPublicDialer(siemDialTimeout)
+	if a.config.AllowInsecureSIEMSinks {
+		dialer = &net.Dialer{
+			Timeout: siemDialTimeout,
+		}
+	}
+	var conn net.Conn
+	var err error
+	if sink.UseTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", sink.Address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", sink.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(siemDialTimeout)); err != nil {
+		return fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+type hecEvent struct {
+	Time       int64     `json:"time"`
+	Source     string    `json:"source"`
+	SourceType string    `json:"sourcetype"`
+	Event      SIEMEvent `json:"event"`
+}
+
+func (a *App) sendHECEvents(ctx context.Context, sink *model.SIEMSink, events []SIEMEvent) error {
+	// HEC accepts multiple events as concatenated JSON objects.
+	var buf bytes.Buffer
+	for _, event := range events {
+		b, err := jsoniter.Marshal(hecEvent{
+			Time:       event.Time.Unix(),
+			Source:     "cloud-snitch",
+			SourceType: "cloudsnitch:" + string(event.Type),
+			Event:      event,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		buf.Write(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.Address, &buf)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	token := model.DecryptSecret(sink.EncryptedToken, a.config.PasswordEncryptionKey)
+	req.Header.Set("Authorization", "Splunk "+string(token))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.siemHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type ForwardToSIEMSinkInput struct {
+	SIEMSinkId model.Id
+	Events     []SIEMEvent
+	Attempt    int
+}
+
+// Sends a batch of events to a SIEM sink. Failures are re-queued with a delay until
+// maxSIEMForwardingAttempts is reached, after which the events are dropped.
+func (a *App) ForwardToSIEMSink(ctx context.Context, input ForwardToSIEMSinkInput) error {
+	sink, err := a.store.GetSIEMSinkById(ctx, input.SIEMSinkId)
+	if err != nil {
+		return fmt.Errorf("failed to get siem sink: %w", err)
+	} else if sink == nil {
+		// The sink was deleted.
+		return nil
+	}
+
+	switch sink.Type {
+	case model.SIEMSinkTypeSyslog:
+		err = a.sendSyslogEvents(ctx, sink, input.Events)
+	case model.SIEMSinkTypeHEC:
+		err = a.sendHECEvents(ctx, sink, input.Events)
+	default:
+		return fmt.Errorf("unexpected siem sink type: %v", sink.Type)
+	}
+	if err == nil {
+		return nil
+	}
+
+	logger := zap.L().With(zap.String("siem_sink_id", sink.Id.String()), zap.Int("attempt", input.Attempt), zap.Error(err))
+	if input.Attempt >= maxSIEMForwardingAttempts {
+		logger.Warn("giving up on siem forwarding")
+		return nil
+	}
+	logger.Info("siem forwarding failed")
+
+	retry := input
+	retry.Attempt++
+	if err := a.QueueMessages(ctx, map[string][]OutgoingQueueMessage{
+		a.awsRegion: {
+			{
+				Message: QueueMessage{
+					ForwardToSIEMSink: &retry,
+				},
+				Delay: min(time.Duration(input.Attempt*input.Attempt)*time.Minute, MaxQueueDelay),
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to queue siem forwarding retry: %w", err)
+	}
+	return nil
+}
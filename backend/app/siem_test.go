@@ -0,0 +1,195 @@
+package app_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+// Reads a single octet-counted syslog message from the reader.
+func readSyslogMessage(r *bufio.Reader) (string, error) {
+	lengthString, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(lengthString))
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func TestSIEMForwarding(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	var err error
+
+	_, err = sess.CreateAlertRule(context.Background(), app.CreateAlertRuleInput{
+		TeamId:              team.Id,
+		Name:                "Outside the US",
+		Type:                model.AlertRuleTypeUnexpectedCountry,
+		AllowedCountryCodes: []string{"US"},
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	hecRequests := make(chan *http.Request, 10)
+	hecBodies := make(chan string, 10)
+	hecServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hecRequests <- r
+		hecBodies <- string(body)
+	}))
+	defer hecServer.Close()
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := sess.CreateSIEMSink(context.Background(), app.CreateSIEMSinkInput{
+			TeamId:  team.Id,
+			Name:    "Syslog",
+			Type:    model.SIEMSinkTypeSyslog,
+			Address: "no-port",
+		})
+		assert.Error(t, err)
+
+		_, err = sess.CreateSIEMSink(context.Background(), app.CreateSIEMSinkInput{
+			TeamId:  team.Id,
+			Name:    "HEC",
+			Type:    model.SIEMSinkTypeHEC,
+			Address: hecServer.URL,
+		})
+		assert.Error(t, err)
+	})
+
+	_, err = sess.CreateSIEMSink(context.Background(), app.CreateSIEMSinkInput{
+		TeamId:  team.Id,
+		Name:    "Syslog",
+		Type:    model.SIEMSinkTypeSyslog,
+		Address: listener.Addr().String(),
+	})
+	require.NoError(t, err)
+
+	_, err = sess.CreateSIEMSink(context.Background(), app.CreateSIEMSinkInput{
+		TeamId:  team.Id,
+		Name:    "HEC",
+		Type:    model.SIEMSinkTypeHEC,
+		Address: hecServer.URL,
+		Token:   "my-hec-token",
+	})
+	require.NoError(t, err)
+
+	sinks, err := sess.GetSIEMSinksByTeamId(context.Background(), team.Id)
+	require.NoError(t, err)
+	assert.Len(t, sinks, 2)
+
+	ignoreSQSRequests := len(a.SQSRequests("us-east-1"))
+	report := a.NewTestAWSCloudTrailReport(sess, team.Id)
+
+	var messages []app.QueueMessage
+	for _, message := range a.SQSMessages("us-east-1", ignoreSQSRequests) {
+		if message.ForwardToSIEMSink != nil {
+			messages = append(messages, message)
+		}
+	}
+	require.Len(t, messages, 2)
+
+	syslogMessages := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := readSyslogMessage(r)
+			if err != nil {
+				close(syslogMessages)
+				return
+			}
+			syslogMessages <- msg
+		}
+	}()
+
+	for _, message := range messages {
+		require.NoError(t, a.HandleQueueMessage(context.Background(), message, app.QueueMessageAttributes{}))
+	}
+
+	t.Run("Syslog", func(t *testing.T) {
+		summary := <-syslogMessages
+		assert.True(t, strings.HasPrefix(summary, "<110>1 "))
+		assert.Contains(t, summary, " report_summary - ")
+		assert.Contains(t, summary, report.Id.String())
+
+		finding := <-syslogMessages
+		assert.True(t, strings.HasPrefix(finding, "<108>1 "))
+		assert.Contains(t, finding, "made calls from China")
+	})
+
+	t.Run("HEC", func(t *testing.T) {
+		request := <-hecRequests
+		assert.Equal(t, "Splunk my-hec-token", request.Header.Get("Authorization"))
+
+		decoder := jsoniter.NewDecoder(strings.NewReader(<-hecBodies))
+		var sourceTypes []string
+		for decoder.More() {
+			var event struct {
+				SourceType string        `json:"sourcetype"`
+				Event      app.SIEMEvent `json:"event"`
+			}
+			require.NoError(t, decoder.Decode(&event))
+			assert.Equal(t, team.Id, event.Event.TeamId)
+			sourceTypes = append(sourceTypes, event.SourceType)
+		}
+		assert.Equal(t, []string{"cloudsnitch:report_summary", "cloudsnitch:finding"}, sourceTypes)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		listener.Close()
+
+		sinkTypes := map[model.Id]model.SIEMSinkType{}
+		for _, sink := range sinks {
+			sinkTypes[sink.Id] = sink.Type
+		}
+
+		var syslogMessage app.QueueMessage
+		for _, message := range messages {
+			if sinkTypes[message.ForwardToSIEMSink.SIEMSinkId] == model.SIEMSinkTypeSyslog {
+				syslogMessage = message
+			}
+		}
+		require.NotNil(t, syslogMessage.ForwardToSIEMSink)
+
+		ignoreSQSRequests := len(a.SQSRequests("us-east-1"))
+		require.NoError(t, a.HandleQueueMessage(context.Background(), syslogMessage, app.QueueMessageAttributes{}))
+
+		retries := a.SQSMessages("us-east-1", ignoreSQSRequests)
+		require.Len(t, retries, 1)
+		require.NotNil(t, retries[0].ForwardToSIEMSink)
+		assert.Equal(t, 2, retries[0].ForwardToSIEMSink.Attempt)
+	})
+}
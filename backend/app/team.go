@@ -40,11 +40,14 @@ func (s *Session) CreateTeam(ctx context.Context, input CreateTeamInput) (*model
 		return nil, s.SanitizedError(err)
 	}
 
+	s.recordAuditEvent(ctx, team.Id, model.AuditEventActionTeamCreate, team.Id.String(), nil, team)
+
 	return team, nil
 }
 
 type TeamPatch struct {
-	Name *string
+	Name                *string
+	RequireSecondFactor *bool
 }
 
 func (s *Session) PatchTeamById(ctx context.Context, teamId model.Id, patch TeamPatch) (*model.Team, UserFacingError) {
@@ -53,7 +56,8 @@ func (s *Session) PatchTeamById(ctx context.Context, teamId model.Id, patch Team
 	}
 
 	storePatch := &store.TeamPatch{
-		Name: patch.Name,
+		Name:                patch.Name,
+		RequireSecondFactor: patch.RequireSecondFactor,
 	}
 
 	if patch.Name != nil {
@@ -62,8 +66,31 @@ func (s *Session) PatchTeamById(ctx context.Context, teamId model.Id, patch Team
 		}
 	}
 
+	if patch.RequireSecondFactor != nil && *patch.RequireSecondFactor && !s.HasUserRole(model.UserRoleAdministrator) && !s.authentication.SecondFactor {
+		// Don't let administrators lock themselves out.
+		if ok, err := s.app.userHasSecondFactor(ctx, s.user); err != nil {
+			return nil, s.SanitizedError(err)
+		} else if !ok {
+			return nil, NewUserError("Please add an authenticator app or passkey to your own account before requiring one for the team.")
+		}
+		return nil, NewUserError("Please sign in again with your authenticator app or passkey before requiring one for the team.")
+	}
+
+	before, err := s.app.store.GetTeamById(ctx, teamId, store.ConsistencyStrongInRegion)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
 	team, err := s.app.store.PatchTeamById(ctx, teamId, storePatch)
-	return team, s.SanitizedError(err)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if team != nil {
+		s.updateTeamForAuthorization(team)
+	}
+
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamUpdate, teamId.String(), before, team)
+
+	return team, nil
 }
 
 func (s *Session) GetTeamById(ctx context.Context, teamId model.Id) (*model.Team, UserFacingError) {
@@ -74,12 +101,24 @@ func (s *Session) GetTeamById(ctx context.Context, teamId model.Id) (*model.Team
 	return team, s.SanitizedError(err)
 }
 
-func (s *Session) GetTeams(ctx context.Context) ([]*model.Team, UserFacingError) {
+func (s *Session) GetTeams(ctx context.Context, input PageInput) (*Page[*model.Team], UserFacingError) {
 	if !s.HasUserRole(model.UserRoleAdministrator) {
 		return nil, AuthorizationError{}
 	}
-	teams, err := s.app.store.GetTeams(ctx)
-	return teams, s.SanitizedError(err)
+
+	pageInput, err := input.storePageInput()
+	if err != nil {
+		return nil, err
+	}
+
+	page, storeErr := s.app.store.GetTeamPage(ctx, pageInput)
+	if storeErr != nil {
+		return nil, s.sanitizedPageError(storeErr)
+	}
+	return &Page[*model.Team]{
+		Items:      page.Items,
+		NextCursor: page.Cursor,
+	}, nil
 }
 
 type InviteToTeamInput struct {
@@ -93,6 +132,8 @@ func (s *Session) InviteToTeam(ctx context.Context, input InviteToTeamInput) Use
 		return err
 	} else if err := ValidateEmailAddress(input.EmailAddress); err != nil {
 		return err
+	} else if !input.Role.IsValid() {
+		return NewUserError("Invalid role.")
 	}
 
 	team, err := s.app.store.GetTeamById(ctx, input.TeamId, store.ConsistencyEventual)
@@ -130,6 +171,8 @@ func (s *Session) InviteToTeam(ctx context.Context, input InviteToTeamInput) Use
 		return s.SanitizedError(err)
 	}
 
+	s.recordAuditEvent(ctx, team.Id, model.AuditEventActionTeamInviteCreate, invite.EmailAddress, nil, invite)
+
 	emailParams := map[string]any{
 		"TeamName": team.Name,
 	}
@@ -170,7 +213,7 @@ func (s *Session) JoinTeam(ctx context.Context, teamId model.Id) (*model.TeamMem
 	membership := &model.TeamMembership{
 		TeamId:       teamId,
 		UserId:       s.user.Id,
-		Role:         invite.Role,
+		Role:         invite.Role.Migrated(),
 		CreationTime: time.Now(),
 	}
 
@@ -180,6 +223,8 @@ func (s *Session) JoinTeam(ctx context.Context, teamId model.Id) (*model.TeamMem
 		return nil, s.SanitizedError(err)
 	}
 
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipCreate, s.user.Id.String(), nil, membership)
+
 	return membership, nil
 }
 
@@ -194,7 +239,18 @@ func (s *Session) DeleteTeamInviteByTeamIdAndEmailAddress(ctx context.Context, t
 		}
 	}
 
-	return s.SanitizedError(s.app.store.DeleteTeamInviteByTeamIdAndEmailAddress(ctx, teamId, emailAddress))
+	invite, err := s.app.store.GetTeamInviteByTeamIdAndEmailAddress(ctx, teamId, emailAddress)
+	if err != nil {
+		return s.SanitizedError(err)
+	} else if err := s.app.store.DeleteTeamInviteByTeamIdAndEmailAddress(ctx, teamId, emailAddress); err != nil {
+		return s.SanitizedError(err)
+	}
+
+	if invite != nil {
+		s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamInviteDelete, invite.EmailAddress, invite, nil)
+	}
+
+	return nil
 }
 
 type UserTeamMembership struct {
@@ -256,16 +312,22 @@ type TeamTeamMembership struct {
 	Membership *model.TeamMembership
 }
 
-// Gets all memberships for the team with the given id.
-func (s *Session) GetTeamMembershipsByTeamId(ctx context.Context, teamId model.Id) ([]*TeamTeamMembership, UserFacingError) {
+// Gets a page of memberships for the team with the given id.
+func (s *Session) GetTeamMembershipsByTeamId(ctx context.Context, teamId model.Id, input PageInput) (*Page[*TeamTeamMembership], UserFacingError) {
 	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
 		return nil, err
 	}
 
-	memberships, err := s.app.store.GetTeamMembershipsByTeamId(ctx, teamId)
+	pageInput, ufErr := input.storePageInput()
+	if ufErr != nil {
+		return nil, ufErr
+	}
+
+	page, err := s.app.store.GetTeamMembershipPageByTeamId(ctx, teamId, pageInput)
 	if err != nil {
-		return nil, s.SanitizedError(err)
+		return nil, s.sanitizedPageError(err)
 	}
+	memberships := page.Items
 
 	userIds := make([]model.Id, 0, len(memberships))
 	for _, membership := range memberships {
@@ -292,7 +354,31 @@ func (s *Session) GetTeamMembershipsByTeamId(ctx context.Context, teamId model.I
 		}
 	}
 
-	return ret, nil
+	return &Page[*TeamTeamMembership]{
+		Items:      ret,
+		NextCursor: page.Cursor,
+	}, nil
+}
+
+// Gets the users that are members of the given team with roles that grant the given permission.
+func (a *App) getTeamMemberUsers(ctx context.Context, teamId model.Id, permission model.TeamPermission) ([]*model.User, error) {
+	memberships, err := a.store.GetTeamMembershipsByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team memberships: %w", err)
+	}
+
+	userIds := make([]model.Id, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Role.HasPermission(permission) {
+			userIds = append(userIds, membership.UserId)
+		}
+	}
+
+	users, err := a.store.GetUsersByIds(ctx, userIds...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	return users, nil
 }
 
 func (s *Session) DeleteTeamMembershipByTeamAndUserId(ctx context.Context, teamId, userId model.Id) UserFacingError {
@@ -302,23 +388,74 @@ func (s *Session) DeleteTeamMembershipByTeamAndUserId(ctx context.Context, teamI
 		}
 	}
 
-	return s.SanitizedError(s.app.store.DeleteTeamMembershipByTeamAndUserId(ctx, teamId, userId))
+	membership, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, userId)
+	if err != nil {
+		return s.SanitizedError(err)
+	} else if err := s.app.store.DeleteTeamMembershipByTeamAndUserId(ctx, teamId, userId); err != nil {
+		return s.SanitizedError(err)
+	}
+
+	if membership != nil {
+		s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipDelete, userId.String(), membership, nil)
+	}
+
+	return nil
 }
 
 type TeamMembershipPatch struct {
-	Role *model.TeamMembershipRole
+	Role            *model.TeamMembershipRole
+	DigestFrequency *model.DigestFrequency
 }
 
+// Updates a team membership. Only administrators can change roles, and only the member themselves
+// can change their digest frequency if their role allows them to read reports.
 func (s *Session) PatchTeamMembershipByTeamAndUserId(ctx context.Context, teamId, userId model.Id, patch TeamMembershipPatch) (*model.TeamMembership, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
-		return nil, err
+	if patch.Role != nil {
+		if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+			return nil, err
+		} else if !patch.Role.IsValid() {
+			return nil, NewUserError("Invalid role.")
+		}
+	}
+
+	if patch.DigestFrequency != nil {
+		if !s.HasUserId(userId) {
+			return nil, AuthorizationError{}
+		} else if err := s.RequirePermission(ctx, teamId, model.TeamPermissionReadReports); err != nil {
+			return nil, err
+		}
+		switch *patch.DigestFrequency {
+		case model.DigestFrequencyNone, model.DigestFrequencyDaily, model.DigestFrequencyWeekly:
+		default:
+			return nil, NewUserError("Invalid digest frequency.")
+		}
+	}
+
+	if patch.Role == nil && patch.DigestFrequency == nil {
+		if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, userId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
 	}
 
 	storePatch := &store.TeamMembershipPatch{
-		Role: patch.Role,
+		Role:            patch.Role,
+		DigestFrequency: patch.DigestFrequency,
 	}
 	membership, err := s.app.store.PatchTeamMembershipByTeamAndUserId(ctx, teamId, userId, storePatch)
-	return membership, s.SanitizedError(err)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	if membership != nil {
+		s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipUpdate, userId.String(), before, membership)
+	}
+
+	return membership, nil
 }
 
 type UserTeamInvite struct {
@@ -423,7 +560,7 @@ type TeamPrincipalSettingsPatch struct {
 }
 
 func (s *Session) CreateOrPatchTeamPrincipalSettingsByTeamIdAndPrincipalKey(ctx context.Context, teamId model.Id, principalKey string, patch TeamPrincipalSettingsPatch) (*model.TeamPrincipalSettings, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionAnalyzeReports); err != nil {
 		return nil, err
 	}
 
@@ -446,15 +583,130 @@ func (s *Session) CreateOrPatchTeamPrincipalSettingsByTeamIdAndPrincipalKey(ctx
 		}
 	}
 
+	before, err := s.app.store.GetTeamPrincipalSettingsByTeamIdAndPrincipalKey(ctx, teamId, principalKey)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
 	settings, err := s.app.store.CreateOrPatchTeamPrincipalSettingsByTeamIdAndPrincipalKey(ctx, teamId, principalKey, storePatch)
-	return settings, s.SanitizedError(err)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamPrincipalSettingsUpdate, principalKey, before, settings)
+
+	return settings, nil
 }
 
 func (s *Session) GetTeamPrincipalSettingsByTeamIdAndPrincipalKey(ctx context.Context, teamId model.Id, principalKey string) (*model.TeamPrincipalSettings, UserFacingError) {
-	if err := s.RequireTeamMember(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionReadReports); err != nil {
 		return nil, err
 	}
 
 	settings, err := s.app.store.GetTeamPrincipalSettingsByTeamIdAndPrincipalKey(ctx, teamId, principalKey)
 	return settings, s.SanitizedError(err)
 }
+
+// Replaces legacy team roles throughout the given team's memberships, invites, and identity
+// provider configurations with the roles they grant. Returns the number of records updated.
+func (a *App) migrateTeamRoles(ctx context.Context, teamId model.Id) (int, error) {
+	n := 0
+
+	memberships, err := a.store.GetTeamMembershipsByTeamId(ctx, teamId)
+	if err != nil {
+		return n, fmt.Errorf("failed to get team memberships: %w", err)
+	}
+	for _, membership := range memberships {
+		if role := membership.Role.Migrated(); role != membership.Role {
+			if _, err := a.store.PatchTeamMembershipByTeamAndUserId(ctx, teamId, membership.UserId, &store.TeamMembershipPatch{
+				Role: &role,
+			}); err != nil {
+				return n, fmt.Errorf("failed to patch team membership: %w", err)
+			}
+			n++
+		}
+	}
+
+	invites, err := a.store.GetTeamInvitesByTeamId(ctx, teamId)
+	if err != nil {
+		return n, fmt.Errorf("failed to get team invites: %w", err)
+	}
+	for _, invite := range invites {
+		if role := invite.Role.Migrated(); role != invite.Role {
+			invite.Role = role
+			if err := a.store.PutTeamInvite(ctx, invite); err != nil {
+				return n, fmt.Errorf("failed to put team invite: %w", err)
+			}
+			n++
+		}
+	}
+
+	if config, err := a.store.GetTeamSAMLConfigurationByTeamId(ctx, teamId); err != nil {
+		return n, fmt.Errorf("failed to get saml configuration: %w", err)
+	} else if config != nil {
+		changed := false
+		if role := config.DefaultRole.Migrated(); role != config.DefaultRole {
+			config.DefaultRole = role
+			changed = true
+		}
+		for i, mapping := range config.RoleMappings {
+			if role := mapping.Role.Migrated(); role != mapping.Role {
+				config.RoleMappings[i].Role = role
+				changed = true
+			}
+		}
+		if changed {
+			if err := a.store.PutTeamSAMLConfiguration(ctx, config); err != nil {
+				return n, fmt.Errorf("failed to put saml configuration: %w", err)
+			}
+			n++
+		}
+	}
+
+	if config, err := a.store.GetTeamOIDCConfigurationByTeamId(ctx, teamId); err != nil {
+		return n, fmt.Errorf("failed to get oidc configuration: %w", err)
+	} else if config != nil {
+		if role := config.AutoJoinRole.Migrated(); role != config.AutoJoinRole {
+			config.AutoJoinRole = role
+			if err := a.store.PutTeamOIDCConfiguration(ctx, config); err != nil {
+				return n, fmt.Errorf("failed to put oidc configuration: %w", err)
+			}
+			n++
+		}
+	}
+
+	groups, err := a.store.GetTeamSCIMGroupsByTeamId(ctx, teamId)
+	if err != nil {
+		return n, fmt.Errorf("failed to get scim groups: %w", err)
+	}
+	for _, group := range groups {
+		if role := group.Role.Migrated(); role != group.Role {
+			group.Role = role
+			if err := a.store.PutTeamSCIMGroup(ctx, group); err != nil {
+				return n, fmt.Errorf("failed to put scim group: %w", err)
+			}
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// Replaces legacy team roles for all teams. Until this is done, legacy roles continue to work, so
+// it's safe to run at any time and more than once. Returns the number of records updated.
+func (a *App) MigrateTeamRoles(ctx context.Context) (int, error) {
+	teams, err := a.store.GetTeams(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	total := 0
+	for _, team := range teams {
+		n, err := a.migrateTeamRoles(ctx, team.Id)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("failed to migrate team %v: %w", team.Id, err)
+		}
+	}
+	return total, nil
+}
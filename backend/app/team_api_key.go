@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+const (
+	maxTeamAPIKeysPerTeam = 20
+
+	defaultTeamAPIKeyLifetime = 90 * 24 * time.Hour
+	maxTeamAPIKeyLifetime     = 366 * 24 * time.Hour
+
+	// Last use times are only updated when they're at least this stale.
+	teamAPIKeyLastUseResolution = time.Minute
+)
+
+var teamAPIKeyScopes = []model.TeamAPIKeyScope{
+	model.TeamAPIKeyScopeReadReports,
+	model.TeamAPIKeyScopeManageIntegrations,
+	model.TeamAPIKeyScopeManageSCPs,
+	model.TeamAPIKeyScopeManageSCIM,
+}
+
+type CreateTeamAPIKeyInput struct {
+	TeamId model.Id
+	Name   string
+	Scopes []model.TeamAPIKeyScope
+
+	// If not given, the key expires after defaultTeamAPIKeyLifetime.
+	ExpirationTime time.Time
+}
+
+// Omits the key's hash from audit events.
+func teamAPIKeyAuditValue(key *model.TeamAPIKey) map[string]any {
+	return map[string]any{
+		"Name":           key.Name,
+		"Scopes":         key.Scopes,
+		"ExpirationTime": key.ExpirationTime,
+	}
+}
+
+// Creates an API key and returns it along with the secret used to authenticate with it. The secret
+// cannot be retrieved later.
+func (s *Session) CreateTeamAPIKey(ctx context.Context, input CreateTeamAPIKeyInput) (*model.TeamAPIKey, string, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, "", err
+	} else if err := ValidateName(input.Name); err != nil {
+		return nil, "", err
+	}
+
+	if len(input.Scopes) == 0 {
+		return nil, "", NewUserError("At least one scope is required.")
+	}
+	var scopes []model.TeamAPIKeyScope
+	for _, scope := range input.Scopes {
+		if !slices.Contains(teamAPIKeyScopes, scope) {
+			return nil, "", NewUserError("Invalid scope.")
+		} else if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := time.Now()
+	expirationTime := input.ExpirationTime
+	if expirationTime.IsZero() {
+		expirationTime = now.Add(defaultTeamAPIKeyLifetime)
+	} else if !expirationTime.After(now) {
+		return nil, "", NewUserError("The expiration time must be in the future.")
+	} else if expirationTime.After(now.Add(maxTeamAPIKeyLifetime)) {
+		return nil, "", NewUserError("API keys cannot be valid for more than a year.")
+	}
+
+	if existing, err := s.app.store.GetTeamAPIKeysByTeamId(ctx, input.TeamId); err != nil {
+		return nil, "", s.SanitizedError(err)
+	} else if len(existing) >= maxTeamAPIKeysPerTeam {
+		return nil, "", NewUserError(fmt.Sprintf("Teams are limited to %d API keys.", maxTeamAPIKeysPerTeam))
+	}
+
+	token := model.NewToken()
+	key := &model.TeamAPIKey{
+		Id:             model.NewTeamAPIKeyId(),
+		TeamId:         input.TeamId,
+		CreatorId:      s.user.Id,
+		CreationTime:   now,
+		ExpirationTime: expirationTime,
+		Name:           input.Name,
+		Hash:           model.TokenHash(token),
+		Scopes:         scopes,
+	}
+	if err := s.app.store.PutTeamAPIKey(ctx, key); err != nil {
+		return nil, "", s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, key.TeamId, model.AuditEventActionTeamAPIKeyCreate, key.Id.String(), nil, teamAPIKeyAuditValue(key))
+
+	// The id is embedded in the secret so that the key can be looked up directly.
+	return key, key.Id.String() + "." + base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Gets the team's unexpired API keys.
+func (s *Session) GetTeamAPIKeysByTeamId(ctx context.Context, teamId model.Id) ([]*model.TeamAPIKey, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return nil, err
+	}
+	keys, err := s.app.store.GetTeamAPIKeysByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	now := time.Now()
+	return slices.DeleteFunc(keys, func(key *model.TeamAPIKey) bool {
+		return !key.ExpirationTime.After(now)
+	}), nil
+}
+
+// Revokes an API key. Requests using it will be rejected immediately.
+func (s *Session) DeleteTeamAPIKeyById(ctx context.Context, id model.Id) UserFacingError {
+	key, err := s.app.store.GetTeamAPIKeyById(ctx, id)
+	if err != nil || key == nil {
+		return s.SanitizedError(err)
+	} else if err := s.RequireTeamAdministrator(ctx, key.TeamId); err != nil {
+		return err
+	} else if err := s.app.store.DeleteTeamAPIKeyById(ctx, id); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, key.TeamId, model.AuditEventActionTeamAPIKeyDelete, key.Id.String(), teamAPIKeyAuditValue(key), nil)
+	return nil
+}
+
+// Returns true if the secret looks like one returned by CreateTeamAPIKey rather than a user access
+// token.
+func IsTeamAPIKeySecret(secret string) bool {
+	return strings.Contains(secret, ".")
+}
+
+// Returns a session authenticated with the given API key secret, if it is valid. Otherwise, it
+// returns nil.
+func (sess Session) WithTeamAPIKey(ctx context.Context, secret string) (*Session, UserFacingError) {
+	id, encodedToken, ok := strings.Cut(secret, ".")
+	if !ok {
+		return nil, nil
+	}
+	token, err := base64.RawURLEncoding.DecodeString(encodedToken)
+	if err != nil {
+		return nil, nil
+	}
+
+	key, err := sess.app.store.GetTeamAPIKeyById(ctx, model.Id(id))
+	if err != nil || key == nil {
+		return nil, sess.SanitizedError(err)
+	}
+
+	now := time.Now()
+	if !key.ExpirationTime.After(now) || subtle.ConstantTimeCompare(key.Hash, model.TokenHash(token)) != 1 {
+		return nil, nil
+	}
+
+	if now.Sub(key.LastUseTime) >= teamAPIKeyLastUseResolution {
+		if updated, err := sess.app.store.UpdateTeamAPIKeyLastUseTime(ctx, key.Id, now); err != nil {
+			return nil, sess.SanitizedError(err)
+		} else if updated == nil {
+			// The key was revoked in the meantime.
+			return nil, nil
+		} else {
+			key = updated
+		}
+	}
+
+	sess.teamAPIKey = key
+	return &sess, nil
+}
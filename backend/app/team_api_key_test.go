@@ -0,0 +1,90 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamAPIKeys(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+	otherTeam := a.NewTestTeamWithSubscription(otherSess, app.TeamSubscriptionTierIndividual)
+
+	t.Run("Validation", func(t *testing.T) {
+		_, _, err := sess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+			TeamId: team.Id,
+			Name:   "CI",
+		})
+		assert.Error(t, err)
+
+		_, _, err = sess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+			TeamId: team.Id,
+			Name:   "CI",
+			Scopes: []model.TeamAPIKeyScope{"everything"},
+		})
+		assert.Error(t, err)
+	})
+
+	key, secret, err := sess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "CI",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeReadReports},
+	})
+	require.NoError(t, err)
+	assert.True(t, app.IsTeamAPIKeySecret(secret))
+
+	keySess, err := a.NewAnonymousSession().WithTeamAPIKey(context.Background(), secret)
+	require.NoError(t, err)
+	require.NotNil(t, keySess)
+	assert.Nil(t, keySess.User())
+	assert.False(t, keySess.TeamAPIKey().LastUseTime.IsZero())
+
+	t.Run("Scopes", func(t *testing.T) {
+		_, err := keySess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{TeamId: team.Id})
+		assert.NoError(t, err)
+
+		_, err = keySess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{TeamId: otherTeam.Id})
+		assert.Error(t, err)
+
+		_, err = keySess.GetAWSIntegrationsByTeamId(context.Background(), team.Id)
+		assert.Error(t, err)
+
+		_, _, err = keySess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+			TeamId: team.Id,
+			Name:   "Escalation",
+			Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeManageIntegrations},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		wrongSess, err := a.NewAnonymousSession().WithTeamAPIKey(context.Background(), key.Id.String()+".AAAA")
+		require.NoError(t, err)
+		assert.Nil(t, wrongSess)
+	})
+
+	keys, err := sess.GetTeamAPIKeysByTeamId(context.Background(), team.Id)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.False(t, keys[0].LastUseTime.IsZero())
+
+	t.Run("Revoke", func(t *testing.T) {
+		require.NoError(t, sess.DeleteTeamAPIKeyById(context.Background(), key.Id))
+
+		revokedSess, err := a.NewAnonymousSession().WithTeamAPIKey(context.Background(), secret)
+		require.NoError(t, err)
+		assert.Nil(t, revokedSess)
+	})
+}
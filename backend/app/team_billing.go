@@ -52,7 +52,7 @@ func teamBillingProfileFromCustomer(c *stripe.Customer) *model.TeamBillingProfil
 }
 
 func (s *Session) GetTeamBillingProfileById(ctx context.Context, teamId model.Id) (*model.TeamBillingProfile, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -78,7 +78,7 @@ type CreateTeamBillingProfileInput struct {
 }
 
 func (s *Session) CreateTeamBillingProfileById(ctx context.Context, teamId model.Id, input CreateTeamBillingProfileInput) (*model.TeamBillingProfile, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -124,11 +124,13 @@ func (s *Session) CreateTeamBillingProfileById(ctx context.Context, teamId model
 	}); err != nil {
 		return nil, s.SanitizedError(err)
 	}
-	return teamBillingProfileFromCustomer(result), nil
+	profile := teamBillingProfileFromCustomer(result)
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamBillingProfileCreate, teamId.String(), nil, profile)
+	return profile, nil
 }
 
 func (s *Session) PatchTeamBillingProfileById(ctx context.Context, teamId model.Id, patch TeamBillingProfilePatch) (*model.TeamBillingProfile, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -170,6 +172,11 @@ func (s *Session) PatchTeamBillingProfileById(ctx context.Context, teamId model.
 		}
 	}
 
+	before, err := s.app.stripe.Customers.Get(team.StripeCustomerId, nil)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to get stripe customer: %w", err))
+	}
+
 	if result, err := s.app.stripe.Customers.Update(team.StripeCustomerId, params); err != nil {
 		if IsStripeBadRequestError(err) {
 			return nil, NewUserError("Request rejected by Stripe. Please double check your billing information.")
@@ -177,7 +184,9 @@ func (s *Session) PatchTeamBillingProfileById(ctx context.Context, teamId model.
 			return nil, s.SanitizedError(fmt.Errorf("unable to update stripe customer: %w", err))
 		}
 	} else {
-		return teamBillingProfileFromCustomer(result), nil
+		profile := teamBillingProfileFromCustomer(result)
+		s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamBillingProfileUpdate, teamId.String(), teamBillingProfileFromCustomer(before), profile)
+		return profile, nil
 	}
 }
 
@@ -204,7 +213,7 @@ func teamPaymentMethodFromStripePaymentMethod(method *stripe.PaymentMethod) *mod
 }
 
 func (s *Session) GetTeamPaymentMethodById(ctx context.Context, teamId model.Id) (*model.TeamPaymentMethod, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -235,7 +244,7 @@ type PutTeamPaymentMethodInput struct {
 }
 
 func (s *Session) PutTeamPaymentMethodById(ctx context.Context, teamId model.Id, input PutTeamPaymentMethodInput) (*model.TeamPaymentMethod, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -248,6 +257,15 @@ func (s *Session) PutTeamPaymentMethodById(ctx context.Context, teamId model.Id,
 		return nil, NewUserError("Team does not have a billing profile configured.")
 	}
 
+	var before *model.TeamPaymentMethod
+	if customer, err := s.app.stripe.Customers.Get(team.StripeCustomerId, &stripe.CustomerParams{
+		Expand: []*string{stripe.String("invoice_settings.default_payment_method")},
+	}); err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to get stripe customer: %w", err))
+	} else if customer != nil && customer.InvoiceSettings != nil && customer.InvoiceSettings.DefaultPaymentMethod != nil {
+		before = teamPaymentMethodFromStripePaymentMethod(customer.InvoiceSettings.DefaultPaymentMethod)
+	}
+
 	method, err := s.app.stripe.PaymentMethods.Get(input.StripePaymentMethodId, nil)
 	if err != nil {
 		return nil, s.SanitizedError(fmt.Errorf("error getting stripe payment method: %w", err))
@@ -285,7 +303,9 @@ func (s *Session) PutTeamPaymentMethodById(ctx context.Context, teamId model.Id,
 		return nil, s.SanitizedError(fmt.Errorf("error updating stripe customer: %w", err))
 	}
 
-	return teamPaymentMethodFromStripePaymentMethod(method), nil
+	after := teamPaymentMethodFromStripePaymentMethod(method)
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamPaymentMethodUpdate, teamId.String(), before, after)
+	return after, nil
 }
 
 func (a *App) getSingleStripeSubscription(customerId string) (*stripe.Subscription, error) {
@@ -379,7 +399,7 @@ type CreateTeamSubscriptionInput struct {
 }
 
 func (s *Session) CreateTeamSubscriptionById(ctx context.Context, teamId model.Id, input CreateTeamSubscriptionInput) (*model.TeamSubscription, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -441,7 +461,11 @@ func (s *Session) CreateTeamSubscriptionById(ctx context.Context, teamId model.I
 	}
 
 	ret, err := s.app.teamSubscriptionFromStripe(subscription)
-	return ret, s.SanitizedError(err)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSubscriptionCreate, teamId.String(), nil, ret)
+	return ret, nil
 }
 
 type updateStripeSubscriptionInput struct {
@@ -528,7 +552,7 @@ type UpdateTeamSubscriptionInput struct {
 }
 
 func (s *Session) UpdateTeamSubscriptionById(ctx context.Context, teamId model.Id, input UpdateTeamSubscriptionInput) (*model.TeamSubscription, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
@@ -543,6 +567,13 @@ func (s *Session) UpdateTeamSubscriptionById(ctx context.Context, teamId model.I
 		return nil, err
 	}
 
+	var before *model.TeamSubscription
+	if subscription, err := s.app.getSingleStripeSubscription(team.StripeCustomerId); err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to get stripe subscription: %w", err))
+	} else if before, err = s.app.teamSubscriptionFromStripe(subscription); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
 	if subscription, err := s.app.updateStripeSubscriptionByCustomerId(ctx, team.StripeCustomerId, updateStripeSubscriptionInput{
 		Tier: input.Tier,
 	}); err != nil {
@@ -554,14 +585,16 @@ func (s *Session) UpdateTeamSubscriptionById(ctx context.Context, teamId model.I
 		StripeCustomerId: team.StripeCustomerId,
 	}); err != nil {
 		return nil, s.SanitizedError(err)
+	} else if ret, err := s.app.teamSubscriptionFromStripe(subscription); err != nil {
+		return nil, s.SanitizedError(err)
 	} else {
-		ret, err := s.app.teamSubscriptionFromStripe(subscription)
-		return ret, s.SanitizedError(err)
+		s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSubscriptionUpdate, teamId.String(), before, ret)
+		return ret, nil
 	}
 }
 
 func (s *Session) GetTeamSubscriptionById(ctx context.Context, teamId model.Id) (*model.TeamSubscription, UserFacingError) {
-	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+	if err := s.RequirePermission(ctx, teamId, model.TeamPermissionManageBilling); err != nil {
 		return nil, err
 	}
 
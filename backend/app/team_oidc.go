@@ -0,0 +1,672 @@
+package app
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const (
+	// How long users have to complete sign-in at their identity provider.
+	oidcAuthenticationSessionDuration = 10 * time.Minute
+
+	maxSSOAllowedEmailDomains = 50
+
+	// Identity provider responses larger than this are rejected.
+	maxOIDCResponseSize = 1024 * 1024
+
+	// How far the identity provider's clock may drift from ours.
+	oidcClockSkew = time.Minute
+)
+
+// The frontend path that identity providers redirect users back to.
+const oidcRedirectPath = "/complete-sso-signin"
+
+var oidcIDTokenSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+var emailDomainRegexp = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+func (a *App) oidcRedirectURI() string {
+	return strings.TrimSuffix(a.config.FrontendURL, "/") + oidcRedirectPath
+}
+
+type PutTeamOIDCConfigurationInput struct {
+	TeamId   model.Id
+	Issuer   string
+	ClientId string
+
+	// May be omitted when updating an existing configuration to keep its secret.
+	ClientSecret string
+
+	AllowedEmailDomains []string
+	AutoJoinRole        model.TeamMembershipRole
+}
+
+// Omits the configuration's client secret from audit events.
+func teamOIDCConfigurationAuditValue(config *model.TeamOIDCConfiguration) map[string]any {
+	return map[string]any{
+		"Issuer":              config.Issuer,
+		"ClientId":            config.ClientId,
+		"AllowedEmailDomains": config.AllowedEmailDomains,
+		"AutoJoinRole":        config.AutoJoinRole,
+	}
+}
+
+// Validates, lowercases, sorts, and deduplicates the email domains allowed to use single sign-on.
+func normalizeSSOEmailDomains(input []string) ([]string, UserFacingError) {
+	var domains []string
+	for _, domain := range input {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if !emailDomainRegexp.MatchString(domain) {
+			return nil, NewUserError(fmt.Sprintf("Invalid email domain: %s", domain))
+		}
+		domains = append(domains, domain)
+	}
+	slices.Sort(domains)
+	domains = slices.Compact(domains)
+	if len(domains) == 0 {
+		return nil, NewUserError("At least one allowed email domain is required.")
+	} else if len(domains) > maxSSOAllowedEmailDomains {
+		return nil, NewUserError(fmt.Sprintf("Single sign-on is limited to %d email domains.", maxSSOAllowedEmailDomains))
+	}
+	return domains, nil
+}
+
+// Returns true if the email address is in one of the allowed domains.
+func isSSOEmailDomainAllowed(emailAddress string, allowedDomains []string) bool {
+	domain := strings.ToLower(emailAddress[strings.LastIndex(emailAddress, "@")+1:])
+	return slices.Contains(allowedDomains, domain)
+}
+
+func (s *Session) validateOIDCIssuer(issuer string) UserFacingError {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return NewUserError("Invalid issuer URL.")
+	} else if u.Scheme != "https" && (u.Scheme != "http" || !s.app.config.AllowInsecureOIDCIssuers) {
+		return NewUserError("Issuer URLs must use HTTPS.")
+	} else if len(issuer) > 1000 {
+		return NewUserError("Please provide a shorter issuer URL.")
+	}
+	// Hostnames are checked when they're resolved, but we can reject obviously bad addresses up
+	// front.
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !isPublicIPAddress(ip) && !s.app.config.AllowInsecureOIDCIssuers {
+		return NewUserError("Issuer URLs must use public addresses.")
+	}
+	return nil
+}
+
+// Creates or replaces the team's identity provider.
+func (s *Session) PutTeamOIDCConfiguration(ctx context.Context, input PutTeamOIDCConfigurationInput) (*model.TeamOIDCConfiguration, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, err
+	}
+
+	if team, err := s.app.store.GetTeamById(ctx, input.TeamId, store.ConsistencyEventual); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if team == nil {
+		return nil, NotFoundError("Team not found.")
+	} else if !team.Entitlements.TeamFeatures {
+		return nil, NewUserError("Single sign-on requires a team subscription.")
+	}
+
+	if err := s.validateOIDCIssuer(input.Issuer); err != nil {
+		return nil, err
+	} else if input.ClientId == "" {
+		return nil, NewUserError("A client id is required.")
+	} else if len(input.ClientId) > 1000 {
+		return nil, NewUserError("Please provide a shorter client id.")
+	} else if len(input.ClientSecret) > 1000 {
+		return nil, NewUserError("Please provide a shorter client secret.")
+	}
+
+	if input.AutoJoinRole != model.TeamMembershipRoleNone && !input.AutoJoinRole.IsValid() {
+		return nil, NewUserError("Invalid auto-join role.")
+	}
+
+	domains, userErr := normalizeSSOEmailDomains(input.AllowedEmailDomains)
+	if userErr != nil {
+		return nil, userErr
+	}
+
+	before, err := s.app.store.GetTeamOIDCConfigurationByTeamId(ctx, input.TeamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	now := time.Now()
+	config := &model.TeamOIDCConfiguration{
+		TeamId:              input.TeamId,
+		CreationTime:        now,
+		UpdateTime:          now,
+		Issuer:              input.Issuer,
+		ClientId:            input.ClientId,
+		AllowedEmailDomains: domains,
+		AutoJoinRole:        input.AutoJoinRole,
+	}
+	if input.ClientSecret != "" {
+		config.EncryptedClientSecret = model.EncryptSecret([]byte(input.ClientSecret), s.app.config.PasswordEncryptionKey)
+	} else if before != nil {
+		config.EncryptedClientSecret = before.EncryptedClientSecret
+	} else {
+		return nil, NewUserError("A client secret is required.")
+	}
+	if before != nil {
+		config.CreationTime = before.CreationTime
+	}
+
+	// Catch typos in the issuer before users run into them.
+	if _, err := s.app.getOIDCProviderMetadata(ctx, config.Issuer); err != nil {
+		s.Logger().Info("unable to get oidc provider metadata", zap.Error(err))
+		return nil, NewUserError("Unable to get the identity provider's configuration. Please check the issuer URL.")
+	}
+
+	if err := s.app.store.PutTeamOIDCConfiguration(ctx, config); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	var beforeValue any
+	if before != nil {
+		beforeValue = teamOIDCConfigurationAuditValue(before)
+	}
+	s.recordAuditEvent(ctx, config.TeamId, model.AuditEventActionTeamOIDCConfigurationUpdate, config.TeamId.String(), beforeValue, teamOIDCConfigurationAuditValue(config))
+	return config, nil
+}
+
+func (s *Session) GetTeamOIDCConfigurationByTeamId(ctx context.Context, teamId model.Id) (*model.TeamOIDCConfiguration, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return nil, err
+	}
+
+	config, err := s.app.store.GetTeamOIDCConfigurationByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NotFoundError("Single sign-on is not configured for this team.")
+	}
+	return config, nil
+}
+
+func (s *Session) DeleteTeamOIDCConfigurationByTeamId(ctx context.Context, teamId model.Id) UserFacingError {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return err
+	}
+
+	config, err := s.app.store.GetTeamOIDCConfigurationByTeamId(ctx, teamId)
+	if err != nil || config == nil {
+		return s.SanitizedError(err)
+	} else if err := s.app.store.DeleteTeamOIDCConfigurationByTeamId(ctx, teamId); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamOIDCConfigurationDelete, teamId.String(), teamOIDCConfigurationAuditValue(config), nil)
+	return nil
+}
+
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func (a *App) doOIDCRequest(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.oidcHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOIDCResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	} else if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return jsoniter.Unmarshal(body, v)
+}
+
+// Gets the provider's metadata via OpenID Connect Discovery.
+func (a *App) getOIDCProviderMetadata(ctx context.Context, issuer string) (*oidcProviderMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var metadata oidcProviderMetadata
+	if err := a.doOIDCRequest(req, &metadata); err != nil {
+		return nil, err
+	} else if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("metadata is for a different issuer: %q", metadata.Issuer)
+	} else if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("metadata is missing required endpoints")
+	}
+	return &metadata, nil
+}
+
+type oidcJSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *oidcJSONWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := func(s string) (*big.Int, error) {
+		buf, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(buf) == 0 {
+			return nil, fmt.Errorf("invalid key parameter")
+		}
+		return new(big.Int).SetBytes(buf), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		} else if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %q", k.Kty)
+	}
+}
+
+// Gets the provider's signing keys, indexed by key id. Keys that can't be used to verify ID tokens
+// are omitted.
+func (a *App) getOIDCSigningKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var jwks struct {
+		Keys []oidcJSONWebKey `json:"keys"`
+	}
+	if err := a.doOIDCRequest(req, &jwks); err != nil {
+		return nil, err
+	}
+	ret := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			ret[jwk.Kid] = key
+		}
+	}
+	return ret, nil
+}
+
+// Returns a random string suitable for use as a nonce or PKCE code verifier.
+func newOIDCRandomString() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+type BeginTeamOIDCAuthenticationOutput struct {
+	// The identity provider URL that the user should be sent to. After signing in, they'll be
+	// redirected back to the frontend with the state and code needed to complete authentication.
+	AuthorizationURL string
+}
+
+// Begins sign-in with a team's identity provider using the authorization code flow with PKCE.
+func (s *Session) BeginTeamOIDCAuthentication(ctx context.Context, teamId model.Id) (*BeginTeamOIDCAuthenticationOutput, UserFacingError) {
+	config, err := s.app.store.GetTeamOIDCConfigurationByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NotFoundError("Single sign-on is not configured for this team.")
+	}
+
+	metadata, err := s.app.getOIDCProviderMetadata(ctx, config.Issuer)
+	if err != nil {
+		s.Logger().Info("unable to get oidc provider metadata", zap.Error(err))
+		return nil, NewUserError("Unable to reach the team's identity provider. Please try again later.")
+	}
+	authorizationURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		s.Logger().Info("invalid oidc authorization endpoint", zap.Error(err))
+		return nil, NewUserError("The team's identity provider is misconfigured.")
+	}
+
+	session := &model.OIDCAuthenticationSession{
+		Id:             model.NewOIDCAuthenticationSessionId(),
+		TeamId:         teamId,
+		ExpirationTime: time.Now().Add(oidcAuthenticationSessionDuration),
+		Nonce:          newOIDCRandomString(),
+		CodeVerifier:   newOIDCRandomString(),
+	}
+	if s.user != nil {
+		session.LinkUserId = s.user.Id
+	}
+	if err := s.app.store.PutOIDCAuthenticationSession(ctx, session); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	codeChallenge := sha256.Sum256([]byte(session.CodeVerifier))
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", config.ClientId)
+	query.Set("redirect_uri", s.app.oidcRedirectURI())
+	query.Set("scope", "openid email")
+	query.Set("state", session.Id.String())
+	query.Set("nonce", session.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(codeChallenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+
+	return &BeginTeamOIDCAuthenticationOutput{
+		AuthorizationURL: authorizationURL.String(),
+	}, nil
+}
+
+type oidcIDTokenClaims struct {
+	jwt.RegisteredClaims
+
+	Nonce string `json:"nonce"`
+	Email string `json:"email"`
+
+	// Some providers send this as a string.
+	EmailVerified any `json:"email_verified"`
+}
+
+func (c *oidcIDTokenClaims) IsEmailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// Exchanges an authorization code for an ID token and returns its verified claims.
+func (a *App) exchangeOIDCAuthorizationCode(ctx context.Context, config *model.TeamOIDCConfiguration, session *model.OIDCAuthenticationSession, code string) (*oidcIDTokenClaims, error) {
+	metadata, err := a.getOIDCProviderMetadata(ctx, config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get provider metadata: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {a.oidcRedirectURI()},
+		"code_verifier": {session.CodeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// RFC 6749 requires client credentials to be form-encoded before they're used for basic
+	// authentication.
+	clientSecret := model.DecryptSecret(config.EncryptedClientSecret, a.config.PasswordEncryptionKey)
+	req.SetBasicAuth(url.QueryEscape(config.ClientId), url.QueryEscape(string(clientSecret)))
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := a.doOIDCRequest(req, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("unable to exchange authorization code: %w", err)
+	} else if tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("token response is missing id token")
+	}
+
+	keys, err := a.getOIDCSigningKeys(ctx, metadata.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get signing keys: %w", err)
+	}
+
+	claims := &oidcIDTokenClaims{}
+	if _, err := jwt.ParseWithClaims(tokenResponse.IDToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	},
+		jwt.WithValidMethods(oidcIDTokenSigningMethods),
+		jwt.WithIssuer(config.Issuer),
+		jwt.WithAudience(config.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	); err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	} else if claims.Nonce != session.Nonce {
+		return nil, fmt.Errorf("id token nonce mismatch")
+	} else if claims.Subject == "" {
+		return nil, fmt.Errorf("id token is missing subject")
+	}
+	return claims, nil
+}
+
+type CompleteTeamOIDCAuthenticationInput struct {
+	// The state parameter the identity provider redirected the user back with.
+	State string
+
+	// The authorization code the identity provider redirected the user back with.
+	Code string
+}
+
+type CompleteTeamOIDCAuthenticationOutput struct {
+	User *model.User
+
+	// The team whose identity provider authenticated the user.
+	TeamId model.Id
+}
+
+// Completes sign-in with a team's identity provider and returns the user that was authenticated. A
+// user is created if no account exists for the email address. Since the team controls the
+// provider, existing accounts are only linked to it if sign-in was begun by the account's user.
+func (s *Session) CompleteTeamOIDCAuthentication(ctx context.Context, input CompleteTeamOIDCAuthenticationInput) (*CompleteTeamOIDCAuthenticationOutput, UserFacingError) {
+	session, err := s.app.store.GetOIDCAuthenticationSessionById(ctx, model.Id(input.State))
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if session == nil || session.ExpirationTime.Before(time.Now()) {
+		return nil, NewUserError("Session expired. Please try again.")
+	} else if err := s.app.store.DeleteOIDCAuthenticationSessionById(ctx, session.Id); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	config, err := s.app.store.GetTeamOIDCConfigurationByTeamId(ctx, session.TeamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NewUserError("Single sign-on is no longer configured for this team.")
+	}
+
+	claims, err := s.app.exchangeOIDCAuthorizationCode(ctx, config, session, input.Code)
+	if err != nil {
+		s.Logger().Info("oidc authentication failed", zap.Error(err), zap.String("team_id", config.TeamId.String()))
+		return nil, NewUserError("Unable to sign in with the team's identity provider. Please try again.")
+	}
+
+	if err := ValidateEmailAddress(claims.Email); err != nil {
+		return nil, NewUserError("The identity provider didn't provide a valid email address.")
+	} else if !claims.IsEmailVerified() {
+		return nil, NewUserError("Your email address must be verified by the identity provider.")
+	}
+	if !isSSOEmailDomainAllowed(claims.Email, config.AllowedEmailDomains) {
+		return nil, NewUserError("Your email address isn't allowed to sign in with this identity provider.")
+	}
+
+	user, userErr := s.getOrCreateOIDCUser(ctx, config, session.LinkUserId, claims.Subject, claims.Email)
+	if userErr != nil {
+		return nil, userErr
+	}
+
+	if config.AutoJoinRole != model.TeamMembershipRoleNone {
+		s.autoJoinSSOTeam(ctx, config.TeamId, user, config.AutoJoinRole)
+	}
+
+	return &CompleteTeamOIDCAuthenticationOutput{
+		User:   user,
+		TeamId: config.TeamId,
+	}, nil
+}
+
+func (s *Session) getOrCreateOIDCUser(ctx context.Context, config *model.TeamOIDCConfiguration, linkUserId model.Id, subject, emailAddress string) (*model.User, UserFacingError) {
+	if identity, err := s.app.store.GetUserOIDCIdentity(ctx, config.TeamId, config.Issuer, subject); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if identity != nil {
+		if linkUserId != "" && identity.UserId != linkUserId {
+			return nil, NewUserError("This identity is already linked to another account.")
+		} else if user, err := s.app.store.GetUserById(ctx, identity.UserId, store.ConsistencyStrongInRegion); err != nil {
+			return nil, s.SanitizedError(err)
+		} else if user != nil {
+			return user, nil
+		}
+	}
+
+	user, userErr := s.getOrCreateSSOUser(ctx, config.TeamId, emailAddress, linkUserId)
+	if userErr != nil {
+		return nil, userErr
+	}
+
+	if err := s.app.store.PutUserOIDCIdentity(ctx, &model.UserOIDCIdentity{
+		TeamId:       config.TeamId,
+		Issuer:       config.Issuer,
+		Subject:      subject,
+		UserId:       user.Id,
+		CreationTime: time.Now(),
+	}); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	return user, nil
+}
+
+// Gets the user with the given email address for sign-in with a team's identity provider, creating
+// them if necessary. The team decides which email addresses its provider asserts, so the provider
+// can only sign in as existing users that it created or that linked their account to it. Accounts
+// are linked when sign-in is begun by the account's user, given by linkUserId.
+func (s *Session) getOrCreateSSOUser(ctx context.Context, teamId model.Id, emailAddress string, linkUserId model.Id) (*model.User, UserFacingError) {
+	user, err := s.app.getUserByEmailAddress(ctx, emailAddress)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	if linkUserId != "" && (user == nil || user.Id != linkUserId) {
+		return nil, NewUserError("Please sign in to the identity provider with your account's email address.")
+	}
+
+	if user != nil {
+		if slices.Contains(user.SSOTeamIds, teamId) {
+			return user, nil
+		} else if user.Id != linkUserId {
+			return nil, NewUserError("An account with this email address already exists. To use single sign-on with it, sign in another way, then sign in with the team's identity provider again.")
+		}
+		ssoTeamIds := append(slices.Clone(user.SSOTeamIds), teamId)
+		if user, err = s.app.store.PatchUserById(ctx, user.Id, &store.UserPatch{
+			SSOTeamIds: &ssoTeamIds,
+		}); err != nil {
+			return nil, s.SanitizedError(err)
+		} else if user == nil {
+			return nil, NotFoundError("")
+		}
+	} else if !s.app.IsUserRegistrationAllowed(emailAddress) {
+		return nil, NewUserError("User registration is disabled at this time.")
+	} else {
+		user = &model.User{
+			Id:           model.NewUserId(),
+			CreationTime: time.Now(),
+			Role:         model.UserRoleCustomer,
+			EmailAddress: emailAddress,
+			SSOTeamIds:   []model.Id{teamId},
+		}
+		if err := s.app.store.PutUser(ctx, user); err != nil {
+			return nil, s.SanitizedError(fmt.Errorf("unable to put user: %w", err))
+		}
+	}
+	return user, nil
+}
+
+// Adds the user to the team if they aren't already a member. Failures are logged rather than
+// returned so that users can still sign in.
+func (s *Session) autoJoinSSOTeam(ctx context.Context, teamId model.Id, user *model.User, role model.TeamMembershipRole) {
+	logger := s.Logger().With(zap.String("team_id", teamId.String()), zap.String("user_id", user.Id.String()))
+
+	if membership, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, user.Id); err != nil {
+		logger.Error("unable to get team membership for sso auto-join", zap.Error(err))
+		return
+	} else if membership != nil {
+		return
+	}
+
+	if team, err := s.app.store.GetTeamById(ctx, teamId, store.ConsistencyEventual); err != nil {
+		logger.Error("unable to get team for sso auto-join", zap.Error(err))
+		return
+	} else if team == nil || !team.Entitlements.TeamFeatures {
+		logger.Info("skipping sso auto-join for team without team features")
+		return
+	}
+
+	membership := &model.TeamMembership{
+		TeamId:       teamId,
+		UserId:       user.Id,
+		Role:         role.Migrated(),
+		CreationTime: time.Now(),
+	}
+	if err := s.app.store.PutTeamMembership(ctx, membership); err != nil {
+		logger.Error("unable to put team membership for sso auto-join", zap.Error(err))
+		return
+	}
+
+	userSess := *s
+	userSess.user = user
+	userSess.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipCreate, user.Id.String(), nil, membership)
+}
@@ -0,0 +1,203 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamOIDC(t *testing.T) {
+	a := apptest.NewTestApp(t)
+	provider := apptest.NewFakeOIDCProvider(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(aliceSess, app.TeamSubscriptionTierTeam)
+
+	carol, carolSess := a.NewTestUser("carol@example.com", model.UserRoleCustomer)
+
+	// Begins sign-in with the given session, which links the account if it's signed in.
+	signInFrom := func(beginSess *app.Session, user apptest.FakeOIDCUser) (*app.Session, error) {
+		output, err := beginSess.BeginTeamOIDCAuthentication(context.Background(), team.Id)
+		require.NoError(t, err)
+		state, code := provider.Authorize(output.AuthorizationURL, user)
+		sess, userErr := a.NewAnonymousSession().WithUserOIDCAuthentication(context.Background(), state, code)
+		if userErr != nil {
+			return nil, userErr
+		}
+		require.NotNil(t, sess)
+		return sess, nil
+	}
+	signIn := func(user apptest.FakeOIDCUser) (*app.Session, error) {
+		return signInFrom(a.NewAnonymousSession(), user)
+	}
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		_, err := a.NewAnonymousSession().BeginTeamOIDCAuthentication(context.Background(), team.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+
+		_, err = aliceSess.GetTeamOIDCConfigurationByTeamId(context.Background(), team.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+
+	input := app.PutTeamOIDCConfigurationInput{
+		TeamId:              team.Id,
+		Issuer:              provider.URL,
+		ClientId:            provider.ClientId,
+		ClientSecret:        provider.ClientSecret,
+		AllowedEmailDomains: []string{"Example.com", "example.com"},
+		AutoJoinRole:        model.TeamMembershipRoleAnalyst,
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		for name, modify := range map[string]func(*app.PutTeamOIDCConfigurationInput){
+			"NoSecret":      func(input *app.PutTeamOIDCConfigurationInput) { input.ClientSecret = "" },
+			"NoDomains":     func(input *app.PutTeamOIDCConfigurationInput) { input.AllowedEmailDomains = nil },
+			"InvalidDomain": func(input *app.PutTeamOIDCConfigurationInput) { input.AllowedEmailDomains = []string{"@example.com"} },
+			"InvalidRole":   func(input *app.PutTeamOIDCConfigurationInput) { input.AutoJoinRole = "owner" },
+			"WrongIssuer":   func(input *app.PutTeamOIDCConfigurationInput) { input.Issuer = provider.URL + "/other" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				invalid := input
+				modify(&invalid)
+				_, err := aliceSess.PutTeamOIDCConfiguration(context.Background(), invalid)
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := a.NewAnonymousSession().PutTeamOIDCConfiguration(context.Background(), input)
+		assert.Error(t, err)
+	})
+
+	config, err := aliceSess.PutTeamOIDCConfiguration(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, config.AllowedEmailDomains)
+
+	t.Run("KeepSecret", func(t *testing.T) {
+		update := input
+		update.ClientSecret = ""
+		updated, err := aliceSess.PutTeamOIDCConfiguration(context.Background(), update)
+		require.NoError(t, err)
+		assert.True(t, config.CreationTime.Equal(updated.CreationTime))
+		assert.Equal(t, config.EncryptedClientSecret, updated.EncryptedClientSecret)
+	})
+
+	t.Run("NewUser", func(t *testing.T) {
+		sess, err := signIn(apptest.FakeOIDCUser{
+			Subject:       "bob",
+			EmailAddress:  "bob@example.com",
+			EmailVerified: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "bob@example.com", sess.User().EmailAddress)
+
+		membership, err := sess.GetTeamMembershipByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, model.TeamMembershipRoleAnalyst, membership.Role)
+
+		// Signing in again, even with a changed email address, gets the same user.
+		again, err := signIn(apptest.FakeOIDCUser{
+			Subject:       "bob",
+			EmailAddress:  "robert@example.com",
+			EmailVerified: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, sess.User().Id, again.User().Id)
+	})
+
+	t.Run("ExistingUser", func(t *testing.T) {
+		aliceIdentity := apptest.FakeOIDCUser{
+			Subject:       "alice",
+			EmailAddress:  "alice@example.com",
+			EmailVerified: true,
+		}
+
+		// Even team members have to link their accounts first.
+		_, err := signIn(aliceIdentity)
+		assert.Error(t, err)
+
+		sess, err := signInFrom(aliceSess, aliceIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, alice.Id, sess.User().Id)
+
+		sess, err = signIn(aliceIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, alice.Id, sess.User().Id)
+	})
+
+	t.Run("ExistingNonMember", func(t *testing.T) {
+		_, err := signIn(apptest.FakeOIDCUser{
+			Subject:       "carol",
+			EmailAddress:  carol.EmailAddress,
+			EmailVerified: true,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("LinkMismatch", func(t *testing.T) {
+		// Users can't link identities for other email addresses.
+		_, err := signInFrom(carolSess, apptest.FakeOIDCUser{
+			Subject:       "dana",
+			EmailAddress:  "dana@example.com",
+			EmailVerified: true,
+		})
+		assert.Error(t, err)
+
+		// Or identities that are already linked to other accounts.
+		_, err = signInFrom(carolSess, apptest.FakeOIDCUser{
+			Subject:       "alice",
+			EmailAddress:  carol.EmailAddress,
+			EmailVerified: true,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("DisallowedDomain", func(t *testing.T) {
+		_, err := signIn(apptest.FakeOIDCUser{
+			Subject:       "dave",
+			EmailAddress:  "dave@example.org",
+			EmailVerified: true,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("UnverifiedEmailAddress", func(t *testing.T) {
+		_, err := signIn(apptest.FakeOIDCUser{
+			Subject:      "erin",
+			EmailAddress: "erin@example.com",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("SessionReuse", func(t *testing.T) {
+		output, err := a.NewAnonymousSession().BeginTeamOIDCAuthentication(context.Background(), team.Id)
+		require.NoError(t, err)
+		state, code := provider.Authorize(output.AuthorizationURL, apptest.FakeOIDCUser{
+			Subject:       "frank",
+			EmailAddress:  "frank@example.com",
+			EmailVerified: true,
+		})
+
+		sess, err := a.NewAnonymousSession().WithUserOIDCAuthentication(context.Background(), state, code)
+		require.NoError(t, err)
+		require.NotNil(t, sess)
+
+		_, err = a.NewAnonymousSession().WithUserOIDCAuthentication(context.Background(), state, code)
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, aliceSess.DeleteTeamOIDCConfigurationByTeamId(context.Background(), team.Id))
+
+		_, err := a.NewAnonymousSession().BeginTeamOIDCAuthentication(context.Background(), team.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+}
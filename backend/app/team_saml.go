@@ -0,0 +1,570 @@
+package app
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const (
+	// How long users have to complete sign-in at their identity provider.
+	samlAuthenticationSessionDuration = 10 * time.Minute
+
+	// How long users have to exchange the token they're redirected to the frontend with.
+	samlSignInTokenDuration = 5 * time.Minute
+
+	maxSAMLMetadataSize = 100 * 1024
+
+	maxSAMLRoleMappings = 100
+)
+
+// The frontend path that users are redirected to after the identity provider posts to the assertion
+// consumer service. This is the same page used for email sign-in links.
+const samlSignInPath = "/complete-email-signin"
+
+func (a *App) samlServiceProviderURL(teamId model.Id, path string) string {
+	return strings.TrimSuffix(a.config.FrontendURL, "/") + "/api/teams/" + url.PathEscape(teamId.String()) + "/saml/" + path
+}
+
+// The service provider entity id is also the URL of the service provider's metadata.
+func (a *App) samlServiceProviderEntityId(teamId model.Id) string {
+	return a.samlServiceProviderURL(teamId, "metadata")
+}
+
+func (a *App) samlAssertionConsumerServiceURL(teamId model.Id) string {
+	return a.samlServiceProviderURL(teamId, "acs")
+}
+
+// Parses identity provider metadata, which may be an EntityDescriptor or an EntitiesDescriptor
+// containing an identity provider.
+func parseSAMLIdPMetadata(metadataXML string) (*saml.EntityDescriptor, error) {
+	var entity saml.EntityDescriptor
+	if err := xml.Unmarshal([]byte(metadataXML), &entity); err != nil {
+		var entities saml.EntitiesDescriptor
+		if xml.Unmarshal([]byte(metadataXML), &entities) != nil {
+			return nil, err
+		}
+		for i := range entities.EntityDescriptors {
+			if len(entities.EntityDescriptors[i].IDPSSODescriptors) > 0 {
+				return &entities.EntityDescriptors[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no identity provider found")
+	}
+	return &entity, nil
+}
+
+var samlWhitespaceRegexp = regexp.MustCompile(`\s+`)
+
+// Returns the number of valid signing certificates in the metadata.
+func samlIdPSigningCertificateCount(metadata *saml.EntityDescriptor) int {
+	count := 0
+	for _, idp := range metadata.IDPSSODescriptors {
+		for _, keyDescriptor := range idp.KeyDescriptors {
+			if keyDescriptor.Use != "" && keyDescriptor.Use != "signing" {
+				continue
+			}
+			for _, cert := range keyDescriptor.KeyInfo.X509Data.X509Certificates {
+				if der, err := base64.StdEncoding.DecodeString(samlWhitespaceRegexp.ReplaceAllString(cert.Data, "")); err != nil {
+					continue
+				} else if _, err := x509.ParseCertificate(der); err == nil {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+func (a *App) samlServiceProvider(config *model.TeamSAMLConfiguration, idpMetadata *saml.EntityDescriptor) *saml.ServiceProvider {
+	acsURL, _ := url.Parse(a.samlAssertionConsumerServiceURL(config.TeamId))
+	metadataURL, _ := url.Parse(a.samlServiceProviderEntityId(config.TeamId))
+	return &saml.ServiceProvider{
+		EntityID:          metadataURL.String(),
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		IDPMetadata:       idpMetadata,
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+		AllowIDPInitiated: config.AllowIdPInitiated,
+	}
+}
+
+// A team's SAML configuration along with the values that its identity provider needs to be
+// configured with.
+type TeamSAMLConfiguration struct {
+	*model.TeamSAMLConfiguration
+
+	ServiceProviderEntityId     string
+	AssertionConsumerServiceURL string
+}
+
+func (a *App) teamSAMLConfiguration(config *model.TeamSAMLConfiguration) *TeamSAMLConfiguration {
+	return &TeamSAMLConfiguration{
+		TeamSAMLConfiguration:       config,
+		ServiceProviderEntityId:     a.samlServiceProviderEntityId(config.TeamId),
+		AssertionConsumerServiceURL: a.samlAssertionConsumerServiceURL(config.TeamId),
+	}
+}
+
+type PutTeamSAMLConfigurationInput struct {
+	TeamId         model.Id
+	IdPMetadataXML string
+
+	// If empty, the assertion's name id is used as the email address.
+	EmailAttribute string
+
+	RoleAttribute       string
+	RoleMappings        []model.TeamSAMLRoleMapping
+	AllowedEmailDomains []string
+	DefaultRole         model.TeamMembershipRole
+	AllowIdPInitiated   bool
+}
+
+// Omits the metadata from audit events, since it's large and mostly certificates.
+func teamSAMLConfigurationAuditValue(config *model.TeamSAMLConfiguration) map[string]any {
+	return map[string]any{
+		"IdPEntityId":         config.IdPEntityId,
+		"EmailAttribute":      config.EmailAttribute,
+		"RoleAttribute":       config.RoleAttribute,
+		"RoleMappings":        config.RoleMappings,
+		"AllowedEmailDomains": config.AllowedEmailDomains,
+		"DefaultRole":         config.DefaultRole,
+		"AllowIdPInitiated":   config.AllowIdPInitiated,
+	}
+}
+
+// Creates or replaces the team's SAML identity provider.
+func (s *Session) PutTeamSAMLConfiguration(ctx context.Context, input PutTeamSAMLConfigurationInput) (*TeamSAMLConfiguration, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, err
+	}
+
+	if team, err := s.app.store.GetTeamById(ctx, input.TeamId, store.ConsistencyEventual); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if team == nil {
+		return nil, NotFoundError("Team not found.")
+	} else if !team.Entitlements.TeamFeatures {
+		return nil, NewUserError("Single sign-on requires a team subscription.")
+	}
+
+	if input.IdPMetadataXML == "" {
+		return nil, NewUserError("Identity provider metadata is required.")
+	} else if len(input.IdPMetadataXML) > maxSAMLMetadataSize {
+		return nil, NewUserError("The identity provider metadata is too large.")
+	} else if len(input.EmailAttribute) > 1000 || len(input.RoleAttribute) > 1000 {
+		return nil, NewUserError("Please provide shorter attribute names.")
+	} else if input.DefaultRole != model.TeamMembershipRoleNone && !input.DefaultRole.IsValid() {
+		return nil, NewUserError("Invalid default role.")
+	} else if len(input.RoleMappings) > maxSAMLRoleMappings {
+		return nil, NewUserError(fmt.Sprintf("Single sign-on is limited to %d role mappings.", maxSAMLRoleMappings))
+	} else if len(input.RoleMappings) > 0 && input.RoleAttribute == "" {
+		return nil, NewUserError("A role attribute is required to map roles.")
+	}
+	for _, mapping := range input.RoleMappings {
+		if mapping.Value == "" || len(mapping.Value) > 1000 {
+			return nil, NewUserError("Role mapping values must be between 1 and 1000 characters.")
+		} else if !mapping.Role.IsValid() {
+			return nil, NewUserError("Invalid role mapping role.")
+		}
+	}
+
+	domains, userErr := normalizeSSOEmailDomains(input.AllowedEmailDomains)
+	if userErr != nil {
+		return nil, userErr
+	}
+
+	metadata, err := parseSAMLIdPMetadata(input.IdPMetadataXML)
+	if err != nil {
+		return nil, NewUserError("Unable to parse the identity provider metadata.")
+	} else if metadata.EntityID == "" || len(metadata.IDPSSODescriptors) == 0 {
+		return nil, NewUserError("The metadata doesn't describe an identity provider.")
+	} else if samlIdPSigningCertificateCount(metadata) == 0 {
+		return nil, NewUserError("The identity provider metadata must include a signing certificate.")
+	}
+
+	before, err := s.app.store.GetTeamSAMLConfigurationByTeamId(ctx, input.TeamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	now := time.Now()
+	config := &model.TeamSAMLConfiguration{
+		TeamId:              input.TeamId,
+		CreationTime:        now,
+		UpdateTime:          now,
+		IdPMetadataXML:      input.IdPMetadataXML,
+		IdPEntityId:         metadata.EntityID,
+		EmailAttribute:      input.EmailAttribute,
+		RoleAttribute:       input.RoleAttribute,
+		RoleMappings:        input.RoleMappings,
+		AllowedEmailDomains: domains,
+		DefaultRole:         input.DefaultRole,
+		AllowIdPInitiated:   input.AllowIdPInitiated,
+	}
+	if before != nil {
+		config.CreationTime = before.CreationTime
+	}
+
+	if s.app.samlServiceProvider(config, metadata).GetSSOBindingLocation(saml.HTTPRedirectBinding) == "" {
+		return nil, NewUserError("The identity provider must support the HTTP-Redirect binding.")
+	}
+
+	if err := s.app.store.PutTeamSAMLConfiguration(ctx, config); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	var beforeValue any
+	if before != nil {
+		beforeValue = teamSAMLConfigurationAuditValue(before)
+	}
+	s.recordAuditEvent(ctx, config.TeamId, model.AuditEventActionTeamSAMLConfigurationUpdate, config.TeamId.String(), beforeValue, teamSAMLConfigurationAuditValue(config))
+	return s.app.teamSAMLConfiguration(config), nil
+}
+
+func (s *Session) GetTeamSAMLConfigurationByTeamId(ctx context.Context, teamId model.Id) (*TeamSAMLConfiguration, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return nil, err
+	}
+
+	config, err := s.app.store.GetTeamSAMLConfigurationByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NotFoundError("SAML single sign-on is not configured for this team.")
+	}
+	return s.app.teamSAMLConfiguration(config), nil
+}
+
+func (s *Session) DeleteTeamSAMLConfigurationByTeamId(ctx context.Context, teamId model.Id) UserFacingError {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return err
+	}
+
+	config, err := s.app.store.GetTeamSAMLConfigurationByTeamId(ctx, teamId)
+	if err != nil || config == nil {
+		return s.SanitizedError(err)
+	} else if err := s.app.store.DeleteTeamSAMLConfigurationByTeamId(ctx, teamId); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSAMLConfigurationDelete, teamId.String(), teamSAMLConfigurationAuditValue(config), nil)
+	return nil
+}
+
+// Gets the service provider metadata that teams can give to their identity providers. This is
+// public, since identity providers may fetch it.
+func (s *Session) GetTeamSAMLServiceProviderMetadata(ctx context.Context, teamId model.Id) ([]byte, UserFacingError) {
+	config, err := s.app.store.GetTeamSAMLConfigurationByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NotFoundError("SAML single sign-on is not configured for this team.")
+	}
+
+	metadata := s.app.samlServiceProvider(config, nil).Metadata()
+	// We only accept assertions via the HTTP-POST binding.
+	for i := range metadata.SPSSODescriptors {
+		descriptor := &metadata.SPSSODescriptors[i]
+		descriptor.AssertionConsumerServices = descriptor.AssertionConsumerServices[:1]
+	}
+
+	buf, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	return buf, nil
+}
+
+type BeginTeamSAMLAuthenticationOutput struct {
+	// The identity provider URL that the user should be sent to. After signing in, the identity
+	// provider posts to the assertion consumer service, which redirects the user to the frontend.
+	AuthenticationURL string
+}
+
+// Begins SP-initiated sign-in with a team's SAML identity provider using the HTTP-Redirect binding.
+func (s *Session) BeginTeamSAMLAuthentication(ctx context.Context, teamId model.Id) (*BeginTeamSAMLAuthenticationOutput, UserFacingError) {
+	config, err := s.app.store.GetTeamSAMLConfigurationByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NotFoundError("SAML single sign-on is not configured for this team.")
+	}
+
+	metadata, err := parseSAMLIdPMetadata(config.IdPMetadataXML)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to parse saml metadata: %w", err))
+	}
+	sp := s.app.samlServiceProvider(config, metadata)
+	request, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to make saml authentication request: %w", err))
+	}
+
+	session := &model.SAMLAuthenticationSession{
+		Id:             model.NewSAMLAuthenticationSessionId(),
+		TeamId:         teamId,
+		ExpirationTime: time.Now().Add(samlAuthenticationSessionDuration),
+		RequestId:      request.ID,
+	}
+	if s.user != nil {
+		session.LinkUserId = s.user.Id
+	}
+	if err := s.app.store.PutSAMLAuthenticationSession(ctx, session); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	authenticationURL, err := request.Redirect(session.Id.String(), sp)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to make saml redirect: %w", err))
+	}
+
+	return &BeginTeamSAMLAuthenticationOutput{
+		AuthenticationURL: authenticationURL.String(),
+	}, nil
+}
+
+type CompleteTeamSAMLAuthenticationInput struct {
+	TeamId model.Id
+
+	// The base64-encoded response that the identity provider posted.
+	SAMLResponse string
+
+	// The relay state that the identity provider posted. For SP-initiated sign-in, this is the
+	// authentication session id.
+	RelayState string
+}
+
+type CompleteTeamSAMLAuthenticationOutput struct {
+	// The frontend URL that the user should be redirected to. On success, it contains a short-lived
+	// token that the frontend exchanges for an access token, just like an email sign-in link. On
+	// failure, it contains an error message.
+	RedirectURL string
+}
+
+// Completes sign-in with a team's SAML identity provider. Since identity providers post responses
+// directly to the API, errors are reported to the user via the redirect URL rather than returned.
+func (s *Session) CompleteTeamSAMLAuthentication(ctx context.Context, input CompleteTeamSAMLAuthenticationInput) *CompleteTeamSAMLAuthenticationOutput {
+	redirectURL := func(key, value string) string {
+		return strings.TrimSuffix(s.app.config.FrontendURL, "/") + samlSignInPath + "#" + url.Values{key: {value}}.Encode()
+	}
+
+	user, userErr := s.authenticateTeamSAMLUser(ctx, input)
+	if userErr != nil {
+		return &CompleteTeamSAMLAuthenticationOutput{
+			RedirectURL: redirectURL("error", userErr.UserFacingError()),
+		}
+	}
+
+	token, err := s.app.createUserEmailAuthenticationToken(ctx, user.Id, input.TeamId, samlSignInTokenDuration)
+	if err != nil {
+		return &CompleteTeamSAMLAuthenticationOutput{
+			RedirectURL: redirectURL("error", s.SanitizedError(err).UserFacingError()),
+		}
+	}
+
+	return &CompleteTeamSAMLAuthenticationOutput{
+		RedirectURL: redirectURL("token", base64.RawURLEncoding.EncodeToString(token)),
+	}
+}
+
+// Validates the identity provider's response and returns the user that was authenticated. Users are
+// created and linked the same way as with OpenID Connect. Only SP-initiated sign-in can link
+// existing accounts, since IdP-initiated sign-in can't be tied to a signed-in user.
+func (s *Session) authenticateTeamSAMLUser(ctx context.Context, input CompleteTeamSAMLAuthenticationInput) (*model.User, UserFacingError) {
+	config, err := s.app.store.GetTeamSAMLConfigurationByTeamId(ctx, input.TeamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if config == nil {
+		return nil, NewUserError("SAML single sign-on is not configured for this team.")
+	}
+
+	var possibleRequestIds []string
+	var linkUserId model.Id
+	if strings.HasPrefix(input.RelayState, "sas-") {
+		session, err := s.app.store.GetSAMLAuthenticationSessionById(ctx, model.Id(input.RelayState))
+		if err != nil {
+			return nil, s.SanitizedError(err)
+		} else if session == nil || session.TeamId != config.TeamId || session.ExpirationTime.Before(time.Now()) {
+			return nil, NewUserError("Session expired. Please try again.")
+		} else if err := s.app.store.DeleteSAMLAuthenticationSessionById(ctx, session.Id); err != nil {
+			return nil, s.SanitizedError(err)
+		}
+		possibleRequestIds = []string{session.RequestId}
+		linkUserId = session.LinkUserId
+	} else if !config.AllowIdPInitiated {
+		return nil, NewUserError("Please begin signing in from CloudSnitch.")
+	}
+
+	metadata, err := parseSAMLIdPMetadata(config.IdPMetadataXML)
+	if err != nil {
+		return nil, s.SanitizedError(fmt.Errorf("unable to parse saml metadata: %w", err))
+	}
+	sp := s.app.samlServiceProvider(config, metadata)
+	// Responses to our own requests must reference them even if IdP-initiated sign-in is allowed.
+	sp.AllowIDPInitiated = possibleRequestIds == nil
+
+	rawResponse, err := base64.StdEncoding.DecodeString(input.SAMLResponse)
+	if err != nil {
+		return nil, NewUserError("Invalid SAML response.")
+	}
+	assertion, err := sp.ParseXMLResponse(rawResponse, possibleRequestIds, sp.AcsURL)
+	if err != nil {
+		if invalid, ok := err.(*saml.InvalidResponseError); ok {
+			err = invalid.PrivateErr
+		}
+		s.Logger().Info("saml authentication failed", zap.Error(err), zap.String("team_id", config.TeamId.String()))
+		return nil, NewUserError("Unable to sign in with the team's identity provider. Please try again.")
+	} else if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		return nil, NewUserError("The identity provider didn't identify the user.")
+	}
+
+	expirationTime := assertion.IssueInstant.Add(saml.MaxIssueDelay)
+	if assertion.Conditions != nil && assertion.Conditions.NotOnOrAfter.After(expirationTime) {
+		expirationTime = assertion.Conditions.NotOnOrAfter
+	}
+	if created, err := s.app.store.CreateSAMLAssertionUse(ctx, &model.SAMLAssertionUse{
+		TeamId:         config.TeamId,
+		AssertionId:    assertion.ID,
+		ExpirationTime: expirationTime.Add(saml.MaxClockSkew),
+	}); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if !created {
+		return nil, NewUserError("This sign-in has already been used. Please try again.")
+	}
+
+	emailAddress := assertion.Subject.NameID.Value
+	if config.EmailAttribute != "" {
+		values := samlAttributeValues(assertion, config.EmailAttribute)
+		if len(values) == 0 {
+			return nil, NewUserError("The identity provider didn't provide an email address.")
+		}
+		emailAddress = values[0]
+	}
+	if err := ValidateEmailAddress(emailAddress); err != nil {
+		return nil, NewUserError("The identity provider didn't provide a valid email address.")
+	} else if !isSSOEmailDomainAllowed(emailAddress, config.AllowedEmailDomains) {
+		return nil, NewUserError("Your email address isn't allowed to sign in with this identity provider.")
+	}
+
+	user, userErr := s.getOrCreateSAMLUser(ctx, config, linkUserId, assertion.Subject.NameID, emailAddress)
+	if userErr != nil {
+		return nil, userErr
+	}
+
+	if role := mapSAMLRole(config, assertion); role != model.TeamMembershipRoleNone {
+		s.setSAMLTeamRole(ctx, config.TeamId, user, role)
+	} else if config.DefaultRole != model.TeamMembershipRoleNone {
+		s.autoJoinSSOTeam(ctx, config.TeamId, user, config.DefaultRole)
+	}
+
+	return user, nil
+}
+
+// Returns the values of the attributes with the given name or friendly name.
+func samlAttributeValues(assertion *saml.Assertion, name string) []string {
+	var ret []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			if attribute.Name == name || attribute.FriendlyName == name {
+				for _, value := range attribute.Values {
+					ret = append(ret, value.Value)
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// Returns the most privileged role that the assertion's role attribute maps to, if any.
+func mapSAMLRole(config *model.TeamSAMLConfiguration, assertion *saml.Assertion) model.TeamMembershipRole {
+	if config.RoleAttribute == "" {
+		return model.TeamMembershipRoleNone
+	}
+	ret := model.TeamMembershipRoleNone
+	for _, value := range samlAttributeValues(assertion, config.RoleAttribute) {
+		for _, mapping := range config.RoleMappings {
+			if mapping.Value == value && mapping.Role.IsMorePrivilegedThan(ret) {
+				ret = mapping.Role
+			}
+		}
+	}
+	return ret
+}
+
+func (s *Session) getOrCreateSAMLUser(ctx context.Context, config *model.TeamSAMLConfiguration, linkUserId model.Id, nameId *saml.NameID, emailAddress string) (*model.User, UserFacingError) {
+	// Transient name ids change every time the user signs in, so they can't be linked.
+	persistent := nameId.Format != string(saml.TransientNameIDFormat)
+
+	if persistent {
+		if identity, err := s.app.store.GetUserSAMLIdentity(ctx, config.TeamId, config.IdPEntityId, nameId.Value); err != nil {
+			return nil, s.SanitizedError(err)
+		} else if identity != nil {
+			if linkUserId != "" && identity.UserId != linkUserId {
+				return nil, NewUserError("This identity is already linked to another account.")
+			} else if user, err := s.app.store.GetUserById(ctx, identity.UserId, store.ConsistencyStrongInRegion); err != nil {
+				return nil, s.SanitizedError(err)
+			} else if user != nil {
+				return user, nil
+			}
+		}
+	}
+
+	user, userErr := s.getOrCreateSSOUser(ctx, config.TeamId, emailAddress, linkUserId)
+	if userErr != nil {
+		return nil, userErr
+	}
+
+	if persistent {
+		if err := s.app.store.PutUserSAMLIdentity(ctx, &model.UserSAMLIdentity{
+			TeamId:       config.TeamId,
+			IdPEntityId:  config.IdPEntityId,
+			NameId:       nameId.Value,
+			UserId:       user.Id,
+			CreationTime: time.Now(),
+		}); err != nil {
+			return nil, s.SanitizedError(err)
+		}
+	}
+	return user, nil
+}
+
+// Gives the user the role mapped from their SAML attributes, adding them to the team if necessary.
+// Like auto-joins, failures are logged rather than returned.
+func (s *Session) setSAMLTeamRole(ctx context.Context, teamId model.Id, user *model.User, role model.TeamMembershipRole) {
+	logger := s.Logger().With(zap.String("team_id", teamId.String()), zap.String("user_id", user.Id.String()))
+	role = role.Migrated()
+
+	before, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, user.Id)
+	if err != nil {
+		logger.Error("unable to get team membership for saml role mapping", zap.Error(err))
+		return
+	} else if before == nil {
+		s.autoJoinSSOTeam(ctx, teamId, user, role)
+		return
+	} else if before.Role == role {
+		return
+	}
+
+	membership, err := s.app.store.PatchTeamMembershipByTeamAndUserId(ctx, teamId, user.Id, &store.TeamMembershipPatch{
+		Role: &role,
+	})
+	if err != nil {
+		logger.Error("unable to update team membership for saml role mapping", zap.Error(err))
+		return
+	}
+
+	userSess := *s
+	userSess.user = user
+	userSess.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipUpdate, user.Id.String(), before, membership)
+}
@@ -0,0 +1,278 @@
+package app_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamSAML(t *testing.T) {
+	a := apptest.NewTestApp(t)
+	idp := apptest.NewFakeSAMLIdentityProvider(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(aliceSess, app.TeamSubscriptionTierTeam)
+
+	carol, carolSess := a.NewTestUser("carol@example.com", model.UserRoleCustomer)
+
+	// Exchanges the redirect URL for a session the same way the frontend does.
+	completeSignIn := func(output *app.CompleteTeamSAMLAuthenticationOutput) (*app.Session, string) {
+		u, err := url.Parse(output.RedirectURL)
+		require.NoError(t, err)
+		require.Equal(t, "/complete-email-signin", u.Path)
+		fragment, err := url.ParseQuery(u.Fragment)
+		require.NoError(t, err)
+		if message := fragment.Get("error"); message != "" {
+			return nil, message
+		}
+		token, err := base64.RawURLEncoding.DecodeString(fragment.Get("token"))
+		require.NoError(t, err)
+		sess, userErr := a.NewAnonymousSession().WithUserEmailAuthentication(context.Background(), token, "")
+		require.NoError(t, userErr)
+		require.NotNil(t, sess)
+		return sess, ""
+	}
+
+	// Begins sign-in with the given session, which links the account if it's signed in.
+	signInFrom := func(beginSess *app.Session, user apptest.FakeSAMLUser) (*app.Session, string) {
+		output, err := beginSess.BeginTeamSAMLAuthentication(context.Background(), team.Id)
+		require.NoError(t, err)
+		samlResponse, relayState := idp.Authenticate(output.AuthenticationURL, user)
+		return completeSignIn(a.NewAnonymousSession().CompleteTeamSAMLAuthentication(context.Background(), app.CompleteTeamSAMLAuthenticationInput{
+			TeamId:       team.Id,
+			SAMLResponse: samlResponse,
+			RelayState:   relayState,
+		}))
+	}
+	signIn := func(user apptest.FakeSAMLUser) (*app.Session, string) {
+		return signInFrom(a.NewAnonymousSession(), user)
+	}
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		_, err := a.NewAnonymousSession().BeginTeamSAMLAuthentication(context.Background(), team.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+
+		_, err = aliceSess.GetTeamSAMLConfigurationByTeamId(context.Background(), team.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+
+	input := app.PutTeamSAMLConfigurationInput{
+		TeamId:         team.Id,
+		IdPMetadataXML: idp.MetadataXML(),
+		EmailAttribute: "email",
+		RoleAttribute:  "role",
+		RoleMappings: []model.TeamSAMLRoleMapping{
+			{Value: "admins", Role: model.TeamMembershipRoleAdministrator},
+			{Value: "engineers", Role: model.TeamMembershipRoleAnalyst},
+		},
+		AllowedEmailDomains: []string{"Example.com"},
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		for name, modify := range map[string]func(*app.PutTeamSAMLConfigurationInput){
+			"NoMetadata":      func(input *app.PutTeamSAMLConfigurationInput) { input.IdPMetadataXML = "" },
+			"InvalidMetadata": func(input *app.PutTeamSAMLConfigurationInput) { input.IdPMetadataXML = "<EntityDescriptor" },
+			"NoCertificate": func(input *app.PutTeamSAMLConfigurationInput) {
+				input.IdPMetadataXML = strings.ReplaceAll(input.IdPMetadataXML, "X509Certificate", "Foo")
+			},
+			"NoDomains":       func(input *app.PutTeamSAMLConfigurationInput) { input.AllowedEmailDomains = nil },
+			"InvalidRole":     func(input *app.PutTeamSAMLConfigurationInput) { input.DefaultRole = "owner" },
+			"NoRoleAttribute": func(input *app.PutTeamSAMLConfigurationInput) { input.RoleAttribute = "" },
+			"EmptyMappingValue": func(input *app.PutTeamSAMLConfigurationInput) {
+				input.RoleMappings = []model.TeamSAMLRoleMapping{{Role: model.TeamMembershipRoleAnalyst}}
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				invalid := input
+				modify(&invalid)
+				_, err := aliceSess.PutTeamSAMLConfiguration(context.Background(), invalid)
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := a.NewAnonymousSession().PutTeamSAMLConfiguration(context.Background(), input)
+		assert.Error(t, err)
+	})
+
+	config, err := aliceSess.PutTeamSAMLConfiguration(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, apptest.FakeSAMLIdentityProviderEntityId, config.IdPEntityId)
+	assert.Equal(t, []string{"example.com"}, config.AllowedEmailDomains)
+	assert.Contains(t, config.AssertionConsumerServiceURL, team.Id.String())
+
+	t.Run("Metadata", func(t *testing.T) {
+		metadata, err := a.NewAnonymousSession().GetTeamSAMLServiceProviderMetadata(context.Background(), team.Id)
+		require.NoError(t, err)
+		assert.Contains(t, string(metadata), config.ServiceProviderEntityId)
+		assert.Contains(t, string(metadata), config.AssertionConsumerServiceURL)
+	})
+
+	t.Run("NewUserWithoutRole", func(t *testing.T) {
+		sess, message := signIn(apptest.FakeSAMLUser{
+			NameId:       "bob",
+			EmailAddress: "bob@example.com",
+		})
+		require.Empty(t, message)
+		assert.Equal(t, "bob@example.com", sess.User().EmailAddress)
+
+		// There's no default role, so bob isn't added to the team.
+		membership, err := sess.GetTeamMembershipByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		assert.Nil(t, membership)
+	})
+
+	t.Run("RoleMapping", func(t *testing.T) {
+		sess, message := signIn(apptest.FakeSAMLUser{
+			NameId:       "dave",
+			EmailAddress: "dave@example.com",
+			Roles:        []string{"engineers"},
+		})
+		require.Empty(t, message)
+
+		membership, err := sess.GetTeamMembershipByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, model.TeamMembershipRoleAnalyst, membership.Role)
+
+		// The most privileged mapped role wins, and roles are updated on each sign-in.
+		again, message := signIn(apptest.FakeSAMLUser{
+			NameId:       "dave",
+			EmailAddress: "dave@example.com",
+			Roles:        []string{"engineers", "admins"},
+		})
+		require.Empty(t, message)
+		assert.Equal(t, sess.User().Id, again.User().Id)
+
+		membership, err = sess.GetTeamMembershipByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membership.Role)
+	})
+
+	t.Run("ExistingUser", func(t *testing.T) {
+		aliceIdentity := apptest.FakeSAMLUser{
+			NameId:       "alice",
+			EmailAddress: "alice@example.com",
+		}
+
+		// Even team members have to link their accounts first.
+		_, message := signIn(aliceIdentity)
+		assert.NotEmpty(t, message)
+
+		sess, message := signInFrom(aliceSess, aliceIdentity)
+		require.Empty(t, message)
+		assert.Equal(t, alice.Id, sess.User().Id)
+
+		sess, message = signIn(aliceIdentity)
+		require.Empty(t, message)
+		assert.Equal(t, alice.Id, sess.User().Id)
+	})
+
+	t.Run("ExistingNonMember", func(t *testing.T) {
+		_, message := signIn(apptest.FakeSAMLUser{
+			NameId:       "carol",
+			EmailAddress: carol.EmailAddress,
+		})
+		assert.NotEmpty(t, message)
+	})
+
+	t.Run("LinkMismatch", func(t *testing.T) {
+		_, message := signInFrom(carolSess, apptest.FakeSAMLUser{
+			NameId:       "frank",
+			EmailAddress: "frank@example.com",
+		})
+		assert.NotEmpty(t, message)
+
+		_, message = signInFrom(carolSess, apptest.FakeSAMLUser{
+			NameId:       "alice",
+			EmailAddress: carol.EmailAddress,
+		})
+		assert.NotEmpty(t, message)
+	})
+
+	t.Run("DisallowedDomain", func(t *testing.T) {
+		_, message := signIn(apptest.FakeSAMLUser{
+			NameId:       "erin",
+			EmailAddress: "erin@example.org",
+		})
+		assert.NotEmpty(t, message)
+	})
+
+	t.Run("UntrustedSignature", func(t *testing.T) {
+		output, err := a.NewAnonymousSession().BeginTeamSAMLAuthentication(context.Background(), team.Id)
+		require.NoError(t, err)
+		samlResponse, relayState := apptest.NewFakeSAMLIdentityProvider(t).Authenticate(output.AuthenticationURL, apptest.FakeSAMLUser{
+			NameId:       "alice",
+			EmailAddress: "alice@example.com",
+		})
+		_, message := completeSignIn(a.NewAnonymousSession().CompleteTeamSAMLAuthentication(context.Background(), app.CompleteTeamSAMLAuthenticationInput{
+			TeamId:       team.Id,
+			SAMLResponse: samlResponse,
+			RelayState:   relayState,
+		}))
+		assert.NotEmpty(t, message)
+	})
+
+	t.Run("SessionReuse", func(t *testing.T) {
+		output, err := a.NewAnonymousSession().BeginTeamSAMLAuthentication(context.Background(), team.Id)
+		require.NoError(t, err)
+		samlResponse, relayState := idp.Authenticate(output.AuthenticationURL, apptest.FakeSAMLUser{
+			NameId:       "alice",
+			EmailAddress: "alice@example.com",
+		})
+		complete := func() string {
+			_, message := completeSignIn(a.NewAnonymousSession().CompleteTeamSAMLAuthentication(context.Background(), app.CompleteTeamSAMLAuthenticationInput{
+				TeamId:       team.Id,
+				SAMLResponse: samlResponse,
+				RelayState:   relayState,
+			}))
+			return message
+		}
+		assert.Empty(t, complete())
+		assert.NotEmpty(t, complete())
+	})
+
+	t.Run("IdPInitiated", func(t *testing.T) {
+		samlResponse := idp.Initiate(config.ServiceProviderEntityId, config.AssertionConsumerServiceURL, apptest.FakeSAMLUser{
+			NameId:       "alice",
+			EmailAddress: "alice@example.com",
+		})
+		complete := func() string {
+			_, message := completeSignIn(a.NewAnonymousSession().CompleteTeamSAMLAuthentication(context.Background(), app.CompleteTeamSAMLAuthenticationInput{
+				TeamId:       team.Id,
+				SAMLResponse: samlResponse,
+			}))
+			return message
+		}
+
+		assert.NotEmpty(t, complete())
+
+		update := input
+		update.AllowIdPInitiated = true
+		_, err := aliceSess.PutTeamSAMLConfiguration(context.Background(), update)
+		require.NoError(t, err)
+
+		assert.Empty(t, complete())
+
+		// Assertions can't be replayed.
+		assert.NotEmpty(t, complete())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, aliceSess.DeleteTeamSAMLConfigurationByTeamId(context.Background(), team.Id))
+
+		_, err := a.NewAnonymousSession().BeginTeamSAMLAuthentication(context.Background(), team.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+}
@@ -0,0 +1,618 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const (
+	maxTeamSCIMGroupsPerTeam = 100
+	maxTeamSCIMGroupMembers  = 5000
+)
+
+// SCIM provisioning is done by the team's identity provider using an API key with the SCIM scope.
+func (s *Session) requireTeamSCIMProvisioning(ctx context.Context, teamId model.Id) UserFacingError {
+	if err := s.requireTeamAPIKeyScope(teamId, model.TeamAPIKeyScopeManageSCIM); err != nil {
+		return err
+	} else if team, err := s.app.store.GetTeamById(ctx, teamId, store.ConsistencyEventual); err != nil {
+		return s.SanitizedError(err)
+	} else if team == nil {
+		return NotFoundError("Team not found.")
+	} else if !team.Entitlements.TeamFeatures {
+		return NewUserError("Please upgrade your subscription to provision team members.")
+	}
+	return nil
+}
+
+func validateTeamSCIMGroupDisplayName(displayName string) UserFacingError {
+	if displayName == "" {
+		return NewUserError("A display name is required.")
+	} else if len(displayName) > 250 {
+		return NewUserError("Display names must be 250 characters or less.")
+	}
+	return nil
+}
+
+func validateTeamSCIMExternalId(externalId string) UserFacingError {
+	if len(externalId) > 1000 {
+		return NewUserError("External ids must be 1000 characters or less.")
+	}
+	return nil
+}
+
+// Returns the role that an active SCIM user should have given the team's groups. Users are
+// analysts unless one of their groups grants a different role, in which case they get the most
+// privileged role granted by their groups. Until some group grants the administrator role, existing
+// members keep their roles so that provisioning doesn't lock administrators out of the team. In
+// that case, false is returned.
+func teamSCIMUserRole(userId model.Id, groups []*model.TeamSCIMGroup) (model.TeamMembershipRole, bool) {
+	managed := false
+	ret := model.TeamMembershipRoleNone
+	for _, group := range groups {
+		if group.Role == model.TeamMembershipRoleAdministrator {
+			managed = true
+		}
+		if group.Role.IsMorePrivilegedThan(ret) && slices.Contains(group.MemberIds, userId) {
+			ret = group.Role.Migrated()
+		}
+	}
+	if ret == model.TeamMembershipRoleNone {
+		ret = model.TeamMembershipRoleAnalyst
+	}
+	return ret, managed
+}
+
+// Makes the given users' team memberships reflect their SCIM state. Users who are inactive or no
+// longer provisioned are removed from the team, and active users are given the role determined by
+// their groups.
+func (s *Session) syncTeamSCIMMemberships(ctx context.Context, teamId model.Id, userIds ...model.Id) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	groups, err := s.app.store.GetTeamSCIMGroupsByTeamId(ctx, teamId)
+	if err != nil {
+		return fmt.Errorf("unable to get scim groups: %w", err)
+	}
+
+	for _, userId := range userIds {
+		scimUser, err := s.app.store.GetTeamSCIMUserByTeamAndUserId(ctx, teamId, userId)
+		if err != nil {
+			return fmt.Errorf("unable to get scim user: %w", err)
+		}
+
+		membership, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, userId)
+		if err != nil {
+			return fmt.Errorf("unable to get team membership: %w", err)
+		}
+
+		if scimUser == nil || !scimUser.Active {
+			if membership != nil {
+				if ok, err := s.keepTeamSCIMAdministrator(ctx, membership); err != nil {
+					return err
+				} else if ok {
+					continue
+				}
+				if err := s.app.store.DeleteTeamMembershipByTeamAndUserId(ctx, teamId, userId); err != nil {
+					return fmt.Errorf("unable to delete team membership: %w", err)
+				}
+				s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipDelete, userId.String(), membership, nil)
+			}
+			continue
+		}
+
+		role, managed := teamSCIMUserRole(userId, groups)
+		if membership == nil {
+			membership := &model.TeamMembership{
+				TeamId:       teamId,
+				UserId:       userId,
+				Role:         role,
+				CreationTime: time.Now(),
+			}
+			if err := s.app.store.PutTeamMembership(ctx, membership); err != nil {
+				return fmt.Errorf("unable to put team membership: %w", err)
+			}
+			s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipCreate, userId.String(), nil, membership)
+		} else if managed && membership.Role != role {
+			if ok, err := s.keepTeamSCIMAdministrator(ctx, membership); err != nil {
+				return err
+			} else if ok {
+				continue
+			}
+			updated, err := s.app.store.PatchTeamMembershipByTeamAndUserId(ctx, teamId, userId, &store.TeamMembershipPatch{
+				Role: &role,
+			})
+			if err != nil {
+				return fmt.Errorf("unable to update team membership: %w", err)
+			} else if updated != nil {
+				s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamMembershipUpdate, userId.String(), membership, updated)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns true if the membership must be left alone because it's the team's last administrator.
+// Like teamSCIMUserRole, this keeps provisioning from locking administrators out of the team.
+func (s *Session) keepTeamSCIMAdministrator(ctx context.Context, membership *model.TeamMembership) (bool, error) {
+	if membership.Role.Migrated() != model.TeamMembershipRoleAdministrator {
+		return false, nil
+	}
+	memberships, err := s.app.store.GetTeamMembershipsByTeamId(ctx, membership.TeamId)
+	if err != nil {
+		return false, fmt.Errorf("unable to get team memberships: %w", err)
+	}
+	for _, other := range memberships {
+		if other.UserId != membership.UserId && other.Role.Migrated() == model.TeamMembershipRoleAdministrator {
+			return false, nil
+		}
+	}
+	s.Logger().Info("keeping the team's last administrator despite scim provisioning", zap.String("team_id", membership.TeamId.String()), zap.String("user_id", membership.UserId.String()))
+	return true, nil
+}
+
+type CreateTeamSCIMUserInput struct {
+	TeamId     model.Id
+	ExternalId string
+
+	// This must be the user's email address.
+	UserName string
+
+	Active bool
+}
+
+// Provisions a user into the team. If no account exists for the user name, one is created in one
+// of the team's single sign-on domains.
+func (s *Session) CreateTeamSCIMUser(ctx context.Context, input CreateTeamSCIMUserInput) (*model.TeamSCIMUser, UserFacingError) {
+	if err := s.requireTeamSCIMProvisioning(ctx, input.TeamId); err != nil {
+		return nil, err
+	} else if err := ValidateEmailAddress(input.UserName); err != nil {
+		return nil, err
+	} else if err := validateTeamSCIMExternalId(input.ExternalId); err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.app.store.GetTeamSCIMUsersByTeamId(ctx, input.TeamId); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if slices.ContainsFunc(existing, func(existing *model.TeamSCIMUser) bool {
+		return strings.EqualFold(existing.UserName, input.UserName)
+	}) {
+		return nil, NewUserError("A user with this user name has already been provisioned.")
+	}
+
+	user, ufErr := s.getOrCreateTeamSCIMAccount(ctx, input.TeamId, input.UserName)
+	if ufErr != nil {
+		return nil, ufErr
+	}
+
+	if existing, err := s.app.store.GetTeamSCIMUserByTeamAndUserId(ctx, input.TeamId, user.Id); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if existing != nil {
+		return nil, NewUserError("A user with this user name has already been provisioned.")
+	}
+
+	now := time.Now()
+	scimUser := &model.TeamSCIMUser{
+		TeamId:       input.TeamId,
+		UserId:       user.Id,
+		CreationTime: now,
+		UpdateTime:   now,
+		ExternalId:   input.ExternalId,
+		UserName:     input.UserName,
+		Active:       input.Active,
+	}
+	if err := s.app.store.PutTeamSCIMUser(ctx, scimUser); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, scimUser.TeamId, model.AuditEventActionTeamSCIMUserCreate, scimUser.UserId.String(), nil, scimUser)
+
+	if err := s.syncTeamSCIMMemberships(ctx, scimUser.TeamId, scimUser.UserId); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	return scimUser, nil
+}
+
+// Gets the account for a SCIM user name, creating it if necessary. The team's identity provider can
+// sign in as the accounts it creates, so new accounts are limited to the team's single sign-on
+// email domains. Existing accounts must already be team members or be linked to the team's identity
+// provider, so provisioning can't be used to attach the team to arbitrary accounts.
+func (s *Session) getOrCreateTeamSCIMAccount(ctx context.Context, teamId model.Id, userName string) (*model.User, UserFacingError) {
+	user, err := s.app.getUserByEmailAddress(ctx, userName)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if user != nil {
+		if slices.Contains(user.SSOTeamIds, teamId) {
+			return user, nil
+		} else if membership, err := s.app.store.GetTeamMembershipByTeamAndUserId(ctx, teamId, user.Id); err != nil {
+			return nil, s.SanitizedError(err)
+		} else if membership == nil {
+			return nil, NewUserError("An account with this user name already exists. It can be provisioned once its user joins the team.")
+		}
+		return user, nil
+	}
+
+	domains, err := s.app.teamSSOAllowedEmailDomains(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if !isSSOEmailDomainAllowed(userName, domains) {
+		return nil, NewUserError("User names must be email addresses in one of the team's single sign-on domains.")
+	}
+	return s.getOrCreateSSOUser(ctx, teamId, userName, "")
+}
+
+// Returns the email domains allowed by the team's identity providers.
+func (a *App) teamSSOAllowedEmailDomains(ctx context.Context, teamId model.Id) ([]string, error) {
+	var ret []string
+	if config, err := a.store.GetTeamOIDCConfigurationByTeamId(ctx, teamId); err != nil {
+		return nil, fmt.Errorf("unable to get oidc configuration: %w", err)
+	} else if config != nil {
+		ret = append(ret, config.AllowedEmailDomains...)
+	}
+	if config, err := a.store.GetTeamSAMLConfigurationByTeamId(ctx, teamId); err != nil {
+		return nil, fmt.Errorf("unable to get saml configuration: %w", err)
+	} else if config != nil {
+		ret = append(ret, config.AllowedEmailDomains...)
+	}
+	return ret, nil
+}
+
+func (s *Session) GetTeamSCIMUserByTeamAndUserId(ctx context.Context, teamId, userId model.Id) (*model.TeamSCIMUser, UserFacingError) {
+	if err := s.requireTeamSCIMProvisioning(ctx, teamId); err != nil {
+		return nil, err
+	}
+
+	if scimUser, err := s.app.store.GetTeamSCIMUserByTeamAndUserId(ctx, teamId, userId); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if scimUser == nil {
+		return nil, NotFoundError("User not found.")
+	} else {
+		return scimUser, nil
+	}
+}
+
+// Gets all of the users provisioned into the team, ordered by id.
+func (s *Session) GetTeamSCIMUsersByTeamId(ctx context.Context, teamId model.Id) ([]*model.TeamSCIMUser, UserFacingError) {
+	if err := s.requireTeamSCIMProvisioning(ctx, teamId); err != nil {
+		return nil, err
+	}
+
+	users, err := s.app.store.GetTeamSCIMUsersByTeamId(ctx, teamId)
+	return users, s.SanitizedError(err)
+}
+
+type TeamSCIMUserPatch struct {
+	ExternalId *string
+
+	// User names can't be changed, since they're tied to the user's account. However, they may be
+	// replaced with a value that differs only in case.
+	UserName *string
+
+	Active *bool
+}
+
+// Updates a provisioned user. Deactivating a user removes them from the team.
+func (s *Session) PatchTeamSCIMUserByTeamAndUserId(ctx context.Context, teamId, userId model.Id, patch TeamSCIMUserPatch) (*model.TeamSCIMUser, UserFacingError) {
+	before, err := s.GetTeamSCIMUserByTeamAndUserId(ctx, teamId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	scimUser := *before
+	if patch.ExternalId != nil {
+		if err := validateTeamSCIMExternalId(*patch.ExternalId); err != nil {
+			return nil, err
+		}
+		scimUser.ExternalId = *patch.ExternalId
+	}
+	if patch.UserName != nil {
+		if !strings.EqualFold(*patch.UserName, scimUser.UserName) {
+			return nil, NewUserError("User names can't be changed.")
+		}
+		scimUser.UserName = *patch.UserName
+	}
+	if patch.Active != nil {
+		scimUser.Active = *patch.Active
+	}
+	scimUser.UpdateTime = time.Now()
+
+	if err := s.app.store.PutTeamSCIMUser(ctx, &scimUser); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSCIMUserUpdate, userId.String(), before, &scimUser)
+
+	if err := s.syncTeamSCIMMemberships(ctx, teamId, userId); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	return &scimUser, nil
+}
+
+// Deprovisions a user, removing them from the team and its SCIM groups. The user's account is not
+// deleted.
+func (s *Session) DeleteTeamSCIMUserByTeamAndUserId(ctx context.Context, teamId, userId model.Id) UserFacingError {
+	scimUser, ufErr := s.GetTeamSCIMUserByTeamAndUserId(ctx, teamId, userId)
+	if ufErr != nil {
+		return ufErr
+	}
+
+	groups, err := s.app.store.GetTeamSCIMGroupsByTeamId(ctx, teamId)
+	if err != nil {
+		return s.SanitizedError(err)
+	}
+	for _, group := range groups {
+		if !slices.Contains(group.MemberIds, userId) {
+			continue
+		}
+		before := *group
+		group.MemberIds = slices.DeleteFunc(slices.Clone(group.MemberIds), func(id model.Id) bool {
+			return id == userId
+		})
+		group.UpdateTime = time.Now()
+		if err := s.app.store.PutTeamSCIMGroup(ctx, group); err != nil {
+			return s.SanitizedError(err)
+		}
+		s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSCIMGroupUpdate, group.Id.String(), &before, group)
+	}
+
+	if err := s.app.store.DeleteTeamSCIMUserByTeamAndUserId(ctx, teamId, userId); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSCIMUserDelete, userId.String(), scimUser, nil)
+
+	if err := s.syncTeamSCIMMemberships(ctx, teamId, userId); err != nil {
+		return s.SanitizedError(err)
+	}
+
+	return nil
+}
+
+// Makes sure the given ids are unique ids of users provisioned into the team.
+func (s *Session) validateTeamSCIMGroupMemberIds(ctx context.Context, teamId model.Id, memberIds []model.Id) ([]model.Id, UserFacingError) {
+	ret := make([]model.Id, 0, len(memberIds))
+	for _, id := range memberIds {
+		if !slices.Contains(ret, id) {
+			ret = append(ret, id)
+		}
+	}
+	if len(ret) > maxTeamSCIMGroupMembers {
+		return nil, NewUserError(fmt.Sprintf("Groups are limited to %d members.", maxTeamSCIMGroupMembers))
+	} else if len(ret) == 0 {
+		return ret, nil
+	}
+
+	users, err := s.app.store.GetTeamSCIMUsersByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	for _, id := range ret {
+		if !slices.ContainsFunc(users, func(user *model.TeamSCIMUser) bool {
+			return user.UserId == id
+		}) {
+			return nil, NewUserError(fmt.Sprintf("User %s has not been provisioned.", id))
+		}
+	}
+	return ret, nil
+}
+
+// Display names are unique within a team since they're how administrators identify groups.
+func (s *Session) validateTeamSCIMGroupDisplayNameIsUnique(ctx context.Context, teamId, groupId model.Id, displayName string) UserFacingError {
+	groups, err := s.app.store.GetTeamSCIMGroupsByTeamId(ctx, teamId)
+	if err != nil {
+		return s.SanitizedError(err)
+	}
+	for _, group := range groups {
+		if group.Id != groupId && strings.EqualFold(group.DisplayName, displayName) {
+			return NewUserError("A group with this display name already exists.")
+		}
+	}
+	return nil
+}
+
+type CreateTeamSCIMGroupInput struct {
+	TeamId      model.Id
+	ExternalId  string
+	DisplayName string
+	MemberIds   []model.Id
+}
+
+// Provisions a group into the team. New groups don't grant any role until a team administrator
+// maps them to one.
+func (s *Session) CreateTeamSCIMGroup(ctx context.Context, input CreateTeamSCIMGroupInput) (*model.TeamSCIMGroup, UserFacingError) {
+	if err := s.requireTeamSCIMProvisioning(ctx, input.TeamId); err != nil {
+		return nil, err
+	} else if err := validateTeamSCIMGroupDisplayName(input.DisplayName); err != nil {
+		return nil, err
+	} else if err := validateTeamSCIMExternalId(input.ExternalId); err != nil {
+		return nil, err
+	} else if err := s.validateTeamSCIMGroupDisplayNameIsUnique(ctx, input.TeamId, "", input.DisplayName); err != nil {
+		return nil, err
+	}
+
+	memberIds, ufErr := s.validateTeamSCIMGroupMemberIds(ctx, input.TeamId, input.MemberIds)
+	if ufErr != nil {
+		return nil, ufErr
+	}
+
+	if existing, err := s.app.store.GetTeamSCIMGroupsByTeamId(ctx, input.TeamId); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if len(existing) >= maxTeamSCIMGroupsPerTeam {
+		return nil, NewUserError(fmt.Sprintf("Teams are limited to %d SCIM groups.", maxTeamSCIMGroupsPerTeam))
+	}
+
+	now := time.Now()
+	group := &model.TeamSCIMGroup{
+		Id:           model.NewTeamSCIMGroupId(),
+		TeamId:       input.TeamId,
+		CreationTime: now,
+		UpdateTime:   now,
+		ExternalId:   input.ExternalId,
+		DisplayName:  input.DisplayName,
+		MemberIds:    memberIds,
+	}
+	if err := s.app.store.PutTeamSCIMGroup(ctx, group); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, group.TeamId, model.AuditEventActionTeamSCIMGroupCreate, group.Id.String(), nil, group)
+
+	return group, nil
+}
+
+func (s *Session) GetTeamSCIMGroupByTeamIdAndId(ctx context.Context, teamId, id model.Id) (*model.TeamSCIMGroup, UserFacingError) {
+	if err := s.requireTeamSCIMProvisioning(ctx, teamId); err != nil {
+		return nil, err
+	}
+	return s.getTeamSCIMGroupByTeamIdAndId(ctx, teamId, id)
+}
+
+func (s *Session) getTeamSCIMGroupByTeamIdAndId(ctx context.Context, teamId, id model.Id) (*model.TeamSCIMGroup, UserFacingError) {
+	if group, err := s.app.store.GetTeamSCIMGroupByTeamIdAndId(ctx, teamId, id); err != nil {
+		return nil, s.SanitizedError(err)
+	} else if group == nil {
+		return nil, NotFoundError("Group not found.")
+	} else {
+		return group, nil
+	}
+}
+
+// Gets all of the groups provisioned into the team, ordered by id. These can be viewed by team
+// administrators as well as the identity provider.
+func (s *Session) GetTeamSCIMGroupsByTeamId(ctx context.Context, teamId model.Id) ([]*model.TeamSCIMGroup, UserFacingError) {
+	if err := s.RequireTeamAdministratorOrAPIKeyScope(ctx, teamId, model.TeamAPIKeyScopeManageSCIM); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.app.store.GetTeamSCIMGroupsByTeamId(ctx, teamId)
+	return groups, s.SanitizedError(err)
+}
+
+type TeamSCIMGroupPatch struct {
+	ExternalId  *string
+	DisplayName *string
+
+	// If given, this replaces the group's members. Additions and removals are applied afterwards.
+	MemberIds       *[]model.Id
+	AddMemberIds    []model.Id
+	RemoveMemberIds []model.Id
+}
+
+// Updates a provisioned group. The roles of affected members are updated accordingly.
+func (s *Session) PatchTeamSCIMGroupByTeamIdAndId(ctx context.Context, teamId, id model.Id, patch TeamSCIMGroupPatch) (*model.TeamSCIMGroup, UserFacingError) {
+	before, ufErr := s.GetTeamSCIMGroupByTeamIdAndId(ctx, teamId, id)
+	if ufErr != nil {
+		return nil, ufErr
+	}
+
+	group := *before
+	if patch.ExternalId != nil {
+		if err := validateTeamSCIMExternalId(*patch.ExternalId); err != nil {
+			return nil, err
+		}
+		group.ExternalId = *patch.ExternalId
+	}
+	if patch.DisplayName != nil {
+		if err := validateTeamSCIMGroupDisplayName(*patch.DisplayName); err != nil {
+			return nil, err
+		} else if err := s.validateTeamSCIMGroupDisplayNameIsUnique(ctx, teamId, id, *patch.DisplayName); err != nil {
+			return nil, err
+		}
+		group.DisplayName = *patch.DisplayName
+	}
+
+	memberIds := slices.Clone(group.MemberIds)
+	if patch.MemberIds != nil {
+		memberIds = slices.Clone(*patch.MemberIds)
+	}
+	memberIds = append(memberIds, patch.AddMemberIds...)
+	memberIds = slices.DeleteFunc(memberIds, func(id model.Id) bool {
+		return slices.Contains(patch.RemoveMemberIds, id)
+	})
+	if group.MemberIds, ufErr = s.validateTeamSCIMGroupMemberIds(ctx, teamId, memberIds); ufErr != nil {
+		return nil, ufErr
+	}
+	group.UpdateTime = time.Now()
+
+	if err := s.app.store.PutTeamSCIMGroup(ctx, &group); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSCIMGroupUpdate, id.String(), before, &group)
+
+	if group.Role != model.TeamMembershipRoleNone {
+		affected := slices.Concat(before.MemberIds, group.MemberIds)
+		slices.Sort(affected)
+		if err := s.syncTeamSCIMMemberships(ctx, teamId, slices.Compact(affected)...); err != nil {
+			return nil, s.SanitizedError(err)
+		}
+	}
+
+	return &group, nil
+}
+
+// Deletes a provisioned group. The roles of its members are updated accordingly.
+func (s *Session) DeleteTeamSCIMGroupByTeamIdAndId(ctx context.Context, teamId, id model.Id) UserFacingError {
+	group, ufErr := s.GetTeamSCIMGroupByTeamIdAndId(ctx, teamId, id)
+	if ufErr != nil {
+		return ufErr
+	}
+
+	if err := s.app.store.DeleteTeamSCIMGroupByTeamIdAndId(ctx, teamId, id); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSCIMGroupDelete, id.String(), group, nil)
+
+	if group.Role != model.TeamMembershipRoleNone {
+		if err := s.syncTeamSCIMMemberships(ctx, teamId, group.MemberIds...); err != nil {
+			return s.SanitizedError(err)
+		}
+	}
+
+	return nil
+}
+
+// Sets the role granted to the group's members. Only team administrators can map groups to roles.
+func (s *Session) PutTeamSCIMGroupRole(ctx context.Context, teamId, id model.Id, role model.TeamMembershipRole) (*model.TeamSCIMGroup, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return nil, err
+	}
+
+	if role != model.TeamMembershipRoleNone && !role.IsValid() {
+		return nil, NewUserError("Invalid role.")
+	}
+
+	before, ufErr := s.getTeamSCIMGroupByTeamIdAndId(ctx, teamId, id)
+	if ufErr != nil {
+		return nil, ufErr
+	}
+
+	group := *before
+	group.Role = role
+	group.UpdateTime = time.Now()
+	if err := s.app.store.PutTeamSCIMGroup(ctx, &group); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, teamId, model.AuditEventActionTeamSCIMGroupUpdate, id.String(), before, &group)
+
+	// Mapping a group to the administrator role can affect users outside of the group too.
+	users, err := s.app.store.GetTeamSCIMUsersByTeamId(ctx, teamId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	userIds := make([]model.Id, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.UserId)
+	}
+	if err := s.syncTeamSCIMMemberships(ctx, teamId, userIds...); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	return &group, nil
+}
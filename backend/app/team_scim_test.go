@@ -0,0 +1,221 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamSCIM(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(aliceSess, app.TeamSubscriptionTierTeam)
+
+	carol, _ := a.NewTestUser("carol@example.com", model.UserRoleCustomer)
+
+	// New accounts are limited to the team's single sign-on domains.
+	provider := apptest.NewFakeOIDCProvider(t)
+	_, err := aliceSess.PutTeamOIDCConfiguration(context.Background(), app.PutTeamOIDCConfigurationInput{
+		TeamId:              team.Id,
+		Issuer:              provider.URL,
+		ClientId:            provider.ClientId,
+		ClientSecret:        provider.ClientSecret,
+		AllowedEmailDomains: []string{"example.com"},
+		AutoJoinRole:        model.TeamMembershipRoleAnalyst,
+	})
+	require.NoError(t, err)
+
+	_, secret, err := aliceSess.CreateTeamAPIKey(context.Background(), app.CreateTeamAPIKeyInput{
+		TeamId: team.Id,
+		Name:   "IdP",
+		Scopes: []model.TeamAPIKeyScope{model.TeamAPIKeyScopeManageSCIM},
+	})
+	require.NoError(t, err)
+	scimSess, err := a.NewAnonymousSession().WithTeamAPIKey(context.Background(), secret)
+	require.NoError(t, err)
+	require.NotNil(t, scimSess)
+
+	membershipRole := func(userId model.Id) model.TeamMembershipRole {
+		memberships, err := aliceSess.GetTeamMembershipsByTeamId(context.Background(), team.Id, app.PageInput{})
+		require.NoError(t, err)
+		for _, membership := range memberships.Items {
+			if membership.Membership.UserId == userId {
+				return membership.Membership.Role
+			}
+		}
+		return model.TeamMembershipRoleNone
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := aliceSess.CreateTeamSCIMUser(context.Background(), app.CreateTeamSCIMUserInput{
+			TeamId:   team.Id,
+			UserName: "bob@example.com",
+			Active:   true,
+		})
+		assert.Error(t, err)
+	})
+
+	bob, err := scimSess.CreateTeamSCIMUser(context.Background(), app.CreateTeamSCIMUserInput{
+		TeamId:     team.Id,
+		ExternalId: "00u1",
+		UserName:   "bob@example.com",
+		Active:     true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.TeamMembershipRoleAnalyst, membershipRole(bob.UserId))
+
+	t.Run("Duplicate", func(t *testing.T) {
+		_, err := scimSess.CreateTeamSCIMUser(context.Background(), app.CreateTeamSCIMUserInput{
+			TeamId:   team.Id,
+			UserName: "Bob@example.com",
+			Active:   true,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("ExistingMember", func(t *testing.T) {
+		user, err := scimSess.CreateTeamSCIMUser(context.Background(), app.CreateTeamSCIMUserInput{
+			TeamId:   team.Id,
+			UserName: "alice@example.com",
+			Active:   true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, alice.Id, user.UserId)
+
+		// Roles aren't managed until a group grants the administrator role.
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(alice.Id))
+	})
+
+	t.Run("ExistingNonMember", func(t *testing.T) {
+		_, err := scimSess.CreateTeamSCIMUser(context.Background(), app.CreateTeamSCIMUserInput{
+			TeamId:   team.Id,
+			UserName: carol.EmailAddress,
+			Active:   true,
+		})
+		assert.Error(t, err)
+		assert.Equal(t, model.TeamMembershipRoleNone, membershipRole(carol.Id))
+	})
+
+	t.Run("DisallowedDomain", func(t *testing.T) {
+		_, err := scimSess.CreateTeamSCIMUser(context.Background(), app.CreateTeamSCIMUserInput{
+			TeamId:   team.Id,
+			UserName: "dave@example.org",
+			Active:   true,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("UserNameChange", func(t *testing.T) {
+		userName := "robert@example.com"
+		_, err := scimSess.PatchTeamSCIMUserByTeamAndUserId(context.Background(), team.Id, bob.UserId, app.TeamSCIMUserPatch{
+			UserName: &userName,
+		})
+		assert.Error(t, err)
+	})
+
+	group, err := scimSess.CreateTeamSCIMGroup(context.Background(), app.CreateTeamSCIMGroupInput{
+		TeamId:      team.Id,
+		DisplayName: "Admins",
+		MemberIds:   []model.Id{alice.Id, bob.UserId},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.TeamMembershipRoleAnalyst, membershipRole(bob.UserId))
+
+	t.Run("GroupValidation", func(t *testing.T) {
+		_, err := scimSess.CreateTeamSCIMGroup(context.Background(), app.CreateTeamSCIMGroupInput{
+			TeamId:      team.Id,
+			DisplayName: "admins",
+		})
+		assert.Error(t, err)
+
+		_, err = scimSess.CreateTeamSCIMGroup(context.Background(), app.CreateTeamSCIMGroupInput{
+			TeamId:      team.Id,
+			DisplayName: "Others",
+			MemberIds:   []model.Id{carol.Id},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("GroupRole", func(t *testing.T) {
+		_, err := scimSess.PutTeamSCIMGroupRole(context.Background(), team.Id, group.Id, model.TeamMembershipRoleAdministrator)
+		assert.Error(t, err)
+
+		group, err := aliceSess.PutTeamSCIMGroupRole(context.Background(), team.Id, group.Id, model.TeamMembershipRoleAdministrator)
+		require.NoError(t, err)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, group.Role)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(bob.UserId))
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(alice.Id))
+
+		groups, err := aliceSess.GetTeamSCIMGroupsByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, groups[0].Role)
+	})
+
+	t.Run("GroupMembers", func(t *testing.T) {
+		_, err := scimSess.PatchTeamSCIMGroupByTeamIdAndId(context.Background(), team.Id, group.Id, app.TeamSCIMGroupPatch{
+			RemoveMemberIds: []model.Id{bob.UserId},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.TeamMembershipRoleAnalyst, membershipRole(bob.UserId))
+
+		_, err = scimSess.PatchTeamSCIMGroupByTeamIdAndId(context.Background(), team.Id, group.Id, app.TeamSCIMGroupPatch{
+			AddMemberIds: []model.Id{bob.UserId},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(bob.UserId))
+	})
+
+	t.Run("Deactivate", func(t *testing.T) {
+		active := false
+		user, err := scimSess.PatchTeamSCIMUserByTeamAndUserId(context.Background(), team.Id, bob.UserId, app.TeamSCIMUserPatch{
+			Active: &active,
+		})
+		require.NoError(t, err)
+		assert.False(t, user.Active)
+		assert.Equal(t, model.TeamMembershipRoleNone, membershipRole(bob.UserId))
+
+		// Reactivation restores the role granted by the user's groups.
+		active = true
+		_, err = scimSess.PatchTeamSCIMUserByTeamAndUserId(context.Background(), team.Id, bob.UserId, app.TeamSCIMUserPatch{
+			Active: &active,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(bob.UserId))
+	})
+
+	t.Run("DeleteGroup", func(t *testing.T) {
+		require.NoError(t, scimSess.DeleteTeamSCIMGroupByTeamIdAndId(context.Background(), team.Id, group.Id))
+
+		// No groups grant the administrator role anymore, so roles are left as they are.
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(bob.UserId))
+
+		_, err := scimSess.GetTeamSCIMGroupByTeamIdAndId(context.Background(), team.Id, group.Id)
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		require.NoError(t, scimSess.DeleteTeamSCIMUserByTeamAndUserId(context.Background(), team.Id, bob.UserId))
+		assert.Equal(t, model.TeamMembershipRoleNone, membershipRole(bob.UserId))
+
+		_, err := scimSess.GetTeamSCIMUserByTeamAndUserId(context.Background(), team.Id, bob.UserId)
+		assert.IsType(t, app.NotFoundError(""), err)
+	})
+
+	t.Run("LastAdministrator", func(t *testing.T) {
+		// Alice is the only administrator left, so deactivating her doesn't remove her.
+		active := false
+		_, err := scimSess.PatchTeamSCIMUserByTeamAndUserId(context.Background(), team.Id, alice.Id, app.TeamSCIMUserPatch{
+			Active: &active,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.TeamMembershipRoleAdministrator, membershipRole(alice.Id))
+	})
+}
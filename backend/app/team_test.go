@@ -0,0 +1,107 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamRolePermissions(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(aliceSess, app.TeamSubscriptionTierTeam)
+
+	join := func(emailAddress string, role model.TeamMembershipRole) (*model.User, *app.Session) {
+		user, sess := a.NewTestUser(emailAddress, model.UserRoleCustomer)
+		require.NoError(t, aliceSess.InviteToTeam(context.Background(), app.InviteToTeamInput{
+			TeamId:       team.Id,
+			EmailAddress: emailAddress,
+			Role:         role,
+		}))
+		_, err := sess.JoinTeam(context.Background(), team.Id)
+		require.NoError(t, err)
+		return user, sess
+	}
+
+	bob, bobSess := join("bob@example.com", model.TeamMembershipRoleAnalyst)
+	_, carolSess := join("carol@example.com", model.TeamMembershipRoleBilling)
+	_, danSess := join("dan@example.com", model.TeamMembershipRoleViewer)
+
+	putSCP := func(sess *app.Session) error {
+		_, err := sess.PutManagedAWSSCPByTeamAndAccountId(context.Background(), team.Id, "123456789012", app.PutManagedAWSSCPInput{
+			Content: "foo",
+		})
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		assert.Error(t, aliceSess.InviteToTeam(context.Background(), app.InviteToTeamInput{
+			TeamId:       team.Id,
+			EmailAddress: "erin@example.com",
+			Role:         model.TeamMembershipRoleLegacyMember,
+		}))
+	})
+
+	t.Run("Analyst", func(t *testing.T) {
+		_, err := bobSess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{
+			TeamId: team.Id,
+		})
+		assert.NoError(t, err)
+
+		assert.IsType(t, app.AuthorizationError{}, putSCP(bobSess))
+
+		_, err = bobSess.GetTeamBillingProfileById(context.Background(), team.Id)
+		assert.Error(t, err)
+	})
+
+	t.Run("Billing", func(t *testing.T) {
+		_, err := carolSess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{
+			TeamId: team.Id,
+		})
+		assert.Error(t, err)
+
+		_, err = carolSess.GetTeamBillingProfileById(context.Background(), team.Id)
+		assert.NoError(t, err)
+
+		_, err = carolSess.GetTeamById(context.Background(), team.Id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Viewer", func(t *testing.T) {
+		_, err := danSess.GetReportsByTeamId(context.Background(), app.GetReportsByTeamIdInput{
+			TeamId: team.Id,
+		})
+		assert.NoError(t, err)
+
+		_, err = danSess.QueueAWSAccessReportGenerationByTeamAndAccountId(context.Background(), team.Id, "123456789012")
+		assert.IsType(t, app.AuthorizationError{}, err)
+	})
+
+	t.Run("SCPManager", func(t *testing.T) {
+		role := model.TeamMembershipRoleSCPManager
+		_, err := bobSess.PatchTeamMembershipByTeamAndUserId(context.Background(), team.Id, bob.Id, app.TeamMembershipPatch{
+			Role: &role,
+		})
+		assert.Error(t, err)
+
+		membership, err := aliceSess.PatchTeamMembershipByTeamAndUserId(context.Background(), team.Id, bob.Id, app.TeamMembershipPatch{
+			Role: &role,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.TeamMembershipRoleSCPManager, membership.Role)
+
+		// The team has no integration that can manage SCPs, but Bob is now permitted to try.
+		assert.NoError(t, putSCP(bobSess))
+		assert.NoError(t, putSCP(aliceSess))
+	})
+}
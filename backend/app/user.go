@@ -131,7 +131,7 @@ func (s *Session) CompleteUserRegistration(ctx context.Context, input CompleteUs
 		CookiePolicyAgreement:   token.CookiePolicyAgreement,
 	}
 
-	accessToken, err := s.app.createUserAccessToken(ctx, user.Id)
+	accessToken, err := s.app.createUserAccessToken(ctx, user.Id, s.ipAddress, s.userAgent, model.UserAuthentication{})
 	if err != nil {
 		return nil, nil, s.SanitizedError(fmt.Errorf("unable to create user access token: %w", err))
 	} else if err := s.app.store.PutUser(ctx, user); err != nil {
@@ -167,13 +167,25 @@ func (a *App) getUserByEmailAddress(ctx context.Context, email string) (*model.U
 	}
 }
 
-func (s *Session) GetUsers(ctx context.Context) ([]*model.User, UserFacingError) {
+// Gets a page of users, ordered by email address.
+func (s *Session) GetUsers(ctx context.Context, input PageInput) (*Page[*model.User], UserFacingError) {
 	if !s.HasUserRole(model.UserRoleAdministrator) {
 		return nil, AuthorizationError{}
 	}
 
-	users, err := s.app.store.GetUsers(ctx)
-	return users, s.SanitizedError(err)
+	pageInput, err := input.storePageInput()
+	if err != nil {
+		return nil, err
+	}
+
+	page, storeErr := s.app.store.GetUserPage(ctx, pageInput)
+	if storeErr != nil {
+		return nil, s.sanitizedPageError(storeErr)
+	}
+	return &Page[*model.User]{
+		Items:      page.Items,
+		NextCursor: page.Cursor,
+	}, nil
 }
 
 func (s *Session) GetUserById(ctx context.Context, id model.Id) (*model.User, UserFacingError) {
@@ -191,18 +203,26 @@ func (s *Session) CreateUserAccessToken(ctx context.Context) ([]byte, UserFacing
 	if s.User() == nil {
 		return nil, AuthorizationError{}
 	}
-	token, err := s.app.createUserAccessToken(ctx, s.User().Id)
+	token, err := s.app.createUserAccessToken(ctx, s.User().Id, s.ipAddress, s.userAgent, s.authentication)
 	return token, s.SanitizedError(err)
 }
 
-func (a *App) createUserAccessToken(ctx context.Context, userId model.Id) ([]byte, error) {
+func (a *App) createUserAccessToken(ctx context.Context, userId model.Id, ipAddress, userAgent string, authentication model.UserAuthentication) ([]byte, error) {
+	if len(userAgent) > maxUserAccessTokenUserAgentLength {
+		userAgent = userAgent[:maxUserAccessTokenUserAgentLength]
+	}
+	now := time.Now()
 	token := model.NewToken()
 	tokenHash := model.TokenHash(token)
 	if err := a.store.PutUserAccessToken(ctx, &model.UserAccessToken{
-		UserId:         userId,
-		CreationTime:   time.Now(),
-		Hash:           tokenHash,
-		ExpirationTime: time.Now().Add(90 * 24 * time.Hour),
+		UserId:            userId,
+		CreationTime:      now,
+		Hash:              tokenHash,
+		ExpirationTime:    userAccessTokenExpirationTime(now, now),
+		CreationIPAddress: ipAddress,
+		UserAgent:         userAgent,
+		LastUseTime:       now,
+		Authentication:    authentication,
 	}); err != nil {
 		return nil, fmt.Errorf("unable to put user access token: %w", err)
 	}
@@ -298,7 +318,23 @@ func (s *Session) PatchUserById(ctx context.Context, userId model.Id, patch User
 	}
 
 	user, err := s.app.store.PatchUserById(ctx, userId, storePatch)
-	return user, s.SanitizedError(err)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	if user != nil && patch.Password != nil {
+		// Changing the password signs the user out everywhere else. If the user changed it
+		// themselves, they stay signed in here.
+		var except *model.UserAccessToken
+		if s.HasUserId(userId) {
+			except = s.userAccessToken
+		}
+		if err := s.app.deleteUserAccessTokens(ctx, userId, except); err != nil {
+			return nil, s.SanitizedError(err)
+		}
+	}
+
+	return user, nil
 }
 
 func (a *App) SetUserRole(ctx context.Context, userId model.Id, role model.UserRole) (*model.User, error) {
@@ -325,6 +361,22 @@ func (s *Session) SanitizeUser(user *model.User) *model.User {
 	}
 }
 
+// Creates a token that can be used once to authenticate as the user via WithUserEmailAuthentication.
+// Creates a token that can be exchanged for an access token. If ssoTeamId is given, the user was
+// authenticated by the team's identity provider.
+func (a *App) createUserEmailAuthenticationToken(ctx context.Context, userId, ssoTeamId model.Id, ttl time.Duration) ([]byte, error) {
+	token := model.NewToken()
+	if err := a.store.PutUserEmailAuthenticationToken(ctx, &model.UserEmailAuthenticationToken{
+		UserId:         userId,
+		Hash:           model.TokenHash(token),
+		ExpirationTime: time.Now().Add(ttl),
+		SSOTeamId:      ssoTeamId,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to put user email authentication token: %w", err)
+	}
+	return token, nil
+}
+
 type BeginUserEmailAuthenticationInput struct {
 	EmailAddress string
 }
@@ -344,16 +396,9 @@ func (s *Session) BeginUserEmailAuthentication(ctx context.Context, input BeginU
 		time.Sleep(100 * time.Millisecond)
 		// TODO: send registration email?
 	} else {
-		token := model.NewToken()
-		tokenHash := model.TokenHash(token)
-
-		now := time.Now()
-		if err := s.app.store.PutUserEmailAuthenticationToken(ctx, &model.UserEmailAuthenticationToken{
-			UserId:         user.Id,
-			Hash:           tokenHash,
-			ExpirationTime: now.Add(1 * time.Hour),
-		}); err != nil {
-			return s.SanitizedError(fmt.Errorf("unable to put user email authentication token: %w", err))
+		token, err := s.app.createUserEmailAuthenticationToken(ctx, user.Id, "", 1*time.Hour)
+		if err != nil {
+			return s.SanitizedError(err)
 		}
 
 		tokenBase64 := base64.RawURLEncoding.EncodeToString(token)
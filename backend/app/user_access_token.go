@@ -0,0 +1,125 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const (
+	// Access tokens expire if they go unused for this long.
+	userAccessTokenIdleTimeout = 30 * 24 * time.Hour
+
+	// Access tokens expire this long after they're created, regardless of use.
+	userAccessTokenMaxLifetime = 90 * 24 * time.Hour
+
+	// To avoid a write on every request, last use times are only updated this often.
+	userAccessTokenTouchInterval = 5 * time.Minute
+
+	maxUserAccessTokenUserAgentLength = 500
+)
+
+func userAccessTokenExpirationTime(creationTime, lastUseTime time.Time) time.Time {
+	expirationTime := lastUseTime.Add(userAccessTokenIdleTimeout)
+	if maxExpirationTime := creationTime.Add(userAccessTokenMaxLifetime); expirationTime.After(maxExpirationTime) {
+		return maxExpirationTime
+	}
+	return expirationTime
+}
+
+func isUserAccessTokenExpired(token *model.UserAccessToken) bool {
+	now := time.Now()
+	return token.ExpirationTime.Before(now) || token.CreationTime.Add(userAccessTokenMaxLifetime).Before(now)
+}
+
+// Records use of the token and extends its expiration. Failures are logged rather than returned
+// since the token is valid either way.
+func (s *Session) touchUserAccessToken(ctx context.Context, token *model.UserAccessToken) *model.UserAccessToken {
+	now := time.Now()
+	if now.Sub(token.LastUseTime) < userAccessTokenTouchInterval {
+		return token
+	}
+
+	expirationTime := userAccessTokenExpirationTime(token.CreationTime, now)
+	patched, err := s.app.store.PatchUserAccessTokenByHash(ctx, token.Hash, &store.UserAccessTokenPatch{
+		LastUseTime:    &now,
+		ExpirationTime: &expirationTime,
+	})
+	if err != nil {
+		s.Logger().Error("unable to update user access token", zap.Error(err))
+		return token
+	} else if patched == nil {
+		// The token was revoked concurrently.
+		return token
+	}
+	return patched
+}
+
+// Gets the user's unexpired access tokens, most recently created first.
+func (s *Session) GetUserAccessTokensByUserId(ctx context.Context, userId model.Id) ([]*model.UserAccessToken, UserFacingError) {
+	if !s.HasUserId(userId) && !s.HasUserRole(model.UserRoleAdministrator) {
+		return nil, AuthorizationError{}
+	}
+
+	tokens, err := s.app.store.GetUserAccessTokensByUserId(ctx, userId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	ret := make([]*model.UserAccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		if !isUserAccessTokenExpired(token) {
+			ret = append(ret, token)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].CreationTime.After(ret[j].CreationTime)
+	})
+	return ret, nil
+}
+
+// Revokes one of the user's access tokens, signing out whichever client is using it.
+func (s *Session) DeleteUserAccessTokenByUserIdAndId(ctx context.Context, userId, id model.Id) UserFacingError {
+	tokens, err := s.GetUserAccessTokensByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Id() == id {
+			return s.SanitizedError(s.app.store.DeleteUserAccessTokenByHash(ctx, token.Hash))
+		}
+	}
+	return NotFoundError("No such session.")
+}
+
+// Revokes all of the user's access tokens, including the current one if it belongs to the user.
+func (s *Session) SignOutEverywhere(ctx context.Context, userId model.Id) UserFacingError {
+	if !s.HasUserId(userId) && !s.HasUserRole(model.UserRoleAdministrator) {
+		return AuthorizationError{}
+	}
+	return s.SanitizedError(s.app.deleteUserAccessTokens(ctx, userId, nil))
+}
+
+// Revokes all of the user's access tokens except the given one, if any.
+func (a *App) deleteUserAccessTokens(ctx context.Context, userId model.Id, except *model.UserAccessToken) error {
+	tokens, err := a.store.GetUserAccessTokensByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	hashes := make([][]byte, 0, len(tokens))
+	for _, token := range tokens {
+		if except == nil || !bytes.Equal(token.Hash, except.Hash) {
+			hashes = append(hashes, token.Hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return a.store.DeleteUserAccessTokensByHashes(ctx, hashes...)
+}
@@ -0,0 +1,92 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestUserAccessTokens(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	_, bobSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+
+	newSession := func() ([]byte, *app.Session) {
+		token, err := aliceSess.WithIPAddress("192.0.2.1").WithUserAgent("curl/8.0").CreateUserAccessToken(context.Background())
+		require.NoError(t, err)
+		sess, err := a.NewAnonymousSession().WithUserAccessToken(context.Background(), token)
+		require.NoError(t, err)
+		require.NotNil(t, sess)
+		return token, sess
+	}
+
+	otherToken, otherSess := newSession()
+
+	t.Run("List", func(t *testing.T) {
+		tokens, err := aliceSess.GetUserAccessTokensByUserId(context.Background(), alice.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, otherSess.UserAccessToken().Id(), tokens[0].Id())
+		assert.Equal(t, "192.0.2.1", tokens[0].CreationIPAddress)
+		assert.Equal(t, "curl/8.0", tokens[0].UserAgent)
+		assert.False(t, tokens[0].LastUseTime.IsZero())
+
+		_, err = bobSess.GetUserAccessTokensByUserId(context.Background(), alice.Id)
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		token, sess := newSession()
+
+		err := bobSess.DeleteUserAccessTokenByUserIdAndId(context.Background(), alice.Id, sess.UserAccessToken().Id())
+		assert.Error(t, err)
+
+		err = aliceSess.DeleteUserAccessTokenByUserIdAndId(context.Background(), alice.Id, model.Id("uat-nope"))
+		assert.IsType(t, app.NotFoundError(""), err)
+
+		require.NoError(t, aliceSess.DeleteUserAccessTokenByUserIdAndId(context.Background(), alice.Id, sess.UserAccessToken().Id()))
+		sess, err = a.NewAnonymousSession().WithUserAccessToken(context.Background(), token)
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+	})
+
+	t.Run("PasswordChange", func(t *testing.T) {
+		password := "correct horse battery staple"
+		_, err := aliceSess.PatchUserById(context.Background(), alice.Id, app.UserPatch{
+			Password: &password,
+		})
+		require.NoError(t, err)
+
+		// Other sessions are signed out, but the one that changed the password isn't.
+		sess, err := a.NewAnonymousSession().WithUserAccessToken(context.Background(), otherToken)
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+
+		tokens, err := aliceSess.GetUserAccessTokensByUserId(context.Background(), alice.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, aliceSess.UserAccessToken().Id(), tokens[0].Id())
+	})
+
+	t.Run("SignOutEverywhere", func(t *testing.T) {
+		token, _ := newSession()
+
+		require.Error(t, bobSess.SignOutEverywhere(context.Background(), alice.Id))
+		require.NoError(t, aliceSess.SignOutEverywhere(context.Background(), alice.Id))
+
+		sess, err := a.NewAnonymousSession().WithUserAccessToken(context.Background(), token)
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+
+		tokens, err := a.NewAnonymousSession().GetUserAccessTokensByUserId(context.Background(), alice.Id)
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+	})
+}
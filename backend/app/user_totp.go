@@ -0,0 +1,235 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+const userTOTPRecoveryCodeCount = 10
+
+// After this many incorrect codes in a row, the authenticator is locked for
+// userTOTPLockoutDuration. This keeps codes from being guessed.
+const userTOTPMaxFailedAttempts = 5
+
+const userTOTPLockoutDuration = 15 * time.Minute
+
+type BeginUserTOTPEnrollmentOutput struct {
+	// The base32-encoded secret, for users that can't scan the provisioning URI.
+	Secret string
+
+	// The otpauth URI for authenticator apps. This is typically presented as a QR code.
+	ProvisioningURI string
+
+	// Single-use codes that can be used in place of a TOTP code. These are only ever returned
+	// here, so the user needs to store them somewhere safe.
+	RecoveryCodes []string
+}
+
+// Begins enrollment of an authenticator app for the current user. Enrollment must be completed
+// via CompleteUserTOTPEnrollment before the authenticator is required for sign-in.
+func (s *Session) BeginUserTOTPEnrollment(ctx context.Context) (*BeginUserTOTPEnrollmentOutput, UserFacingError) {
+	if err := s.RequireUser(); err != nil {
+		return nil, err
+	} else if s.user.HasTOTP() {
+		return nil, NewUserError("An authenticator app is already enabled. Please remove it before adding a new one.")
+	}
+
+	secret := model.NewTOTPSecret()
+	recoveryCodes := make([]string, userTOTPRecoveryCodeCount)
+	recoveryCodeHashes := make([][]byte, userTOTPRecoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i] = model.NewRecoveryCode()
+		recoveryCodeHashes[i] = model.TokenHash([]byte(model.NormalizeRecoveryCode(recoveryCodes[i])))
+	}
+
+	if _, err := s.app.store.PatchUserById(ctx, s.user.Id, &store.UserPatch{
+		TOTP: &model.UserTOTP{
+			EncryptedSecret:    model.EncryptSecret(secret, s.app.config.PasswordEncryptionKey),
+			RecoveryCodeHashes: recoveryCodeHashes,
+			Revision:           s.user.TOTP.Revision + 1,
+		},
+	}); err != nil {
+		return nil, s.SanitizedError(err)
+	}
+
+	return &BeginUserTOTPEnrollmentOutput{
+		Secret:          model.TOTPSecretString(secret),
+		ProvisioningURI: model.TOTPProvisioningURI(secret, "Cloud Snitch", s.user.EmailAddress),
+		RecoveryCodes:   recoveryCodes,
+	}, nil
+}
+
+// Completes enrollment of the current user's authenticator app. The code must be generated by
+// the authenticator, not a recovery code.
+func (s *Session) CompleteUserTOTPEnrollment(ctx context.Context, code string) (*model.User, UserFacingError) {
+	if err := s.RequireUser(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.app.store.GetUserById(ctx, s.user.Id, store.ConsistencyStrongInRegion)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if user == nil || !user.TOTP.IsPending() {
+		return nil, NewUserError("Please begin adding an authenticator app first.")
+	}
+
+	timeStep, ok := s.app.verifyTOTPCode(user.TOTP, code)
+	if !ok {
+		return nil, NewUserError("Invalid code. Please make sure your device's clock is correct and try again.")
+	}
+
+	totp := user.TOTP
+	totp.ConfirmationTime = time.Now()
+	totp.LastTimeStep = timeStep
+	totp.Revision++
+	user, err = s.app.store.PatchUserTOTPById(ctx, user.Id, user.TOTP.Revision, &totp)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if user == nil {
+		return nil, NewUserError("Your authenticator app was modified by another request. Please try again.")
+	}
+	return user, nil
+}
+
+// Removes the user's authenticator app. Users removing their own authenticator must provide a
+// code from it or one of their recovery codes. Administrators can remove it without one for users
+// that have lost their authenticator and recovery codes.
+func (s *Session) DeleteUserTOTPByUserId(ctx context.Context, userId model.Id, code string) (*model.User, UserFacingError) {
+	if !s.HasUserId(userId) && !s.HasUserRole(model.UserRoleAdministrator) {
+		return nil, AuthorizationError{}
+	}
+
+	user, err := s.app.store.GetUserById(ctx, userId, store.ConsistencyStrongInRegion)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	} else if user == nil {
+		return nil, NotFoundError("")
+	}
+
+	if s.HasUserId(userId) && user.HasTOTP() {
+		if code == "" {
+			return nil, NewUserError("Please enter a code from your authenticator app or one of your recovery codes.")
+		} else if ok, err := s.verifyUserTOTP(ctx, userId, code); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, NewUserError("Invalid code.")
+		}
+		// Pick up the changes made by verification.
+		if user, err = s.app.store.GetUserById(ctx, userId, store.ConsistencyStrongInRegion); err != nil {
+			return nil, s.SanitizedError(err)
+		} else if user == nil {
+			return nil, NotFoundError("")
+		}
+	}
+
+	user, err = s.app.store.PatchUserById(ctx, userId, &store.UserPatch{
+		TOTP: &model.UserTOTP{
+			Revision: user.TOTP.Revision + 1,
+		},
+	})
+	return user, s.SanitizedError(err)
+}
+
+// Checks a code generated by the authenticator, returning its time step if it's valid. One time
+// step of clock skew is tolerated in either direction.
+func (a *App) verifyTOTPCode(totp model.UserTOTP, code string) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != model.TOTPDigits {
+		return 0, false
+	}
+
+	// DecryptSecret decrypts in place, so make sure we don't clobber the user's secret.
+	secret := model.DecryptSecret(bytes.Clone(totp.EncryptedSecret), a.config.PasswordEncryptionKey)
+	if secret == nil {
+		return 0, false
+	}
+
+	now := model.TOTPTimeStep(time.Now())
+	for timeStep := now - 1; timeStep <= now+1; timeStep++ {
+		if timeStep > totp.LastTimeStep && subtle.ConstantTimeCompare([]byte(model.TOTPCode(secret, timeStep)), []byte(code)) == 1 {
+			return timeStep, true
+		}
+	}
+	return 0, false
+}
+
+// Verifies the second factor for a user with an enabled authenticator. The code may either be
+// generated by the authenticator or be one of the user's unused recovery codes. Either way, the
+// code is consumed and can't be used again. Incorrect codes count towards a lockout, during which
+// all codes are rejected with an error.
+func (s *Session) verifyUserTOTP(ctx context.Context, userId model.Id, code string) (bool, UserFacingError) {
+	for {
+		user, err := s.app.store.GetUserById(ctx, userId, store.ConsistencyStrongInRegion)
+		if err != nil {
+			return false, s.SanitizedError(err)
+		} else if user == nil || !user.HasTOTP() {
+			return false, nil
+		}
+
+		now := time.Now()
+		totp := user.TOTP
+		if now.Before(totp.LockoutExpirationTime) {
+			return false, NewUserError("Too many incorrect codes. Please wait a few minutes and try again.")
+		}
+
+		ok := s.app.consumeTOTPCode(&totp, code)
+		if ok {
+			totp.FailedAttempts = 0
+		} else if totp.FailedAttempts++; totp.FailedAttempts >= userTOTPMaxFailedAttempts {
+			totp.FailedAttempts = 0
+			totp.LockoutExpirationTime = now.Add(userTOTPLockoutDuration)
+			s.Logger().Warn("locking authenticator after too many incorrect codes", zap.String("user_id", userId.String()))
+		}
+		totp.Revision++
+
+		// The write is conditioned on the revision we read so that two requests can't both
+		// consume the same code. If it fails, try again with the latest state.
+		if updated, err := s.app.store.PatchUserTOTPById(ctx, userId, user.TOTP.Revision, &totp); err != nil {
+			return false, s.SanitizedError(err)
+		} else if updated != nil {
+			return ok, nil
+		}
+	}
+}
+
+// Consumes the code if it's valid, returning true if it was. The code may either be generated by
+// the authenticator or be one of the unused recovery codes.
+func (a *App) consumeTOTPCode(totp *model.UserTOTP, code string) bool {
+	if timeStep, ok := a.verifyTOTPCode(*totp, code); ok {
+		totp.LastTimeStep = timeStep
+		return true
+	}
+
+	hash := model.TokenHash([]byte(model.NormalizeRecoveryCode(code)))
+	remaining := make([][]byte, 0, len(totp.RecoveryCodeHashes))
+	for _, h := range totp.RecoveryCodeHashes {
+		if subtle.ConstantTimeCompare(h, hash) != 1 {
+			remaining = append(remaining, h)
+		}
+	}
+	if len(remaining) == len(totp.RecoveryCodeHashes) {
+		return false
+	}
+	totp.RecoveryCodeHashes = remaining
+	return true
+}
+
+// Returns true if the user has a second factor that satisfies teams that require one.
+func (a *App) userHasSecondFactor(ctx context.Context, user *model.User) (bool, error) {
+	if user.HasTOTP() {
+		return true, nil
+	}
+	passkeys, err := a.store.GetUserPasskeysByUserId(ctx, user.Id)
+	if err != nil {
+		return false, err
+	}
+	return len(passkeys) > 0, nil
+}
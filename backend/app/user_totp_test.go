@@ -0,0 +1,216 @@
+package app_test
+
+import (
+	"context"
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestUserTOTP(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	password := "correct horse battery staple"
+	_, err := aliceSess.PatchUserById(context.Background(), alice.Id, app.UserPatch{
+		Password: &password,
+	})
+	require.NoError(t, err)
+
+	t.Run("CompleteWithoutBegin", func(t *testing.T) {
+		_, err := aliceSess.CompleteUserTOTPEnrollment(context.Background(), "123456")
+		assert.Error(t, err)
+	})
+
+	enrollment, err := aliceSess.BeginUserTOTPEnrollment(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/")
+	assert.Len(t, enrollment.RecoveryCodes, 10)
+
+	secret, decodeErr := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, decodeErr)
+	code := model.TOTPCode(secret, model.TOTPTimeStep(time.Now()))
+
+	t.Run("PendingNotRequired", func(t *testing.T) {
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, "")
+		require.NoError(t, err)
+		assert.NotNil(t, sess)
+	})
+
+	t.Run("InvalidEnrollmentCode", func(t *testing.T) {
+		_, err := aliceSess.CompleteUserTOTPEnrollment(context.Background(), enrollment.RecoveryCodes[0])
+		assert.Error(t, err)
+	})
+
+	user, err := aliceSess.CompleteUserTOTPEnrollment(context.Background(), code)
+	require.NoError(t, err)
+	assert.True(t, user.HasTOTP())
+
+	t.Run("CodeRequired", func(t *testing.T) {
+		_, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, "")
+		assert.IsType(t, app.SecondFactorRequiredError{}, err)
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, "nope", "")
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		// The code was already used to complete enrollment.
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, code)
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, enrollment.RecoveryCodes[0])
+		require.NoError(t, err)
+		require.NotNil(t, sess)
+
+		// Recovery codes can only be used once.
+		sess, err = a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, enrollment.RecoveryCodes[0])
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+	})
+
+	t.Run("AlreadyEnabled", func(t *testing.T) {
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, enrollment.RecoveryCodes[1])
+		require.NoError(t, err)
+		require.NotNil(t, sess)
+
+		_, err = sess.BeginUserTOTPEnrollment(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, bobSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+		_, err := bobSess.DeleteUserTOTPByUserId(context.Background(), alice.Id, "")
+		assert.Error(t, err)
+
+		_, err = aliceSess.DeleteUserTOTPByUserId(context.Background(), alice.Id, "")
+		assert.Error(t, err)
+
+		user, err := aliceSess.DeleteUserTOTPByUserId(context.Background(), alice.Id, enrollment.RecoveryCodes[3])
+		require.NoError(t, err)
+		assert.False(t, user.HasTOTP())
+
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, "")
+		require.NoError(t, err)
+		assert.NotNil(t, sess)
+	})
+}
+
+func TestUserTOTPLockout(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	password := "correct horse battery staple"
+	_, err := aliceSess.PatchUserById(context.Background(), alice.Id, app.UserPatch{
+		Password: &password,
+	})
+	require.NoError(t, err)
+
+	enrollment, err := aliceSess.BeginUserTOTPEnrollment(context.Background())
+	require.NoError(t, err)
+	secret, decodeErr := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, decodeErr)
+	_, err = aliceSess.CompleteUserTOTPEnrollment(context.Background(), model.TOTPCode(secret, model.TOTPTimeStep(time.Now())))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, "not a code")
+		require.NoError(t, err)
+		assert.Nil(t, sess)
+	}
+
+	// Even valid codes are rejected while the authenticator is locked.
+	sess, err := a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, enrollment.RecoveryCodes[0])
+	assert.Error(t, err)
+	assert.Nil(t, sess)
+
+	_, err = aliceSess.DeleteUserTOTPByUserId(context.Background(), alice.Id, enrollment.RecoveryCodes[0])
+	assert.Error(t, err)
+
+	// Administrators can still remove it.
+	_, adminSess := a.NewTestUser("admin@example.com", model.UserRoleAdministrator)
+	user, err := adminSess.DeleteUserTOTPByUserId(context.Background(), alice.Id, "")
+	require.NoError(t, err)
+	assert.False(t, user.HasTOTP())
+}
+
+func TestTeamRequireSecondFactor(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	alice, aliceSess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+	team := a.NewTestTeamWithSubscription(aliceSess, app.TeamSubscriptionTierTeam)
+
+	bob, bobSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+	require.NoError(t, aliceSess.InviteToTeam(context.Background(), app.InviteToTeamInput{
+		TeamId:       team.Id,
+		EmailAddress: bob.EmailAddress,
+		Role:         model.TeamMembershipRoleAnalyst,
+	}))
+	_, err := bobSess.JoinTeam(context.Background(), team.Id)
+	require.NoError(t, err)
+
+	requireSecondFactor := true
+
+	t.Run("AdministratorWithoutSecondFactor", func(t *testing.T) {
+		_, err := aliceSess.PatchTeamById(context.Background(), team.Id, app.TeamPatch{
+			RequireSecondFactor: &requireSecondFactor,
+		})
+		assert.Error(t, err)
+	})
+
+	// Enroll an authenticator for Alice, then sign in again so the session reflects it.
+	password := "correct horse battery staple"
+	_, err = aliceSess.PatchUserById(context.Background(), alice.Id, app.UserPatch{
+		Password: &password,
+	})
+	require.NoError(t, err)
+	enrollment, err := aliceSess.BeginUserTOTPEnrollment(context.Background())
+	require.NoError(t, err)
+	secret, decodeErr := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, decodeErr)
+	_, err = aliceSess.CompleteUserTOTPEnrollment(context.Background(), model.TOTPCode(secret, model.TOTPTimeStep(time.Now())))
+	require.NoError(t, err)
+	aliceRegistrationSess := aliceSess
+	aliceSess, err = a.NewAnonymousSession().WithUserCredentials(context.Background(), alice.EmailAddress, password, enrollment.RecoveryCodes[0])
+	require.NoError(t, err)
+	require.NotNil(t, aliceSess)
+
+	updated, err := aliceSess.PatchTeamById(context.Background(), team.Id, app.TeamPatch{
+		RequireSecondFactor: &requireSecondFactor,
+	})
+	require.NoError(t, err)
+	assert.True(t, updated.RequireSecondFactor)
+
+	_, err = aliceSess.GetTeamById(context.Background(), team.Id)
+	assert.NoError(t, err)
+
+	_, err = bobSess.GetTeamById(context.Background(), team.Id)
+	assert.Error(t, err)
+
+	t.Run("SignedInWithoutSecondFactor", func(t *testing.T) {
+		// Alice has an authenticator now, but this session wasn't authenticated with it.
+		_, err := aliceRegistrationSess.GetTeamById(context.Background(), team.Id)
+		assert.Error(t, err)
+
+		// Access tokens remember how the session was authenticated.
+		token, err := aliceSess.CreateUserAccessToken(context.Background())
+		require.NoError(t, err)
+		sess, err := a.NewAnonymousSession().WithUserAccessToken(context.Background(), token)
+		require.NoError(t, err)
+		_, err = sess.GetTeamById(context.Background(), team.Id)
+		assert.NoError(t, err)
+	})
+}
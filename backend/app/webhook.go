@@ -0,0 +1,329 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+const (
+	maxWebhooksPerTeam = 10
+
+	// Deliveries are retried with increasing delays until this many attempts have been made.
+	maxWebhookDeliveryAttempts = 5
+
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+)
+
+var webhookEventTypes = []model.WebhookEventType{
+	model.WebhookEventTypeReportGenerated,
+	model.WebhookEventTypeIntegrationFailure,
+	model.WebhookEventTypeSCPChanged,
+	model.WebhookEventTypeAlertTriggered,
+}
+
+type CreateWebhookInput struct {
+	TeamId     model.Id
+	URL        string
+	EventTypes []model.WebhookEventType
+
+	// If empty, a secret will be generated.
+	Secret string
+}
+
+func (a *App) validateWebhookURL(s string) UserFacingError {
+	if len(s) > 2000 {
+		return NewUserError("URLs are limited to 2000 characters.")
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return NewUserError("Invalid URL.")
+	}
+	if u.Scheme != "https" && (u.Scheme != "http" || !a.config.AllowInsecureWebhookURLs) {
+		return NewUserError("Webhook URLs must use HTTPS.")
+	}
+	// Hostnames are checked when they're resolved at delivery time, but we can reject obviously bad
+	// addresses up front.
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !isPublicIPAddress(ip) && !a.config.AllowInsecureWebhookURLs {
+		return NewUserError("Webhook URLs must use public addresses.")
+	}
+	return nil
+}
+
+// Omits the webhook's secret from audit events.
+func webhookAuditValue(webhook *model.Webhook) map[string]any {
+	return map[string]any{
+		"URL":        webhook.URL,
+		"EventTypes": webhook.EventTypes,
+	}
+}
+
+// Creates a webhook and returns it along with its signing secret. The secret cannot be retrieved
+// later.
+func (s *Session) CreateWebhook(ctx context.Context, input CreateWebhookInput) (*model.Webhook, string, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, input.TeamId); err != nil {
+		return nil, "", err
+	}
+
+	if err := s.app.validateWebhookURL(input.URL); err != nil {
+		return nil, "", err
+	}
+
+	if len(input.EventTypes) == 0 {
+		return nil, "", NewUserError("At least one event type is required.")
+	}
+	var eventTypes []model.WebhookEventType
+	for _, eventType := range input.EventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			return nil, "", NewUserError("Invalid event type.")
+		} else if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		secret = "whsec_" + base64.RawURLEncoding.EncodeToString(model.NewToken())
+	} else if len(secret) < 16 || len(secret) > 200 {
+		return nil, "", NewUserError("Secrets must be between 16 and 200 characters.")
+	}
+
+	existing, err := s.app.store.GetWebhooksByTeamId(ctx, input.TeamId)
+	if err != nil {
+		return nil, "", s.SanitizedError(err)
+	} else if len(existing) >= maxWebhooksPerTeam {
+		return nil, "", NewUserError(fmt.Sprintf("Teams are limited to %d webhooks.", maxWebhooksPerTeam))
+	}
+
+	webhook := &model.Webhook{
+		Id:              model.NewWebhookId(),
+		TeamId:          input.TeamId,
+		CreationTime:    time.Now(),
+		URL:             input.URL,
+		EncryptedSecret: model.EncryptSecret([]byte(secret), s.app.config.PasswordEncryptionKey),
+		EventTypes:      eventTypes,
+	}
+	if err := s.app.store.PutWebhook(ctx, webhook); err != nil {
+		return nil, "", s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, webhook.TeamId, model.AuditEventActionWebhookCreate, webhook.Id.String(), nil, webhookAuditValue(webhook))
+	return webhook, secret, nil
+}
+
+func (s *Session) GetWebhooksByTeamId(ctx context.Context, teamId model.Id) ([]*model.Webhook, UserFacingError) {
+	if err := s.RequireTeamAdministrator(ctx, teamId); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.app.store.GetWebhooksByTeamId(ctx, teamId)
+	return webhooks, s.SanitizedError(err)
+}
+
+func (s *Session) DeleteWebhookById(ctx context.Context, id model.Id) UserFacingError {
+	webhook, err := s.app.store.GetWebhookById(ctx, id)
+	if err != nil || webhook == nil {
+		return s.SanitizedError(err)
+	} else if err := s.RequireTeamAdministrator(ctx, webhook.TeamId); err != nil {
+		return err
+	} else if err := s.app.store.DeleteWebhookById(ctx, id); err != nil {
+		return s.SanitizedError(err)
+	}
+	s.recordAuditEvent(ctx, webhook.TeamId, model.AuditEventActionWebhookDelete, webhook.Id.String(), webhookAuditValue(webhook), nil)
+	return nil
+}
+
+// Gets the webhook's unexpired deliveries, most recent first.
+func (s *Session) GetWebhookDeliveriesByWebhookId(ctx context.Context, webhookId model.Id) ([]*model.WebhookDelivery, UserFacingError) {
+	webhook, err := s.app.store.GetWebhookById(ctx, webhookId)
+	if err != nil || webhook == nil {
+		return nil, s.SanitizedError(err)
+	} else if err := s.RequireTeamAdministrator(ctx, webhook.TeamId); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.app.store.GetWebhookDeliveriesByWebhookId(ctx, webhookId)
+	if err != nil {
+		return nil, s.SanitizedError(err)
+	}
+	now := time.Now()
+	ret := make([]*model.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		if delivery.ExpirationTime.After(now) {
+			ret = append(ret, delivery)
+		}
+	}
+	slices.SortFunc(ret, func(a, b *model.WebhookDelivery) int {
+		return b.CreationTime.Compare(a.CreationTime)
+	})
+	return ret, nil
+}
+
+type webhookPayload struct {
+	Id     model.Id               `json:"id"`
+	Type   model.WebhookEventType `json:"type"`
+	TeamId model.Id               `json:"teamId"`
+	Time   time.Time              `json:"time"`
+	Data   map[string]any         `json:"data"`
+}
+
+// Queues delivery of an event to each of the team's webhooks that are subscribed to it.
+func (a *App) emitWebhookEvent(ctx context.Context, teamId model.Id, eventType model.WebhookEventType, data map[string]any) error {
+	webhooks, err := a.store.GetWebhooksByTeamId(ctx, teamId)
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	var subscribed []*model.Webhook
+	for _, webhook := range webhooks {
+		if webhook.HasEventType(eventType) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	payload := webhookPayload{
+		Id:     model.NewWebhookEventId(),
+		Type:   eventType,
+		TeamId: teamId,
+		Time:   time.Now().UTC(),
+		Data:   data,
+	}
+	buf, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	messages := make([]OutgoingQueueMessage, len(subscribed))
+	for i, webhook := range subscribed {
+		messages[i] = OutgoingQueueMessage{
+			Message: QueueMessage{
+				DeliverWebhook: &DeliverWebhookInput{
+					WebhookId: webhook.Id,
+					EventId:   payload.Id,
+					EventType: eventType,
+					Payload:   string(buf),
+					Attempt:   1,
+				},
+			},
+		}
+	}
+
+	if err := a.QueueMessages(ctx, map[string][]OutgoingQueueMessage{
+		a.awsRegion: messages,
+	}); err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// Emits an integration failure event. Since this is done while already handling a failure, errors
+// are logged rather than returned.
+func (a *App) emitAWSIntegrationFailureWebhookEvent(ctx context.Context, integration *model.AWSIntegration, message string) {
+	if err := a.emitWebhookEvent(ctx, integration.TeamId, model.WebhookEventTypeIntegrationFailure, map[string]any{
+		"awsIntegrationId": integration.Id,
+		"message":          message,
+	}); err != nil {
+		zap.L().Error("failed to emit integration failure webhook event", zap.String("integration_id", integration.Id.String()), zap.Error(err))
+	}
+}
+
+// Returns the value of the X-CloudSnitch-Signature header for a payload. Receivers should compute
+// the HMAC-SHA256 of "<t>.<payload>" using their secret and compare it to v1.
+func WebhookSignature(secret []byte, timestamp time.Time, payload string) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(t + "." + payload))
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type DeliverWebhookInput struct {
+	WebhookId model.Id
+	EventId   model.Id
+	EventType model.WebhookEventType
+	Payload   string
+	Attempt   int
+}
+
+// Attempts to deliver an event to a webhook, recording the outcome. Failed deliveries are re-queued
+// with a delay until maxWebhookDeliveryAttempts is reached.
+func (a *App) DeliverWebhook(ctx context.Context, input DeliverWebhookInput) error {
+	webhook, err := a.store.GetWebhookById(ctx, input.WebhookId)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	} else if webhook == nil {
+		// The webhook was deleted.
+		return nil
+	}
+
+	now := time.Now()
+	delivery := &model.WebhookDelivery{
+		Id:             model.NewWebhookDeliveryId(),
+		WebhookId:      webhook.Id,
+		CreationTime:   now,
+		ExpirationTime: now.Add(webhookDeliveryRetention),
+		EventId:        input.EventId,
+		EventType:      input.EventType,
+		Attempt:        input.Attempt,
+	}
+
+	secret := model.DecryptSecret(webhook.EncryptedSecret, a.config.PasswordEncryptionKey)
+	if req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader([]byte(input.Payload))); err != nil {
+		delivery.ErrorMessage = err.Error()
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "CloudSnitch-Webhook/1.0")
+		req.Header.Set("X-CloudSnitch-Event", string(input.EventType))
+		req.Header.Set("X-CloudSnitch-Delivery", input.EventId.String())
+		req.Header.Set("X-CloudSnitch-Signature", WebhookSignature(secret, now, input.Payload))
+
+		resp, err := a.webhookHTTPClient.Do(req)
+		if err != nil {
+			delivery.ErrorMessage = err.Error()
+		} else {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+			delivery.StatusCode = resp.StatusCode
+		}
+	}
+	delivery.Duration = time.Since(now)
+
+	if err := a.store.PutWebhookDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to put webhook delivery: %w", err)
+	}
+
+	if delivery.IsSuccess() || input.Attempt >= maxWebhookDeliveryAttempts {
+		return nil
+	}
+
+	retry := input
+	retry.Attempt++
+	if err := a.QueueMessages(ctx, map[string][]OutgoingQueueMessage{
+		a.awsRegion: {
+			{
+				Message: QueueMessage{
+					DeliverWebhook: &retry,
+				},
+				Delay: min(time.Duration(input.Attempt*input.Attempt)*time.Minute, MaxQueueDelay),
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to queue webhook delivery retry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package app_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/app/apptest"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type webhookRequest struct {
+	Header http.Header
+	Body   string
+}
+
+func TestWebhooks(t *testing.T) {
+	a := apptest.NewTestApp(t)
+
+	_, sess := a.NewTestUser("alice@example.com", model.UserRoleCustomer)
+
+	team := a.NewTestTeamWithSubscription(sess, app.TeamSubscriptionTierIndividual)
+
+	integration, err := sess.CreateAWSIntegration(context.Background(), app.CreateAWSIntegrationInput{
+		TeamId:  team.Id,
+		Name:    "My Integration",
+		RoleARN: "arn:aws:iam::123456789012:role/MyRole",
+		CloudTrailTrail: &app.CreateAWSIntegrationCloudTrailTrailInput{
+			S3BucketName: "aws-cloudtrail-logs",
+		},
+	})
+	require.NoError(t, err)
+
+	var m sync.Mutex
+	var requests []webhookRequest
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		m.Lock()
+		defer m.Unlock()
+		requests = append(requests, webhookRequest{
+			Header: r.Header,
+			Body:   string(body),
+		})
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	t.Run("Validation", func(t *testing.T) {
+		_, _, err := sess.CreateWebhook(context.Background(), app.CreateWebhookInput{
+			TeamId:     team.Id,
+			URL:        "ftp://example.com",
+			EventTypes: []model.WebhookEventType{model.WebhookEventTypeReportGenerated},
+		})
+		assert.Error(t, err)
+
+		_, _, err = sess.CreateWebhook(context.Background(), app.CreateWebhookInput{
+			TeamId: team.Id,
+			URL:    server.URL,
+		})
+		assert.Error(t, err)
+	})
+
+	webhook, secret, err := sess.CreateWebhook(context.Background(), app.CreateWebhookInput{
+		TeamId:     team.Id,
+		URL:        server.URL,
+		EventTypes: []model.WebhookEventType{model.WebhookEventTypeReportGenerated},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret)
+
+	webhooks, err := sess.GetWebhooksByTeamId(context.Background(), team.Id)
+	require.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+
+	// Generates a report and returns any webhook deliveries that were queued as a result.
+	generateReport := func() []app.QueueMessage {
+		ignoreSQSRequests := len(a.SQSRequests("us-east-1"))
+		_, err := a.GenerateAWSCloudTrailReport(context.Background(), app.GenerateAWSCloudTrailReportInput{
+			FutureReportId:    model.NewReportId(),
+			AWSIntegrationId:  integration.Id,
+			StartTime:         time.Date(2025, 3, 6, 2, 25, 0, 0, time.UTC),
+			Duration:          60 * time.Minute,
+			AccountsKeyPrefix: "AWSLogs/o-1234abcde/",
+			AccountId:         "222222222222",
+			Region:            "us-east-1",
+			BucketRegion:      "us-east-1",
+			Retention:         model.ReportRetentionOneWeek,
+		})
+		require.NoError(t, err)
+		return webhookQueueMessages(a, ignoreSQSRequests)
+	}
+
+	messages := generateReport()
+	require.Len(t, messages, 1)
+	require.NoError(t, a.HandleQueueMessage(context.Background(), messages[0], app.QueueMessageAttributes{}))
+
+	require.Len(t, requests, 1)
+	request := requests[0]
+	assert.Equal(t, "report_generated", request.Header.Get("X-CloudSnitch-Event"))
+
+	var payload struct {
+		Id     string
+		Type   string
+		TeamId string
+		Time   time.Time
+		Data   map[string]any
+	}
+	require.NoError(t, jsoniter.UnmarshalFromString(request.Body, &payload))
+	assert.Equal(t, "report_generated", payload.Type)
+	assert.Equal(t, team.Id.String(), payload.TeamId)
+	assert.Equal(t, "222222222222", payload.Data["accountId"])
+	assert.Equal(t, payload.Id, request.Header.Get("X-CloudSnitch-Delivery"))
+	assert.Equal(t, app.WebhookSignature([]byte(secret), payload.Time, request.Body), request.Header.Get("X-CloudSnitch-Signature"))
+
+	deliveries, err := sess.GetWebhookDeliveriesByWebhookId(context.Background(), webhook.Id)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.True(t, deliveries[0].IsSuccess())
+
+	t.Run("Retry", func(t *testing.T) {
+		m.Lock()
+		statusCode = http.StatusInternalServerError
+		m.Unlock()
+
+		messages := generateReport()
+		require.Len(t, messages, 1)
+
+		ignoreSQSRequests := len(a.SQSRequests("us-east-1"))
+		require.NoError(t, a.HandleQueueMessage(context.Background(), messages[0], app.QueueMessageAttributes{}))
+
+		retries := webhookQueueMessages(a, ignoreSQSRequests)
+		require.Len(t, retries, 1)
+		assert.Equal(t, 2, retries[0].DeliverWebhook.Attempt)
+		assert.Equal(t, messages[0].DeliverWebhook.Payload, retries[0].DeliverWebhook.Payload)
+
+		deliveries, err := sess.GetWebhookDeliveriesByWebhookId(context.Background(), webhook.Id)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, http.StatusInternalServerError, deliveries[0].StatusCode)
+		assert.False(t, deliveries[0].IsSuccess())
+	})
+
+	t.Run("NonAdministrator", func(t *testing.T) {
+		_, otherSess := a.NewTestUser("bob@example.com", model.UserRoleCustomer)
+
+		_, err := otherSess.GetWebhooksByTeamId(context.Background(), team.Id)
+		assert.Error(t, err)
+
+		_, err = otherSess.GetWebhookDeliveriesByWebhookId(context.Background(), webhook.Id)
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, sess.DeleteWebhookById(context.Background(), webhook.Id))
+
+		webhooks, err := sess.GetWebhooksByTeamId(context.Background(), team.Id)
+		require.NoError(t, err)
+		assert.Empty(t, webhooks)
+
+		assert.Empty(t, generateReport())
+	})
+}
+
+// Gets the webhook deliveries queued after the first ignoreSQSRequests requests.
+func webhookQueueMessages(a *apptest.TestApp, ignoreSQSRequests int) []app.QueueMessage {
+	var ret []app.QueueMessage
+	for _, message := range a.SQSMessages("us-east-1", ignoreSQSRequests) {
+		if message.DeliverWebhook != nil {
+			ret = append(ret, message)
+		}
+	}
+	return ret
+}
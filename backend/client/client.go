@@ -0,0 +1,63 @@
+//go:generate go tool github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -config codegen.yaml ../api/apispec/openapi.yaml
+
+// Package client is a Go client for the Cloud Snitch API. Most of it is generated from the OpenAPI
+// spec. Use New to construct a client that authenticates with an access token or team API key.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// The API server used by cloudsnitch.io.
+const DefaultServer = "https://cloudsnitch.io/api"
+
+// Authenticates every request with the given user access token or team API key secret.
+func WithToken(token string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "token "+token)
+		return nil
+	})
+}
+
+func New(server, token string, opts ...ClientOption) (*ClientWithResponses, error) {
+	return NewClientWithResponses(server, append([]ClientOption{WithToken(token)}, opts...)...)
+}
+
+// Returned by CheckResponse for unsuccessful responses.
+type ResponseError struct {
+	StatusCode int
+
+	// The message returned by the API, if any.
+	Message string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+// Returns a *ResponseError if the response's status code doesn't indicate success. The body should
+// be given if it has already been read, as is the case for the *WithResponse methods. Otherwise,
+// the body is read from the response.
+func CheckResponse(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if body == nil && resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	}
+	ret := &ResponseError{
+		StatusCode: resp.StatusCode,
+	}
+	var e Error
+	if err := json.Unmarshal(body, &e); err == nil {
+		ret.Message = e.Message
+	}
+	return ret
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "saves an access token or team api key for future commands",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			// Reading the token from stdin keeps it out of shell history and process listings.
+			fmt.Fprint(os.Stderr, "Token: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("unable to read token: %w", err)
+			}
+			token = strings.TrimSpace(line)
+		}
+		if token == "" {
+			return fmt.Errorf("a token is required")
+		}
+
+		server, _ := cmd.Flags().GetString("server")
+		return saveCredentials(&credentials{
+			Server: server,
+			Token:  token,
+		})
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "removes saved credentials",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := credentialsPath()
+		if err != nil {
+			return err
+		} else if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	loginCmd.Flags().String("token", "", "the access token or team api key (default: read from stdin)")
+
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -0,0 +1,104 @@
+// Command cloudsnitch is a command-line client for the Cloud Snitch API, intended for use from
+// scripts. Authenticate with "cloudsnitch login" or the CLOUDSNITCH_TOKEN environment variable.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccbrown/cloud-snitch/backend/client"
+)
+
+type credentials struct {
+	Server string `json:"server"`
+	Token  string `json:"token"`
+}
+
+func credentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cloudsnitch", "credentials.json"), nil
+}
+
+// Loads the saved credentials, if any. Environment variables take precedence over saved values.
+func loadCredentials() (*credentials, error) {
+	ret := &credentials{}
+	if path, err := credentialsPath(); err != nil {
+		return nil, err
+	} else if buf, err := os.ReadFile(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	} else if err == nil {
+		if err := json.Unmarshal(buf, ret); err != nil {
+			return nil, fmt.Errorf("invalid credentials file: %w", err)
+		}
+	}
+	if token := os.Getenv("CLOUDSNITCH_TOKEN"); token != "" {
+		ret.Token = token
+	}
+	if server := os.Getenv("CLOUDSNITCH_SERVER"); server != "" {
+		ret.Server = server
+	}
+	return ret, nil
+}
+
+func saveCredentials(creds *credentials) error {
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0600)
+}
+
+// Constructs a client using the saved credentials and the --server flag.
+func newClient(cmd *cobra.Command) (*client.ClientWithResponses, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	} else if creds.Token == "" {
+		return nil, fmt.Errorf("not logged in (run \"cloudsnitch login\" or set CLOUDSNITCH_TOKEN)")
+	}
+	server := creds.Server
+	if s, _ := cmd.Flags().GetString("server"); s != "" {
+		server = s
+	} else if server == "" {
+		server = client.DefaultServer
+	}
+	return client.New(server, creds.Token)
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+var rootCmd = &cobra.Command{
+	Use:           "cloudsnitch",
+	Short:         "a command-line client for the cloud snitch api",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("server", "", "the api server (default: the logged in server or "+client.DefaultServer+")")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
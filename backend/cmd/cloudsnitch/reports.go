@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccbrown/cloud-snitch/backend/client"
+)
+
+var reportsCmd = &cobra.Command{
+	Use:   "reports",
+	Short: "manages reports",
+}
+
+var reportsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists a team's reports",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		teamId, _ := cmd.Flags().GetString("team-id")
+		params := &client.GetReportsByTeamIdParams{}
+		if cursor, _ := cmd.Flags().GetString("cursor"); cursor != "" {
+			params.Cursor = &cursor
+		}
+		if limit, _ := cmd.Flags().GetInt("limit"); limit != 0 {
+			params.Limit = &limit
+		}
+		resp, err := c.GetReportsByTeamIdWithResponse(cmd.Context(), teamId, params)
+		if err != nil {
+			return err
+		} else if err := client.CheckResponse(resp.HTTPResponse, resp.Body); err != nil {
+			return err
+		}
+		return printJSON(resp.JSON200)
+	},
+}
+
+var reportsDownloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "downloads a report as json, csv, or ndjson",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		reportId, _ := cmd.Flags().GetString("report-id")
+
+		var resp *http.Response
+		switch format, _ := cmd.Flags().GetString("format"); format {
+		case "json":
+			resp, err = c.GetReportContent(cmd.Context(), reportId)
+		case "csv":
+			resp, err = c.ExportReport(cmd.Context(), reportId, &client.ExportReportParams{Format: client.CSV})
+		case "ndjson":
+			resp, err = c.ExportReport(cmd.Context(), reportId, &client.ExportReportParams{Format: client.NDJSON})
+		default:
+			return fmt.Errorf("unknown format %q", format)
+		}
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := client.CheckResponse(resp, nil); err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if output, _ := cmd.Flags().GetString("output"); output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		_, err = io.Copy(w, resp.Body)
+		return err
+	},
+}
+
+func init() {
+	reportsListCmd.Flags().String("team-id", "", "the team id")
+	reportsListCmd.MarkFlagRequired("team-id")
+	reportsListCmd.Flags().String("cursor", "", "the nextCursor value from a previous page")
+	reportsListCmd.Flags().Int("limit", 0, "the maximum number of reports to list (default: 100)")
+	reportsCmd.AddCommand(reportsListCmd)
+
+	reportsDownloadCmd.Flags().String("report-id", "", "the report id")
+	reportsDownloadCmd.MarkFlagRequired("report-id")
+	reportsDownloadCmd.Flags().StringP("format", "f", "json", "the format to download (json, csv, ndjson)")
+	reportsDownloadCmd.Flags().StringP("output", "o", "", "the file to write to (default: stdout)")
+	reportsCmd.AddCommand(reportsDownloadCmd)
+
+	rootCmd.AddCommand(reportsCmd)
+}
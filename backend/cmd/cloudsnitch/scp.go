@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccbrown/cloud-snitch/backend/client"
+)
+
+var scpCmd = &cobra.Command{
+	Use:   "scp",
+	Short: "manages service control policies",
+}
+
+var scpGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "prints an account's managed scp",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		teamId, _ := cmd.Flags().GetString("team-id")
+		accountId, _ := cmd.Flags().GetString("account-id")
+		resp, err := c.GetManagedAWSSCPWithResponse(cmd.Context(), teamId, accountId)
+		if err != nil {
+			return err
+		} else if err := client.CheckResponse(resp.HTTPResponse, resp.Body); err != nil {
+			return err
+		}
+
+		// The content is printed as-is so that it can be edited and passed back to "scp put".
+		_, err = os.Stdout.WriteString(resp.JSON200.Content + "\n")
+		return err
+	},
+}
+
+var scpPutCmd = &cobra.Command{
+	Use:   "put",
+	Short: "creates or updates an account's managed scp",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		var content []byte
+		if path, _ := cmd.Flags().GetString("file"); path == "-" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return err
+		}
+
+		teamId, _ := cmd.Flags().GetString("team-id")
+		accountId, _ := cmd.Flags().GetString("account-id")
+		resp, err := c.PutManagedAWSSCPWithResponse(cmd.Context(), teamId, accountId, client.PutManagedAWSSCPJSONRequestBody{
+			Content: string(content),
+		})
+		if err != nil {
+			return err
+		}
+		return client.CheckResponse(resp.HTTPResponse, resp.Body)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{scpGetCmd, scpPutCmd} {
+		cmd.Flags().String("team-id", "", "the team id")
+		cmd.MarkFlagRequired("team-id")
+		cmd.Flags().String("account-id", "", "the aws account id")
+		cmd.MarkFlagRequired("account-id")
+		scpCmd.AddCommand(cmd)
+	}
+
+	scpPutCmd.Flags().StringP("file", "f", "", "the file containing the policy content, or \"-\" for stdin")
+	scpPutCmd.MarkFlagRequired("file")
+
+	rootCmd.AddCommand(scpCmd)
+}
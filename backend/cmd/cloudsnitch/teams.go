@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ccbrown/cloud-snitch/backend/client"
+)
+
+var teamsCmd = &cobra.Command{
+	Use:   "teams",
+	Short: "manages teams",
+}
+
+var teamsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists the teams you're a member of",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.GetTeamMembershipsByUserIdWithResponse(cmd.Context(), "self")
+		if err != nil {
+			return err
+		} else if err := client.CheckResponse(resp.HTTPResponse, resp.Body); err != nil {
+			return err
+		}
+
+		teams := []client.Team{}
+		for _, membership := range *resp.JSON200 {
+			teams = append(teams, membership.Team)
+		}
+		return printJSON(teams)
+	},
+}
+
+var integrationsCmd = &cobra.Command{
+	Use:   "integrations",
+	Short: "manages aws integrations",
+}
+
+var integrationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists a team's aws integrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		teamId, _ := cmd.Flags().GetString("team-id")
+		resp, err := c.GetAWSIntegrationsByTeamIdWithResponse(cmd.Context(), teamId)
+		if err != nil {
+			return err
+		} else if err := client.CheckResponse(resp.HTTPResponse, resp.Body); err != nil {
+			return err
+		}
+		return printJSON(resp.JSON200)
+	},
+}
+
+func init() {
+	teamsCmd.AddCommand(teamsListCmd)
+	rootCmd.AddCommand(teamsCmd)
+
+	integrationsListCmd.Flags().String("team-id", "", "the team id")
+	integrationsListCmd.MarkFlagRequired("team-id")
+
+	integrationsCmd.AddCommand(integrationsListCmd)
+	rootCmd.AddCommand(integrationsCmd)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/report"
+)
+
+var exportReportsCmd = &cobra.Command{
+	Use:   "export-reports",
+	Short: "exports a team's reports as csv or ndjson",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go catchSignal(cancel)
+
+		a, err := app.New(rootConfig.App)
+		if err != nil {
+			return err
+		}
+
+		teamId, _ := cmd.Flags().GetString("team-id")
+		accountId, _ := cmd.Flags().GetString("account-id")
+		region, _ := cmd.Flags().GetString("region")
+
+		startString, _ := cmd.Flags().GetString("start")
+		start, err := time.Parse(time.RFC3339, startString)
+		if err != nil {
+			return fmt.Errorf("invalid start time: %w", err)
+		}
+
+		end := time.Now()
+		if endString, _ := cmd.Flags().GetString("end"); endString != "" {
+			if end, err = time.Parse(time.RFC3339, endString); err != nil {
+				return fmt.Errorf("invalid end time: %w", err)
+			}
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		w, err := report.NewExportWriter(os.Stdout, report.ExportFormat(format))
+		if err != nil {
+			return err
+		}
+
+		export, err := a.ExportTeamReports(ctx, app.ExportTeamReportsInput{
+			TeamId:    model.Id(teamId),
+			StartTime: start,
+			EndTime:   end,
+			AccountId: accountId,
+			Region:    region,
+		})
+		if err != nil {
+			return err
+		}
+		return export.Write(ctx, w)
+	},
+}
+
+func init() {
+	exportReportsCmd.Flags().String("team-id", "", "the team id")
+	exportReportsCmd.MarkFlagRequired("team-id")
+
+	exportReportsCmd.Flags().String("start", "", "only reports overlapping this time or later are exported (example: \"2025-03-01T00:00:00Z\")")
+	exportReportsCmd.MarkFlagRequired("start")
+
+	exportReportsCmd.Flags().String("end", "", "only reports overlapping times before this are exported (default: now)")
+	exportReportsCmd.Flags().String("account-id", "", "only export reports for this aws account")
+	exportReportsCmd.Flags().String("region", "", "only export reports for this aws region")
+	exportReportsCmd.Flags().StringP("format", "f", "csv", "the output format (csv, ndjson)")
+
+	rootCmd.AddCommand(exportReportsCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+)
+
+var migrateReportKeysCmd = &cobra.Command{
+	Use:   "migrate-report-keys",
+	Short: "reindexes reports written by older versions so they can be found by time range",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go catchSignal(cancel)
+
+		a, err := app.New(rootConfig.App)
+		if err != nil {
+			return err
+		}
+
+		n, err := a.MigrateReportKeys(ctx)
+		zap.L().Info("migrated report keys", zap.Int("updated_records", n))
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateReportKeysCmd)
+}
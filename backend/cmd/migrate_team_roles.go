@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/ccbrown/cloud-snitch/backend/app"
+)
+
+var migrateTeamRolesCmd = &cobra.Command{
+	Use:   "migrate-team-roles",
+	Short: "replaces legacy team roles with their fine-grained equivalents",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go catchSignal(cancel)
+
+		a, err := app.New(rootConfig.App)
+		if err != nil {
+			return err
+		}
+
+		n, err := a.MigrateTeamRoles(ctx)
+		zap.L().Info("migrated team roles", zap.Int("updated_records", n))
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateTeamRolesCmd)
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+func NewAlertRuleId() Id {
+	return NewId("ar")
+}
+
+type AlertRuleType string
+
+const (
+	// Triggered when a principal makes calls from a country that isn't explicitly allowed.
+	AlertRuleTypeUnexpectedCountry AlertRuleType = "unexpected_country"
+
+	// Triggered when a principal performs an action matching a pattern such as "iam:Create*".
+	AlertRuleTypeEventPattern AlertRuleType = "event_pattern"
+
+	// Triggered when the percentage of a principal's calls that result in errors exceeds a threshold.
+	AlertRuleTypeErrorRate AlertRuleType = "error_rate"
+)
+
+type AlertRule struct {
+	Id           Id
+	TeamId       Id
+	CreationTime time.Time
+
+	Name string
+	Type AlertRuleType
+
+	// If given, the rule only applies to this principal. Otherwise it applies to all principals.
+	PrincipalKey string
+
+	// For AlertRuleTypeUnexpectedCountry, the ISO country codes that calls are allowed to come from.
+	AllowedCountryCodes []string
+
+	// For AlertRuleTypeEventPattern, a pattern matched against actions such as "iam:CreateUser". The
+	// "*" wildcard matches any sequence of characters.
+	EventPattern string
+
+	// For AlertRuleTypeErrorRate, the percentage of calls which must fail to trigger the rule.
+	ErrorRateThreshold float64
+}
+
+func NewAlertId() Id {
+	return NewId("al")
+}
+
+// An alert is created each time a rule is triggered. Alerts with the same fingerprint are
+// deduplicated for a while, so recurring activity doesn't generate repeated alerts. Alerts are
+// deleted once they expire.
+type Alert struct {
+	Id             Id
+	TeamId         Id
+	AlertRuleId    Id
+	CreationTime   time.Time
+	ExpirationTime time.Time
+
+	// Identifies the activity that triggered the alert, e.g. the principal and country.
+	Fingerprint string
+
+	ReportId     Id
+	PrincipalKey string
+	Message      string
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+func NewAuditEventId() Id {
+	return NewId("ae")
+}
+
+type AuditEventAction string
+
+const (
+	AuditEventActionTeamCreate                  AuditEventAction = "team.create"
+	AuditEventActionTeamUpdate                  AuditEventAction = "team.update"
+	AuditEventActionTeamInviteCreate            AuditEventAction = "team_invite.create"
+	AuditEventActionTeamInviteDelete            AuditEventAction = "team_invite.delete"
+	AuditEventActionTeamMembershipCreate        AuditEventAction = "team_membership.create"
+	AuditEventActionTeamMembershipUpdate        AuditEventAction = "team_membership.update"
+	AuditEventActionTeamMembershipDelete        AuditEventAction = "team_membership.delete"
+	AuditEventActionTeamPrincipalSettingsUpdate AuditEventAction = "team_principal_settings.update"
+	AuditEventActionTeamBillingProfileCreate    AuditEventAction = "team_billing_profile.create"
+	AuditEventActionTeamBillingProfileUpdate    AuditEventAction = "team_billing_profile.update"
+	AuditEventActionTeamPaymentMethodUpdate     AuditEventAction = "team_payment_method.update"
+	AuditEventActionTeamSubscriptionCreate      AuditEventAction = "team_subscription.create"
+	AuditEventActionTeamSubscriptionUpdate      AuditEventAction = "team_subscription.update"
+	AuditEventActionTeamAPIKeyCreate            AuditEventAction = "team_api_key.create"
+	AuditEventActionTeamAPIKeyDelete            AuditEventAction = "team_api_key.delete"
+	AuditEventActionAWSIntegrationCreate        AuditEventAction = "aws_integration.create"
+	AuditEventActionAWSIntegrationUpdate        AuditEventAction = "aws_integration.update"
+	AuditEventActionAWSIntegrationDelete        AuditEventAction = "aws_integration.delete"
+	AuditEventActionAWSSCPUpdate                AuditEventAction = "aws_scp.update"
+	AuditEventActionAWSRCPUpdate                AuditEventAction = "aws_rcp.update"
+	AuditEventActionAlertRuleCreate             AuditEventAction = "alert_rule.create"
+	AuditEventActionAlertRuleDelete             AuditEventAction = "alert_rule.delete"
+	AuditEventActionWebhookCreate               AuditEventAction = "webhook.create"
+	AuditEventActionWebhookDelete               AuditEventAction = "webhook.delete"
+	AuditEventActionSIEMSinkCreate              AuditEventAction = "siem_sink.create"
+	AuditEventActionSIEMSinkDelete              AuditEventAction = "siem_sink.delete"
+	AuditEventActionTeamOIDCConfigurationUpdate AuditEventAction = "team_oidc_configuration.update"
+	AuditEventActionTeamOIDCConfigurationDelete AuditEventAction = "team_oidc_configuration.delete"
+	AuditEventActionTeamSAMLConfigurationUpdate AuditEventAction = "team_saml_configuration.update"
+	AuditEventActionTeamSAMLConfigurationDelete AuditEventAction = "team_saml_configuration.delete"
+	AuditEventActionTeamSCIMUserCreate          AuditEventAction = "team_scim_user.create"
+	AuditEventActionTeamSCIMUserUpdate          AuditEventAction = "team_scim_user.update"
+	AuditEventActionTeamSCIMUserDelete          AuditEventAction = "team_scim_user.delete"
+	AuditEventActionTeamSCIMGroupCreate         AuditEventAction = "team_scim_group.create"
+	AuditEventActionTeamSCIMGroupUpdate         AuditEventAction = "team_scim_group.update"
+	AuditEventActionTeamSCIMGroupDelete         AuditEventAction = "team_scim_group.delete"
+	AuditEventActionReportDelete                AuditEventAction = "report.delete"
+)
+
+// An audit event records a change made to a team's resources. Audit events are never modified after
+// they're created.
+type AuditEvent struct {
+	Id             Id
+	TeamId         Id
+	Time           time.Time
+	ExpirationTime time.Time
+
+	// Exactly one of these identifies the actor.
+	ActorUserId       Id
+	ActorTeamAPIKeyId Id
+
+	// The IP address the change was requested from, if known.
+	IPAddress string
+
+	Action AuditEventAction
+
+	// Identifies the resource that was changed within the team, e.g. an integration id or account
+	// id. The type of the resource is implied by the action.
+	TargetId string
+
+	// JSON representations of the resource before and after the change. These are empty for
+	// resources that didn't exist before or after the change.
+	Before string
+	After  string
+}
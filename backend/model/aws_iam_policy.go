@@ -0,0 +1,18 @@
+package model
+
+// An IAM policy generated from the activity observed in a team's reports.
+type GeneratedAWSIAMPolicy struct {
+	PrincipalARN string
+
+	// The policy document as JSON.
+	Content string
+
+	// The actions granted by the policy, sorted.
+	Actions []string
+
+	// The number of reports the activity was gathered from.
+	ReportCount int
+
+	// True if some of the reports were incomplete, meaning the policy may be missing actions.
+	IsIncomplete bool
+}
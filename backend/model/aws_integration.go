@@ -17,6 +17,7 @@ type AWSIntegration struct {
 
 	GetAccountNamesFromOrganizations bool
 	ManageSCPs                       bool
+	ManageRCPs                       bool
 	CloudTrailTrail                  *AWSIntegrationCloudTrailTrail
 }
 
@@ -33,10 +34,24 @@ type AWSIntegrationRecon struct {
 	Time           time.Time
 	ExpirationTime time.Time
 	CanManageSCPs  bool
+	CanManageRCPs  bool
+
+	// The organization's management account, if known.
+	ManagementAccountId string
+
+	// True if the integration's role is in a delegated administrator account rather than the
+	// organization's management account.
+	IsDelegatedAdministrator bool
 
 	Accounts []AWSIntegrationAccountRecon
 }
 
+// Delegated administrators can't manage policies for the management account, so this returns false
+// in that case.
+func (r *AWSIntegrationRecon) CanManagePoliciesForAccount(accountId string) bool {
+	return !r.IsDelegatedAdministrator || accountId != r.ManagementAccountId
+}
+
 type AWSIntegrationAccountRecon struct {
 	Id   string
 	Name string
@@ -46,6 +61,19 @@ type AWSSCP struct {
 	Content string
 }
 
+type AWSRCP struct {
+	Content string
+}
+
+// A starting point for a policy, such as a common data perimeter rule. The content may contain
+// placeholders such as "<ORGANIZATION_ID>" which must be filled in before use.
+type AWSPolicyTemplate struct {
+	Id          string
+	Name        string
+	Description string
+	Content     string
+}
+
 type AWSAccessReport struct {
 	Services []AWSAccessReportService
 }
@@ -55,3 +83,31 @@ type AWSAccessReportService struct {
 	Namespace              string
 	LastAuthenticationTime time.Time
 }
+
+type AWSAccessReportJobStatus string
+
+const (
+	AWSAccessReportJobStatusInProgress AWSAccessReportJobStatus = "in_progress"
+	AWSAccessReportJobStatusCompleted  AWSAccessReportJobStatus = "completed"
+	AWSAccessReportJobStatusFailed     AWSAccessReportJobStatus = "failed"
+)
+
+// Access reports are generated asynchronously by IAM. This tracks the generation of a report for an
+// account and caches the results once they're available.
+type AWSAccessReportJob struct {
+	TeamId    Id
+	AccountId string
+
+	AWSIntegrationId Id
+	IAMJobId         string
+
+	CreationTime   time.Time
+	CompletionTime time.Time
+	ExpirationTime time.Time
+
+	Status       AWSAccessReportJobStatus
+	ErrorMessage string
+
+	// Only present once the job is completed.
+	Report *AWSAccessReport
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+// Records a request made with an idempotency key so that retries can be answered with the original
+// response instead of being performed again.
+type IdempotentRequest struct {
+	// A hash of the authenticated principal and the idempotency key.
+	KeyHash []byte
+
+	// A hash of the request's method, path, and body. Keys can't be reused for different requests.
+	Fingerprint []byte
+
+	CreationTime   time.Time
+	ExpirationTime time.Time
+
+	// These are zero until the request completes.
+	ResponseStatusCode  int
+	ResponseContentType string
+
+	// Responses may contain secrets such as API keys, so they're encrypted.
+	EncryptedResponseBody []byte
+}
+
+func (r *IdempotentRequest) IsComplete() bool {
+	return r.ResponseStatusCode != 0
+}
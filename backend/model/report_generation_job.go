@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+type ReportGenerationJobState string
+
+const (
+	ReportGenerationJobStateQueued    ReportGenerationJobState = "queued"
+	ReportGenerationJobStateScanning  ReportGenerationJobState = "scanning"
+	ReportGenerationJobStateImporting ReportGenerationJobState = "importing"
+	ReportGenerationJobStateDone      ReportGenerationJobState = "done"
+	ReportGenerationJobStateFailed    ReportGenerationJobState = "failed"
+)
+
+// Tracks the generation of a single report. The job's id is the id the report will have once it's
+// generated.
+type ReportGenerationJob struct {
+	Id             Id
+	CreationTime   time.Time
+	UpdateTime     time.Time
+	ExpirationTime time.Time
+
+	TeamId           Id
+	AWSIntegrationId Id
+	Scope            ReportScope
+
+	State ReportGenerationJobState
+
+	// While importing, these indicate how many log files have been imported so far.
+	ImportedObjectCount int
+	TotalObjectCount    int
+
+	// Set if the job is done and a report was generated. Reports aren't generated when there's no
+	// activity.
+	ReportId Id
+
+	// Set if the job has failed. This is suitable for display to users.
+	FailureReason string
+}
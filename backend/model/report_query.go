@@ -0,0 +1,70 @@
+package model
+
+import "github.com/ccbrown/cloud-snitch/backend/report"
+
+type ReportQuerySort string
+
+const (
+	// Sorts by event count, descending.
+	ReportQuerySortCount ReportQuerySort = "count"
+
+	// Sorts by name, ascending.
+	ReportQuerySortName ReportQuerySort = "name"
+)
+
+// A page of results from a report query.
+type ReportQueryPage[T any] struct {
+	Items []T
+
+	// The total number of results matching the query, across all pages.
+	TotalCount int
+
+	// The number of reports that were merged to answer the query.
+	ReportCount int
+
+	// True if the underlying reports were truncated or too many reports overlapped the time range.
+	IsIncomplete bool
+}
+
+type ReportQueryPrincipal struct {
+	Key  string
+	Name string
+	Type report.PrincipalType
+	ARN  string
+
+	EventCount     int
+	ErrorCount     int
+	IPAddressCount int
+}
+
+type ReportQueryIPAddress struct {
+	IPAddress string
+
+	// Empty if the network is unknown.
+	Network string
+
+	EventCount     int
+	PrincipalCount int
+}
+
+type ReportQueryNetwork struct {
+	Network string
+
+	CountryCode string
+	CountryName string
+	CityName    string
+
+	EventCount     int
+	IPAddressCount int
+	PrincipalCount int
+}
+
+type ReportQueryEvent struct {
+	Source string
+	Name   string
+
+	EventCount     int
+	ErrorCount     int
+	ErrorCodes     map[string]int
+	PrincipalCount int
+}
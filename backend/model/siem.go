@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+func NewSIEMSinkId() Id {
+	return NewId("ss")
+}
+
+type SIEMSinkType string
+
+const (
+	// RFC 5424 syslog messages sent over TCP, optionally with TLS.
+	SIEMSinkTypeSyslog SIEMSinkType = "syslog"
+
+	// JSON events sent to a Splunk-style HTTP event collector.
+	SIEMSinkTypeHEC SIEMSinkType = "hec"
+)
+
+// A destination that report summaries and findings are forwarded to.
+type SIEMSink struct {
+	Id           Id
+	TeamId       Id
+	CreationTime time.Time
+	Name         string
+	Type         SIEMSinkType
+
+	// For syslog sinks, this is a "host:port" address. For HEC sinks, it's the collector's URL.
+	Address string
+
+	// Only used by syslog sinks.
+	UseTLS bool
+
+	// The HEC token, encrypted with EncryptSecret. Only used by HEC sinks.
+	EncryptedToken []byte
+}
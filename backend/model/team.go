@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 func NewTeamId() Id {
 	return NewId("t")
@@ -12,6 +15,10 @@ type Team struct {
 	Name             string
 	StripeCustomerId string
 	Entitlements     TeamEntitlements
+
+	// If true, members can only access the team if they have a second factor, either a TOTP
+	// authenticator or a passkey.
+	RequireSecondFactor bool
 }
 
 type TeamMembershipRole string
@@ -19,14 +26,130 @@ type TeamMembershipRole string
 const (
 	TeamMembershipRoleNone          TeamMembershipRole = ""
 	TeamMembershipRoleAdministrator TeamMembershipRole = "administrator"
-	TeamMembershipRoleMember        TeamMembershipRole = "member"
+	TeamMembershipRoleViewer        TeamMembershipRole = "viewer"
+	TeamMembershipRoleAnalyst       TeamMembershipRole = "analyst"
+	TeamMembershipRoleSCPManager    TeamMembershipRole = "scp_manager"
+	TeamMembershipRoleBilling       TeamMembershipRole = "billing"
+
+	// Before roles were fine-grained, all non-administrators had this role. It can still be
+	// found in records that haven't been migrated yet, and grants the same permissions as the
+	// analyst role. New memberships should never be given this role.
+	TeamMembershipRoleLegacyMember TeamMembershipRole = "member"
 )
 
+// The roles that can be assigned, ordered from most to least privileged.
+var TeamMembershipRoles = []TeamMembershipRole{
+	TeamMembershipRoleAdministrator,
+	TeamMembershipRoleSCPManager,
+	TeamMembershipRoleAnalyst,
+	TeamMembershipRoleBilling,
+	TeamMembershipRoleViewer,
+}
+
+func (r TeamMembershipRole) IsValid() bool {
+	return slices.Contains(TeamMembershipRoles, r)
+}
+
+// Returns the role that legacy roles should be migrated to. Other roles are returned as-is.
+func (r TeamMembershipRole) Migrated() TeamMembershipRole {
+	if r == TeamMembershipRoleLegacyMember {
+		return TeamMembershipRoleAnalyst
+	}
+	return r
+}
+
+// Returns true if r is more privileged than other. Invalid roles are less privileged than all
+// valid roles.
+func (r TeamMembershipRole) IsMorePrivilegedThan(other TeamMembershipRole) bool {
+	rank := func(role TeamMembershipRole) int {
+		if i := slices.Index(TeamMembershipRoles, role.Migrated()); i >= 0 {
+			return len(TeamMembershipRoles) - i
+		}
+		return 0
+	}
+	return rank(r) > rank(other)
+}
+
+type TeamPermission string
+
+const (
+	// Allows viewing of reports, alerts, and anything derived from them.
+	TeamPermissionReadReports TeamPermission = "reports:read"
+
+	// Allows actions that produce new analysis, such as queuing access reports and annotating
+	// principals.
+	TeamPermissionAnalyzeReports TeamPermission = "reports:analyze"
+
+	// Allows management of both SCPs and RCPs.
+	TeamPermissionManageSCPs TeamPermission = "scps:manage"
+
+	TeamPermissionManageBilling TeamPermission = "billing:manage"
+
+	// Allows management of the team itself, including its members, integrations, and settings.
+	TeamPermissionManageTeam TeamPermission = "team:manage"
+)
+
+var teamMembershipRolePermissions = map[TeamMembershipRole][]TeamPermission{
+	TeamMembershipRoleAdministrator: {
+		TeamPermissionReadReports,
+		TeamPermissionAnalyzeReports,
+		TeamPermissionManageSCPs,
+		TeamPermissionManageBilling,
+		TeamPermissionManageTeam,
+	},
+	TeamMembershipRoleSCPManager: {
+		TeamPermissionReadReports,
+		TeamPermissionAnalyzeReports,
+		TeamPermissionManageSCPs,
+	},
+	TeamMembershipRoleAnalyst: {
+		TeamPermissionReadReports,
+		TeamPermissionAnalyzeReports,
+	},
+	TeamMembershipRoleBilling: {
+		TeamPermissionManageBilling,
+	},
+	TeamMembershipRoleViewer: {
+		TeamPermissionReadReports,
+	},
+}
+
+// Returns the permissions granted by the role.
+func (r TeamMembershipRole) Permissions() []TeamPermission {
+	return teamMembershipRolePermissions[r.Migrated()]
+}
+
+func (r TeamMembershipRole) HasPermission(permission TeamPermission) bool {
+	return slices.Contains(r.Permissions(), permission)
+}
+
+type DigestFrequency string
+
+const (
+	DigestFrequencyNone   DigestFrequency = ""
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+func (f DigestFrequency) Duration() time.Duration {
+	switch f {
+	case DigestFrequencyDaily:
+		return 24 * time.Hour
+	case DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
 type TeamMembership struct {
 	UserId       Id
 	TeamId       Id
 	Role         TeamMembershipRole
 	CreationTime time.Time
+
+	// How often the member would like to receive digest emails for the team.
+	DigestFrequency DigestFrequency
 }
 
 type TeamInvite struct {
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+func NewTeamAPIKeyId() Id {
+	return NewId("tak")
+}
+
+type TeamAPIKeyScope string
+
+const (
+	TeamAPIKeyScopeReadReports        TeamAPIKeyScope = "reports:read"
+	TeamAPIKeyScopeManageIntegrations TeamAPIKeyScope = "integrations:manage"
+
+	// Allows management of both SCPs and RCPs.
+	TeamAPIKeyScopeManageSCPs TeamAPIKeyScope = "scps:manage"
+
+	// Allows provisioning of users and groups via SCIM.
+	TeamAPIKeyScopeManageSCIM TeamAPIKeyScope = "scim:manage"
+)
+
+// An API key grants automated access to a subset of a team's resources without being tied to a
+// user.
+type TeamAPIKey struct {
+	Id             Id
+	TeamId         Id
+	CreatorId      Id
+	CreationTime   time.Time
+	ExpirationTime time.Time
+	Name           string
+	Hash           []byte
+	Scopes         []TeamAPIKeyScope
+
+	// This is updated with limited precision to avoid a write on every request.
+	LastUseTime time.Time
+}
+
+func (k *TeamAPIKey) HasScope(scope TeamAPIKeyScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
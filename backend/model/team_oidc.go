@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// A team's OpenID Connect identity provider, which users can sign in with.
+type TeamOIDCConfiguration struct {
+	TeamId       Id
+	CreationTime time.Time
+	UpdateTime   time.Time
+
+	Issuer   string
+	ClientId string
+
+	// The client secret, encrypted with EncryptSecret.
+	EncryptedClientSecret []byte
+
+	// Only users whose email addresses are in these domains may sign in.
+	AllowedEmailDomains []string
+
+	// If set, users who sign in are added to the team with this role if they aren't already
+	// members.
+	AutoJoinRole TeamMembershipRole
+}
+
+func NewOIDCAuthenticationSessionId() Id {
+	return NewId("oas")
+}
+
+// This contains the short-lived data needed to complete sign-in with an OpenID Connect provider.
+// The id is used as the OAuth 2.0 state parameter.
+type OIDCAuthenticationSession struct {
+	Id             Id
+	TeamId         Id
+	ExpirationTime time.Time
+
+	Nonce string
+
+	// The PKCE code verifier, which is never sent to the browser.
+	CodeVerifier string
+
+	// If sign-in was begun by a signed-in user, their id. Completing sign-in links their account
+	// to the identity provider.
+	LinkUserId Id
+}
+
+// Links a user to a subject at a team's OpenID Connect provider.
+type UserOIDCIdentity struct {
+	TeamId       Id
+	Issuer       string
+	Subject      string
+	UserId       Id
+	CreationTime time.Time
+}
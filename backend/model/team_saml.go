@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// Maps a value of a SAML role attribute to a team role.
+type TeamSAMLRoleMapping struct {
+	Value string
+	Role  TeamMembershipRole
+}
+
+// A team's SAML 2.0 identity provider, which users can sign in with.
+type TeamSAMLConfiguration struct {
+	TeamId       Id
+	CreationTime time.Time
+	UpdateTime   time.Time
+
+	// The identity provider's metadata, as provided by the team.
+	IdPMetadataXML string
+
+	// The identity provider's entity id, parsed from its metadata.
+	IdPEntityId string
+
+	// The attribute containing users' email addresses. If empty, the assertion's name id is used.
+	EmailAttribute string
+
+	// The attribute containing users' roles. If empty, roles are never mapped.
+	RoleAttribute string
+
+	// Determines the team role of users whose role attributes contain these values. If multiple
+	// mappings match, the most privileged role is used.
+	RoleMappings []TeamSAMLRoleMapping
+
+	// Only users whose email addresses are in these domains may sign in.
+	AllowedEmailDomains []string
+
+	// If set, users who sign in without a mapped role are added to the team with this role if they
+	// aren't already members.
+	DefaultRole TeamMembershipRole
+
+	// If true, users may sign in by starting at the identity provider rather than at CloudSnitch.
+	AllowIdPInitiated bool
+}
+
+func NewSAMLAuthenticationSessionId() Id {
+	return NewId("sas")
+}
+
+// This contains the short-lived data needed to complete SP-initiated sign-in with a SAML identity
+// provider. The id is used as the relay state.
+type SAMLAuthenticationSession struct {
+	Id             Id
+	TeamId         Id
+	ExpirationTime time.Time
+
+	// The id of the authentication request sent to the identity provider.
+	RequestId string
+
+	// If sign-in was begun by a signed-in user, their id. Completing sign-in links their account
+	// to the identity provider.
+	LinkUserId Id
+}
+
+// Records that an assertion was used so that it can't be replayed.
+type SAMLAssertionUse struct {
+	TeamId      Id
+	AssertionId string
+
+	// After this time, the assertion is no longer valid anyway.
+	ExpirationTime time.Time
+}
+
+// Links a user to a name id at a team's SAML identity provider.
+type UserSAMLIdentity struct {
+	TeamId       Id
+	IdPEntityId  string
+	NameId       string
+	UserId       Id
+	CreationTime time.Time
+}
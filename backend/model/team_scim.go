@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// A user that a team's identity provider has provisioned via SCIM. The user's SCIM id is their
+// user id.
+type TeamSCIMUser struct {
+	TeamId       Id
+	UserId       Id
+	CreationTime time.Time
+	UpdateTime   time.Time
+
+	// The identity provider's id for the user, if it gave one.
+	ExternalId string
+
+	// This is always the user's email address.
+	UserName string
+
+	// Active users are members of the team. Inactive users are not.
+	Active bool
+}
+
+func NewTeamSCIMGroupId() Id {
+	return NewId("sg")
+}
+
+// A group that a team's identity provider has provisioned via SCIM. Groups are mapped to team
+// roles by team administrators.
+type TeamSCIMGroup struct {
+	Id           Id
+	TeamId       Id
+	CreationTime time.Time
+	UpdateTime   time.Time
+
+	// The identity provider's id for the group, if it gave one.
+	ExternalId string
+
+	DisplayName string
+
+	// The user ids of the group's members. These are always SCIM users of the same team.
+	MemberIds []Id
+
+	// If set, active members of the group have at least this role.
+	Role TeamMembershipRole
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamMembershipRole(t *testing.T) {
+	assert.False(t, TeamMembershipRoleLegacyMember.IsValid())
+	assert.Equal(t, TeamMembershipRoleAnalyst.Permissions(), TeamMembershipRoleLegacyMember.Permissions())
+
+	assert.True(t, TeamMembershipRoleAdministrator.IsMorePrivilegedThan(TeamMembershipRoleSCPManager))
+	assert.True(t, TeamMembershipRoleSCPManager.IsMorePrivilegedThan(TeamMembershipRoleLegacyMember))
+	assert.True(t, TeamMembershipRoleViewer.IsMorePrivilegedThan(TeamMembershipRoleNone))
+	assert.False(t, TeamMembershipRoleLegacyMember.IsMorePrivilegedThan(TeamMembershipRoleAnalyst))
+
+	for _, role := range TeamMembershipRoles {
+		assert.NotEmpty(t, role.Permissions())
+		assert.Equal(t, role == TeamMembershipRoleAdministrator, role.HasPermission(TeamPermissionManageTeam))
+	}
+	assert.False(t, TeamMembershipRoleAnalyst.HasPermission(TeamPermissionManageSCPs))
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP codes are generated as described in RFC 6238 using the defaults that all common
+// authenticator apps support: SHA-1, 6 digits, and a 30 second period.
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 * time.Second
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func NewTOTPSecret() []byte {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+// Encodes the secret the way authenticator apps expect it to be entered manually.
+func TOTPSecretString(secret []byte) string {
+	return totpEncoding.EncodeToString(secret)
+}
+
+func TOTPTimeStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+func TOTPCode(secret []byte, timeStep int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(timeStep))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	n := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", n%1000000)
+}
+
+// Returns the otpauth URI that authenticator apps use to enroll the secret, typically scanned as
+// a QR code.
+func TOTPProvisioningURI(secret []byte, issuer, accountName string) string {
+	q := url.Values{}
+	q.Set("secret", TOTPSecretString(secret))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(TOTPDigits))
+	q.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: q.Encode(),
+	}).String()
+}
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// Recovery codes are formatted as two groups of five characters, e.g. "k3m9p-x2qrt".
+func NewRecoveryCode() string {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	code := recoveryCodeEncoding.EncodeToString(buf)[:10]
+	return code[:5] + "-" + code[5:]
+}
+
+// Normalizes a recovery code as entered by a user so that it can be hashed and compared.
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors from RFC 6238, truncated to 6 digits.
+	secret := []byte("12345678901234567890")
+	assert.Equal(t, "287082", TOTPCode(secret, TOTPTimeStep(time.Unix(59, 0))))
+	assert.Equal(t, "081804", TOTPCode(secret, TOTPTimeStep(time.Unix(1111111109, 0))))
+	assert.Equal(t, "050471", TOTPCode(secret, TOTPTimeStep(time.Unix(1111111111, 0))))
+	assert.Equal(t, "005924", TOTPCode(secret, TOTPTimeStep(time.Unix(1234567890, 0))))
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI([]byte("12345678901234567890"), "Cloud Snitch", "alice@example.com")
+	assert.Equal(t, "otpauth://totp/Cloud%20Snitch:alice@example.com?algorithm=SHA1&digits=6&issuer=Cloud+Snitch&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri)
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code := NewRecoveryCode()
+	assert.Len(t, code, 11)
+	assert.Equal(t, NormalizeRecoveryCode(code), NormalizeRecoveryCode(" "+code[:5]+code[6:]+" "))
+}
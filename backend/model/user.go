@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"time"
 
+	"github.com/jxskiss/base62"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -41,6 +42,14 @@ type User struct {
 
 	EncryptedPasswordHash []byte
 
+	// The user's authenticator app, which is required in addition to their password if enrolled.
+	TOTP UserTOTP
+
+	// Teams whose identity providers may sign in as the user. This includes the team whose
+	// identity provider created the account and any teams the user explicitly linked their
+	// account to.
+	SSOTeamIds []Id
+
 	TermsOfServiceAgreement UserAgreement
 	PrivacyPolicyAgreement  UserAgreement
 	CookiePolicyAgreement   UserAgreement
@@ -89,6 +98,42 @@ func (u *User) VerifyPassword(password string, encryptionKey []byte) bool {
 	return VerifyEncryptedPasswordHash(u.EncryptedPasswordHash, password, encryptionKey)
 }
 
+type UserTOTP struct {
+	EncryptedSecret []byte
+
+	// Enrollment is pending until the user proves they can generate codes. Until then, the
+	// authenticator isn't required for sign-in.
+	ConfirmationTime time.Time
+
+	// Hashes of the recovery codes that haven't been used yet.
+	RecoveryCodeHashes [][]byte
+
+	// The time step of the most recently accepted code. Codes for it or earlier time steps are
+	// rejected so that they can't be replayed.
+	LastTimeStep int64
+
+	// Incorrect codes entered since the last correct one. Once there are too many, codes are
+	// rejected until the lockout expires.
+	FailedAttempts        int
+	LockoutExpirationTime time.Time
+
+	// Incremented every time the authenticator is modified so that concurrent modifications, such
+	// as two requests using the same recovery code, can be detected.
+	Revision int64
+}
+
+func (t UserTOTP) IsPending() bool {
+	return len(t.EncryptedSecret) > 0 && t.ConfirmationTime.IsZero()
+}
+
+func (t UserTOTP) IsEnabled() bool {
+	return len(t.EncryptedSecret) > 0 && !t.ConfirmationTime.IsZero()
+}
+
+func (u *User) HasTOTP() bool {
+	return u.TOTP.IsEnabled()
+}
+
 type UserRegistrationToken struct {
 	EmailAddress            string
 	Hash                    []byte
@@ -103,6 +148,37 @@ type UserAccessToken struct {
 	CreationTime   time.Time
 	Hash           []byte
 	ExpirationTime time.Time
+
+	// The IP address and user agent of the client that the token was created for, if known.
+	CreationIPAddress string
+	UserAgent         string
+
+	// This is only updated periodically, so it may lag behind the token's actual use.
+	LastUseTime time.Time
+
+	// How the user authenticated when the token was created.
+	Authentication UserAuthentication
+}
+
+// Describes how a user authenticated when signing in.
+type UserAuthentication struct {
+	// True if the user signed in with a passkey or a code from their authenticator app.
+	SecondFactor bool
+
+	// If the user signed in with a team's identity provider, the team's id. The identity provider
+	// takes the place of a second factor for that team.
+	SSOTeamId Id
+}
+
+// Access tokens are identified by their hash, which we don't expose. This derives a public id
+// from the hash so that tokens can be listed and revoked.
+func (t *UserAccessToken) Id() Id {
+	h := sha512.Sum512(t.Hash)
+	s := base62.EncodeToString(h[:16])
+	if len(s) > 22 {
+		s = s[:22]
+	}
+	return Id("uat-" + s)
 }
 
 type UserEmailAuthenticationToken struct {
@@ -110,4 +186,7 @@ type UserEmailAuthenticationToken struct {
 	CreationTime   time.Time
 	Hash           []byte
 	ExpirationTime time.Time
+
+	// If the token was created for sign-in with a team's identity provider, the team's id.
+	SSOTeamId Id
 }
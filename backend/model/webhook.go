@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+func NewWebhookId() Id {
+	return NewId("wh")
+}
+
+type WebhookEventType string
+
+const (
+	WebhookEventTypeReportGenerated    WebhookEventType = "report_generated"
+	WebhookEventTypeIntegrationFailure WebhookEventType = "integration_failure"
+	WebhookEventTypeSCPChanged         WebhookEventType = "scp_changed"
+	WebhookEventTypeAlertTriggered     WebhookEventType = "alert_triggered"
+)
+
+type Webhook struct {
+	Id           Id
+	TeamId       Id
+	CreationTime time.Time
+
+	URL string
+
+	// The secret used to sign payloads, encrypted with EncryptSecret.
+	EncryptedSecret []byte
+
+	EventTypes []WebhookEventType
+}
+
+func (w *Webhook) HasEventType(t WebhookEventType) bool {
+	for _, eventType := range w.EventTypes {
+		if eventType == t {
+			return true
+		}
+	}
+	return false
+}
+
+func NewWebhookEventId() Id {
+	return NewId("whe")
+}
+
+func NewWebhookDeliveryId() Id {
+	return NewId("whd")
+}
+
+// Records an attempt to deliver an event to a webhook.
+type WebhookDelivery struct {
+	Id             Id
+	WebhookId      Id
+	CreationTime   time.Time
+	ExpirationTime time.Time
+
+	EventId   Id
+	EventType WebhookEventType
+	Attempt   int
+
+	// The HTTP status code of the response. This is zero if no response was received.
+	StatusCode   int
+	ErrorMessage string
+	Duration     time.Duration
+}
+
+func (d *WebhookDelivery) IsSuccess() bool {
+	return d.ErrorMessage == "" && d.StatusCode >= 200 && d.StatusCode < 300
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/ccbrown/go-geoip"
@@ -374,6 +375,10 @@ type ImportAWSCloudTrailLogsForAccountRegionConfig struct {
 
 	// If non-zero, we won't look at more than this many bytes of log files.
 	MaxSourceBytes int64
+
+	// If given, this is invoked once the in-scope log files have been listed, and again after each
+	// one is imported.
+	Progress func(imported, total int)
 }
 
 func (r *Report) ImportAWSCloudTrailLogsForAccountRegion(ctx context.Context, config ImportAWSCloudTrailLogsForAccountRegionConfig) error {
@@ -382,6 +387,8 @@ func (r *Report) ImportAWSCloudTrailLogsForAccountRegion(ctx context.Context, co
 	timePadding := 5 * time.Minute
 	lastDay := r.StartTime.Add(r.Duration() + timePadding).Truncate(24 * time.Hour)
 
+	// List everything up front so that progress can be reported.
+	var objects []s3types.Object
 	for day := r.StartTime.Add(-timePadding).Truncate(24 * time.Hour); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
 		regionPrefix := prefix + config.Region + "/"
 		dayPrefix := regionPrefix + day.Format("2006/01/02/")
@@ -412,20 +419,31 @@ func (r *Report) ImportAWSCloudTrailLogsForAccountRegion(ctx context.Context, co
 				}
 
 				// This is an in-scope object.
+				objects = append(objects, object)
+			}
+		}
+	}
 
-				if config.MaxSourceBytes > 0 && object.Size != nil && r.SourceBytes+*object.Size > config.MaxSourceBytes {
-					r.IsIncomplete = true
-					return nil
-				}
+	if config.Progress != nil {
+		config.Progress(0, len(objects))
+	}
 
-				if err := r.ImportAWSCloudTrailLogBucketObject(ctx, ImportAWSCloudTrailLogBucketObjectConfig{
-					S3:         config.S3,
-					BucketName: config.BucketName,
-					ObjectKey:  *object.Key,
-				}); err != nil {
-					return fmt.Errorf("failed to import log object: %w", err)
-				}
-			}
+	for i, object := range objects {
+		if config.MaxSourceBytes > 0 && object.Size != nil && r.SourceBytes+*object.Size > config.MaxSourceBytes {
+			r.IsIncomplete = true
+			return nil
+		}
+
+		if err := r.ImportAWSCloudTrailLogBucketObject(ctx, ImportAWSCloudTrailLogBucketObjectConfig{
+			S3:         config.S3,
+			BucketName: config.BucketName,
+			ObjectKey:  *object.Key,
+		}); err != nil {
+			return fmt.Errorf("failed to import log object: %w", err)
+		}
+
+		if config.Progress != nil {
+			config.Progress(i+1, len(objects))
 		}
 	}
 
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+type ExportRowType string
+
+const (
+	// Event rows have one principal, event, and error code. Events without errors have an empty
+	// error code.
+	ExportRowTypeEvent ExportRowType = "event"
+
+	// IP address rows have one principal and IP address, along with the address's location if known.
+	ExportRowTypeIPAddress ExportRowType = "ip_address"
+)
+
+// Describes the report that exported rows come from, since reports don't contain this information
+// themselves.
+type ExportSource struct {
+	ReportId  string
+	AccountId string
+	Region    string
+}
+
+// A single flattened row of report data. Fields that don't apply to the row's type are left empty.
+type ExportRow struct {
+	ReportId  string    `json:"reportId,omitempty"`
+	AccountId string    `json:"accountId,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	Type ExportRowType `json:"type"`
+
+	PrincipalKey  string        `json:"principalKey"`
+	PrincipalName string        `json:"principalName,omitempty"`
+	PrincipalType PrincipalType `json:"principalType,omitempty"`
+	PrincipalARN  string        `json:"principalArn,omitempty"`
+
+	EventSource string `json:"eventSource,omitempty"`
+	EventName   string `json:"eventName,omitempty"`
+	ErrorCode   string `json:"errorCode,omitempty"`
+
+	IPAddress   string `json:"ipAddress,omitempty"`
+	Network     string `json:"network,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	CountryName string `json:"countryName,omitempty"`
+	CityName    string `json:"cityName,omitempty"`
+
+	Count int `json:"count"`
+}
+
+var exportCSVHeader = []string{
+	"report_id",
+	"account_id",
+	"region",
+	"start_time",
+	"end_time",
+	"type",
+	"principal_key",
+	"principal_name",
+	"principal_type",
+	"principal_arn",
+	"event_source",
+	"event_name",
+	"error_code",
+	"ip_address",
+	"network",
+	"country_code",
+	"country_name",
+	"city_name",
+	"count",
+}
+
+func (row *ExportRow) csvRecord() []string {
+	return []string{
+		row.ReportId,
+		row.AccountId,
+		row.Region,
+		row.StartTime.UTC().Format(time.RFC3339),
+		row.EndTime.UTC().Format(time.RFC3339),
+		string(row.Type),
+		row.PrincipalKey,
+		row.PrincipalName,
+		string(row.PrincipalType),
+		row.PrincipalARN,
+		row.EventSource,
+		row.EventName,
+		row.ErrorCode,
+		row.IPAddress,
+		row.Network,
+		row.CountryCode,
+		row.CountryName,
+		row.CityName,
+		strconv.Itoa(row.Count),
+	}
+}
+
+// Flattens the report into rows, invoking f for each one. Rows are emitted in a deterministic
+// order. If f returns an error, iteration stops and the error is returned.
+func (r *Report) ExportRows(source ExportSource, f func(*ExportRow) error) error {
+	base := ExportRow{
+		ReportId:  source.ReportId,
+		AccountId: source.AccountId,
+		Region:    source.Region,
+		StartTime: r.StartTime,
+		EndTime:   r.StartTime.Add(r.Duration()),
+	}
+
+	for _, principalKey := range slices.Sorted(maps.Keys(r.Principals)) {
+		principal := r.Principals[principalKey]
+
+		principalRow := base
+		principalRow.PrincipalKey = principalKey
+		principalRow.PrincipalName = principal.Name
+		principalRow.PrincipalType = principal.Type
+		principalRow.PrincipalARN = principal.ARN
+
+		for _, eventKey := range slices.Sorted(maps.Keys(principal.Events)) {
+			event := principal.Events[eventKey]
+
+			eventRow := principalRow
+			eventRow.Type = ExportRowTypeEvent
+			eventRow.EventSource = event.Source
+			eventRow.EventName = event.Name
+
+			// The counts are split so that the rows for an event sum to its total count.
+			successCount := event.Count
+			for _, code := range slices.Sorted(maps.Keys(event.ErrorCodes)) {
+				row := eventRow
+				row.ErrorCode = code
+				row.Count = event.ErrorCodes[code]
+				successCount -= row.Count
+				if err := f(&row); err != nil {
+					return err
+				}
+			}
+			if successCount > 0 {
+				row := eventRow
+				row.Count = successCount
+				if err := f(&row); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, ip := range slices.Sorted(maps.Keys(principal.IPAddresses)) {
+			row := principalRow
+			row.Type = ExportRowTypeIPAddress
+			row.IPAddress = ip
+			row.Count = principal.IPAddresses[ip]
+			if network := r.IPAddressNetworks[ip]; network != nil {
+				row.Network = *network
+				if location := r.NetworkLocations[*network]; location != nil {
+					row.CountryCode = location.CountryCode
+					row.CountryName = location.CountryName
+					row.CityName = location.CityName
+				}
+			}
+			if err := f(&row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type ExportWriter interface {
+	WriteRow(row *ExportRow) error
+
+	// Writes any buffered rows to the underlying writer.
+	Flush() error
+}
+
+func NewExportWriter(w io.Writer, format ExportFormat) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w)
+	case ExportFormatNDJSON:
+		return &ndjsonExportWriter{
+			encoder: jsoniter.NewEncoder(w),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q", format)
+	}
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	ret := &csvExportWriter{
+		w: csv.NewWriter(w),
+	}
+	if err := ret.w.Write(exportCSVHeader); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (w *csvExportWriter) WriteRow(row *ExportRow) error {
+	return w.w.Write(row.csvRecord())
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonExportWriter struct {
+	encoder *jsoniter.Encoder
+}
+
+func (w *ndjsonExportWriter) WriteRow(row *ExportRow) error {
+	// The encoder writes each row immediately, followed by a newline.
+	return w.encoder.Encode(row)
+}
+
+func (w *ndjsonExportWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_ExportRows(t *testing.T) {
+	network := "1.2.3.0/24"
+
+	r := &Report{
+		StartTime:       time.Date(2025, 3, 6, 2, 0, 0, 0, time.UTC),
+		DurationSeconds: 60 * 60,
+		NetworkLocations: map[string]*Location{
+			network: {CountryCode: "US", CountryName: "United States", CityName: "Dallas"},
+		},
+		IPAddressNetworks: map[string]*string{
+			"1.2.3.4": &network,
+		},
+		Principals: map[string]*Principal{
+			"alice": {
+				Name: "alice",
+				Type: PrincipalTypeAWSIAMUser,
+				IPAddresses: map[string]int{
+					"1.2.3.4": 3,
+					"5.6.7.8": 1,
+				},
+				Events: map[string]*EventSummary{
+					"s3.amazonaws.com:GetObject": {
+						Name:   "GetObject",
+						Source: "s3.amazonaws.com",
+						Count:  4,
+						ErrorCodes: map[string]int{
+							"AccessDenied": 1,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var rows []*ExportRow
+	require.NoError(t, r.ExportRows(ExportSource{ReportId: "r1", AccountId: "123456789012"}, func(row *ExportRow) error {
+		rows = append(rows, row)
+		return nil
+	}))
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, ExportRowTypeEvent, rows[0].Type)
+	assert.Equal(t, "AccessDenied", rows[0].ErrorCode)
+	assert.Equal(t, 1, rows[0].Count)
+	assert.Equal(t, "r1", rows[0].ReportId)
+	assert.Equal(t, r.StartTime.Add(time.Hour), rows[0].EndTime)
+
+	assert.Equal(t, ExportRowTypeEvent, rows[1].Type)
+	assert.Empty(t, rows[1].ErrorCode)
+	assert.Equal(t, 3, rows[1].Count)
+
+	assert.Equal(t, ExportRowTypeIPAddress, rows[2].Type)
+	assert.Equal(t, "1.2.3.4", rows[2].IPAddress)
+	assert.Equal(t, "US", rows[2].CountryCode)
+	assert.Equal(t, 3, rows[2].Count)
+
+	assert.Equal(t, "5.6.7.8", rows[3].IPAddress)
+	assert.Empty(t, rows[3].Network)
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewExportWriter(&buf, ExportFormatCSV)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, w.WriteRow(row))
+		}
+		require.NoError(t, w.Flush())
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 5)
+		assert.Equal(t, strings.Join(exportCSVHeader, ","), lines[0])
+		assert.Equal(t, "r1,123456789012,,2025-03-06T02:00:00Z,2025-03-06T03:00:00Z,event,alice,alice,AWSIAMUser,,s3.amazonaws.com,GetObject,AccessDenied,,,,,,1", lines[1])
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewExportWriter(&buf, ExportFormatNDJSON)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, w.WriteRow(row))
+		}
+		require.NoError(t, w.Flush())
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.Contains(t, lines[2], `"ipAddress":"1.2.3.4"`)
+		assert.Contains(t, lines[2], `"cityName":"Dallas"`)
+	})
+}
@@ -69,6 +69,100 @@ type EventSummary struct {
 	Name   string `json:"name"`
 	Source string `json:"source"`
 
+	// The IAM namespace and display name of the service the event belongs to. These may be empty
+	// for older reports.
+	ServiceNamespace string `json:"serviceNamespace,omitempty"`
+	ServiceName      string `json:"serviceName,omitempty"`
+
 	Count      int            `json:"count"`
 	ErrorCodes map[string]int `json:"errorCodes,omitempty"`
 }
+
+// Merges the data from another report into this one. The time range of the receiver is expanded to
+// include the other report's time range. No references to the other report's data are retained.
+func (r *Report) Merge(other *Report) {
+	if r.StartTime.IsZero() {
+		r.StartTime = other.StartTime
+		r.DurationSeconds = other.DurationSeconds
+	} else if !other.StartTime.IsZero() {
+		endTime := r.StartTime.Add(r.Duration())
+		if otherEndTime := other.StartTime.Add(other.Duration()); otherEndTime.After(endTime) {
+			endTime = otherEndTime
+		}
+		if other.StartTime.Before(r.StartTime) {
+			r.StartTime = other.StartTime
+		}
+		r.DurationSeconds = int(endTime.Sub(r.StartTime).Seconds())
+	}
+
+	r.SourceBytes += other.SourceBytes
+	r.IsIncomplete = r.IsIncomplete || other.IsIncomplete
+
+	for network, location := range other.NetworkLocations {
+		if r.NetworkLocations == nil {
+			r.NetworkLocations = make(map[string]*Location)
+		}
+		if _, ok := r.NetworkLocations[network]; !ok {
+			copy := *location
+			r.NetworkLocations[network] = &copy
+		}
+	}
+
+	for ip, network := range other.IPAddressNetworks {
+		if r.IPAddressNetworks == nil {
+			r.IPAddressNetworks = make(map[string]*string)
+		}
+		if existing, ok := r.IPAddressNetworks[ip]; !ok || existing == nil {
+			if network != nil {
+				network := *network
+				r.IPAddressNetworks[ip] = &network
+			} else {
+				r.IPAddressNetworks[ip] = nil
+			}
+		}
+	}
+
+	for key, otherPrincipal := range other.Principals {
+		if r.Principals == nil {
+			r.Principals = make(map[string]*Principal)
+		}
+		principal, ok := r.Principals[key]
+		if !ok {
+			principal = &Principal{
+				Name: otherPrincipal.Name,
+				Type: otherPrincipal.Type,
+				ARN:  otherPrincipal.ARN,
+			}
+			r.Principals[key] = principal
+		}
+		principal.UserAgents = mergeCounts(principal.UserAgents, otherPrincipal.UserAgents)
+		principal.IPAddresses = mergeCounts(principal.IPAddresses, otherPrincipal.IPAddresses)
+		for eventKey, otherEvent := range otherPrincipal.Events {
+			if principal.Events == nil {
+				principal.Events = make(map[string]*EventSummary)
+			}
+			event, ok := principal.Events[eventKey]
+			if !ok {
+				event = &EventSummary{
+					Name:             otherEvent.Name,
+					Source:           otherEvent.Source,
+					ServiceNamespace: otherEvent.ServiceNamespace,
+					ServiceName:      otherEvent.ServiceName,
+				}
+				principal.Events[eventKey] = event
+			}
+			event.Count += otherEvent.Count
+			event.ErrorCodes = mergeCounts(event.ErrorCodes, otherEvent.ErrorCodes)
+		}
+	}
+}
+
+func mergeCounts(dest, src map[string]int) map[string]int {
+	for k, v := range src {
+		if dest == nil {
+			dest = make(map[string]int, len(src))
+		}
+		dest[k] += v
+	}
+	return dest
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport_Merge(t *testing.T) {
+	network := "1.2.3.0/24"
+
+	r := &Report{
+		StartTime:       time.Date(2025, 3, 6, 2, 0, 0, 0, time.UTC),
+		DurationSeconds: 60 * 60,
+		SourceBytes:     100,
+		Principals: map[string]*Principal{
+			"alice": {
+				Name: "alice",
+				IPAddresses: map[string]int{
+					"1.2.3.4": 1,
+				},
+				Events: map[string]*EventSummary{
+					"s3.amazonaws.com:GetObject": {
+						Name:   "GetObject",
+						Source: "s3.amazonaws.com",
+						Count:  2,
+					},
+				},
+			},
+		},
+	}
+
+	r.Merge(&Report{
+		StartTime:       time.Date(2025, 3, 6, 4, 0, 0, 0, time.UTC),
+		DurationSeconds: 60 * 60,
+		SourceBytes:     50,
+		IsIncomplete:    true,
+		NetworkLocations: map[string]*Location{
+			network: {CountryCode: "US"},
+		},
+		IPAddressNetworks: map[string]*string{
+			"1.2.3.4": &network,
+		},
+		Principals: map[string]*Principal{
+			"alice": {
+				Name: "alice",
+				IPAddresses: map[string]int{
+					"1.2.3.4": 3,
+				},
+				Events: map[string]*EventSummary{
+					"s3.amazonaws.com:GetObject": {
+						Name:   "GetObject",
+						Source: "s3.amazonaws.com",
+						Count:  1,
+						ErrorCodes: map[string]int{
+							"AccessDenied": 1,
+						},
+					},
+				},
+			},
+			"bob": {
+				Name: "bob",
+			},
+		},
+	})
+
+	assert.Equal(t, time.Date(2025, 3, 6, 2, 0, 0, 0, time.UTC), r.StartTime)
+	assert.Equal(t, 3*60*60, r.DurationSeconds)
+	assert.Equal(t, int64(150), r.SourceBytes)
+	assert.True(t, r.IsIncomplete)
+	assert.Equal(t, "US", r.NetworkLocations[network].CountryCode)
+	assert.Equal(t, network, *r.IPAddressNetworks["1.2.3.4"])
+	assert.Len(t, r.Principals, 2)
+	assert.Equal(t, 4, r.Principals["alice"].IPAddresses["1.2.3.4"])
+	event := r.Principals["alice"].Events["s3.amazonaws.com:GetObject"]
+	assert.Equal(t, 3, event.Count)
+	assert.Equal(t, map[string]int{"AccessDenied": 1}, event.ErrorCodes)
+}
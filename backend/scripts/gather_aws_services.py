@@ -0,0 +1,103 @@
+#!/usr/bin/env python3
+"""
+Generates the entries for KnownAWSServices in app/aws_services.go.
+
+Service metadata comes from the API models bundled with botocore. CloudTrail event sources are
+derived from each service's endpoint prefix, and IAM namespaces from its signing name. Both are
+checked against the IAM service reference so that namespaces which don't correspond to any IAM
+service are reported rather than silently emitted.
+"""
+import json
+import sys
+import urllib.request
+
+import botocore.session
+
+SERVICE_REFERENCE_URL = 'https://servicereference.us-east-1.amazonaws.com/'
+
+"""
+Some services don't follow the usual conventions. These take precedence over anything derived from
+the botocore models.
+"""
+event_source_overrides = {
+    # CloudWatch's endpoint is "monitoring", but its actions are "cloudwatch:*".
+    'cloudwatch': 'monitoring.amazonaws.com',
+    # SES's endpoint is "email", but its actions are "ses:*".
+    'ses': 'email.amazonaws.com',
+    'sesv2': 'ses.amazonaws.com',
+    'lexv2-models': 'lex.amazonaws.com',
+    'meteringmarketplace': 'metering-marketplace.amazonaws.com',
+    'resourcegroupstaggingapi': 'tagging.amazonaws.com',
+    'cloudcontrol': 'cloudcontrolapi.amazonaws.com',
+    'ecr': 'ecr.amazonaws.com',
+    'elbv2': 'elasticloadbalancing.amazonaws.com',
+    'emr': 'elasticmapreduce.amazonaws.com',
+    'opensearch': 'es.amazonaws.com',
+}
+
+namespace_overrides = {
+    'monitoring.amazonaws.com': 'cloudwatch',
+    'email.amazonaws.com': 'ses',
+    'tagging.amazonaws.com': 'tag',
+    'cloudcontrolapi.amazonaws.com': 'cloudformation',
+    'metering-marketplace.amazonaws.com': 'aws-marketplace',
+    'billingconsole.amazonaws.com': 'aws-portal',
+    'models.lex.amazonaws.com': 'lex',
+    'runtime.lex.amazonaws.com': 'lex',
+    'api.ecr.amazonaws.com': 'ecr',
+    'iotdata.amazonaws.com': 'iot',
+    'iot-data.amazonaws.com': 'iot',
+}
+
+"""
+Event sources that don't have a botocore model, but do show up in CloudTrail logs.
+"""
+extra_services = {
+    'billingconsole.amazonaws.com': 'AWS Billing Console',
+    'signin.amazonaws.com': 'AWS Sign-In',
+    'ssmmessages.amazonaws.com': 'Amazon Session Manager Message Gateway Service',
+    # Alternate event sources that some services use for some of their events.
+    'models.lex.amazonaws.com': 'Amazon Lex Model Building Service',
+    'runtime.lex.amazonaws.com': 'Amazon Lex Runtime Service',
+    'api.ecr.amazonaws.com': 'Amazon EC2 Container Registry',
+    'iotdata.amazonaws.com': 'AWS IoT Data Plane',
+    'iot-data.amazonaws.com': 'AWS IoT Data Plane',
+}
+
+# Services that are superseded by others with the same event source.
+skipped_services = {
+    'elb',
+    'es',
+    'lex-models',
+}
+
+
+def get_iam_namespaces():
+    with urllib.request.urlopen(SERVICE_REFERENCE_URL) as resp:
+        return {s['service'] for s in json.load(resp)}
+
+
+def get_services():
+    session = botocore.session.get_session()
+    ret = {}
+    for service_name in session.get_available_services():
+        if service_name in skipped_services:
+            continue
+        model = session.get_service_model(service_name)
+        metadata = model.metadata
+        event_source = event_source_overrides.get(service_name, f'{metadata["endpointPrefix"]}.amazonaws.com')
+        namespace = namespace_overrides.get(event_source, metadata.get('signingName', metadata['endpointPrefix']))
+        ret[event_source] = (namespace, metadata['serviceFullName'])
+    for event_source, name in extra_services.items():
+        namespace = namespace_overrides.get(event_source, event_source.removesuffix('.amazonaws.com'))
+        ret[event_source] = (namespace, name)
+    return ret
+
+
+iam_namespaces = get_iam_namespaces()
+
+for event_source, (namespace, name) in sorted(get_services().items()):
+    if namespace not in iam_namespaces and event_source not in extra_services:
+        print(f'skipping {event_source}: unknown iam namespace "{namespace}"', file=sys.stderr)
+        continue
+    print(f'"{event_source}": {{"{namespace}", "{name}"}},')
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedAlertRule struct {
+	*model.AlertRule
+
+	PrimaryIndex
+	ByteByteIndex1
+}
+
+func (s *Store) PutAlertRule(ctx context.Context, rule *model.AlertRule) error {
+	return s.put(ctx, &IndexedAlertRule{
+		AlertRule: rule,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("alert_rule:" + rule.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("alert_rules:" + rule.TeamId),
+			RangeKey: []byte(rule.Id),
+		},
+	})
+}
+
+func (s *Store) GetAlertRuleById(ctx context.Context, id model.Id) (*model.AlertRule, error) {
+	return getByPrimaryKey[model.AlertRule](ctx, s, []byte("alert_rule:"+id), ConsistencyEventual)
+}
+
+func (s *Store) GetAlertRulesByTeamId(ctx context.Context, teamId model.Id) ([]*model.AlertRule, error) {
+	return getAllByHashKey[model.AlertRule](ctx, s, "_bb1", "_bb1h", []byte("alert_rules:"+teamId))
+}
+
+func (s *Store) DeleteAlertRuleById(ctx context.Context, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("alert_rule:"+id))
+}
+
+type IndexedAlert struct {
+	*model.Alert
+
+	PrimaryIndex
+	ByteByteIndex1
+
+	TTL
+}
+
+// Prevents alerts with the same rule and fingerprint from being created until it expires. This is
+// kept separate from the alert itself so that alerts can be retained for longer than the
+// deduplication window.
+type IndexedAlertDeduplicationLock struct {
+	AlertId model.Id
+
+	PrimaryIndex
+
+	TTL
+}
+
+// Fingerprints can contain arbitrarily long principal keys, so they're hashed to keep keys small.
+func alertDeduplicationLockHashKey(teamId, alertRuleId model.Id, fingerprint string) []byte {
+	fingerprintHash := sha256.Sum256([]byte(fingerprint))
+	return []byte("alert_dedup:" + teamId.String() + ":" + alertRuleId.String() + ":" + hex.EncodeToString(fingerprintHash[:]))
+}
+
+// Creates the alert unless an unexpired alert with the same rule and fingerprint was created before
+// deduplicationExpirationTime. Returns true if the alert was created.
+func (s *Store) CreateAlert(ctx context.Context, alert *model.Alert, deduplicationExpirationTime time.Time) (bool, error) {
+	now := attributevalue.UnixTime(time.Now())
+	condition := expression.AttributeNotExists(expression.Name("_hk")).Or(expression.Name("_ttl").LessThan(expression.Value(&now)))
+	if ok, err := s.putWithCondition(ctx, &IndexedAlertDeduplicationLock{
+		AlertId: alert.Id,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  alertDeduplicationLockHashKey(alert.TeamId, alert.AlertRuleId, alert.Fingerprint),
+			RangeKey: []byte("_"),
+		},
+		TTL: NewTTL(deduplicationExpirationTime),
+	}, condition); err != nil || !ok {
+		return false, err
+	}
+
+	if err := s.put(ctx, &IndexedAlert{
+		Alert: alert,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("alert:" + alert.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("alerts:" + alert.TeamId),
+			RangeKey: []byte(alert.Id),
+		},
+		TTL: NewTTL(alert.ExpirationTime),
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) GetAlertsByTeamId(ctx context.Context, teamId model.Id) ([]*model.Alert, error) {
+	return getAllByHashKey[model.Alert](ctx, s, "_bb1", "_bb1h", []byte("alerts:"+teamId))
+}
@@ -0,0 +1,80 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestAlertRule(t *testing.T) {
+	s := NewTestStore(t)
+
+	rule := &model.AlertRule{
+		Id:                  model.NewAlertRuleId(),
+		TeamId:              model.NewTeamId(),
+		CreationTime:        time.Now().Truncate(time.Second).UTC(),
+		Name:                "Unexpected Country",
+		Type:                model.AlertRuleTypeUnexpectedCountry,
+		AllowedCountryCodes: []string{"US"},
+	}
+	require.NoError(t, s.PutAlertRule(context.Background(), rule))
+
+	got, err := s.GetAlertRuleById(context.Background(), rule.Id)
+	require.NoError(t, err)
+	assert.Equal(t, rule, got)
+
+	rules, err := s.GetAlertRulesByTeamId(context.Background(), rule.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.AlertRule{rule}, rules)
+
+	require.NoError(t, s.DeleteAlertRuleById(context.Background(), rule.Id))
+
+	rules, err = s.GetAlertRulesByTeamId(context.Background(), rule.TeamId)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestAlert(t *testing.T) {
+	s := NewTestStore(t)
+
+	teamId := model.NewTeamId()
+	ruleId := model.NewAlertRuleId()
+
+	newAlert := func() *model.Alert {
+		return &model.Alert{
+			Id:             model.NewAlertId(),
+			TeamId:         teamId,
+			AlertRuleId:    ruleId,
+			CreationTime:   time.Now().Truncate(time.Second).UTC(),
+			ExpirationTime: time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC(),
+			Fingerprint:    "alice:CN",
+			Message:        "alice made calls from China.",
+		}
+	}
+
+	first := newAlert()
+	created, err := s.CreateAlert(context.Background(), first, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// The first alert's deduplication window has passed, so this one is created too.
+	second := newAlert()
+	created, err = s.CreateAlert(context.Background(), second, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// The second alert's deduplication window hasn't passed, so this one is deduplicated.
+	created, err = s.CreateAlert(context.Background(), newAlert(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// Both alerts are retained despite sharing a fingerprint.
+	alerts, err := s.GetAlertsByTeamId(context.Background(), teamId)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*model.Alert{first, second}, alerts)
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedAuditEvent struct {
+	*model.AuditEvent
+
+	PrimaryIndex
+	ByteByteIndex1
+
+	TTL
+}
+
+// Creates the audit event. Existing events are never overwritten.
+func (s *Store) CreateAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	_, err := s.putWithCondition(ctx, &IndexedAuditEvent{
+		AuditEvent: event,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("audit_event:" + event.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("audit_events:" + event.TeamId),
+			RangeKey: append(sortableTimeKey(event.Time), []byte(":"+event.Id)...),
+		},
+		TTL: NewTTL(event.ExpirationTime),
+	}, expression.AttributeNotExists(expression.Name("_hk")))
+	return err
+}
+
+type GetAuditEventPageByTeamIdInput struct {
+	PageInput
+
+	// Only events that occurred at or after this time are returned.
+	StartTime time.Time
+
+	// Only events that occurred before this time are returned.
+	EndTime time.Time
+}
+
+// Gets a page of the team's audit events, most recent first.
+func (s *Store) GetAuditEventPageByTeamId(ctx context.Context, teamId model.Id, input GetAuditEventPageByTeamIdInput) (*Page[model.AuditEvent], error) {
+	return getPageByHashKey[model.AuditEvent](ctx, s, getPageByHashKeyInput{
+		PageInput:    input.PageInput,
+		Index:        "_bb1",
+		HashKeyName:  "_bb1h",
+		HashKey:      []byte("audit_events:" + teamId),
+		RangeKeyName: "_bb1r",
+		MinRangeKey:  sortableTimeKey(input.StartTime),
+		// Range keys have an id suffix, so events that occurred exactly at this time are excluded.
+		MaxRangeKey: sortableTimeKey(input.EndTime),
+		Descending:  true,
+	})
+}
@@ -0,0 +1,63 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+func TestAuditEvent(t *testing.T) {
+	s := NewTestStore(t)
+
+	teamId := model.NewTeamId()
+	now := time.Now().Truncate(time.Second).UTC()
+
+	var events []*model.AuditEvent
+	for i := range 3 {
+		event := &model.AuditEvent{
+			Id:             model.NewAuditEventId(),
+			TeamId:         teamId,
+			Time:           now.Add(time.Duration(i) * time.Hour),
+			ExpirationTime: now.Add(24 * time.Hour),
+			ActorUserId:    model.NewUserId(),
+			IPAddress:      "127.0.0.1",
+			Action:         model.AuditEventActionTeamUpdate,
+			TargetId:       teamId.String(),
+			Before:         `{"Name":"Before"}`,
+			After:          `{"Name":"After"}`,
+		}
+		require.NoError(t, s.CreateAuditEvent(context.Background(), event))
+		events = append(events, event)
+	}
+
+	page, err := s.GetAuditEventPageByTeamId(context.Background(), teamId, store.GetAuditEventPageByTeamIdInput{
+		PageInput: store.PageInput{Limit: 10},
+		StartTime: now.Add(30 * time.Minute),
+		EndTime:   now.Add(24 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.AuditEvent{events[2], events[1]}, page.Items)
+
+	page, err = s.GetAuditEventPageByTeamId(context.Background(), teamId, store.GetAuditEventPageByTeamIdInput{
+		PageInput: store.PageInput{Limit: 2},
+		StartTime: now,
+		EndTime:   events[2].Time,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.AuditEvent{events[1], events[0]}, page.Items)
+	require.NotEmpty(t, page.Cursor)
+
+	page, err = s.GetAuditEventPageByTeamId(context.Background(), teamId, store.GetAuditEventPageByTeamIdInput{
+		PageInput: store.PageInput{Limit: 2, Cursor: page.Cursor},
+		StartTime: now,
+		EndTime:   events[2].Time,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
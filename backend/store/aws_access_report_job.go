@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedAWSAccessReportJob struct {
+	*model.AWSAccessReportJob
+
+	PrimaryIndex
+
+	TTL
+}
+
+func awsAccessReportJobHashKey(teamId model.Id, accountId string) []byte {
+	return []byte("aws_access_report_job:" + teamId.String() + ":" + accountId)
+}
+
+// Puts the job, replacing any existing job for the same team and account.
+func (s *Store) PutAWSAccessReportJob(ctx context.Context, job *model.AWSAccessReportJob) error {
+	return s.put(ctx, &IndexedAWSAccessReportJob{
+		AWSAccessReportJob: job,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  awsAccessReportJobHashKey(job.TeamId, job.AccountId),
+			RangeKey: []byte("_"),
+		},
+		TTL: NewTTL(job.ExpirationTime),
+	})
+}
+
+func (s *Store) GetAWSAccessReportJobByTeamAndAccountId(ctx context.Context, teamId model.Id, accountId string, consistency Consistency) (*model.AWSAccessReportJob, error) {
+	return getByPrimaryKey[model.AWSAccessReportJob](ctx, s, awsAccessReportJobHashKey(teamId, accountId), consistency)
+}
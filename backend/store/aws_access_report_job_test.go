@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
+)
+
+func TestAWSAccessReportJob(t *testing.T) {
+	s := NewTestStore(t)
+
+	job := &model.AWSAccessReportJob{
+		TeamId:           model.NewTeamId(),
+		AccountId:        "123456789012",
+		AWSIntegrationId: model.NewAWSIntegrationId(),
+		IAMJobId:         "job-id",
+		CreationTime:     time.Now().Truncate(time.Second).UTC(),
+		ExpirationTime:   time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		Status:           model.AWSAccessReportJobStatusInProgress,
+	}
+
+	require.NoError(t, s.PutAWSAccessReportJob(context.Background(), job))
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := s.GetAWSAccessReportJobByTeamAndAccountId(context.Background(), job.TeamId, job.AccountId, store.ConsistencyStrongInRegion)
+		require.NoError(t, err)
+		assert.Equal(t, job, got)
+
+		notAJob, err := s.GetAWSAccessReportJobByTeamAndAccountId(context.Background(), job.TeamId, "000000000000", store.ConsistencyStrongInRegion)
+		require.NoError(t, err)
+		assert.Nil(t, notAJob)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		job.Status = model.AWSAccessReportJobStatusCompleted
+		job.CompletionTime = time.Now().Truncate(time.Second).UTC()
+		job.Report = &model.AWSAccessReport{
+			Services: []model.AWSAccessReportService{
+				{
+					Name:      "AWS IAM Access Analyzer",
+					Namespace: "access-analyzer",
+				},
+			},
+		}
+		require.NoError(t, s.PutAWSAccessReportJob(context.Background(), job))
+
+		got, err := s.GetAWSAccessReportJobByTeamAndAccountId(context.Background(), job.TeamId, job.AccountId, store.ConsistencyStrongInRegion)
+		require.NoError(t, err)
+		assert.Equal(t, job, got)
+	})
+}
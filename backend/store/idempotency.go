@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedIdempotentRequest struct {
+	*model.IdempotentRequest
+
+	PrimaryIndex
+
+	TTL
+}
+
+func idempotentRequestHashKey(keyHash []byte) []byte {
+	return append([]byte("idempotent_request:"), keyHash...)
+}
+
+func newIndexedIdempotentRequest(r *model.IdempotentRequest) *IndexedIdempotentRequest {
+	return &IndexedIdempotentRequest{
+		IdempotentRequest: r,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  idempotentRequestHashKey(r.KeyHash),
+			RangeKey: []byte("_"),
+		},
+		TTL: NewTTL(r.ExpirationTime),
+	}
+}
+
+// Creates the request unless an unexpired request with the same key hash already exists. Returns
+// true if the request was created.
+func (s *Store) CreateIdempotentRequest(ctx context.Context, r *model.IdempotentRequest) (bool, error) {
+	now := attributevalue.UnixTime(time.Now())
+	condition := expression.AttributeNotExists(expression.Name("_hk")).Or(expression.Name("_ttl").LessThan(expression.Value(&now)))
+	return s.putWithCondition(ctx, newIndexedIdempotentRequest(r), condition)
+}
+
+func (s *Store) PutIdempotentRequest(ctx context.Context, r *model.IdempotentRequest) error {
+	return s.put(ctx, newIndexedIdempotentRequest(r))
+}
+
+func (s *Store) GetIdempotentRequestByKeyHash(ctx context.Context, keyHash []byte) (*model.IdempotentRequest, error) {
+	return getByPrimaryKey[model.IdempotentRequest](ctx, s, idempotentRequestHashKey(keyHash), ConsistencyStrongInRegion)
+}
+
+func (s *Store) DeleteIdempotentRequestByKeyHash(ctx context.Context, keyHash []byte) error {
+	return deleteByPrimaryKey(ctx, s, idempotentRequestHashKey(keyHash))
+}
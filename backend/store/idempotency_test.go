@@ -0,0 +1,46 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestIdempotentRequest(t *testing.T) {
+	s := NewTestStore(t)
+
+	r := &model.IdempotentRequest{
+		KeyHash:        []byte("key"),
+		Fingerprint:    []byte("fingerprint"),
+		CreationTime:   time.Now().Truncate(time.Second).UTC(),
+		ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+	}
+
+	created, err := s.CreateIdempotentRequest(context.Background(), r)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = s.CreateIdempotentRequest(context.Background(), r)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	r.ResponseStatusCode = 200
+	r.ResponseContentType = "application/json"
+	r.EncryptedResponseBody = []byte(`{}`)
+	require.NoError(t, s.PutIdempotentRequest(context.Background(), r))
+
+	got, err := s.GetIdempotentRequestByKeyHash(context.Background(), r.KeyHash)
+	require.NoError(t, err)
+	assert.Equal(t, r, got)
+
+	require.NoError(t, s.DeleteIdempotentRequestByKeyHash(context.Background(), r.KeyHash))
+
+	got, err = s.GetIdempotentRequestByKeyHash(context.Background(), r.KeyHash)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
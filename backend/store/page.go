@@ -0,0 +1,170 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Returned when a cursor is malformed or was returned by a different query.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type PageInput struct {
+	// The maximum number of items to return. Must be positive.
+	Limit int
+
+	// A cursor returned with a previous page. If empty, the first page is returned.
+	Cursor string
+}
+
+type Page[T any] struct {
+	Items []*T
+
+	// Can be used to get the next page. Empty if there are no more items. The next page may be
+	// empty even if this is given.
+	Cursor string
+}
+
+// Cursors are the query's LastEvaluatedKey, encoded as base64 JSON. All key attributes in the table
+// are binary, so the key can be represented as a map of byte slices.
+func encodeCursor(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+	m := make(map[string][]byte, len(key))
+	for name, value := range key {
+		b, ok := value.(*types.AttributeValueMemberB)
+		if !ok {
+			return "", errors.New("unexpected non-binary key attribute")
+		}
+		m[name] = b.Value
+	}
+	buf, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Decodes a cursor, verifying that it only contains the given key attributes and that it was
+// returned by a query for the given hash key.
+func decodeCursor(cursor string, hkname string, hk []byte, allowedNames ...string) (map[string]types.AttributeValue, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var m map[string][]byte
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, ErrInvalidCursor
+	} else if !bytes.Equal(m[hkname], hk) {
+		return nil, ErrInvalidCursor
+	}
+
+	ret := make(map[string]types.AttributeValue, len(m))
+	for name, value := range m {
+		allowed := name == hkname
+		for _, allowedName := range allowedNames {
+			allowed = allowed || name == allowedName
+		}
+		if !allowed {
+			return nil, ErrInvalidCursor
+		}
+		ret[name] = &types.AttributeValueMemberB{
+			Value: value,
+		}
+	}
+	return ret, nil
+}
+
+type getPageByHashKeyInput struct {
+	PageInput
+
+	Index        string
+	HashKeyName  string
+	HashKey      []byte
+	RangeKeyName string
+
+	// Optional inclusive bounds on the range key.
+	MinRangeKey []byte
+	MaxRangeKey []byte
+
+	// If true, items are returned in descending order of range key.
+	Descending bool
+}
+
+func getPageByHashKey[T any](ctx context.Context, s *Store, input getPageByHashKeyInput) (*Page[T], error) {
+	var indexNamePtr *string
+	if input.Index != "" {
+		indexNamePtr = &input.Index
+	}
+
+	names := map[string]string{
+		"#hk": input.HashKeyName,
+	}
+	values := map[string]types.AttributeValue{
+		":hkv": &types.AttributeValueMemberB{Value: input.HashKey},
+	}
+	condition := "#hk = :hkv"
+	if input.MinRangeKey != nil || input.MaxRangeKey != nil {
+		names["#rk"] = input.RangeKeyName
+	}
+	if input.MinRangeKey != nil {
+		values[":min"] = &types.AttributeValueMemberB{Value: input.MinRangeKey}
+	}
+	if input.MaxRangeKey != nil {
+		values[":max"] = &types.AttributeValueMemberB{Value: input.MaxRangeKey}
+	}
+	switch {
+	case input.MinRangeKey != nil && input.MaxRangeKey != nil:
+		condition += " AND #rk BETWEEN :min AND :max"
+	case input.MinRangeKey != nil:
+		condition += " AND #rk >= :min"
+	case input.MaxRangeKey != nil:
+		condition += " AND #rk <= :max"
+	}
+
+	var exclusiveStartKey map[string]types.AttributeValue
+	if input.Cursor != "" {
+		key, err := decodeCursor(input.Cursor, input.HashKeyName, input.HashKey, "_hk", "_rk", input.RangeKeyName)
+		if err != nil {
+			return nil, err
+		}
+		exclusiveStartKey = key
+	}
+
+	output, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		KeyConditionExpression:    aws.String(condition),
+		IndexName:                 indexNamePtr,
+		TableName:                 &s.config.DynamoDB.TableName,
+		ExclusiveStartKey:         exclusiveStartKey,
+		Limit:                     aws.Int32(int32(input.Limit)),
+		ScanIndexForward:          aws.Bool(!input.Descending),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Page[T]{
+		Items: make([]*T, 0, len(output.Items)),
+	}
+	for _, item := range output.Items {
+		var v T
+		if err := attributevalue.UnmarshalMap(item, &v); err != nil {
+			return nil, err
+		}
+		ret.Items = append(ret.Items, &v)
+	}
+	if ret.Cursor, err = encodeCursor(output.LastEvaluatedKey); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
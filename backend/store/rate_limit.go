@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+type rateLimitCounter struct {
+	Count int
+}
+
+// Atomically increments the counter with the given key and returns its new value. Counters start
+// at zero and are deleted some time after the given expiration time.
+func (s *Store) IncrementRateLimitCounter(ctx context.Context, key string, expirationTime time.Time) (int, error) {
+	update := expression.
+		Add(expression.Name("Count"), expression.Value(1)).
+		Set(expression.Name("_ttl"), expression.Value(attributevalue.UnixTime(expirationTime)))
+	counter, err := createOrUpdateByPrimaryKey[rateLimitCounter](ctx, s, []byte("rate_limit:"+key), update)
+	if err != nil {
+		return 0, err
+	} else if counter == nil {
+		return 0, nil
+	}
+	return counter.Count, nil
+}
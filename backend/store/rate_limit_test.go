@@ -0,0 +1,26 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitCounter(t *testing.T) {
+	s := NewTestStore(t)
+
+	expirationTime := time.Now().Add(time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		count, err := s.IncrementRateLimitCounter(context.Background(), "foo", expirationTime)
+		require.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+
+	count, err := s.IncrementRateLimitCounter(context.Background(), "bar", expirationTime)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
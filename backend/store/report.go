@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 
 	"github.com/ccbrown/cloud-snitch/backend/model"
 )
@@ -24,12 +27,32 @@ func (s *Store) PutReport(ctx context.Context, report *model.Report) error {
 		},
 		ByteByteIndex1: ByteByteIndex1{
 			HashKey:  []byte("reports:" + report.TeamId),
-			RangeKey: []byte(report.Id),
+			RangeKey: reportRangeKey(report),
 		},
 		TTL: NewTTL(report.ExpirationTime),
 	})
 }
 
+// Ordering by start time allows reports to be paginated within a time range.
+func reportRangeKey(report *model.Report) []byte {
+	return append(sortableTimeKey(report.Scope.StartTime), []byte(":"+report.Id)...)
+}
+
+// Reports written before their range keys were ordered by start time can't be found by
+// GetReportPageByTeamId's time bounds until they're reindexed. Returns false if the report doesn't
+// exist.
+func (s *Store) ReindexReport(ctx context.Context, report *model.Report) (bool, error) {
+	updated, err := updateByPrimaryKey[model.Report](ctx, s, []byte("report:"+report.Id), expression.UpdateBuilder{}.
+		Set(expression.Name("_bb1r"), expression.Value(reportRangeKey(report))))
+	return updated != nil, err
+}
+
+// Gets the team's reports that haven't been reindexed yet. Their range keys are their ids, which
+// sort after all of the time-ordered range keys.
+func (s *Store) GetLegacyIndexedReportsByTeamId(ctx context.Context, teamId model.Id) ([]*model.Report, error) {
+	return getAllByHashKeyWithMinRangeKey[model.Report](ctx, s, "_bb1", "_bb1h", []byte("reports:"+teamId), "_bb1r", []byte("r-"))
+}
+
 func (s *Store) GetReportById(ctx context.Context, id model.Id) (*model.Report, error) {
 	return getByPrimaryKey[model.Report](ctx, s, []byte("report:"+id), ConsistencyEventual)
 }
@@ -38,6 +61,36 @@ func (s *Store) GetReportsByTeamId(ctx context.Context, teamId model.Id) ([]*mod
 	return getAllByHashKey[model.Report](ctx, s, "_bb1", "_bb1h", []byte("reports:"+teamId))
 }
 
+type GetReportPageByTeamIdInput struct {
+	PageInput
+
+	// If given, only reports that start at or after this time are returned.
+	MinStartTime time.Time
+
+	// If given, only reports that start before this time are returned.
+	MaxStartTime time.Time
+}
+
+// Gets a page of the team's reports, ordered by start time with the most recent first.
+func (s *Store) GetReportPageByTeamId(ctx context.Context, teamId model.Id, input GetReportPageByTeamIdInput) (*Page[model.Report], error) {
+	pageInput := getPageByHashKeyInput{
+		PageInput:    input.PageInput,
+		Index:        "_bb1",
+		HashKeyName:  "_bb1h",
+		HashKey:      []byte("reports:" + teamId),
+		RangeKeyName: "_bb1r",
+		Descending:   true,
+	}
+	if !input.MinStartTime.IsZero() {
+		pageInput.MinRangeKey = sortableTimeKey(input.MinStartTime)
+	}
+	if !input.MaxStartTime.IsZero() {
+		// Range keys have an id suffix, so reports that start exactly at this time are excluded.
+		pageInput.MaxRangeKey = sortableTimeKey(input.MaxStartTime)
+	}
+	return getPageByHashKey[model.Report](ctx, s, pageInput)
+}
+
 func (s *Store) DeleteReportById(ctx context.Context, id model.Id) error {
 	return s.DeleteReportsByIds(ctx, id)
 }
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedReportGenerationJob struct {
+	*model.ReportGenerationJob
+
+	PrimaryIndex
+	ByteByteIndex1
+
+	TTL
+}
+
+func indexedReportGenerationJob(job *model.ReportGenerationJob) *IndexedReportGenerationJob {
+	return &IndexedReportGenerationJob{
+		ReportGenerationJob: job,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("report_generation_job:" + job.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("report_generation_jobs:" + job.TeamId),
+			RangeKey: []byte(job.Id),
+		},
+		TTL: NewTTL(job.ExpirationTime),
+	}
+}
+
+func (s *Store) PutReportGenerationJob(ctx context.Context, job *model.ReportGenerationJob) error {
+	return s.put(ctx, indexedReportGenerationJob(job))
+}
+
+// Puts any number of jobs. This is not atomic.
+func (s *Store) PutReportGenerationJobs(ctx context.Context, jobs ...*model.ReportGenerationJob) error {
+	items := make([]any, len(jobs))
+	for i, job := range jobs {
+		items[i] = indexedReportGenerationJob(job)
+	}
+	return s.batchPut(ctx, items...)
+}
+
+func (s *Store) GetReportGenerationJobById(ctx context.Context, id model.Id) (*model.ReportGenerationJob, error) {
+	return getByPrimaryKey[model.ReportGenerationJob](ctx, s, []byte("report_generation_job:"+id), ConsistencyStrongInRegion)
+}
+
+func (s *Store) GetReportGenerationJobsByTeamId(ctx context.Context, teamId model.Id) ([]*model.ReportGenerationJob, error) {
+	return getAllByHashKey[model.ReportGenerationJob](ctx, s, "_bb1", "_bb1h", []byte("report_generation_jobs:"+teamId))
+}
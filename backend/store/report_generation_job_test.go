@@ -0,0 +1,65 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestReportGenerationJob(t *testing.T) {
+	s := NewTestStore(t)
+
+	job := &model.ReportGenerationJob{
+		Id:               model.NewReportId(),
+		CreationTime:     time.Now().Truncate(time.Second).UTC(),
+		UpdateTime:       time.Now().Truncate(time.Second).UTC(),
+		ExpirationTime:   time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		TeamId:           model.NewTeamId(),
+		AWSIntegrationId: model.NewAWSIntegrationId(),
+		Scope: model.ReportScope{
+			StartTime: time.Date(2025, 3, 6, 2, 0, 0, 0, time.UTC),
+			Duration:  time.Hour,
+			AWS: model.ReportScopeAWS{
+				AccountId: "123456789012",
+				Region:    "us-east-1",
+			},
+		},
+		State: model.ReportGenerationJobStateQueued,
+	}
+	require.NoError(t, s.PutReportGenerationJob(context.Background(), job))
+
+	job.State = model.ReportGenerationJobStateImporting
+	job.ImportedObjectCount = 1
+	job.TotalObjectCount = 2
+	require.NoError(t, s.PutReportGenerationJob(context.Background(), job))
+
+	got, err := s.GetReportGenerationJobById(context.Background(), job.Id)
+	require.NoError(t, err)
+	assert.Equal(t, job, got)
+
+	jobs, err := s.GetReportGenerationJobsByTeamId(context.Background(), job.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.ReportGenerationJob{job}, jobs)
+
+	t.Run("Batch", func(t *testing.T) {
+		teamId := model.NewTeamId()
+		var batch []*model.ReportGenerationJob
+		for i := 0; i < 30; i++ {
+			batch = append(batch, &model.ReportGenerationJob{
+				Id:     model.NewReportId(),
+				TeamId: teamId,
+				State:  model.ReportGenerationJobStateQueued,
+			})
+		}
+		require.NoError(t, s.PutReportGenerationJobs(context.Background(), batch...))
+
+		jobs, err := s.GetReportGenerationJobsByTeamId(context.Background(), teamId)
+		require.NoError(t, err)
+		assert.Len(t, jobs, 30)
+	})
+}
@@ -3,11 +3,13 @@ package store_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ccbrown/cloud-snitch/backend/model"
+	"github.com/ccbrown/cloud-snitch/backend/store"
 )
 
 func TestReport(t *testing.T) {
@@ -63,4 +65,90 @@ func TestReport(t *testing.T) {
 		require.NoError(t, err)
 		assert.Empty(t, reports)
 	})
+
+	t.Run("Pages", func(t *testing.T) {
+		teamId := model.NewTeamId()
+		start := time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, s.PutReport(context.Background(), &model.Report{
+				Id:     model.NewReportId(),
+				TeamId: teamId,
+				Scope: model.ReportScope{
+					StartTime: start.Add(time.Duration(i) * time.Hour),
+					Duration:  time.Hour,
+				},
+			}))
+		}
+
+		var reports []*model.Report
+		input := store.GetReportPageByTeamIdInput{
+			PageInput: store.PageInput{
+				Limit: 2,
+			},
+		}
+		for {
+			page, err := s.GetReportPageByTeamId(context.Background(), teamId, input)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Items), 2)
+			reports = append(reports, page.Items...)
+			if page.Cursor == "" {
+				break
+			}
+			input.Cursor = page.Cursor
+		}
+		require.Len(t, reports, 5)
+		for i, r := range reports {
+			assert.Equal(t, start.Add(time.Duration(4-i)*time.Hour), r.Scope.StartTime.UTC())
+		}
+
+		page, err := s.GetReportPageByTeamId(context.Background(), teamId, store.GetReportPageByTeamIdInput{
+			PageInput: store.PageInput{
+				Limit: 10,
+			},
+			MinStartTime: start.Add(time.Hour),
+			MaxStartTime: start.Add(3 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 2)
+		assert.Equal(t, start.Add(2*time.Hour), page.Items[0].Scope.StartTime.UTC())
+		assert.Equal(t, start.Add(time.Hour), page.Items[1].Scope.StartTime.UTC())
+
+		_, err = s.GetReportPageByTeamId(context.Background(), model.NewTeamId(), store.GetReportPageByTeamIdInput{
+			PageInput: store.PageInput{
+				Limit:  10,
+				Cursor: input.Cursor,
+			},
+		})
+		assert.ErrorIs(t, err, store.ErrInvalidCursor)
+	})
+
+	t.Run("Reindex", func(t *testing.T) {
+		r := &model.Report{
+			Id:     model.NewReportId(),
+			TeamId: model.NewTeamId(),
+		}
+
+		ok, err := s.ReindexReport(context.Background(), r)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, s.PutReport(context.Background(), r))
+
+		ok, err = s.ReindexReport(context.Background(), r)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		legacy, err := s.GetLegacyIndexedReportsByTeamId(context.Background(), r.TeamId)
+		require.NoError(t, err)
+		assert.Empty(t, legacy)
+
+		page, err := s.GetReportPageByTeamId(context.Background(), r.TeamId, store.GetReportPageByTeamIdInput{
+			PageInput: store.PageInput{
+				Limit: 10,
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, r.Id, page.Items[0].Id)
+	})
 }
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedSIEMSink struct {
+	*model.SIEMSink
+
+	PrimaryIndex
+	ByteByteIndex1
+}
+
+func (s *Store) PutSIEMSink(ctx context.Context, sink *model.SIEMSink) error {
+	return s.put(ctx, &IndexedSIEMSink{
+		SIEMSink: sink,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("siem_sink:" + sink.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("siem_sinks:" + sink.TeamId),
+			RangeKey: []byte(sink.Id),
+		},
+	})
+}
+
+func (s *Store) GetSIEMSinkById(ctx context.Context, id model.Id) (*model.SIEMSink, error) {
+	return getByPrimaryKey[model.SIEMSink](ctx, s, []byte("siem_sink:"+id), ConsistencyEventual)
+}
+
+func (s *Store) GetSIEMSinksByTeamId(ctx context.Context, teamId model.Id) ([]*model.SIEMSink, error) {
+	return getAllByHashKey[model.SIEMSink](ctx, s, "_bb1", "_bb1h", []byte("siem_sinks:"+teamId))
+}
+
+func (s *Store) DeleteSIEMSinkById(ctx context.Context, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("siem_sink:"+id))
+}
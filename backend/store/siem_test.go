@@ -0,0 +1,41 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestSIEMSink(t *testing.T) {
+	s := NewTestStore(t)
+
+	sink := &model.SIEMSink{
+		Id:           model.NewSIEMSinkId(),
+		TeamId:       model.NewTeamId(),
+		CreationTime: time.Now().Truncate(time.Second).UTC(),
+		Name:         "Syslog",
+		Type:         model.SIEMSinkTypeSyslog,
+		Address:      "siem.example.com:6514",
+		UseTLS:       true,
+	}
+	require.NoError(t, s.PutSIEMSink(context.Background(), sink))
+
+	got, err := s.GetSIEMSinkById(context.Background(), sink.Id)
+	require.NoError(t, err)
+	assert.Equal(t, sink, got)
+
+	sinks, err := s.GetSIEMSinksByTeamId(context.Background(), sink.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.SIEMSink{sink}, sinks)
+
+	require.NoError(t, s.DeleteSIEMSinkById(context.Background(), sink.Id))
+
+	got, err = s.GetSIEMSinkById(context.Background(), sink.Id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -171,6 +171,70 @@ func (s *Store) put(ctx context.Context, items ...any) error {
 	}
 }
 
+// Like put, but writes the items in non-transactional batches, so there's no limit on the number of
+// items.
+func (s *Store) batchPut(ctx context.Context, items ...any) error {
+	const batchSize = 25
+
+	for len(items) > 0 {
+		batch := items
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		items = items[len(batch):]
+
+		var ops []types.WriteRequest
+		for _, item := range batch {
+			attrs, err := attributevalue.MarshalMap(item)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, types.WriteRequest{
+				PutRequest: &types.PutRequest{
+					Item: attrs,
+				},
+			})
+		}
+		if _, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{
+				s.config.DynamoDB.TableName: ops,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Puts the item if the condition is met. Returns false if the condition wasn't met.
+func (s *Store) putWithCondition(ctx context.Context, item any, condition expression.ConditionBuilder) (bool, error) {
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	attrs, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		Item:                      attrs,
+		TableName:                 &s.config.DynamoDB.TableName,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}); err != nil {
+		var ccfe *types.ConditionalCheckFailedException
+		if errors.As(err, &ccfe) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 func createOrUpdateByPrimaryKey[T any](ctx context.Context, s *Store, hk []byte, update expression.UpdateBuilder) (*T, error) {
 	expr, err := expression.NewBuilder().WithUpdate(update).Build()
 	if err != nil {
@@ -210,7 +274,12 @@ func updateByPrimaryKey[T any](ctx context.Context, s *Store, hk []byte, update
 		return getByPrimaryKey[T](ctx, s, hk, ConsistencyStrongInRegion)
 	}
 
-	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(expression.AttributeExists(expression.Name("_hk"))).Build()
+	return updateByPrimaryKeyWithCondition[T](ctx, s, hk, update, expression.AttributeExists(expression.Name("_hk")))
+}
+
+// Updates the item if the condition is met. Returns nil if the condition wasn't met.
+func updateByPrimaryKeyWithCondition[T any](ctx context.Context, s *Store, hk []byte, update expression.UpdateBuilder, condition expression.ConditionBuilder) (*T, error) {
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
 	if err != nil {
 		return nil, err
 	}
@@ -585,6 +654,13 @@ func NewTTL(t time.Time) TTL {
 	}
 }
 
+// Formats times such that they sort lexicographically, for use in range keys.
+const sortableTimeLayout = "20060102150405.000000000"
+
+func sortableTimeKey(t time.Time) []byte {
+	return []byte(t.UTC().Format(sortableTimeLayout))
+}
+
 func prefixIds(prefix string, ids []model.Id) [][]byte {
 	m := make(map[model.Id]struct{}, len(ids))
 	ret := make([][]byte, 0, len(ids))
@@ -42,10 +42,22 @@ func (s *Store) GetTeams(ctx context.Context) ([]*model.Team, error) {
 	return getAllByHashKey[model.Team](ctx, s, "_bb1", "_bb1h", []byte("team_ids"))
 }
 
+func (s *Store) GetTeamPage(ctx context.Context, input PageInput) (*Page[model.Team], error) {
+	return getPageByHashKey[model.Team](ctx, s, getPageByHashKeyInput{
+		PageInput:    input,
+		Index:        "_bb1",
+		HashKeyName:  "_bb1h",
+		HashKey:      []byte("team_ids"),
+		RangeKeyName: "_bb1r",
+	})
+}
+
 type TeamPatch struct {
 	Name             *string
 	StripeCustomerId *string
 	Entitlements     *model.TeamEntitlements
+
+	RequireSecondFactor *bool
 }
 
 func (p *TeamPatch) Apply(update expression.UpdateBuilder) expression.UpdateBuilder {
@@ -58,6 +70,9 @@ func (p *TeamPatch) Apply(update expression.UpdateBuilder) expression.UpdateBuil
 	if p.Entitlements != nil {
 		update = update.Set(expression.Name("Entitlements"), expression.Value(p.Entitlements))
 	}
+	if p.RequireSecondFactor != nil {
+		update = update.Set(expression.Name("RequireSecondFactor"), expression.Value(p.RequireSecondFactor))
+	}
 	return update
 }
 
@@ -100,6 +115,16 @@ func (s *Store) GetTeamMembershipsByTeamId(ctx context.Context, teamId model.Id)
 	return getAllByHashKey[model.TeamMembership](ctx, s, "_bb1", "_bb1h", []byte("team_memberships:"+teamId))
 }
 
+func (s *Store) GetTeamMembershipPageByTeamId(ctx context.Context, teamId model.Id, input PageInput) (*Page[model.TeamMembership], error) {
+	return getPageByHashKey[model.TeamMembership](ctx, s, getPageByHashKeyInput{
+		PageInput:    input,
+		Index:        "_bb1",
+		HashKeyName:  "_bb1h",
+		HashKey:      []byte("team_memberships:" + teamId),
+		RangeKeyName: "_bb1r",
+	})
+}
+
 func (s *Store) GetTeamMembershipsByUserId(ctx context.Context, userId model.Id) ([]*model.TeamMembership, error) {
 	return getAllByHashKey[model.TeamMembership](ctx, s, "_bb2", "_bb2h", []byte("team_memberships:"+userId))
 }
@@ -109,13 +134,17 @@ func (s *Store) DeleteTeamMembershipByTeamAndUserId(ctx context.Context, teamId,
 }
 
 type TeamMembershipPatch struct {
-	Role *model.TeamMembershipRole
+	Role            *model.TeamMembershipRole
+	DigestFrequency *model.DigestFrequency
 }
 
 func (p *TeamMembershipPatch) Apply(update expression.UpdateBuilder) expression.UpdateBuilder {
 	if p.Role != nil {
 		update = update.Set(expression.Name("Role"), expression.Value(p.Role))
 	}
+	if p.DigestFrequency != nil {
+		update = update.Set(expression.Name("DigestFrequency"), expression.Value(p.DigestFrequency))
+	}
 	return update
 }
 
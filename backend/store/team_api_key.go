@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedTeamAPIKey struct {
+	*model.TeamAPIKey
+
+	PrimaryIndex
+	ByteByteIndex1
+
+	TTL
+}
+
+func (s *Store) PutTeamAPIKey(ctx context.Context, key *model.TeamAPIKey) error {
+	return s.put(ctx, &IndexedTeamAPIKey{
+		TeamAPIKey: key,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("team_api_key:" + key.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("team_api_keys:" + key.TeamId),
+			RangeKey: []byte(key.Id),
+		},
+		TTL: NewTTL(key.ExpirationTime),
+	})
+}
+
+func (s *Store) GetTeamAPIKeyById(ctx context.Context, id model.Id) (*model.TeamAPIKey, error) {
+	return getByPrimaryKey[model.TeamAPIKey](ctx, s, []byte("team_api_key:"+id), ConsistencyStrongInRegion)
+}
+
+func (s *Store) GetTeamAPIKeysByTeamId(ctx context.Context, teamId model.Id) ([]*model.TeamAPIKey, error) {
+	return getAllByHashKey[model.TeamAPIKey](ctx, s, "_bb1", "_bb1h", []byte("team_api_keys:"+teamId))
+}
+
+func (s *Store) UpdateTeamAPIKeyLastUseTime(ctx context.Context, id model.Id, t time.Time) (*model.TeamAPIKey, error) {
+	update := expression.Set(expression.Name("LastUseTime"), expression.Value(t))
+	return updateByPrimaryKey[model.TeamAPIKey](ctx, s, []byte("team_api_key:"+id), update)
+}
+
+func (s *Store) DeleteTeamAPIKeyById(ctx context.Context, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("team_api_key:"+id))
+}
@@ -0,0 +1,47 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamAPIKey(t *testing.T) {
+	s := NewTestStore(t)
+
+	key := &model.TeamAPIKey{
+		Id:             model.NewTeamAPIKeyId(),
+		TeamId:         model.NewTeamId(),
+		CreatorId:      model.NewUserId(),
+		CreationTime:   time.Now().Truncate(time.Second).UTC(),
+		ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		Name:           "CI",
+		Hash:           model.TokenHash(model.NewToken()),
+		Scopes:         []model.TeamAPIKeyScope{model.TeamAPIKeyScopeReadReports},
+	}
+	require.NoError(t, s.PutTeamAPIKey(context.Background(), key))
+
+	got, err := s.GetTeamAPIKeyById(context.Background(), key.Id)
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	keys, err := s.GetTeamAPIKeysByTeamId(context.Background(), key.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TeamAPIKey{key}, keys)
+
+	lastUseTime := time.Now().Truncate(time.Second).UTC()
+	got, err = s.UpdateTeamAPIKeyLastUseTime(context.Background(), key.Id, lastUseTime)
+	require.NoError(t, err)
+	assert.Equal(t, lastUseTime, got.LastUseTime.UTC())
+
+	require.NoError(t, s.DeleteTeamAPIKeyById(context.Background(), key.Id))
+
+	got, err = s.GetTeamAPIKeyById(context.Background(), key.Id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
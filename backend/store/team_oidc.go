@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedTeamOIDCConfiguration struct {
+	*model.TeamOIDCConfiguration
+
+	PrimaryIndex
+}
+
+func (s *Store) PutTeamOIDCConfiguration(ctx context.Context, config *model.TeamOIDCConfiguration) error {
+	return s.put(ctx, &IndexedTeamOIDCConfiguration{
+		TeamOIDCConfiguration: config,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("team_oidc_configuration:" + config.TeamId),
+			RangeKey: []byte("_"),
+		},
+	})
+}
+
+func (s *Store) GetTeamOIDCConfigurationByTeamId(ctx context.Context, teamId model.Id) (*model.TeamOIDCConfiguration, error) {
+	return getByPrimaryKey[model.TeamOIDCConfiguration](ctx, s, []byte("team_oidc_configuration:"+teamId), ConsistencyStrongInRegion)
+}
+
+func (s *Store) DeleteTeamOIDCConfigurationByTeamId(ctx context.Context, teamId model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("team_oidc_configuration:"+teamId))
+}
+
+type IndexedOIDCAuthenticationSession struct {
+	*model.OIDCAuthenticationSession
+
+	PrimaryIndex
+
+	TTL
+}
+
+func (s *Store) PutOIDCAuthenticationSession(ctx context.Context, session *model.OIDCAuthenticationSession) error {
+	return s.put(ctx, &IndexedOIDCAuthenticationSession{
+		OIDCAuthenticationSession: session,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("oidc_authentication_session:" + session.Id),
+			RangeKey: []byte("_"),
+		},
+		TTL: NewTTL(session.ExpirationTime),
+	})
+}
+
+func (s *Store) GetOIDCAuthenticationSessionById(ctx context.Context, id model.Id) (*model.OIDCAuthenticationSession, error) {
+	return getByPrimaryKey[model.OIDCAuthenticationSession](ctx, s, []byte("oidc_authentication_session:"+id), ConsistencyStrongInRegion)
+}
+
+func (s *Store) DeleteOIDCAuthenticationSessionById(ctx context.Context, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("oidc_authentication_session:"+id))
+}
+
+type IndexedUserOIDCIdentity struct {
+	*model.UserOIDCIdentity
+
+	PrimaryIndex
+}
+
+// Identities are scoped to teams so that one team's provider can never be used to sign in as a user
+// linked by another team's provider.
+func userOIDCIdentityHashKey(teamId model.Id, issuer, subject string) []byte {
+	return []byte(fmt.Sprintf("user_oidc_identity:%s:%d:%s:%s", teamId, len(issuer), issuer, subject))
+}
+
+func (s *Store) PutUserOIDCIdentity(ctx context.Context, identity *model.UserOIDCIdentity) error {
+	return s.put(ctx, &IndexedUserOIDCIdentity{
+		UserOIDCIdentity: identity,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  userOIDCIdentityHashKey(identity.TeamId, identity.Issuer, identity.Subject),
+			RangeKey: []byte("_"),
+		},
+	})
+}
+
+func (s *Store) GetUserOIDCIdentity(ctx context.Context, teamId model.Id, issuer, subject string) (*model.UserOIDCIdentity, error) {
+	return getByPrimaryKey[model.UserOIDCIdentity](ctx, s, userOIDCIdentityHashKey(teamId, issuer, subject), ConsistencyStrongInRegion)
+}
@@ -0,0 +1,82 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamOIDCConfiguration(t *testing.T) {
+	s := NewTestStore(t)
+
+	config := &model.TeamOIDCConfiguration{
+		TeamId:                model.NewTeamId(),
+		CreationTime:          time.Now().Truncate(time.Second).UTC(),
+		UpdateTime:            time.Now().Truncate(time.Second).UTC(),
+		Issuer:                "https://idp.example.com",
+		ClientId:              "client",
+		EncryptedClientSecret: []byte("secret"),
+		AllowedEmailDomains:   []string{"example.com"},
+		AutoJoinRole:          model.TeamMembershipRoleAnalyst,
+	}
+	require.NoError(t, s.PutTeamOIDCConfiguration(context.Background(), config))
+
+	got, err := s.GetTeamOIDCConfigurationByTeamId(context.Background(), config.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, config, got)
+
+	require.NoError(t, s.DeleteTeamOIDCConfigurationByTeamId(context.Background(), config.TeamId))
+
+	got, err = s.GetTeamOIDCConfigurationByTeamId(context.Background(), config.TeamId)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestOIDCAuthenticationSession(t *testing.T) {
+	s := NewTestStore(t)
+
+	session := &model.OIDCAuthenticationSession{
+		Id:             model.NewOIDCAuthenticationSessionId(),
+		TeamId:         model.NewTeamId(),
+		ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		Nonce:          "nonce",
+		CodeVerifier:   "verifier",
+	}
+	require.NoError(t, s.PutOIDCAuthenticationSession(context.Background(), session))
+
+	got, err := s.GetOIDCAuthenticationSessionById(context.Background(), session.Id)
+	require.NoError(t, err)
+	assert.Equal(t, session, got)
+
+	require.NoError(t, s.DeleteOIDCAuthenticationSessionById(context.Background(), session.Id))
+
+	got, err = s.GetOIDCAuthenticationSessionById(context.Background(), session.Id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestUserOIDCIdentity(t *testing.T) {
+	s := NewTestStore(t)
+
+	identity := &model.UserOIDCIdentity{
+		TeamId:       model.NewTeamId(),
+		Issuer:       "https://idp.example.com",
+		Subject:      "alice",
+		UserId:       model.NewUserId(),
+		CreationTime: time.Now().Truncate(time.Second).UTC(),
+	}
+	require.NoError(t, s.PutUserOIDCIdentity(context.Background(), identity))
+
+	got, err := s.GetUserOIDCIdentity(context.Background(), identity.TeamId, identity.Issuer, identity.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, identity, got)
+
+	got, err = s.GetUserOIDCIdentity(context.Background(), model.NewTeamId(), identity.Issuer, identity.Subject)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
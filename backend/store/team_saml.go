@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedTeamSAMLConfiguration struct {
+	*model.TeamSAMLConfiguration
+
+	PrimaryIndex
+}
+
+func (s *Store) PutTeamSAMLConfiguration(ctx context.Context, config *model.TeamSAMLConfiguration) error {
+	return s.put(ctx, &IndexedTeamSAMLConfiguration{
+		TeamSAMLConfiguration: config,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("team_saml_configuration:" + config.TeamId),
+			RangeKey: []byte("_"),
+		},
+	})
+}
+
+func (s *Store) GetTeamSAMLConfigurationByTeamId(ctx context.Context, teamId model.Id) (*model.TeamSAMLConfiguration, error) {
+	return getByPrimaryKey[model.TeamSAMLConfiguration](ctx, s, []byte("team_saml_configuration:"+teamId), ConsistencyStrongInRegion)
+}
+
+func (s *Store) DeleteTeamSAMLConfigurationByTeamId(ctx context.Context, teamId model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("team_saml_configuration:"+teamId))
+}
+
+type IndexedSAMLAuthenticationSession struct {
+	*model.SAMLAuthenticationSession
+
+	PrimaryIndex
+
+	TTL
+}
+
+func (s *Store) PutSAMLAuthenticationSession(ctx context.Context, session *model.SAMLAuthenticationSession) error {
+	return s.put(ctx, &IndexedSAMLAuthenticationSession{
+		SAMLAuthenticationSession: session,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("saml_authentication_session:" + session.Id),
+			RangeKey: []byte("_"),
+		},
+		TTL: NewTTL(session.ExpirationTime),
+	})
+}
+
+func (s *Store) GetSAMLAuthenticationSessionById(ctx context.Context, id model.Id) (*model.SAMLAuthenticationSession, error) {
+	return getByPrimaryKey[model.SAMLAuthenticationSession](ctx, s, []byte("saml_authentication_session:"+id), ConsistencyStrongInRegion)
+}
+
+func (s *Store) DeleteSAMLAuthenticationSessionById(ctx context.Context, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("saml_authentication_session:"+id))
+}
+
+type IndexedSAMLAssertionUse struct {
+	*model.SAMLAssertionUse
+
+	PrimaryIndex
+
+	TTL
+}
+
+// Creates the assertion use unless an unexpired use of the same assertion already exists. Returns
+// true if the use was created.
+func (s *Store) CreateSAMLAssertionUse(ctx context.Context, use *model.SAMLAssertionUse) (bool, error) {
+	now := attributevalue.UnixTime(time.Now())
+	condition := expression.AttributeNotExists(expression.Name("_hk")).Or(expression.Name("_ttl").LessThan(expression.Value(&now)))
+	return s.putWithCondition(ctx, &IndexedSAMLAssertionUse{
+		SAMLAssertionUse: use,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte(fmt.Sprintf("saml_assertion_use:%s:%s", use.TeamId, use.AssertionId)),
+			RangeKey: []byte("_"),
+		},
+		TTL: NewTTL(use.ExpirationTime),
+	}, condition)
+}
+
+type IndexedUserSAMLIdentity struct {
+	*model.UserSAMLIdentity
+
+	PrimaryIndex
+}
+
+// Like OIDC identities, SAML identities are scoped to teams.
+func userSAMLIdentityHashKey(teamId model.Id, idpEntityId, nameId string) []byte {
+	return []byte(fmt.Sprintf("user_saml_identity:%s:%d:%s:%s", teamId, len(idpEntityId), idpEntityId, nameId))
+}
+
+func (s *Store) PutUserSAMLIdentity(ctx context.Context, identity *model.UserSAMLIdentity) error {
+	return s.put(ctx, &IndexedUserSAMLIdentity{
+		UserSAMLIdentity: identity,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  userSAMLIdentityHashKey(identity.TeamId, identity.IdPEntityId, identity.NameId),
+			RangeKey: []byte("_"),
+		},
+	})
+}
+
+func (s *Store) GetUserSAMLIdentity(ctx context.Context, teamId model.Id, idpEntityId, nameId string) (*model.UserSAMLIdentity, error) {
+	return getByPrimaryKey[model.UserSAMLIdentity](ctx, s, userSAMLIdentityHashKey(teamId, idpEntityId, nameId), ConsistencyStrongInRegion)
+}
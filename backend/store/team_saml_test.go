@@ -0,0 +1,111 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamSAMLConfiguration(t *testing.T) {
+	s := NewTestStore(t)
+
+	config := &model.TeamSAMLConfiguration{
+		TeamId:         model.NewTeamId(),
+		CreationTime:   time.Now().Truncate(time.Second).UTC(),
+		UpdateTime:     time.Now().Truncate(time.Second).UTC(),
+		IdPMetadataXML: "<EntityDescriptor/>",
+		IdPEntityId:    "https://idp.example.com",
+		EmailAttribute: "email",
+		RoleAttribute:  "groups",
+		RoleMappings: []model.TeamSAMLRoleMapping{
+			{Value: "admins", Role: model.TeamMembershipRoleAdministrator},
+		},
+		AllowedEmailDomains: []string{"example.com"},
+		DefaultRole:         model.TeamMembershipRoleAnalyst,
+		AllowIdPInitiated:   true,
+	}
+	require.NoError(t, s.PutTeamSAMLConfiguration(context.Background(), config))
+
+	got, err := s.GetTeamSAMLConfigurationByTeamId(context.Background(), config.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, config, got)
+
+	require.NoError(t, s.DeleteTeamSAMLConfigurationByTeamId(context.Background(), config.TeamId))
+
+	got, err = s.GetTeamSAMLConfigurationByTeamId(context.Background(), config.TeamId)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSAMLAuthenticationSession(t *testing.T) {
+	s := NewTestStore(t)
+
+	session := &model.SAMLAuthenticationSession{
+		Id:             model.NewSAMLAuthenticationSessionId(),
+		TeamId:         model.NewTeamId(),
+		ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		RequestId:      "id-123",
+	}
+	require.NoError(t, s.PutSAMLAuthenticationSession(context.Background(), session))
+
+	got, err := s.GetSAMLAuthenticationSessionById(context.Background(), session.Id)
+	require.NoError(t, err)
+	assert.Equal(t, session, got)
+
+	require.NoError(t, s.DeleteSAMLAuthenticationSessionById(context.Background(), session.Id))
+
+	got, err = s.GetSAMLAuthenticationSessionById(context.Background(), session.Id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSAMLAssertionUse(t *testing.T) {
+	s := NewTestStore(t)
+
+	use := &model.SAMLAssertionUse{
+		TeamId:         model.NewTeamId(),
+		AssertionId:    "id-123",
+		ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+	}
+
+	created, err := s.CreateSAMLAssertionUse(context.Background(), use)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = s.CreateSAMLAssertionUse(context.Background(), use)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// Assertion ids are only unique per identity provider.
+	other := *use
+	other.TeamId = model.NewTeamId()
+	created, err = s.CreateSAMLAssertionUse(context.Background(), &other)
+	require.NoError(t, err)
+	assert.True(t, created)
+}
+
+func TestUserSAMLIdentity(t *testing.T) {
+	s := NewTestStore(t)
+
+	identity := &model.UserSAMLIdentity{
+		TeamId:       model.NewTeamId(),
+		IdPEntityId:  "https://idp.example.com",
+		NameId:       "alice",
+		UserId:       model.NewUserId(),
+		CreationTime: time.Now().Truncate(time.Second).UTC(),
+	}
+	require.NoError(t, s.PutUserSAMLIdentity(context.Background(), identity))
+
+	got, err := s.GetUserSAMLIdentity(context.Background(), identity.TeamId, identity.IdPEntityId, identity.NameId)
+	require.NoError(t, err)
+	assert.Equal(t, identity, got)
+
+	got, err = s.GetUserSAMLIdentity(context.Background(), model.NewTeamId(), identity.IdPEntityId, identity.NameId)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
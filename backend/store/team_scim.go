@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedTeamSCIMUser struct {
+	*model.TeamSCIMUser
+
+	PrimaryIndex
+	ByteByteIndex1
+}
+
+func (s *Store) PutTeamSCIMUser(ctx context.Context, user *model.TeamSCIMUser) error {
+	return s.put(ctx, &IndexedTeamSCIMUser{
+		TeamSCIMUser: user,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("team_scim_user:" + user.TeamId + ":" + user.UserId),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("team_scim_users:" + user.TeamId),
+			RangeKey: []byte(user.UserId),
+		},
+	})
+}
+
+func (s *Store) GetTeamSCIMUserByTeamAndUserId(ctx context.Context, teamId, userId model.Id) (*model.TeamSCIMUser, error) {
+	return getByPrimaryKey[model.TeamSCIMUser](ctx, s, []byte("team_scim_user:"+teamId+":"+userId), ConsistencyStrongInRegion)
+}
+
+func (s *Store) GetTeamSCIMUsersByTeamId(ctx context.Context, teamId model.Id) ([]*model.TeamSCIMUser, error) {
+	return getAllByHashKey[model.TeamSCIMUser](ctx, s, "_bb1", "_bb1h", []byte("team_scim_users:"+teamId))
+}
+
+func (s *Store) DeleteTeamSCIMUserByTeamAndUserId(ctx context.Context, teamId, userId model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("team_scim_user:"+teamId+":"+userId))
+}
+
+type IndexedTeamSCIMGroup struct {
+	*model.TeamSCIMGroup
+
+	PrimaryIndex
+	ByteByteIndex1
+}
+
+func (s *Store) PutTeamSCIMGroup(ctx context.Context, group *model.TeamSCIMGroup) error {
+	return s.put(ctx, &IndexedTeamSCIMGroup{
+		TeamSCIMGroup: group,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("team_scim_group:" + group.TeamId + ":" + group.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("team_scim_groups:" + group.TeamId),
+			RangeKey: []byte(group.Id),
+		},
+	})
+}
+
+func (s *Store) GetTeamSCIMGroupByTeamIdAndId(ctx context.Context, teamId, id model.Id) (*model.TeamSCIMGroup, error) {
+	return getByPrimaryKey[model.TeamSCIMGroup](ctx, s, []byte("team_scim_group:"+teamId+":"+id), ConsistencyStrongInRegion)
+}
+
+func (s *Store) GetTeamSCIMGroupsByTeamId(ctx context.Context, teamId model.Id) ([]*model.TeamSCIMGroup, error) {
+	return getAllByHashKey[model.TeamSCIMGroup](ctx, s, "_bb1", "_bb1h", []byte("team_scim_groups:"+teamId))
+}
+
+func (s *Store) DeleteTeamSCIMGroupByTeamIdAndId(ctx context.Context, teamId, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("team_scim_group:"+teamId+":"+id))
+}
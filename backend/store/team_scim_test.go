@@ -0,0 +1,80 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestTeamSCIMUser(t *testing.T) {
+	s := NewTestStore(t)
+
+	user := &model.TeamSCIMUser{
+		TeamId:       model.NewTeamId(),
+		UserId:       model.NewUserId(),
+		CreationTime: time.Now().Truncate(time.Second).UTC(),
+		UpdateTime:   time.Now().Truncate(time.Second).UTC(),
+		ExternalId:   "ext-123",
+		UserName:     "alice@example.com",
+		Active:       true,
+	}
+	require.NoError(t, s.PutTeamSCIMUser(context.Background(), user))
+
+	got, err := s.GetTeamSCIMUserByTeamAndUserId(context.Background(), user.TeamId, user.UserId)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	users, err := s.GetTeamSCIMUsersByTeamId(context.Background(), user.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TeamSCIMUser{user}, users)
+
+	users, err = s.GetTeamSCIMUsersByTeamId(context.Background(), model.NewTeamId())
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	require.NoError(t, s.DeleteTeamSCIMUserByTeamAndUserId(context.Background(), user.TeamId, user.UserId))
+
+	got, err = s.GetTeamSCIMUserByTeamAndUserId(context.Background(), user.TeamId, user.UserId)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestTeamSCIMGroup(t *testing.T) {
+	s := NewTestStore(t)
+
+	group := &model.TeamSCIMGroup{
+		Id:           model.NewTeamSCIMGroupId(),
+		TeamId:       model.NewTeamId(),
+		CreationTime: time.Now().Truncate(time.Second).UTC(),
+		UpdateTime:   time.Now().Truncate(time.Second).UTC(),
+		ExternalId:   "ext-123",
+		DisplayName:  "Engineers",
+		MemberIds:    []model.Id{model.NewUserId()},
+		Role:         model.TeamMembershipRoleAnalyst,
+	}
+	require.NoError(t, s.PutTeamSCIMGroup(context.Background(), group))
+
+	got, err := s.GetTeamSCIMGroupByTeamIdAndId(context.Background(), group.TeamId, group.Id)
+	require.NoError(t, err)
+	assert.Equal(t, group, got)
+
+	// Groups are only accessible via their own team.
+	got, err = s.GetTeamSCIMGroupByTeamIdAndId(context.Background(), model.NewTeamId(), group.Id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	groups, err := s.GetTeamSCIMGroupsByTeamId(context.Background(), group.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.TeamSCIMGroup{group}, groups)
+
+	require.NoError(t, s.DeleteTeamSCIMGroupByTeamIdAndId(context.Background(), group.TeamId, group.Id))
+
+	got, err = s.GetTeamSCIMGroupByTeamIdAndId(context.Background(), group.TeamId, group.Id)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 
 	"github.com/ccbrown/cloud-snitch/backend/model"
@@ -34,6 +36,8 @@ type UserPatch struct {
 	EmailAddress          *string
 	Role                  *model.UserRole
 	EncryptedPasswordHash *[]byte
+	TOTP                  *model.UserTOTP
+	SSOTeamIds            *[]model.Id
 
 	TermsOfServiceAgreement *model.UserAgreement
 	PrivacyPolicyAgreement  *model.UserAgreement
@@ -51,6 +55,12 @@ func (p *UserPatch) Apply(update expression.UpdateBuilder) expression.UpdateBuil
 	if p.EncryptedPasswordHash != nil {
 		update = update.Set(expression.Name("EncryptedPasswordHash"), expression.Value(p.EncryptedPasswordHash))
 	}
+	if p.TOTP != nil {
+		update = update.Set(expression.Name("TOTP"), expression.Value(*p.TOTP))
+	}
+	if p.SSOTeamIds != nil {
+		update = update.Set(expression.Name("SSOTeamIds"), expression.Value(*p.SSOTeamIds))
+	}
 	if p.TermsOfServiceAgreement != nil {
 		update = update.Set(expression.Name("TermsOfServiceAgreement"), expression.Value(*p.TermsOfServiceAgreement))
 	}
@@ -75,6 +85,17 @@ func (s *Store) GetUsers(ctx context.Context) ([]*model.User, error) {
 	return getAllByHashKey[model.User](ctx, s, "_bb1", "_bb1h", []byte("user_email_addresses"))
 }
 
+// Gets a page of users, ordered by email address.
+func (s *Store) GetUserPage(ctx context.Context, input PageInput) (*Page[model.User], error) {
+	return getPageByHashKey[model.User](ctx, s, getPageByHashKeyInput{
+		PageInput:    input,
+		Index:        "_bb1",
+		HashKeyName:  "_bb1h",
+		HashKey:      []byte("user_email_addresses"),
+		RangeKeyName: "_bb1r",
+	})
+}
+
 func (s *Store) GetUsersByEmailAddress(ctx context.Context, emailAddress string) ([]*model.User, error) {
 	return getAllByHashAndRangeKey[model.User](ctx, s, "_bb1", []byte("user_email_addresses"), []byte(strings.ToLower(emailAddress)))
 }
@@ -84,6 +105,19 @@ func (s *Store) PatchUserById(ctx context.Context, id model.Id, patch *UserPatch
 	return updateByPrimaryKey[model.User](ctx, s, []byte("user:"+id), update)
 }
 
+// Replaces the user's authenticator if its revision still matches the given one. Returns nil if
+// the user doesn't exist or the authenticator was modified since it was read.
+func (s *Store) PatchUserTOTPById(ctx context.Context, id model.Id, revision int64, totp *model.UserTOTP) (*model.User, error) {
+	revisionName := expression.Name("TOTP.Revision")
+	revisionCondition := revisionName.Equal(expression.Value(revision))
+	if revision == 0 {
+		// Authenticators added before revisions were tracked don't have one.
+		revisionCondition = revisionCondition.Or(expression.AttributeNotExists(revisionName))
+	}
+	update := (&UserPatch{TOTP: totp}).Apply(expression.UpdateBuilder{})
+	return updateByPrimaryKeyWithCondition[model.User](ctx, s, []byte("user:"+id), update, expression.AttributeExists(expression.Name("_hk")).And(revisionCondition))
+}
+
 type IndexedUserRegistrationToken struct {
 	*model.UserRegistrationToken
 
@@ -139,10 +173,43 @@ func (s *Store) GetUserAccessTokenByHash(ctx context.Context, hash []byte) (*mod
 	return getByPrimaryKey[model.UserAccessToken](ctx, s, append([]byte("user_access_token:"), hash...), ConsistencyStrongInRegion)
 }
 
+func (s *Store) GetUserAccessTokensByUserId(ctx context.Context, userId model.Id) ([]*model.UserAccessToken, error) {
+	return getAllByHashKey[model.UserAccessToken](ctx, s, "_bb1", "_bb1h", []byte("user_access_token:"+userId.String()))
+}
+
+type UserAccessTokenPatch struct {
+	LastUseTime    *time.Time
+	ExpirationTime *time.Time
+}
+
+func (p *UserAccessTokenPatch) Apply(update expression.UpdateBuilder) expression.UpdateBuilder {
+	if p.LastUseTime != nil {
+		update = update.Set(expression.Name("LastUseTime"), expression.Value(*p.LastUseTime))
+	}
+	if p.ExpirationTime != nil {
+		update = update.Set(expression.Name("ExpirationTime"), expression.Value(*p.ExpirationTime))
+		update = update.Set(expression.Name("_ttl"), expression.Value(attributevalue.UnixTime(*p.ExpirationTime)))
+	}
+	return update
+}
+
+func (s *Store) PatchUserAccessTokenByHash(ctx context.Context, hash []byte, patch *UserAccessTokenPatch) (*model.UserAccessToken, error) {
+	update := patch.Apply(expression.UpdateBuilder{})
+	return updateByPrimaryKey[model.UserAccessToken](ctx, s, append([]byte("user_access_token:"), hash...), update)
+}
+
 func (s *Store) DeleteUserAccessTokenByHash(ctx context.Context, hash []byte) error {
 	return deleteByPrimaryKey(ctx, s, append([]byte("user_access_token:"), hash...))
 }
 
+func (s *Store) DeleteUserAccessTokensByHashes(ctx context.Context, hashes ...[]byte) error {
+	hks := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		hks[i] = append([]byte("user_access_token:"), hash...)
+	}
+	return deleteByPrimaryKeys(ctx, s, hks...)
+}
+
 type IndexedUserEmailAuthenticationToken struct {
 	*model.UserEmailAuthenticationToken
 
@@ -3,6 +3,7 @@ package store_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -51,4 +52,110 @@ func TestUser(t *testing.T) {
 		assert.Len(t, users, 1)
 		assert.Equal(t, u, users[0])
 	})
+
+	t.Run("PatchTOTP", func(t *testing.T) {
+		u.TOTP = model.UserTOTP{
+			EncryptedSecret:    []byte("secret"),
+			RecoveryCodeHashes: [][]byte{[]byte("a"), []byte("b")},
+			LastTimeStep:       1,
+		}
+		newUser, err := s.PatchUserById(context.Background(), u.Id, &store.UserPatch{
+			TOTP: &u.TOTP,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, u, newUser)
+
+		u.TOTP = model.UserTOTP{}
+		newUser, err = s.PatchUserById(context.Background(), u.Id, &store.UserPatch{
+			TOTP: &u.TOTP,
+		})
+		require.NoError(t, err)
+		assert.False(t, newUser.TOTP.IsEnabled())
+		assert.Empty(t, newUser.TOTP.EncryptedSecret)
+	})
+
+	t.Run("PatchTOTPWithRevision", func(t *testing.T) {
+		totp := model.UserTOTP{
+			EncryptedSecret: []byte("secret"),
+			Revision:        1,
+		}
+		newUser, err := s.PatchUserTOTPById(context.Background(), u.Id, 0, &totp)
+		require.NoError(t, err)
+		require.NotNil(t, newUser)
+		assert.Equal(t, int64(1), newUser.TOTP.Revision)
+
+		// The revision no longer matches, so this should fail.
+		stale := totp
+		stale.Revision = 2
+		newUser, err = s.PatchUserTOTPById(context.Background(), u.Id, 0, &stale)
+		require.NoError(t, err)
+		assert.Nil(t, newUser)
+
+		newUser, err = s.PatchUserTOTPById(context.Background(), u.Id, 1, &stale)
+		require.NoError(t, err)
+		require.NotNil(t, newUser)
+		assert.Equal(t, int64(2), newUser.TOTP.Revision)
+
+		notAUser, err := s.PatchUserTOTPById(context.Background(), model.NewUserId(), 0, &totp)
+		require.NoError(t, err)
+		assert.Nil(t, notAUser)
+	})
+}
+
+func TestUserAccessToken(t *testing.T) {
+	s := NewTestStore(t)
+
+	userId := model.NewUserId()
+	tokens := []*model.UserAccessToken{
+		{
+			UserId:            userId,
+			Hash:              model.TokenHash(model.NewToken()),
+			ExpirationTime:    time.Now().Add(time.Hour),
+			CreationIPAddress: "192.0.2.1",
+			UserAgent:         "curl/8.0",
+		},
+		{
+			UserId:         userId,
+			Hash:           model.TokenHash(model.NewToken()),
+			ExpirationTime: time.Now().Add(time.Hour),
+		},
+	}
+	for _, token := range tokens {
+		require.NoError(t, s.PutUserAccessToken(context.Background(), token))
+	}
+
+	t.Run("GetByUserId", func(t *testing.T) {
+		got, err := s.GetUserAccessTokensByUserId(context.Background(), userId)
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+
+		got, err = s.GetUserAccessTokensByUserId(context.Background(), model.NewUserId())
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Patch", func(t *testing.T) {
+		lastUseTime := time.Now()
+		token, err := s.PatchUserAccessTokenByHash(context.Background(), tokens[0].Hash, &store.UserAccessTokenPatch{
+			LastUseTime: &lastUseTime,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, token)
+		assert.True(t, lastUseTime.Equal(token.LastUseTime))
+		assert.Equal(t, "curl/8.0", token.UserAgent)
+
+		token, err = s.PatchUserAccessTokenByHash(context.Background(), model.TokenHash(model.NewToken()), &store.UserAccessTokenPatch{
+			LastUseTime: &lastUseTime,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, token)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, s.DeleteUserAccessTokensByHashes(context.Background(), tokens[0].Hash, tokens[1].Hash))
+
+		got, err := s.GetUserAccessTokensByUserId(context.Background(), userId)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
 }
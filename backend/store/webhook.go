@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+type IndexedWebhook struct {
+	*model.Webhook
+
+	PrimaryIndex
+	ByteByteIndex1
+}
+
+func (s *Store) PutWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return s.put(ctx, &IndexedWebhook{
+		Webhook: webhook,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("webhook:" + webhook.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("webhooks:" + webhook.TeamId),
+			RangeKey: []byte(webhook.Id),
+		},
+	})
+}
+
+func (s *Store) GetWebhookById(ctx context.Context, id model.Id) (*model.Webhook, error) {
+	return getByPrimaryKey[model.Webhook](ctx, s, []byte("webhook:"+id), ConsistencyEventual)
+}
+
+func (s *Store) GetWebhooksByTeamId(ctx context.Context, teamId model.Id) ([]*model.Webhook, error) {
+	return getAllByHashKey[model.Webhook](ctx, s, "_bb1", "_bb1h", []byte("webhooks:"+teamId))
+}
+
+func (s *Store) DeleteWebhookById(ctx context.Context, id model.Id) error {
+	return deleteByPrimaryKey(ctx, s, []byte("webhook:"+id))
+}
+
+type IndexedWebhookDelivery struct {
+	*model.WebhookDelivery
+
+	PrimaryIndex
+	ByteByteIndex1
+
+	TTL
+}
+
+func (s *Store) PutWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return s.put(ctx, &IndexedWebhookDelivery{
+		WebhookDelivery: delivery,
+		PrimaryIndex: PrimaryIndex{
+			HashKey:  []byte("webhook_delivery:" + delivery.Id),
+			RangeKey: []byte("_"),
+		},
+		ByteByteIndex1: ByteByteIndex1{
+			HashKey:  []byte("webhook_deliveries:" + delivery.WebhookId),
+			RangeKey: []byte(delivery.Id),
+		},
+		TTL: NewTTL(delivery.ExpirationTime),
+	})
+}
+
+func (s *Store) GetWebhookDeliveriesByWebhookId(ctx context.Context, webhookId model.Id) ([]*model.WebhookDelivery, error) {
+	return getAllByHashKey[model.WebhookDelivery](ctx, s, "_bb1", "_bb1h", []byte("webhook_deliveries:"+webhookId))
+}
@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/cloud-snitch/backend/model"
+)
+
+func TestWebhook(t *testing.T) {
+	s := NewTestStore(t)
+
+	webhook := &model.Webhook{
+		Id:              model.NewWebhookId(),
+		TeamId:          model.NewTeamId(),
+		CreationTime:    time.Now().Truncate(time.Second).UTC(),
+		URL:             "https://example.com/webhook",
+		EncryptedSecret: []byte("secret"),
+		EventTypes:      []model.WebhookEventType{model.WebhookEventTypeReportGenerated},
+	}
+	require.NoError(t, s.PutWebhook(context.Background(), webhook))
+
+	got, err := s.GetWebhookById(context.Background(), webhook.Id)
+	require.NoError(t, err)
+	assert.Equal(t, webhook, got)
+
+	webhooks, err := s.GetWebhooksByTeamId(context.Background(), webhook.TeamId)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Webhook{webhook}, webhooks)
+
+	delivery := &model.WebhookDelivery{
+		Id:             model.NewWebhookDeliveryId(),
+		WebhookId:      webhook.Id,
+		CreationTime:   time.Now().Truncate(time.Second).UTC(),
+		ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		EventId:        model.NewWebhookEventId(),
+		EventType:      model.WebhookEventTypeReportGenerated,
+		Attempt:        1,
+		StatusCode:     200,
+		Duration:       time.Second,
+	}
+	require.NoError(t, s.PutWebhookDelivery(context.Background(), delivery))
+
+	deliveries, err := s.GetWebhookDeliveriesByWebhookId(context.Background(), webhook.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.WebhookDelivery{delivery}, deliveries)
+
+	require.NoError(t, s.DeleteWebhookById(context.Background(), webhook.Id))
+
+	webhooks, err = s.GetWebhooksByTeamId(context.Background(), webhook.TeamId)
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+}
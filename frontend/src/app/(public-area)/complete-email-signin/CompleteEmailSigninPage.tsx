@@ -3,11 +3,16 @@
 import { useRouter } from 'next/navigation';
 import React, { useEffect, useState } from 'react';
 
-import { ErrorMessage } from '@/components';
+import { Button, ErrorMessage, TextField } from '@/components';
+import { ApiError } from '@/models/api';
 import { useDispatch } from '@/store';
 
 export const CompleteEmailSigninPage = () => {
     const [errorMessage, setErrorMessage] = useState('');
+    const [token, setToken] = useState('');
+    const [totpCode, setTotpCode] = useState('');
+    const [isTotpCodeRequired, setIsTotpCodeRequired] = useState(false);
+    const [isBusy, setIsBusy] = useState(false);
 
     const router = useRouter();
     const dispatch = useDispatch();
@@ -15,16 +20,27 @@ export const CompleteEmailSigninPage = () => {
     useEffect(() => {
         const completeSignin = async () => {
             const params = new URLSearchParams(window.location.hash.slice(1));
+            const error = params.get('error');
+            if (error) {
+                setErrorMessage(error);
+                return;
+            }
+
             const token = params.get('token');
             if (!token) {
                 setErrorMessage('Invalid sign-in link.');
                 return;
             }
+            setToken(token);
 
             try {
                 await dispatch.api.signIn({ token });
                 router.push('/dashboard');
             } catch (err) {
+                if (err instanceof ApiError && err.code === 'SECOND_FACTOR_REQUIRED') {
+                    setIsTotpCodeRequired(true);
+                    return;
+                }
                 setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
             }
         };
@@ -32,9 +48,41 @@ export const CompleteEmailSigninPage = () => {
         completeSignin();
     }, [dispatch, router]);
 
+    const signInWithTotpCode = async () => {
+        if (isBusy) {
+            return;
+        }
+        setIsBusy(true);
+
+        try {
+            await dispatch.api.signIn({ token, totpCode });
+            router.push('/dashboard');
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
     return (
         <div className="translucent-snow max-w-4xl mx-auto rounded-xl p-4">
-            {errorMessage ? <ErrorMessage>{errorMessage}</ErrorMessage> : <p>Signing in...</p>}
+            {errorMessage && <ErrorMessage>{errorMessage}</ErrorMessage>}
+            {isTotpCodeRequired ? (
+                <form className="flex flex-col gap-4 max-w-md">
+                    <p>Please enter the code from your authenticator app or one of your recovery codes.</p>
+                    <TextField
+                        disabled={isBusy}
+                        label="Authenticator Code"
+                        autocomplete="one-time-code"
+                        onChange={setTotpCode}
+                        required
+                        value={totpCode}
+                    />
+                    <Button disabled={isBusy} label="Sign In" onClick={() => signInWithTotpCode()} type="submit" />
+                </form>
+            ) : (
+                !errorMessage && <p>Signing in...</p>
+            )}
         </div>
     );
 };
@@ -0,0 +1,44 @@
+'use client';
+
+import { useRouter } from 'next/navigation';
+import React, { useEffect, useState } from 'react';
+
+import { ErrorMessage } from '@/components';
+import { useDispatch } from '@/store';
+
+export const CompleteSsoSigninPage = () => {
+    const [errorMessage, setErrorMessage] = useState('');
+
+    const router = useRouter();
+    const dispatch = useDispatch();
+
+    useEffect(() => {
+        const completeSignin = async () => {
+            const params = new URLSearchParams(window.location.search);
+            const state = params.get('state');
+            const code = params.get('code');
+            if (params.get('error')) {
+                setErrorMessage(params.get('error_description') || 'Sign-in was cancelled or denied.');
+                return;
+            } else if (!state || !code) {
+                setErrorMessage('Invalid sign-in link.');
+                return;
+            }
+
+            try {
+                await dispatch.api.signIn({ state, code });
+                router.push('/dashboard');
+            } catch (err) {
+                setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+            }
+        };
+
+        completeSignin();
+    }, [dispatch, router]);
+
+    return (
+        <div className="translucent-snow max-w-4xl mx-auto rounded-xl p-4">
+            {errorMessage ? <ErrorMessage>{errorMessage}</ErrorMessage> : <p>Signing in...</p>}
+        </div>
+    );
+};
@@ -0,0 +1,15 @@
+import type { Metadata } from 'next';
+
+import { CompleteSsoSigninPage } from './CompleteSsoSigninPage';
+
+export const metadata: Metadata = {
+    title: 'Sign In',
+    robots: {
+        index: false,
+        follow: false,
+    },
+};
+
+const Page = () => <CompleteSsoSigninPage />;
+
+export default Page;
@@ -86,6 +86,27 @@ const FAQ = [
             </div>
         ),
     },
+    {
+        question: 'How do I upgrade my integration to the latest CloudFormation template?',
+        answer: (
+            <div>
+                <p>
+                    We occasionally publish new revisions of{' '}
+                    <Link href={INTEGRATION_TEMPLATE_URL} target="_blank" className="link">
+                        the CloudFormation template
+                    </Link>{' '}
+                    to support new features such as resource control policy management. Existing integrations keep
+                    working, but may not be able to use the new features until they&apos;re upgraded.
+                </p>
+                <p>
+                    To upgrade, update your existing integration stack in the CloudFormation console, choose to
+                    replace the existing template, and provide the new template URL. You can keep all of your
+                    parameters as they are. The role ARN doesn&apos;t change, so there&apos;s nothing to update in
+                    Cloud Snitch.
+                </p>
+            </div>
+        ),
+    },
     {
         question: 'Does Cloud Snitch only work with AWS?',
         answer: (
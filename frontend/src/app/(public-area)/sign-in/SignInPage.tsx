@@ -7,6 +7,7 @@ import { useRouter } from 'next/navigation';
 import { useEffect, useState } from 'react';
 
 import { Button, Dialog, ErrorMessage, SuccessMessage, TextField } from '@/components';
+import { ApiError } from '@/models/api';
 import { useDispatch, useSelector } from '@/store';
 
 const AccountRecoveryForm = () => {
@@ -66,6 +67,8 @@ export const SignInPage = () => {
     const [errorMessage, setErrorMessage] = useState('');
     const [emailAddress, setEmailAddress] = useState('');
     const [password, setPassword] = useState('');
+    const [totpCode, setTotpCode] = useState('');
+    const [isTotpCodeRequired, setIsTotpCodeRequired] = useState(false);
     const [isBusy, setIsBusy] = useState(false);
     const [isRecovering, setIsRecovering] = useState(false);
 
@@ -106,8 +109,12 @@ export const SignInPage = () => {
             await dispatch.api.signIn({
                 emailAddress,
                 password,
+                totpCode: totpCode || undefined,
             });
         } catch (err) {
+            if (err instanceof ApiError && err.code === 'SECOND_FACTOR_REQUIRED') {
+                setIsTotpCodeRequired(true);
+            }
             setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
         } finally {
             setIsBusy(false);
@@ -168,6 +175,16 @@ export const SignInPage = () => {
                                     required
                                     value={password}
                                 />
+                                {isTotpCodeRequired && (
+                                    <TextField
+                                        disabled={isBusy}
+                                        label="Authenticator Code"
+                                        autocomplete="one-time-code"
+                                        onChange={setTotpCode}
+                                        required
+                                        value={totpCode}
+                                    />
+                                )}
                                 <Button
                                     className="w-full"
                                     disabled={isBusy}
@@ -2,9 +2,10 @@
 
 import { create, parseCreationOptionsFromJSON } from '@github/webauthn-json/browser-ponyfill';
 import { PlusCircleIcon, TrashIcon } from '@heroicons/react/24/outline';
-import { useState } from 'react';
+import { useCallback, useEffect, useState } from 'react';
 
 import { Button, Dialog, ErrorMessage, TextField } from '@/components';
+import { BeginUserTOTPEnrollmentOutput, UserSession } from '@/generated/api';
 import { useCurrentUser, useCurrentUserPasskeys } from '@/hooks';
 import { useDispatch } from '@/store';
 
@@ -61,6 +62,155 @@ const NewPasskeyForm = (props: NewPasskeyFormProps) => {
     );
 };
 
+interface NewTotpFormProps {
+    onSuccess: () => void;
+}
+
+const NewTotpForm = (props: NewTotpFormProps) => {
+    const currentUser = useCurrentUser();
+    const dispatch = useDispatch();
+
+    const [enrollment, setEnrollment] = useState<BeginUserTOTPEnrollmentOutput | null>(null);
+    const [code, setCode] = useState('');
+    const [isBusy, setIsBusy] = useState(false);
+    const [errorMessage, setErrorMessage] = useState('');
+
+    const doBegin = async () => {
+        if (isBusy || !currentUser) {
+            return;
+        }
+        setIsBusy(true);
+        setErrorMessage('');
+
+        try {
+            setEnrollment(await dispatch.users.beginTotpEnrollment(currentUser.id));
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
+    const doComplete = async () => {
+        if (isBusy || !currentUser) {
+            return;
+        }
+        setIsBusy(true);
+        setErrorMessage('');
+
+        try {
+            await dispatch.users.completeTotpEnrollment({
+                userId: currentUser.id,
+                code,
+            });
+            props.onSuccess();
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+            setIsBusy(false);
+        }
+    };
+
+    return !enrollment ? (
+        <div className="flex flex-col">
+            {errorMessage && <ErrorMessage>{errorMessage}</ErrorMessage>}
+            <p>
+                Once enabled, you&apos;ll need a code from your authenticator app whenever you sign in with your
+                password.
+            </p>
+            <Button disabled={isBusy} label="Continue" onClick={doBegin} className="mt-4" />
+        </div>
+    ) : (
+        <form className="flex flex-col gap-2">
+            {errorMessage && <ErrorMessage>{errorMessage}</ErrorMessage>}
+            <p>
+                <a className="link" href={enrollment.provisioningUri}>
+                    Open this link
+                </a>{' '}
+                on a device with an authenticator app, or enter this secret into it manually:
+            </p>
+            <p className="font-mono break-all">{enrollment.secret}</p>
+            <p>
+                Save these recovery codes somewhere safe. Each one can be used once in place of a code if you lose
+                access to your authenticator. They won&apos;t be shown again.
+            </p>
+            <ul className="font-mono grid grid-cols-2">
+                {enrollment.recoveryCodes.map((c) => (
+                    <li key={c}>{c}</li>
+                ))}
+            </ul>
+            <TextField
+                disabled={isBusy}
+                label="Code from Authenticator"
+                autocomplete="one-time-code"
+                required
+                value={code}
+                onChange={setCode}
+            />
+            <Button
+                disabled={isBusy || !code}
+                label="Enable Authenticator App"
+                onClick={doComplete}
+                type="submit"
+                className="mt-2"
+            />
+        </form>
+    );
+};
+
+interface DeleteTotpFormProps {
+    onSuccess: () => void;
+}
+
+const DeleteTotpForm = (props: DeleteTotpFormProps) => {
+    const currentUser = useCurrentUser();
+    const dispatch = useDispatch();
+
+    const [code, setCode] = useState('');
+    const [isBusy, setIsBusy] = useState(false);
+    const [errorMessage, setErrorMessage] = useState('');
+
+    const doDelete = async () => {
+        if (isBusy || !currentUser) {
+            return;
+        }
+        setIsBusy(true);
+        setErrorMessage('');
+
+        try {
+            await dispatch.users.deleteTotp({
+                userId: currentUser.id,
+                code,
+            });
+            props.onSuccess();
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+            setIsBusy(false);
+        }
+    };
+
+    return (
+        <form className="flex flex-col gap-2">
+            {errorMessage && <ErrorMessage>{errorMessage}</ErrorMessage>}
+            <p>To remove your authenticator app, enter a code from it or one of your recovery codes.</p>
+            <TextField
+                disabled={isBusy}
+                label="Authenticator Code"
+                autocomplete="one-time-code"
+                required
+                value={code}
+                onChange={setCode}
+            />
+            <Button
+                disabled={isBusy || !code}
+                label="Remove Authenticator App"
+                onClick={doDelete}
+                type="submit"
+                className="mt-2"
+            />
+        </form>
+    );
+};
+
 interface NewPasswordFormProps {
     onSuccess: () => void;
 }
@@ -132,12 +282,114 @@ const NewPasswordForm = (props: NewPasswordFormProps) => {
     );
 };
 
+const Sessions = () => {
+    const currentUserId = useCurrentUser()?.id;
+    const dispatch = useDispatch();
+
+    const [sessions, setSessions] = useState<UserSession[] | null>(null);
+    const [errorMessage, setErrorMessage] = useState('');
+
+    const fetchSessions = useCallback(async () => {
+        if (!currentUserId) {
+            return;
+        }
+        try {
+            setSessions(await dispatch.users.fetchSessions(currentUserId));
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+        }
+    }, [currentUserId, dispatch]);
+
+    useEffect(() => {
+        fetchSessions();
+    }, [fetchSessions]);
+
+    const deleteSession = async (sessionId: string) => {
+        if (!currentUserId || !confirm('Are you sure you want to sign out this session?')) {
+            return;
+        }
+
+        try {
+            await dispatch.users.deleteSession({ userId: currentUserId, sessionId });
+            await fetchSessions();
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+        }
+    };
+
+    const signOutEverywhere = async () => {
+        if (!currentUserId || !confirm('Are you sure you want to sign out everywhere, including here?')) {
+            return;
+        }
+
+        try {
+            await dispatch.users.signOutEverywhere(currentUserId);
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+        }
+    };
+
+    return (
+        <>
+            <h2 className="mt-8 mb-4">Sessions</h2>
+            {errorMessage && <ErrorMessage>{errorMessage}</ErrorMessage>}
+            {!sessions ? (
+                <p>Loading...</p>
+            ) : (
+                <>
+                    <table className="w-full text-left">
+                        <thead className="uppercase text-sm text-english-violet">
+                            <tr>
+                                <th>Device</th>
+                                <th>IP Address</th>
+                                <th>Created</th>
+                                <th>Last Used</th>
+                                <th />
+                            </tr>
+                        </thead>
+                        <tbody>
+                            {sessions.map((session) => (
+                                <tr key={session.id}>
+                                    <td className="max-w-xs truncate" title={session.userAgent}>
+                                        {session.userAgent || 'Unknown'}
+                                        {session.current && <strong> (this session)</strong>}
+                                    </td>
+                                    <td>{session.ipAddress || 'Unknown'}</td>
+                                    <td>{session.creationTime.toLocaleDateString()}</td>
+                                    <td>{session.lastUseTime?.toLocaleString() || 'Unknown'}</td>
+                                    <td align="right" className="p-2">
+                                        {!session.current && (
+                                            <TrashIcon
+                                                className="h-[1.5rem] cursor-pointer hover:text-amethyst"
+                                                onClick={() => deleteSession(session.id)}
+                                            />
+                                        )}
+                                    </td>
+                                </tr>
+                            ))}
+                        </tbody>
+                    </table>
+                    <p className="mt-2">
+                        If you don&apos;t recognize a session, you can{' '}
+                        <span className="link" onClick={() => signOutEverywhere()}>
+                            sign out everywhere
+                        </span>
+                        . Changing your password also signs out all of your other sessions.
+                    </p>
+                </>
+            )}
+        </>
+    );
+};
+
 const Page = () => {
     const dispatch = useDispatch();
     const currentUser = useCurrentUser();
     const passkeys = useCurrentUserPasskeys();
     const [isCreatingPasskey, setIsCreatingPasskey] = useState(false);
     const [isSettingPassword, setIsSettingPassword] = useState(false);
+    const [isEnablingTotp, setIsEnablingTotp] = useState(false);
+    const [isDeletingTotp, setIsDeletingTotp] = useState(false);
     const [errorMessage, setErrorMessage] = useState('');
 
     const canDeletePasskey = (passkeys && passkeys.length > 1) || currentUser?.hasPassword;
@@ -213,6 +465,33 @@ const Page = () => {
                     </tbody>
                 </table>
             )}
+            <h2 className="mt-8 mb-4">Authenticator App</h2>
+            <Dialog isOpen={isEnablingTotp} onClose={() => setIsEnablingTotp(false)} title="Authenticator App">
+                <NewTotpForm onSuccess={() => setIsEnablingTotp(false)} />
+            </Dialog>
+            <Dialog isOpen={isDeletingTotp} onClose={() => setIsDeletingTotp(false)} title="Authenticator App">
+                <DeleteTotpForm onSuccess={() => setIsDeletingTotp(false)} />
+            </Dialog>
+            {!currentUser ? (
+                <p>Loading...</p>
+            ) : currentUser.hasTotp ? (
+                <p>
+                    Your authenticator app is <strong>enabled</strong> and is required when you sign in with your
+                    password. You can{' '}
+                    <span className="link" onClick={() => setIsDeletingTotp(true)}>
+                        click here
+                    </span>{' '}
+                    to remove it.
+                </p>
+            ) : (
+                <p>
+                    You don&apos;t currently have an authenticator app. If you use a password, we recommend{' '}
+                    <span className="link" onClick={() => setIsEnablingTotp(true)}>
+                        adding one
+                    </span>{' '}
+                    as a second factor.
+                </p>
+            )}
             <h2 className="mt-8 mb-4">Password</h2>
             <Dialog isOpen={isSettingPassword} onClose={() => setIsSettingPassword(false)} title="New Passkey">
                 <NewPasswordForm onSuccess={() => setIsSettingPassword(false)} />
@@ -249,6 +528,7 @@ const Page = () => {
                     </p>
                 </div>
             )}
+            <Sessions />
         </>
     );
 };
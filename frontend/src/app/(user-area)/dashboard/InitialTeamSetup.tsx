@@ -8,15 +8,31 @@ import { useDispatch } from '@/store';
 
 export const formatMembershipRole = (role: TeamMembershipRole) => {
     switch (role) {
-        case TeamMembershipRole.Member:
-            return 'Member';
         case TeamMembershipRole.Administrator:
             return 'Administrator';
+        case TeamMembershipRole.ScpManager:
+            return 'SCP Manager';
+        case TeamMembershipRole.Analyst:
+        case TeamMembershipRole.Member:
+            return 'Analyst';
+        case TeamMembershipRole.Billing:
+            return 'Billing';
+        case TeamMembershipRole.Viewer:
+            return 'Viewer';
         default:
             return 'Unknown';
     }
 };
 
+// The roles that can be assigned to team members, from most to least privileged.
+export const membershipRoleOptions = [
+    TeamMembershipRole.Administrator,
+    TeamMembershipRole.ScpManager,
+    TeamMembershipRole.Analyst,
+    TeamMembershipRole.Billing,
+    TeamMembershipRole.Viewer,
+].map((role) => ({ label: formatMembershipRole(role), value: role }));
+
 const JoinTeamForm = () => {
     const dispatch = useDispatch();
     const router = useRouter();
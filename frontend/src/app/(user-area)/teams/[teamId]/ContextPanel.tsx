@@ -11,16 +11,39 @@ import { Selection, useSelection } from './selection';
 import { Markdown, PrincipalIcon, TextArea, Tooltip } from '@/components';
 import {
     useAwsRegions,
+    useAwsServicesMap,
     useCurrentTeamId,
     useCurrentTeamPrincipalSettings,
     useSearchParamFilterState,
     useTeamAwsAccountsMap,
 } from '@/hooks';
-import { CombinedReport, formatPrincipalType } from '@/report';
+import { CombinedReport, EventSummary, formatPrincipalType } from '@/report';
 import { useDispatch, useSelector } from '@/store';
 
 const COLLAPSED_LIST_LENGTH = 5;
 
+interface AwsServiceInfoProps {
+    summary: EventSummary;
+}
+
+const AwsServiceInfo = (props: AwsServiceInfoProps) => {
+    const services = useAwsServicesMap();
+    // Newer reports include the service, but older ones need to be looked up in the catalogue.
+    const service =
+        props.summary.serviceNamespace && props.summary.serviceName
+            ? { namespace: props.summary.serviceNamespace, name: props.summary.serviceName }
+            : services.get(props.summary.source);
+    if (!service) {
+        return null;
+    }
+
+    return (
+        <div>
+            <strong>Service:</strong> {service.name} (<code>{service.namespace}</code>)
+        </div>
+    );
+};
+
 interface AwsRegionContextProps {
     id: string;
 }
@@ -440,6 +463,7 @@ const PrincipalContext = ({ id, combinedReport }: PrincipalContextProps) => {
                                         <div>
                                             <strong>Source:</strong> {summary.source}
                                         </div>
+                                        <AwsServiceInfo summary={summary} />
                                         <div>
                                             <Link
                                                 href={`https://console.aws.amazon.com/cloudtrailv2/home#/events?EventName=${summary.name}`}
@@ -8,7 +8,7 @@ import { useState } from 'react';
 import { Header as UserAreaHeader } from '../../Header';
 import { CreateTeamForm } from '../../dashboard/InitialTeamSetup';
 import { Dialog } from '@/components';
-import { TeamMembershipRole } from '@/generated/api';
+import { TeamPermission } from '@/generated/api';
 import { useCurrentTeam, useCurrentUserTeamMemberships } from '@/hooks';
 
 interface Props {
@@ -22,9 +22,9 @@ export const Header = (props: Props) => {
 
     const memberships = useCurrentUserTeamMemberships();
 
-    const isTeamAdmin = memberships?.some(
-        (m) => m.team.id === teamId && m.membership.role === TeamMembershipRole.Administrator,
-    );
+    const permissions = memberships?.find((m) => m.team.id === teamId)?.membership.permissions;
+    const canManageTeam = permissions?.includes(TeamPermission.ManageTeam);
+    const canManageBilling = permissions?.includes(TeamPermission.ManageBilling);
 
     return (
         <UserAreaHeader
@@ -42,11 +42,15 @@ export const Header = (props: Props) => {
                             anchor="bottom start"
                             className="flex flex-col translucent-snow rounded-lg border-1 border-platinum"
                         >
-                            {isTeamAdmin && (
+                            {(canManageTeam || canManageBilling) && (
                                 <div className="p-2 border-b border-platinum">
                                     <Link
                                         className="whitespace-nowrap flex items-center gap-2 cursor-pointer hover:bg-white/80 p-2 rounded-md"
-                                        href={`/teams/${teamId}/settings`}
+                                        href={
+                                            canManageTeam
+                                                ? `/teams/${teamId}/settings`
+                                                : `/teams/${teamId}/settings/billing`
+                                        }
                                     >
                                         <CogIcon className="h-[1.5rem]" />
                                         <span>Team Settings</span>
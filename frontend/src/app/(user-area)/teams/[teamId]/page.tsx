@@ -18,7 +18,7 @@ import {
     useTeamAwsAccountsMap,
     useTeamAwsIntegrations,
 } from '@/hooks';
-import { Report, TeamMembershipRole } from '@/generated/api';
+import { Report, TeamPermission } from '@/generated/api';
 import { useSelector } from '@/store';
 import { Header } from './Header';
 import { ContextPanel } from './ContextPanel';
@@ -41,7 +41,7 @@ const Page = () => {
         const memberships = state.users.currentUserId
             ? state.teams.userTeamMemberships[state.users.currentUserId]
             : undefined;
-        return memberships && memberships[teamId]?.role === TeamMembershipRole.Administrator;
+        return memberships && memberships[teamId]?.permissions.includes(TeamPermission.ManageTeam);
     });
 
     const [isRulesOpen, setIsRulesOpen] = useState(false);
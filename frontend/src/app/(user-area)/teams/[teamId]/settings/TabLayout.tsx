@@ -5,7 +5,8 @@ import Link from 'next/link';
 import React from 'react';
 
 import { TabLayout as TabLayoutImpl } from '@/components';
-import { useCurrentTeamId } from '@/hooks';
+import { TeamPermission } from '@/generated/api';
+import { useCurrentTeamId, useCurrentUserTeamMemberships } from '@/hooks';
 
 interface Props {
     children?: React.ReactNode;
@@ -13,6 +14,40 @@ interface Props {
 
 export const TabLayout = ({ children }: Props) => {
     const teamId = useCurrentTeamId();
+    const permissions = useCurrentUserTeamMemberships()?.find((m) => m.team.id === teamId)?.membership.permissions;
+
+    const tabs = [
+        {
+            title: 'General',
+            path: `/teams/${teamId}/settings`,
+            icon: CogIcon,
+            permission: TeamPermission.ManageTeam,
+        },
+        {
+            title: 'Members',
+            path: `/teams/${teamId}/settings/members`,
+            icon: UserGroupIcon,
+            permission: TeamPermission.ManageTeam,
+        },
+        {
+            title: 'Integrations',
+            path: `/teams/${teamId}/settings/integrations`,
+            icon: LinkIcon,
+            permission: TeamPermission.ManageTeam,
+        },
+        {
+            title: 'Data',
+            path: `/teams/${teamId}/settings/data`,
+            icon: CircleStackIcon,
+            permission: TeamPermission.ManageTeam,
+        },
+        {
+            title: 'Billing',
+            path: `/teams/${teamId}/settings/billing`,
+            icon: CreditCardIcon,
+            permission: TeamPermission.ManageBilling,
+        },
+    ];
 
     return (
         <TabLayoutImpl
@@ -24,33 +59,7 @@ export const TabLayout = ({ children }: Props) => {
                     </Link>
                 </div>
             }
-            tabs={[
-                {
-                    title: 'General',
-                    path: `/teams/${teamId}/settings`,
-                    icon: CogIcon,
-                },
-                {
-                    title: 'Members',
-                    path: `/teams/${teamId}/settings/members`,
-                    icon: UserGroupIcon,
-                },
-                {
-                    title: 'Integrations',
-                    path: `/teams/${teamId}/settings/integrations`,
-                    icon: LinkIcon,
-                },
-                {
-                    title: 'Data',
-                    path: `/teams/${teamId}/settings/data`,
-                    icon: CircleStackIcon,
-                },
-                {
-                    title: 'Billing',
-                    path: `/teams/${teamId}/settings/billing`,
-                    icon: CreditCardIcon,
-                },
-            ]}
+            tabs={tabs.filter((tab) => permissions?.includes(tab.permission))}
         >
             {children}
         </TabLayoutImpl>
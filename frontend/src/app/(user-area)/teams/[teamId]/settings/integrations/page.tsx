@@ -155,7 +155,7 @@ const CreateIntegrationForm = (props: CreateIntegrationFormProps) => {
                             checked={manageScps}
                             onChange={setManageScps}
                             label="Enable SCP management"
-                            subLabel="If you're deploying to an organization management account, checking this box will allow Cloud Snitch to enforce access controls through service control policies. For example, Cloud Snitch can be configured to block activity for services and regions that you don't use. Cloud Snitch will only be able to block actions and will not be able to read or modify existing policies or grant additional access."
+                            subLabel="If you're deploying to an organization management account or a delegated administrator account, checking this box will allow Cloud Snitch to enforce access controls through service control policies. For example, Cloud Snitch can be configured to block activity for services and regions that you don't use. Cloud Snitch will only be able to block actions and will not be able to read or modify existing policies or grant additional access. For delegated administrator accounts, you'll also need to provide your management account id via the OrganizationManagementAccountId parameter."
                         />
                     </Tooltip>
                     <Button
@@ -4,6 +4,7 @@ import Link from 'next/link';
 import { PencilIcon, PlusCircleIcon } from '@heroicons/react/24/outline';
 import { useState } from 'react';
 
+import { formatMembershipRole, membershipRoleOptions } from '../../../../dashboard/InitialTeamSetup';
 import { Button, Dialog, ErrorMessage, Select, SuccessMessage, TextField } from '@/components';
 import { TeamMembershipRole, TeamTeamMembership } from '@/generated/api';
 import { useCurrentTeam, useCurrentTeamId, useCurrentTeamTeamMemberships, useCurrentUser } from '@/hooks';
@@ -21,7 +22,7 @@ const InviteMemberForm = (props: InviteMemberFormProps) => {
     const [errorMessage, setErrorMessage] = useState('');
 
     const [emailAddress, setEmailAddress] = useState('');
-    const [role, setRole] = useState<TeamMembershipRole>(TeamMembershipRole.Member);
+    const [role, setRole] = useState<TeamMembershipRole>(TeamMembershipRole.Analyst);
 
     const doInvite = async () => {
         if (isBusy) {
@@ -53,10 +54,7 @@ const InviteMemberForm = (props: InviteMemberFormProps) => {
                 label="Role"
                 value={role}
                 onChange={(value) => setRole(value as TeamMembershipRole)}
-                options={[
-                    { label: 'Member', value: TeamMembershipRole.Member },
-                    { label: 'Admin', value: TeamMembershipRole.Administrator },
-                ]}
+                options={membershipRoleOptions}
             />
             <Button disabled={isBusy} label="Invite Team Member" onClick={doInvite} type="submit" className="mt-4" />
         </form>
@@ -127,10 +125,7 @@ const EditMemberForm = ({ teamId, userId, onSuccess, current }: EditMemberFormPr
                 label="Role"
                 value={role}
                 onChange={(value) => setRole(value as TeamMembershipRole)}
-                options={[
-                    { label: 'Member', value: TeamMembershipRole.Member },
-                    { label: 'Admin', value: TeamMembershipRole.Administrator },
-                ]}
+                options={membershipRoleOptions}
             />
             <Button disabled={isBusy} label="Update Membership" onClick={doUpdate} type="submit" className="mt-4" />
             <div className="mt-4 text-sm flex items-center justify-center">
@@ -142,17 +137,6 @@ const EditMemberForm = ({ teamId, userId, onSuccess, current }: EditMemberFormPr
     );
 };
 
-const formatMembershipRole = (role: TeamMembershipRole) => {
-    switch (role) {
-        case TeamMembershipRole.Member:
-            return 'Member';
-        case TeamMembershipRole.Administrator:
-            return 'Administrator';
-        default:
-            return 'Unknown';
-    }
-};
-
 const Page = () => {
     const teamId = useCurrentTeamId();
     const currentUserId = useCurrentUser()?.id;
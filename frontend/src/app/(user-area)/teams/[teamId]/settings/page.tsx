@@ -51,8 +51,28 @@ const EditNameForm = ({ onSuccess, teamId, currentName }: EditNameFormProps) =>
 };
 
 const Page = () => {
+    const dispatch = useDispatch();
     const team = useCurrentTeam();
     const [isRenaming, setIsRenaming] = useState(false);
+    const [errorMessage, setErrorMessage] = useState('');
+
+    const setRequireSecondFactor = async (requireSecondFactor: boolean) => {
+        if (!team) {
+            return;
+        }
+        setErrorMessage('');
+
+        try {
+            await dispatch.teams.update({
+                teamId: team.id,
+                input: {
+                    requireSecondFactor,
+                },
+            });
+        } catch (err) {
+            setErrorMessage(err instanceof Error ? err.message : 'An unknown error occurred.');
+        }
+    };
 
     return (
         <div>
@@ -74,6 +94,27 @@ const Page = () => {
                     onClick={() => setIsRenaming(true)}
                 />
             </div>
+            <h3 className="label mt-4 mb-2">Two-Factor Authentication</h3>
+            {errorMessage && <ErrorMessage>{errorMessage}</ErrorMessage>}
+            {team?.requireSecondFactor ? (
+                <p>
+                    Members must sign in with an authenticator app, passkey, or the team&apos;s single sign-on to
+                    access this team. Members that signed in without one will need to sign in again. You can{' '}
+                    <span className="link" onClick={() => setRequireSecondFactor(false)}>
+                        click here
+                    </span>{' '}
+                    to stop requiring it.
+                </p>
+            ) : (
+                <p>
+                    Members can access this team without an authenticator app or passkey. You can{' '}
+                    <span className="link" onClick={() => setRequireSecondFactor(true)}>
+                        click here
+                    </span>{' '}
+                    to require one. Members will be unable to access the team until they add one and sign in with
+                    it.
+                </p>
+            )}
         </div>
     );
 };
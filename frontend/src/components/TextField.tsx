@@ -5,7 +5,7 @@ interface Props {
     label?: string;
     placeholder?: string;
     type?: 'text' | 'email' | 'password' | 'search';
-    autocomplete?: 'email' | 'current-password' | 'new-password';
+    autocomplete?: 'email' | 'current-password' | 'new-password' | 'one-time-code';
     required?: boolean;
     onChange?: (value: string) => void;
     value: string;
@@ -5,6 +5,7 @@ import {
     AWSAccount,
     AWSIntegration,
     AWSRegion,
+    AWSService,
     AWSSCP,
     Report,
     TeamBillingProfile,
@@ -326,6 +327,23 @@ export const useAwsRegionsMap = (): Map<string, AWSRegion> => {
     return useMemo(() => new Map(Object.entries(regions)), [regions]);
 };
 
+export const useAwsServicesMap = (): Map<string, AWSService> => {
+    const dispatch = useDispatch();
+    const services = useSelector((state) => state.aws.services);
+    const needsServices = Object.keys(services).length === 0;
+    const [didFetch, setDidFetch] = useState(false);
+    const isLoading = useSelector((state) => state.loading.effects.aws.fetchServices);
+
+    useEffect(() => {
+        if (needsServices && !isLoading && !didFetch) {
+            setDidFetch(true);
+            dispatch.aws.fetchServices();
+        }
+    }, [needsServices, dispatch, isLoading, didFetch]);
+
+    return useMemo(() => new Map(Object.entries(services)), [services]);
+};
+
 export const useModifySearchParams = (): ((updates: Record<string, string | null | undefined>) => void) => {
     const router = useRouter();
     const pathname = usePathname();
@@ -15,10 +15,12 @@ const getBasePath = () => process.env.NEXT_PUBLIC_API_URL || `${window.location.
 
 export class ApiError extends Error {
     status: number;
+    code?: string;
 
-    constructor(message: string, status: number) {
+    constructor(message: string, status: number, code?: string) {
         super(message);
         this.status = status;
+        this.code = code;
     }
 }
 
@@ -27,7 +29,7 @@ const middleware = [
         post: async (context: ResponseContext) => {
             if (context.response.status !== 200) {
                 const body = (await context.response.json()) as ModelError;
-                throw new ApiError(body.message, context.response.status);
+                throw new ApiError(body.message, context.response.status, body.code);
             }
             return context.response;
         },
@@ -5,10 +5,12 @@ import { apiConfiguration, ApiError } from './api';
 
 import {
     AwsApi,
+    AWSAccessReportJobStatus,
     AWSAccount,
     AWSIntegration,
     AWSRegion,
     AWSSCP,
+    AWSService,
     CreateAWSIntegrationInput,
     PutAWSSCPInput,
     UpdateAWSIntegrationInput,
@@ -18,6 +20,7 @@ interface AwsState {
     integrations: Record<string, AWSIntegration>;
     teamIntegrationIds: Record<string, string[]>;
     regions: Record<string, AWSRegion>;
+    services: Record<string, AWSService>;
     accounts: Record<string, AWSAccount>;
     teamAccountIds: Record<string, string[]>;
     managedScps: Record<string, AWSSCP | null>;
@@ -28,6 +31,7 @@ export const aws = createModel<RootModel>()({
         integrations: {},
         teamIntegrationIds: {},
         regions: {},
+        services: {},
         accounts: {},
         teamAccountIds: {},
         managedScps: {},
@@ -39,6 +43,9 @@ export const aws = createModel<RootModel>()({
         putRegion(state, region: AWSRegion) {
             state.regions[region.id] = region;
         },
+        putService(state, service: AWSService) {
+            state.services[service.eventSource] = service;
+        },
         putTeamIntegrationId(state, teamId: string, integrationId: string) {
             if (!state.teamIntegrationIds[teamId]) {
                 state.teamIntegrationIds[teamId] = [];
@@ -75,6 +82,13 @@ export const aws = createModel<RootModel>()({
                 dispatch.aws.putRegion(region);
             });
         },
+        async fetchServices(_payload: void, state) {
+            const api = new AwsApi(apiConfiguration(state.api));
+            const resp = await api.getAWSServices();
+            resp.forEach((service) => {
+                dispatch.aws.putService(service);
+            });
+        },
         async fetchIntegrationsByTeamId(teamId: string, state) {
             const api = new AwsApi(apiConfiguration(state.api));
             const resp = await api.getAWSIntegrationsByTeamId({
@@ -158,8 +172,15 @@ export const aws = createModel<RootModel>()({
         },
         async fetchAccessReportByTeamAndAccountId(payload: { teamId: string; accountId: string }, state) {
             const api = new AwsApi(apiConfiguration(state.api));
-            const resp = await api.getAWSAccessReport(payload);
-            return resp;
+            let job = await api.queueAWSAccessReportGeneration(payload);
+            while (job.status === AWSAccessReportJobStatus.InProgress) {
+                await new Promise((resolve) => setTimeout(resolve, 2000));
+                job = await api.getAWSAccessReportJob(payload);
+            }
+            if (!job.report) {
+                throw new Error(job.errorMessage || 'Failed to generate access report.');
+            }
+            return job.report;
         },
     }),
 });
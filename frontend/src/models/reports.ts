@@ -71,9 +71,17 @@ export const reports = createModel<RootModel>()({
     effects: (dispatch) => ({
         async fetchTeamReports(teamId: string, state) {
             const api = new TeamApi(apiConfiguration(state.api));
-            const resp = await api.getReportsByTeamId({
-                teamId,
-            });
+            const resp: Report[] = [];
+            let cursor: string | undefined;
+            do {
+                const page = await api.getReportsByTeamId({
+                    teamId,
+                    cursor,
+                    limit: 1000,
+                });
+                resp.push(...page.items);
+                cursor = page.nextCursor;
+            } while (cursor);
             resp.forEach((r) => {
                 dispatch.reports.put(r);
             });
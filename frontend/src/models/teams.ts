@@ -15,9 +15,11 @@ import {
     TeamInvite,
     TeamMembership,
     TeamMembershipRole,
+    TeamPermission,
     TeamPaymentMethod,
     TeamPrincipalSettings,
     TeamSubscription,
+    TeamTeamMembership,
     UpdateTeamInput,
     UpdateTeamBillingProfileInput,
     UpdateTeamMembershipInput,
@@ -141,10 +143,17 @@ export const teams = createModel<RootModel>()({
         },
         async fetchAll(_payload: void, state) {
             const api = new TeamApi(apiConfiguration(state.api));
-            const resp = await api.getTeams();
-            resp.forEach((team) => {
-                dispatch.teams.put(team);
-            });
+            let cursor: string | undefined;
+            do {
+                const page = await api.getTeams({
+                    cursor,
+                    limit: 1000,
+                });
+                page.items.forEach((team) => {
+                    dispatch.teams.put(team);
+                });
+                cursor = page.nextCursor;
+            } while (cursor);
         },
         async fetchBillingProfile(id: string, state) {
             const api = new TeamApi(apiConfiguration(state.api));
@@ -220,9 +229,17 @@ export const teams = createModel<RootModel>()({
         },
         async fetchMemberships(teamId: string, state) {
             const api = new TeamApi(apiConfiguration(state.api));
-            const resp = await api.getTeamMembershipsByTeamId({
-                teamId,
-            });
+            const resp: TeamTeamMembership[] = [];
+            let cursor: string | undefined;
+            do {
+                const page = await api.getTeamMembershipsByTeamId({
+                    teamId,
+                    cursor,
+                    limit: 1000,
+                });
+                resp.push(...page.items);
+                cursor = page.nextCursor;
+            } while (cursor);
             resp.forEach((m) => {
                 dispatch.users.put(m.user);
             });
@@ -270,6 +287,7 @@ export const teams = createModel<RootModel>()({
                     userId: state.users.currentUserId,
                     teamId: resp.id,
                     role: TeamMembershipRole.Administrator,
+                    permissions: Object.values(TeamPermission),
                 });
             }
             return resp;
@@ -142,6 +142,54 @@ export const users = createModel<RootModel>()({
             });
             dispatch.users.putPasskey(resp);
         },
+        async beginTotpEnrollment(userId: string, state) {
+            const api = new UserApi(apiConfiguration(state.api));
+            return await api.beginUserTOTPEnrollment({
+                body: {},
+                userId,
+            });
+        },
+        async completeTotpEnrollment(input: { userId: string; code: string }, state) {
+            const api = new UserApi(apiConfiguration(state.api));
+            const resp = await api.completeUserTOTPEnrollment({
+                userId: input.userId,
+                completeUserTOTPEnrollmentInput: {
+                    code: input.code,
+                },
+            });
+            dispatch.users.put(resp);
+        },
+        async deleteTotp(input: { userId: string; code: string }, state) {
+            const api = new UserApi(apiConfiguration(state.api));
+            const resp = await api.deleteUserTOTP({
+                userId: input.userId,
+                deleteUserTOTPInput: {
+                    code: input.code,
+                },
+            });
+            dispatch.users.put(resp);
+        },
+        async fetchSessions(userId: string, state) {
+            const api = new UserApi(apiConfiguration(state.api));
+            return await api.getUserSessions({
+                userId,
+            });
+        },
+        async deleteSession(input: { userId: string; sessionId: string }, state) {
+            const api = new UserApi(apiConfiguration(state.api));
+            await api.deleteUserSession({
+                userId: input.userId,
+                sessionId: input.sessionId,
+            });
+        },
+        async signOutEverywhere(userId: string, state) {
+            const api = new UserApi(apiConfiguration(state.api));
+            await api.signOutEverywhere({
+                userId,
+            });
+            dispatch.api.setAuth(undefined);
+            await dispatch({ type: 'RESET_ALL' });
+        },
         async deletePasskey(id: string, state) {
             const api = new UserApi(apiConfiguration(state.api));
             await api.deleteUserPasskeyById({
@@ -54,6 +54,8 @@ export interface Principal {
 export interface EventSummary {
     name: string;
     source: string;
+    serviceNamespace?: string;
+    serviceName?: string;
     count: number;
     errorCodes?: Record<string, number>;
 }
@@ -253,6 +255,8 @@ export class CombinedReportPrincipal {
             e = {
                 name: summary.name,
                 source: summary.source,
+                serviceNamespace: summary.serviceNamespace,
+                serviceName: summary.serviceName,
                 count: 0,
             };
             this.events.set(id, e);